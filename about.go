@@ -0,0 +1,168 @@
+package firefly
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ZanzyTHEbar/errbuilder-go"
+)
+
+// AboutModel describes the Firefly III server the client talks to
+type AboutModel struct {
+	Version    string
+	APIVersion string
+	OS         string
+	PHPVersion string
+	Driver     string
+}
+
+// Minimum server versions of the features that are not available on every Firefly III release
+var featureMinVersions = map[string]string{
+	"native currency": "6.2.0", // GET /currencies/native, formerly /currencies/default
+	"exchange rates":  "6.2.0", // GET /exchange-rates
+}
+
+// GetAbout retrieves the version information of the Firefly III server.
+// The result is cached for the lifetime of the client, separately for each WithToken token.
+func (c *FireflyClient) GetAbout(ctx context.Context) (*AboutModel, error) {
+	// The lock is not held during the request, so a slow server does not stall other callers;
+	// concurrent first calls may each fetch the information
+	scope := cacheScope(ctx)
+	c.aboutMu.Lock()
+	about, ok := c.abouts[scope]
+	c.aboutMu.Unlock()
+	if ok {
+		return &about, nil
+	}
+
+	// Call the API
+	resp, err := c.clientAPI.GetAboutWithResponse(ctx, &GetAboutParams{})
+	if err != nil {
+		return nil, APIErr("Failed to get server information", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseErr("About", resp.HTTPResponse, resp.Body)
+	}
+
+	// Convert API response to AboutModel
+	if resp.HTTPResponse == nil || len(resp.Body) == 0 {
+		return nil, APIErr("No server information found", fmt.Errorf("empty response"))
+	}
+
+	var apiResp SystemInfo
+	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
+		return nil, APIErr("Failed to parse server information response", err)
+	}
+
+	if apiResp.Data != nil {
+		about = AboutModel{
+			Version:    stringValue(apiResp.Data.Version),
+			APIVersion: stringValue(apiResp.Data.ApiVersion),
+			OS:         stringValue(apiResp.Data.Os),
+			PHPVersion: stringValue(apiResp.Data.PhpVersion),
+			Driver:     stringValue(apiResp.Data.Driver),
+		}
+	}
+
+	c.aboutMu.Lock()
+	if c.abouts == nil {
+		c.abouts = make(map[string]AboutModel)
+	}
+	c.abouts[scope] = about
+	c.aboutMu.Unlock()
+
+	result := about
+	return &result, nil
+}
+
+// RequireMinVersion returns an error wrapping ErrUnsupportedVersionSentinel if the server is older than version.
+// Servers reporting a version that is not numeric, such as development builds, are assumed to be recent.
+func (c *FireflyClient) RequireMinVersion(ctx context.Context, version string) error {
+	return c.requireVersion(ctx, "", version)
+}
+
+// requireFeature checks the server version for a feature listed in featureMinVersions.
+// The check only runs when ClientConfig.CheckServerVersion is set.
+func (c *FireflyClient) requireFeature(ctx context.Context, feature string) error {
+	if c.config == nil || !c.config.CheckServerVersion {
+		return nil
+	}
+	return c.requireVersion(ctx, feature, featureMinVersions[feature])
+}
+
+// requireVersion compares the server version with the required version of a feature
+func (c *FireflyClient) requireVersion(ctx context.Context, feature, required string) error {
+	want, ok := parseVersion(required)
+	if !ok {
+		var errs errbuilder.ErrorMap
+		errs.Set("version", fmt.Sprintf("Invalid version %q", required))
+		return ValidationErr("Version", errs)
+	}
+
+	about, err := c.GetAbout(ctx)
+	if err != nil {
+		return err
+	}
+	actual := about.Version
+	if actual == "" {
+		actual = about.APIVersion
+	}
+
+	have, ok := parseVersion(actual)
+	if !ok {
+		return nil
+	}
+	if compareVersions(have, want) < 0 {
+		return UnsupportedVersionErr(feature, required, actual)
+	}
+	return nil
+}
+
+// parseVersion parses a version such as "6.2.8" or "v6.2.8-beta.1" into its numeric parts.
+// Pre-release and build suffixes are ignored.
+func parseVersion(version string) ([]int, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+ "); i >= 0 {
+		version = version[:i]
+	}
+	if version == "" {
+		return nil, false
+	}
+
+	parts := strings.Split(version, ".")
+	numbers := make([]int, 0, len(parts))
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, true
+}
+
+// compareVersions compares two parsed versions, treating missing parts as zero
+func compareVersions(a, b []int) int {
+	for i := range max(len(a), len(b)) {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
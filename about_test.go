@@ -0,0 +1,141 @@
+package firefly
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZanzyTHEbar/fireflyiii-client-go/fireflytest"
+)
+
+// aboutJSON returns the server information of a Firefly III server running version
+func aboutJSON(version string) string {
+	return `{"data": {"version": "` + version + `", "api_version": "` + version + `", "php_version": "8.3.4", "os": "Linux", "driver": "pgsql"}}`
+}
+
+// TestGetAbout tests fetching and caching the server information
+func TestGetAbout(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/about", http.StatusOK, aboutJSON("6.2.8"))
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	about, err := client.GetAbout(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, AboutModel{Version: "6.2.8", APIVersion: "6.2.8", OS: "Linux", PHPVersion: "8.3.4", Driver: "pgsql"}, *about)
+
+	_, err = client.GetAbout(ctx)
+	require.NoError(t, err)
+	assert.Len(t, server.Requests(), 1, "the server information is cached")
+}
+
+// TestGetAboutSlowServer tests that a slow server information request does not block other callers
+func TestGetAboutSlowServer(t *testing.T) {
+	release := make(chan struct{})
+	var calls atomic.Int32
+	server := fireflytest.NewServer(t)
+	server.HandleFunc(http.MethodGet, "/v1/about", func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			<-release
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(aboutJSON("6.2.8")))
+	})
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	slow := make(chan error, 1)
+	go func() {
+		_, err := client.GetAbout(ctx)
+		slow <- err
+	}()
+	require.Eventually(t, func() bool { return calls.Load() == 1 }, 5*time.Second, time.Millisecond)
+
+	// A feature check completes while the first request is still waiting
+	require.NoError(t, client.RequireMinVersion(ctx, "6.2.0"))
+
+	close(release)
+	require.NoError(t, <-slow)
+	assert.Len(t, server.Requests(), 2)
+}
+
+// TestRequireMinVersion tests gating features on the server version
+func TestRequireMinVersion(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		server      string
+		required    string
+		unsupported bool
+	}{
+		{server: "6.2.8", required: "6.2.0"},
+		{server: "6.2.8", required: "6.2.8"},
+		{server: "6.2", required: "6.2.0"},
+		{server: "v6.10.0", required: "6.9.1"},
+		{server: "6.1.24", required: "6.2.0", unsupported: true},
+		{server: "5.7.18", required: "6", unsupported: true},
+		{server: "6.2.0-beta.1", required: "6.2.1", unsupported: true},
+		{server: "develop/2024-05-01", required: "6.2.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.server+" requires "+tt.required, func(t *testing.T) {
+			server := fireflytest.NewServer(t)
+			server.Handle(http.MethodGet, "/v1/about", http.StatusOK, aboutJSON(tt.server))
+
+			client, err := NewFireflyClient(server.URL, "test-token")
+			require.NoError(t, err)
+
+			err = client.RequireMinVersion(ctx, tt.required)
+			if !tt.unsupported {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrUnsupportedVersionSentinel))
+			assert.Contains(t, err.Error(), "Unsupported on this server version")
+			assert.Contains(t, err.Error(), tt.server)
+		})
+	}
+
+	t.Run("invalid version", func(t *testing.T) {
+		client, err := NewFireflyClient("http://localhost", "test-token")
+		require.NoError(t, err)
+		assert.Error(t, client.RequireMinVersion(ctx, "latest"))
+	})
+}
+
+// TestVersionGatedFeature tests that version dependent methods fail early on a too old server
+func TestVersionGatedFeature(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/about", http.StatusOK, aboutJSON("6.1.24"))
+	server.Handle(http.MethodGet, "/v1/currencies/native", http.StatusOK, `{"data": {"id": "1", "type": "currencies", "attributes": {"code": "EUR", "name": "Euro", "symbol": "€"}}}`)
+	ctx := context.Background()
+
+	config := DefaultClientConfig().WithServerVersionCheck()
+	config.BaseURL = server.URL
+	client, err := NewFireflyClientWithConfig(config)
+	require.NoError(t, err)
+
+	_, err = client.GetDefaultCurrency(ctx)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrUnsupportedVersionSentinel)
+	assert.Empty(t, server.RequestsTo(http.MethodGet, "/v1/currencies/native"))
+
+	// Without the check the request is sent as before
+	unchecked, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	currency, err := unchecked.GetDefaultCurrency(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", currency.Code)
+}
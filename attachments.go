@@ -0,0 +1,440 @@
+package firefly
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/ZanzyTHEbar/errbuilder-go"
+)
+
+// attachableTypeCategory links attachments to categories. The API spec does not list it,
+// but Firefly III accepts it like the other attachable types.
+const attachableTypeCategory AttachableType = "Category"
+
+// AttachmentUpload describes a file to attach to a Firefly III object
+type AttachmentUpload struct {
+	AttachableType AttachableType // Kind of object the file is attached to
+	AttachableID   string         // ID of the object the file is attached to
+	Filename       string
+	Content        []byte
+	Title          string
+	Notes          string
+	ContentType    string // MIME type of Content, detected from the content and filename if empty
+}
+
+// contentType returns the MIME type of the upload, detecting it from the content and falling back
+// to the filename extension for content the detection does not recognize
+func (u AttachmentUpload) contentType() string {
+	if u.ContentType != "" {
+		return u.ContentType
+	}
+
+	detected := http.DetectContentType(u.Content)
+	if detected == "application/octet-stream" {
+		if byExtension := mime.TypeByExtension(filepath.Ext(u.Filename)); byExtension != "" {
+			return byExtension
+		}
+	}
+	return detected
+}
+
+// validateUpload validates an upload against the attachment limits of the client configuration
+func (c *FireflyClient) validateUpload(upload AttachmentUpload) errbuilder.ErrorMap {
+	errs := validateAttachment(upload.Filename, upload.Content, upload.Title)
+	if c.config == nil {
+		return errs
+	}
+
+	if c.config.MaxAttachmentSize > 0 && int64(len(upload.Content)) > c.config.MaxAttachmentSize {
+		errs.Set("file", fmt.Sprintf("File is %d bytes, the maximum is %d bytes", len(upload.Content), c.config.MaxAttachmentSize))
+	}
+	if len(upload.Content) > 0 && len(c.config.AllowedAttachmentTypes) > 0 {
+		contentType := upload.contentType()
+		if !attachmentTypeAllowed(contentType, c.config.AllowedAttachmentTypes) {
+			errs.Set("content_type", fmt.Sprintf("Content type %s is not allowed", contentType))
+		}
+	}
+
+	return errs
+}
+
+// attachmentTypeAllowed reports whether a MIME type matches one of the allowed types.
+// Parameters such as the charset are ignored and "type/*" matches any subtype.
+func attachmentTypeAllowed(contentType string, allowed []string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, allowedType := range allowed {
+		allowedType = strings.ToLower(strings.TrimSpace(allowedType))
+		if allowedType == mediaType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(allowedType, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyingReader checks the content read from an attachment download against the stored hash
+// and fails the final read on a mismatch
+type verifyingReader struct {
+	io.ReadCloser
+	attachmentID string
+	want         string
+	hash         hash.Hash
+}
+
+// newVerifyingReader wraps body so that reading it verifies the content against a hex encoded digest.
+// The algorithm follows from the digest length. Unknown digests are not verified.
+func newVerifyingReader(body io.ReadCloser, attachmentID, digest string) io.ReadCloser {
+	var h hash.Hash
+	switch len(digest) {
+	case md5.Size * 2:
+		h = md5.New()
+	case sha1.Size * 2:
+		h = sha1.New()
+	case sha256.Size * 2:
+		h = sha256.New()
+	default:
+		return body
+	}
+	return &verifyingReader{ReadCloser: body, attachmentID: attachmentID, want: strings.ToLower(digest), hash: h}
+}
+
+func (r *verifyingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.hash.Write(p[:n])
+	if err == io.EOF {
+		if got := hex.EncodeToString(r.hash.Sum(nil)); got != r.want {
+			return n, AttachmentIntegrityErr(r.attachmentID, r.want, got)
+		}
+	}
+	return n, err
+}
+
+// verifyAttachments reports whether downloads are checked against the stored hash
+func (c *FireflyClient) verifyAttachments() bool {
+	return c.config != nil && c.config.VerifyAttachments
+}
+
+// attachmentModelFromRead converts an API attachment to an AttachmentModel
+func attachmentModelFromRead(attachmentRead AttachmentRead) AttachmentModel {
+	hash := stringValue(attachmentRead.Attributes.Hash)
+	if hash == "" {
+		hash = stringValue(attachmentRead.Attributes.Md5)
+	}
+
+	return AttachmentModel{
+		ID:          attachmentRead.Id,
+		Filename:    attachmentRead.Attributes.Filename,
+		Title:       stringValue(attachmentRead.Attributes.Title),
+		Notes:       stringValue(attachmentRead.Attributes.Notes),
+		Size:        int32Value(attachmentRead.Attributes.Size),
+		MimeType:    stringValue(attachmentRead.Attributes.Mime),
+		CreatedAt:   timeValue(attachmentRead.Attributes.CreatedAt),
+		UpdatedAt:   timeValue(attachmentRead.Attributes.UpdatedAt),
+		DownloadURL: stringValue(attachmentRead.Attributes.DownloadUrl),
+		Hash:        hash,
+	}
+}
+
+// attachmentsFromBody parses an attachment list response
+func attachmentsFromBody(resp *http.Response, body []byte) ([]AttachmentModel, error) {
+	if resp == nil || len(body) == 0 {
+		return []AttachmentModel{}, nil
+	}
+
+	var apiResp AttachmentArray
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, APIErr("Failed to parse attachments response", err)
+	}
+
+	attachments := make([]AttachmentModel, 0, len(apiResp.Data))
+	for _, attachmentRead := range apiResp.Data {
+		attachments = append(attachments, attachmentModelFromRead(attachmentRead))
+	}
+	return attachments, nil
+}
+
+// GetAttachment retrieves the metadata of an attachment
+func (c *FireflyClient) GetAttachment(ctx context.Context, attachmentID string) (*AttachmentModel, error) {
+	// Call the API
+	resp, err := c.clientAPI.GetAttachmentWithResponse(ctx, attachmentID, &GetAttachmentParams{})
+	if err != nil {
+		return nil, APIErr("Failed to get attachment", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseErr("Attachment", resp.HTTPResponse, resp.Body)
+	}
+
+	// Parse API response
+	if resp.HTTPResponse == nil || len(resp.Body) == 0 {
+		return nil, APIErr("No attachment data found", fmt.Errorf("empty response"))
+	}
+
+	var apiResp AttachmentSingle
+	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
+		return nil, APIErr("Failed to parse attachment response", err)
+	}
+
+	attachment := attachmentModelFromRead(apiResp.Data)
+	return &attachment, nil
+}
+
+// AddAttachment attaches a file to a Firefly III object and uploads its content
+func (c *FireflyClient) AddAttachment(ctx context.Context, upload AttachmentUpload) (*AttachmentModel, error) {
+	// Validate attachment
+	if errs := c.validateUpload(upload); errs != nil {
+		return nil, AttachmentValidationErr(errs)
+	}
+
+	store := StoreAttachmentJSONRequestBody{
+		AttachableId:   upload.AttachableID,
+		AttachableType: upload.AttachableType,
+		Filename:       upload.Filename,
+		Title:          stringPtr(upload.Title),
+	}
+	if upload.Notes != "" {
+		store.Notes = stringPtr(upload.Notes)
+	}
+
+	// Create the attachment
+	resp, err := c.clientAPI.StoreAttachmentWithResponse(ctx, &StoreAttachmentParams{}, store)
+	if err != nil {
+		return nil, APIErr("Failed to create attachment", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusCreated {
+		return nil, responseErr("Attachment", resp.HTTPResponse, resp.Body)
+	}
+
+	var apiResp AttachmentSingle
+	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
+		return nil, APIErr("Failed to parse attachment response", err)
+	}
+	attachment := attachmentModelFromRead(apiResp.Data)
+
+	// Upload the content
+	contentType := upload.contentType()
+	result, err := c.clientAPI.UploadAttachmentWithBodyWithResponse(ctx, attachment.ID, &UploadAttachmentParams{}, contentType, bytes.NewReader(upload.Content))
+	if err != nil {
+		return nil, APIErr("Failed to upload attachment", err)
+	}
+
+	// Check response
+	if result.StatusCode() != http.StatusNoContent && result.StatusCode() != http.StatusOK {
+		return nil, responseErr("Attachment", result.HTTPResponse, result.Body)
+	}
+
+	if attachment.MimeType == "" {
+		attachment.MimeType = contentType
+	}
+	return &attachment, nil
+}
+
+// DownloadAttachment downloads the content of an attachment.
+// It returns the content and the filename of the attachment.
+// With ClientConfig.VerifyAttachments the content is checked against the stored hash.
+func (c *FireflyClient) DownloadAttachment(ctx context.Context, attachmentID string) ([]byte, string, error) {
+	attachment, err := c.GetAttachment(ctx, attachmentID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	stream, err := c.streamAttachment(ctx, attachmentID)
+	if err != nil {
+		return nil, "", err
+	}
+	if c.verifyAttachments() {
+		stream = newVerifyingReader(stream, attachmentID, attachment.Hash)
+	}
+	defer stream.Close()
+
+	content, err := io.ReadAll(stream)
+	if err != nil {
+		if errors.Is(err, ErrChecksumSentinel) {
+			return nil, "", err
+		}
+		return nil, "", APIErr("Failed to read attachment", err)
+	}
+
+	return content, attachment.Filename, nil
+}
+
+// StreamAttachment downloads the content of an attachment without buffering it.
+// The caller must close the returned reader. With ClientConfig.VerifyAttachments the
+// final read returns an error if the content does not match the stored hash.
+func (c *FireflyClient) StreamAttachment(ctx context.Context, attachmentID string) (io.ReadCloser, error) {
+	if !c.verifyAttachments() {
+		return c.streamAttachment(ctx, attachmentID)
+	}
+
+	attachment, err := c.GetAttachment(ctx, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := c.streamAttachment(ctx, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+	return newVerifyingReader(stream, attachmentID, attachment.Hash), nil
+}
+
+// streamAttachment requests the content of an attachment
+func (c *FireflyClient) streamAttachment(ctx context.Context, attachmentID string) (io.ReadCloser, error) {
+	// Call the API
+	resp, err := c.clientAPI.DownloadAttachment(ctx, attachmentID, &DownloadAttachmentParams{})
+	if err != nil {
+		return nil, APIErr("Failed to download attachment", err)
+	}
+
+	// Check response
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body := readErrorBody(resp)
+		return nil, responseErr("Attachment", resp, body)
+	}
+
+	return resp.Body, nil
+}
+
+// AddCategoryAttachment adds an attachment to a category
+func (c *FireflyClient) AddCategoryAttachment(ctx context.Context, categoryID string, filename string, file []byte, title, notes string) (*AttachmentModel, error) {
+	return c.AddAttachment(ctx, AttachmentUpload{
+		AttachableType: attachableTypeCategory,
+		AttachableID:   categoryID,
+		Filename:       filename,
+		Content:        file,
+		Title:          title,
+		Notes:          notes,
+	})
+}
+
+// GetCategoryAttachments retrieves all attachments of a category
+func (c *FireflyClient) GetCategoryAttachments(ctx context.Context, categoryID string) ([]AttachmentModel, error) {
+	// Call the API
+	resp, err := c.clientAPI.ListAttachmentByCategoryWithResponse(ctx, categoryID, &ListAttachmentByCategoryParams{})
+	if err != nil {
+		return nil, APIErr("Failed to list category attachments", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseErr("Category", resp.HTTPResponse, resp.Body)
+	}
+
+	return attachmentsFromBody(resp.HTTPResponse, resp.Body)
+}
+
+// DownloadCategoryAttachment downloads the content of an attachment.
+// It returns the content and the filename of the attachment.
+// With ClientConfig.VerifyAttachments the content is checked against the stored hash.
+func (c *FireflyClient) DownloadCategoryAttachment(ctx context.Context, attachmentID string) ([]byte, string, error) {
+	return c.DownloadAttachment(ctx, attachmentID)
+}
+
+// DeleteCategoryAttachment deletes an attachment
+func (c *FireflyClient) DeleteCategoryAttachment(ctx context.Context, attachmentID string) error {
+	// Call the API
+	resp, err := c.clientAPI.DeleteAttachmentWithResponse(ctx, attachmentID, &DeleteAttachmentParams{})
+	if err != nil {
+		return APIErr("Failed to delete attachment", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusNoContent {
+		return responseErr("Attachment", resp.HTTPResponse, resp.Body)
+	}
+
+	return nil
+}
+
+// UpdateCategoryAttachment updates the filename, title and notes of an attachment
+func (c *FireflyClient) UpdateCategoryAttachment(ctx context.Context, attachmentID string, filename, title, notes string) error {
+	update := UpdateAttachmentJSONRequestBody{
+		Notes: stringPtr(notes),
+	}
+	if filename != "" {
+		update.Filename = stringPtr(filename)
+	}
+	if title != "" {
+		update.Title = stringPtr(title)
+	}
+
+	// Call the API
+	resp, err := c.clientAPI.UpdateAttachmentWithResponse(ctx, attachmentID, &UpdateAttachmentParams{}, update)
+	if err != nil {
+		return APIErr("Failed to update attachment", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK {
+		return responseErr("Attachment", resp.HTTPResponse, resp.Body)
+	}
+
+	return nil
+}
+
+// GetTransactionAttachments retrieves all attachments of a transaction group
+func (c *FireflyClient) GetTransactionAttachments(ctx context.Context, transactionID string) ([]AttachmentModel, error) {
+	// Call the API
+	resp, err := c.clientAPI.ListAttachmentByTransactionWithResponse(ctx, transactionID, &ListAttachmentByTransactionParams{})
+	if err != nil {
+		return nil, APIErr("Failed to list transaction attachments", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseErr("Transaction", resp.HTTPResponse, resp.Body)
+	}
+
+	return attachmentsFromBody(resp.HTTPResponse, resp.Body)
+}
+
+// AddTransactionAttachment adds an attachment, such as a receipt, to a transaction group.
+// Firefly III links attachments to a split, so it is added to the first split of the group.
+func (c *FireflyClient) AddTransactionAttachment(ctx context.Context, transactionID string, filename string, file []byte, title, notes string) (*AttachmentModel, error) {
+	upload := AttachmentUpload{
+		AttachableType: AttachableTypeTransactionJournal,
+		Filename:       filename,
+		Content:        file,
+		Title:          title,
+		Notes:          notes,
+	}
+
+	// Validate attachment before looking up the transaction
+	if errs := c.validateUpload(upload); errs != nil {
+		return nil, AttachmentValidationErr(errs)
+	}
+
+	txRead, err := c.getTransactionRead(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(txRead.Attributes.Transactions) == 0 || stringValue(txRead.Attributes.Transactions[0].TransactionJournalId) == "" {
+		return nil, APIErr("Failed to add transaction attachment", fmt.Errorf("transaction %s has no splits", transactionID))
+	}
+	upload.AttachableID = stringValue(txRead.Attributes.Transactions[0].TransactionJournalId)
+
+	return c.AddAttachment(ctx, upload)
+}
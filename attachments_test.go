@@ -0,0 +1,256 @@
+package firefly
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZanzyTHEbar/fireflyiii-client-go/fireflytest"
+)
+
+// attachmentJSON is the API representation of a receipt attachment
+const attachmentJSON = `{
+	"type": "attachments",
+	"id": "31",
+	"attributes": {
+		"created_at": "2024-03-02T09:20:00+01:00",
+		"updated_at": "2024-03-02T09:20:00+01:00",
+		"attachable_id": "601",
+		"attachable_type": "TransactionJournal",
+		"md5": "0cc175b9c0f1b6a831c399e269772661",
+		"hash": "0cc175b9c0f1b6a831c399e269772661",
+		"filename": "receipt.pdf",
+		"download_url": "https://firefly.example.com/api/v1/attachments/31/download",
+		"upload_url": "https://firefly.example.com/api/v1/attachments/31/upload",
+		"title": "Receipt",
+		"notes": "Groceries",
+		"mime": "application/pdf",
+		"size": 1
+	}
+}`
+
+// TestTransactionAttachments tests listing, adding and downloading the attachments of a transaction
+func TestTransactionAttachments(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/transactions/512/attachments", http.StatusOK, `{"data": [`+attachmentJSON+`], "meta": {}}`)
+	server.Handle(http.MethodGet, "/v1/transactions/512", http.StatusOK, `{"data": {"id": "512", "type": "transactions", "attributes": {
+		"created_at": "2024-03-02T09:15:42+01:00",
+		"transactions": [{"transaction_journal_id": "601", "type": "withdrawal", "date": "2024-03-01T00:00:00+01:00", "amount": "42.50", "description": "Weekly groceries"}]
+	}}}`)
+	server.Handle(http.MethodPost, "/v1/attachments", http.StatusOK, `{"data": `+attachmentJSON+`}`)
+	server.HandleFunc(http.MethodPost, "/v1/attachments/31/upload", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server.Handle(http.MethodGet, "/v1/attachments/31", http.StatusOK, `{"data": `+attachmentJSON+`}`)
+	server.HandleFunc(http.MethodGet, "/v1/attachments/31/download", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		io.WriteString(w, "a")
+	})
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("list", func(t *testing.T) {
+		attachments, err := client.GetTransactionAttachments(ctx, "512")
+		require.NoError(t, err)
+		require.Len(t, attachments, 1)
+		assert.Equal(t, "31", attachments[0].ID)
+		assert.Equal(t, "receipt.pdf", attachments[0].Filename)
+		assert.Equal(t, "Receipt", attachments[0].Title)
+		assert.Equal(t, "application/pdf", attachments[0].MimeType)
+		assert.Equal(t, "0cc175b9c0f1b6a831c399e269772661", attachments[0].Hash)
+	})
+
+	t.Run("add", func(t *testing.T) {
+		attachment, err := client.AddTransactionAttachment(ctx, "512", "receipt.pdf", []byte("a"), "Receipt", "Groceries")
+		require.NoError(t, err)
+		assert.Equal(t, "31", attachment.ID)
+
+		stores := server.RequestsTo(http.MethodPost, "/v1/attachments")
+		require.Len(t, stores, 1)
+		var store AttachmentStore
+		require.NoError(t, stores[0].JSON(&store))
+		assert.Equal(t, AttachableTypeTransactionJournal, store.AttachableType)
+		assert.Equal(t, "601", store.AttachableId, "attachments link to the split's journal")
+		assert.Equal(t, "receipt.pdf", store.Filename)
+
+		uploads := server.RequestsTo(http.MethodPost, "/v1/attachments/31/upload")
+		require.Len(t, uploads, 1)
+		assert.Equal(t, []byte("a"), uploads[0].Body)
+	})
+
+	t.Run("download", func(t *testing.T) {
+		content, filename, err := client.DownloadAttachment(ctx, "31")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("a"), content)
+		assert.Equal(t, "receipt.pdf", filename)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		server.Reset()
+		_, err := client.AddTransactionAttachment(ctx, "512", "", nil, "", "")
+		assert.Error(t, err)
+		assert.Empty(t, server.Requests())
+	})
+}
+
+// TestAttachmentContentType tests that uploads carry the detected or explicit MIME type
+func TestAttachmentContentType(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodPost, "/v1/attachments", http.StatusOK, `{"data": {"id": "32", "type": "attachments", "attributes": {"filename": "upload", "attachable_id": "5", "attachable_type": "Category"}}}`)
+	server.HandleFunc(http.MethodPost, "/v1/attachments/32/upload", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00")
+	tests := []struct {
+		name   string
+		upload func() (*AttachmentModel, error)
+		want   string
+	}{
+		{
+			name: "detected png",
+			upload: func() (*AttachmentModel, error) {
+				return client.AddCategoryAttachment(ctx, "5", "logo.bin", png, "Logo", "")
+			},
+			want: "image/png",
+		},
+		{
+			name: "by extension",
+			upload: func() (*AttachmentModel, error) {
+				return client.AddCategoryAttachment(ctx, "5", "export.json", []byte{0x00, 0x01}, "Export", "")
+			},
+			want: "application/json",
+		},
+		{
+			name: "explicit",
+			upload: func() (*AttachmentModel, error) {
+				return client.AddAttachment(ctx, AttachmentUpload{
+					AttachableType: AttachableTypeBill,
+					AttachableID:   "5",
+					Filename:       "statement.txt",
+					Content:        []byte("1,2,3"),
+					Title:          "Statement",
+					ContentType:    "text/csv",
+				})
+			},
+			want: "text/csv",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server.Reset()
+			attachment, err := tt.upload()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, attachment.MimeType)
+
+			uploads := server.RequestsTo(http.MethodPost, "/v1/attachments/32/upload")
+			require.Len(t, uploads, 1)
+			assert.Equal(t, tt.want, uploads[0].Header.Get("Content-Type"))
+		})
+	}
+}
+
+// TestAttachmentLimits tests that uploads exceeding the configured size or type limits fail before any request
+func TestAttachmentLimits(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodPost, "/v1/attachments", http.StatusOK, `{"data": {"id": "33", "type": "attachments", "attributes": {"filename": "upload", "attachable_id": "5", "attachable_type": "Category"}}}`)
+	server.Handle(http.MethodPost, "/v1/attachments/33/upload", http.StatusNoContent, "")
+
+	config := DefaultClientConfig().WithAttachmentLimits(16, "application/pdf", "image/*", "text/plain")
+	config.BaseURL = server.URL
+	client, err := NewFireflyClientWithConfig(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		filename string
+		content  []byte
+		field    string
+	}{
+		{name: "allowed", filename: "note.txt", content: []byte("paid in cash")},
+		{name: "wildcard", filename: "logo.png", content: []byte("\x89PNG\r\n\x1a\n")},
+		{name: "oversize", filename: "note.txt", content: []byte("this note is longer than sixteen bytes"), field: "File is 38 bytes, the maximum is 16 bytes"},
+		{name: "disallowed type", filename: "export.json", content: []byte{0x00, 0x01}, field: "Content type application/json is not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server.Reset()
+			_, err := client.AddCategoryAttachment(ctx, "5", tt.filename, tt.content, "Attachment", "")
+			if tt.field == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "Invalid Attachment Data")
+			assert.Contains(t, err.Error(), tt.field)
+			assert.Empty(t, server.Requests(), "invalid uploads must not reach the server")
+		})
+	}
+
+	t.Run("transaction", func(t *testing.T) {
+		server.Reset()
+		_, err := client.AddTransactionAttachment(ctx, "512", "export.json", []byte{0x00, 0x01}, "Export", "")
+		assert.Error(t, err)
+		assert.Empty(t, server.Requests(), "the transaction is not looked up for an invalid upload")
+	})
+}
+
+// TestAttachmentVerification tests that downloads are checked against the stored hash
+func TestAttachmentVerification(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/attachments/31", http.StatusOK, `{"data": `+attachmentJSON+`}`)
+	server.Handle(http.MethodGet, "/v1/attachments/31/download", http.StatusOK, "a")
+
+	config := DefaultClientConfig().WithAttachmentVerification()
+	config.BaseURL = server.URL
+	client, err := NewFireflyClientWithConfig(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("match", func(t *testing.T) {
+		data, filename, err := client.DownloadCategoryAttachment(ctx, "31")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("a"), data)
+		assert.Equal(t, "receipt.pdf", filename)
+	})
+
+	server.Handle(http.MethodGet, "/v1/attachments/31/download", http.StatusOK, "tampered")
+
+	t.Run("download mismatch", func(t *testing.T) {
+		_, _, err := client.DownloadCategoryAttachment(ctx, "31")
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrChecksumSentinel)
+		assert.Contains(t, err.Error(), "0cc175b9c0f1b6a831c399e269772661")
+	})
+
+	t.Run("stream mismatch", func(t *testing.T) {
+		stream, err := client.StreamAttachment(ctx, "31")
+		require.NoError(t, err)
+		defer stream.Close()
+
+		_, err = io.ReadAll(stream)
+		assert.ErrorIs(t, err, ErrChecksumSentinel)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		unverified, err := NewFireflyClient(server.URL, "test-token")
+		require.NoError(t, err)
+
+		data, _, err := unverified.DownloadCategoryAttachment(ctx, "31")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("tampered"), data)
+	})
+}
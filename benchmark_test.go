@@ -1,11 +1,19 @@
 package firefly
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // BenchmarkClientCreation benchmarks client creation performance
@@ -64,6 +72,116 @@ func BenchmarkContextCreation(b *testing.B) {
 	}
 }
 
+// transactionListBody returns a list response with n copies of the withdrawal fixture
+func transactionListBody(tb testing.TB, n int) []byte {
+	tb.Helper()
+
+	var single TransactionSingle
+	loadFixture(tb, "transaction_withdrawal.json", &single)
+
+	list := TransactionArray{Data: make([]TransactionRead, n)}
+	for i := range list.Data {
+		list.Data[i] = single.Data
+		list.Data[i].Id = strconv.Itoa(i + 1)
+	}
+	body, err := json.Marshal(list)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return body
+}
+
+// BenchmarkListTransactionsDecode benchmarks decoding a page of 1000 transactions into TransactionModels
+func BenchmarkListTransactionsDecode(b *testing.B) {
+	body := transactionListBody(b, 1000)
+	client, err := NewFireflyClient("https://demo.firefly-iii.org", "test-token")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(body)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var apiResp TransactionArray
+		if err := json.Unmarshal(body, &apiResp); err != nil {
+			b.Fatal(err)
+		}
+		if transactions := client.transactionModelsFromReads(apiResp.Data); len(transactions) != 1000 {
+			b.Fatalf("decoded %d transactions, want 1000", len(transactions))
+		}
+	}
+}
+
+// BenchmarkListResponseDecoding compares the memory use of decoding a 1000 transaction list
+// response after buffering it, as the generated client does, with decoding it while it is read
+func BenchmarkListResponseDecoding(b *testing.B) {
+	body := transactionListBody(b, 1000)
+	newResponse := func() *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/vnd.api+json"}},
+			Body:       io.NopCloser(bytes.NewReader(body)),
+		}
+	}
+
+	b.Run("buffered", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			resp, err := ParseListTransactionResponse(newResponse())
+			if err != nil {
+				b.Fatal(err)
+			}
+			var apiResp TransactionArray
+			if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("streaming", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var apiResp TransactionArray
+			if _, err := decodeListResponse(newResponse(), "Transaction", "transactions", &apiResp); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkImportDataSequential benchmarks many sequential CSV imports, as a bulk importer makes them
+func BenchmarkImportDataSequential(b *testing.B) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"imported":100}`))
+	}))
+	defer server.Close()
+
+	client, err := NewFireflyClient(server.URL+"/api", "test-token")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var csv bytes.Buffer
+	csv.WriteString("date,description,amount\n")
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&csv, "2024-01-%02d,Transaction %d,%d.50\n", i%28+1, i, i)
+	}
+	data := csv.Bytes()
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.ImportData(ctx, ImportTypeTransactions, ImportFormatCSV, data, &ImportOptions{DuplicateDetection: true}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 // TestBenchmarkValidation tests that benchmark functions work correctly
 func TestBenchmarkValidation(t *testing.T) {
 	// Test that benchmark functions don't panic
@@ -95,4 +213,26 @@ func TestBenchmarkValidation(t *testing.T) {
 			assert.Greater(t, result.N, 0)
 		})
 	})
+
+	t.Run("ImportDataSequential", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			result := testing.Benchmark(BenchmarkImportDataSequential)
+			assert.Greater(t, result.N, 0)
+		})
+	})
+
+	t.Run("ListTransactionsDecode", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("decoding benchmark skipped in short mode")
+		}
+
+		// About 41 allocations per transaction when this was written, the budget leaves room for
+		// small changes but catches per-field or per-split regressions
+		const maxAllocsPerTransaction = 60
+
+		result := testing.Benchmark(BenchmarkListTransactionsDecode)
+		require.Greater(t, result.N, 0)
+		t.Log(result.String())
+		assert.LessOrEqual(t, result.AllocsPerOp(), int64(1000*maxAllocsPerTransaction))
+	})
 }
@@ -102,11 +102,7 @@ var configShowCmd = &cobra.Command{
 
 		token := viper.GetString("token")
 		if token != "" {
-			if len(token) > 8 {
-				fmt.Printf("  Token: %s... (hidden)\n", token[:8])
-			} else {
-				fmt.Printf("  Token: %s... (hidden)\n", token)
-			}
+			fmt.Printf("  Token: (hidden)\n")
 		} else {
 			fmt.Printf("  Token: (not set)\n")
 		}
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	firefly "github.com/ZanzyTHEbar/fireflyiii-client-go"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// loginCmd represents the login command
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Log in to Firefly III using the OAuth2 device flow",
+	Long: `Obtain an access token without a browser redirect using the OAuth2 device authorization flow.
+
+You will be shown a code to enter on another device. Once approved, the access
+token is printed so it can be stored in your config file or FIREFLY_TOKEN.
+
+Examples:
+  firefly-client login --client-id=5 --device-auth-url=https://firefly.example.com/oauth/device/code --token-url=https://firefly.example.com/oauth/token`,
+	Run: func(cmd *cobra.Command, args []string) {
+		url := viper.GetString("firefly_url")
+		if url == "" {
+			log.Fatal("Firefly URL is required. Set it via --url flag, FIREFLY_URL environment variable, or config file.")
+		}
+
+		oauth2Config := firefly.OAuth2Config{
+			ClientID:      viper.GetString("oauth2.client_id"),
+			ClientSecret:  viper.GetString("oauth2.client_secret"),
+			DeviceAuthURL: viper.GetString("oauth2.device_auth_url"),
+			TokenURL:      viper.GetString("oauth2.token_url"),
+		}
+		if scopes := viper.GetString("oauth2.scopes"); scopes != "" {
+			oauth2Config.Scopes = strings.Fields(scopes)
+		}
+
+		config := firefly.DefaultClientConfig().WithOAuth2(oauth2Config)
+		config.BaseURL = url
+		config.AllowInsecure = viper.GetBool("allow_insecure")
+
+		client, err := firefly.NewFireflyClientWithConfig(config)
+		if err != nil {
+			log.Fatalf("Failed to create Firefly client: %v", err)
+		}
+
+		ctx := context.Background()
+		auth, err := client.StartDeviceAuth(ctx)
+		if err != nil {
+			log.Fatalf("Failed to start login: %v", err)
+		}
+
+		if auth.VerificationURIComplete != "" {
+			fmt.Printf("🔗 Open %s to approve this device\n", auth.VerificationURIComplete)
+		} else {
+			fmt.Printf("🔗 Open %s and enter the code: %s\n", auth.VerificationURI, auth.UserCode)
+		}
+		fmt.Println("⏳ Waiting for approval...")
+
+		token, err := client.PollDeviceToken(ctx, auth.DeviceCode)
+		if err != nil {
+			log.Fatalf("Login failed: %v", err)
+		}
+
+		fmt.Println("✅ Login successful!")
+		fmt.Printf("🔑 Access token: %s\n", token.AccessToken)
+		if token.RefreshToken != "" {
+			fmt.Printf("🔄 Refresh token: %s\n", token.RefreshToken)
+		}
+		fmt.Println("\n💡 Store the access token as 'token' in your config file or in FIREFLY_TOKEN")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(loginCmd)
+
+	loginCmd.Flags().String("client-id", "", "OAuth2 client ID")
+	loginCmd.Flags().String("client-secret", "", "OAuth2 client secret (confidential clients only)")
+	loginCmd.Flags().String("device-auth-url", "", "OAuth2 device authorization endpoint")
+	loginCmd.Flags().String("token-url", "", "OAuth2 token endpoint")
+	loginCmd.Flags().String("scopes", "", "Space separated OAuth2 scopes")
+
+	viper.BindPFlag("oauth2.client_id", loginCmd.Flags().Lookup("client-id"))
+	viper.BindPFlag("oauth2.client_secret", loginCmd.Flags().Lookup("client-secret"))
+	viper.BindPFlag("oauth2.device_auth_url", loginCmd.Flags().Lookup("device-auth-url"))
+	viper.BindPFlag("oauth2.token_url", loginCmd.Flags().Lookup("token-url"))
+	viper.BindPFlag("oauth2.scopes", loginCmd.Flags().Lookup("scopes"))
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 
+	firefly "github.com/ZanzyTHEbar/fireflyiii-client-go"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -12,6 +13,7 @@ import (
 var cfgFile string
 var fireflyURL string
 var token string
+var allowInsecure bool
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -36,6 +38,7 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.firefly-client/config.yaml or ./config.yaml)")
 	rootCmd.PersistentFlags().StringVarP(&fireflyURL, "url", "u", "", "Firefly III instance URL (e.g., http://localhost:8080)")
 	rootCmd.PersistentFlags().StringVarP(&token, "token", "t", "", "Firefly III API token")
+	rootCmd.PersistentFlags().BoolVar(&allowInsecure, "allow-insecure", false, "Allow plain http URLs for hosts other than localhost")
 
 	// TODO: Add more persistent flags as needed, e.g., for output format (json, yaml, text)
 
@@ -43,6 +46,18 @@ func init() {
 	cobra.CheckErr(vipErr)
 	vipErr = viper.BindPFlag("token", rootCmd.PersistentFlags().Lookup("token"))
 	cobra.CheckErr(vipErr)
+	vipErr = viper.BindPFlag("allow_insecure", rootCmd.PersistentFlags().Lookup("allow-insecure"))
+	cobra.CheckErr(vipErr)
+}
+
+// newClient creates a Firefly client for the given URL and token using the shared CLI settings
+func newClient(url, token string) (*firefly.FireflyClient, error) {
+	config := firefly.DefaultClientConfig()
+	config.BaseURL = url
+	config.Token = token
+	config.AllowInsecure = viper.GetBool("allow_insecure")
+
+	return firefly.NewFireflyClientWithConfig(config)
 }
 
 // initConfig reads in config file and ENV variables if set.
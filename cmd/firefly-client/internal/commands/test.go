@@ -5,7 +5,6 @@ import (
 	"log"
 	"time"
 
-	firefly "github.com/ZanzyTHEbar/fireflyiii-client-go"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -38,11 +37,11 @@ Examples:
 
 		fmt.Println("🔧 Testing Firefly III connection...")
 		fmt.Printf("📍 URL: %s\n", url)
-		fmt.Printf("🔑 Token: %s...\n", token[:min(len(token), 8)])
+		fmt.Println("🔑 Token: (hidden)")
 
 		// Create client with timeout
 		start := time.Now()
-		client, err := firefly.NewFireflyClient(url, token)
+		client, err := newClient(url, token)
 		if err != nil {
 			fmt.Printf("❌ Failed to create client: %v\n", err)
 			return
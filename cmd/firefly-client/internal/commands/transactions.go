@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"log"
 
-	firefly "github.com/ZanzyTHEbar/fireflyiii-client-go"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -37,13 +36,13 @@ var transactionsListCmd = &cobra.Command{
 		}
 
 		// Create Firefly client
-		client, err := firefly.NewFireflyClient(url, token)
+		client, err := newClient(url, token)
 		if err != nil {
 			log.Fatalf("Failed to create Firefly client: %v", err)
 		}
 
 		fmt.Printf("Connecting to Firefly III at: %s\n", url)
-		fmt.Printf("Using token: %s...\n", token[:min(len(token), 8)])
+		fmt.Println("Using token: (hidden)")
 		fmt.Printf("Client created successfully: %v\n", client != nil)
 
 		// TODO: Implement actual transaction listing using the client
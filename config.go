@@ -0,0 +1,257 @@
+package firefly
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ZanzyTHEbar/errbuilder-go"
+	"gopkg.in/yaml.v3"
+)
+
+// envReference matches ${NAME} references to environment variables in configuration files
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// LoadClientConfig reads a ClientConfig from a YAML (.yaml, .yml) or JSON (.json) file.
+// Keys are the yaml/json names of the ClientConfig fields, and fields missing from the file
+// keep the values of DefaultClientConfig. Durations are written as strings such as "30s".
+//
+// References like ${FIREFLY_TOKEN} are replaced with the value of the environment variable,
+// so secrets do not have to be stored in the file. The base URL and either a token or OAuth2
+// client credentials are required.
+func LoadClientConfig(path string) (*ClientConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client config: %w", err)
+	}
+
+	var errs errbuilder.ErrorMap
+	ext := strings.ToLower(filepath.Ext(path))
+	switch ext {
+	case ".yaml", ".yml", ".json":
+	default:
+		errs.Set("path", fmt.Sprintf("Unsupported config file extension %q, use .yaml, .yml or .json", ext))
+		return nil, ValidationErr("ClientConfig", errs)
+	}
+
+	if ext == ".json" && !json.Valid(data) {
+		return nil, fmt.Errorf("failed to parse client config %s: invalid JSON", path)
+	}
+
+	// JSON is valid YAML, and decoding both with the YAML decoder allows durations like "30s".
+	// References are expanded in the parsed values, so an environment variable can never change
+	// the structure of the file, and the document is encoded again to decode it strictly.
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse client config %s: %w", path, err)
+	}
+	expandEnvReferences(&document)
+	data, err = yaml.Marshal(&document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse client config %s: %w", path, err)
+	}
+
+	config := DefaultClientConfig()
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil {
+		return nil, fmt.Errorf("failed to parse client config %s: %w", path, err)
+	}
+
+	if errs := validateClientConfig(config); errs != nil {
+		return nil, ValidationErr("ClientConfig", errs)
+	}
+
+	return config, nil
+}
+
+// expandEnvReferences replaces ${NAME} references in the scalar values of a parsed YAML node.
+// Unquoted values are resolved again, so "rate_limit: ${RATE_LIMIT}" still decodes as a number.
+func expandEnvReferences(node *yaml.Node) {
+	for i, child := range node.Content {
+		if node.Kind == yaml.MappingNode && i%2 == 0 {
+			continue // Keys are never expanded
+		}
+		expandEnvReferences(child)
+	}
+	if node.Kind != yaml.ScalarNode || !envReference.MatchString(node.Value) {
+		return
+	}
+	node.Value = envReference.ReplaceAllStringFunc(node.Value, func(ref string) string {
+		return os.Getenv(envReference.FindStringSubmatch(ref)[1])
+	})
+	if node.Style == 0 {
+		node.Tag = ""
+	}
+}
+
+// validateClientConfig validates a loaded client configuration and returns an error map
+func validateClientConfig(config *ClientConfig) errbuilder.ErrorMap {
+	var errs errbuilder.ErrorMap
+
+	if config.BaseURL == "" {
+		errs.Set("base_url", "Base URL is required")
+	}
+	if config.Token == "" && (config.OAuth2 == nil || config.OAuth2.ClientID == "") {
+		errs.Set("token", "Token or OAuth2 client ID is required")
+	}
+	if config.OAuth2 != nil && config.OAuth2.ClientID != "" && config.OAuth2.TokenURL == "" {
+		errs.Set("oauth2.token_url", "Token URL is required for OAuth2")
+	}
+	if config.Timeout < 0 {
+		errs.Set("timeout", "Timeout must not be negative")
+	}
+
+	return errs
+}
+
+// Environment variables read by ClientConfigFromEnv
+const (
+	EnvBaseURL            = "FIREFLY_BASE_URL"
+	EnvURL                = "FIREFLY_URL" // Used by the CLI, read when FIREFLY_BASE_URL is not set
+	EnvToken              = "FIREFLY_TOKEN"
+	EnvTimeout            = "FIREFLY_TIMEOUT"     // Duration such as "30s"
+	EnvRateLimit          = "FIREFLY_RATE_LIMIT"  // Requests per minute
+	EnvRetryCount         = "FIREFLY_RETRY_COUNT" // Number of retries
+	EnvRetryDelay         = "FIREFLY_RETRY_DELAY" // Duration such as "1s"
+	EnvUserAgent          = "FIREFLY_USER_AGENT"
+	EnvAllowInsecure      = "FIREFLY_ALLOW_INSECURE" // Boolean such as "true"
+	EnvOAuth2ClientID     = "FIREFLY_OAUTH2_CLIENT_ID"
+	EnvOAuth2ClientSecret = "FIREFLY_OAUTH2_CLIENT_SECRET"
+	EnvOAuth2TokenURL     = "FIREFLY_OAUTH2_TOKEN_URL"
+	EnvOAuth2AuthURL      = "FIREFLY_OAUTH2_AUTH_URL"
+	EnvOAuth2RedirectURL  = "FIREFLY_OAUTH2_REDIRECT_URL"
+	EnvOAuth2Scopes       = "FIREFLY_OAUTH2_SCOPES" // Comma separated
+	EnvOAuth2RefreshToken = "FIREFLY_OAUTH2_REFRESH_TOKEN"
+)
+
+// ClientConfigFromEnv builds a ClientConfig from FIREFLY_ environment variables on top of
+// DefaultClientConfig. FIREFLY_BASE_URL (or FIREFLY_URL) and either FIREFLY_TOKEN or
+// FIREFLY_OAUTH2_CLIENT_ID are required; see the Env constants for the optional variables.
+func ClientConfigFromEnv() (*ClientConfig, error) {
+	config := DefaultClientConfig()
+	var errs errbuilder.ErrorMap
+
+	config.BaseURL = os.Getenv(EnvBaseURL)
+	if config.BaseURL == "" {
+		config.BaseURL = os.Getenv(EnvURL)
+	}
+	config.Token = os.Getenv(EnvToken)
+	if value := os.Getenv(EnvUserAgent); value != "" {
+		config.UserAgent = value
+	}
+
+	parseEnv := func(name string, parse func(string) error) {
+		if value := os.Getenv(name); value != "" {
+			if err := parse(value); err != nil {
+				errs.Set(name, fmt.Sprintf("Invalid value %q: %v", value, err))
+			}
+		}
+	}
+	parseEnv(EnvTimeout, func(value string) (err error) {
+		config.Timeout, err = time.ParseDuration(value)
+		return err
+	})
+	parseEnv(EnvRetryDelay, func(value string) (err error) {
+		config.RetryDelay, err = time.ParseDuration(value)
+		return err
+	})
+	parseEnv(EnvRateLimit, func(value string) (err error) {
+		config.RateLimit, err = strconv.Atoi(value)
+		return err
+	})
+	parseEnv(EnvRetryCount, func(value string) (err error) {
+		config.RetryCount, err = strconv.Atoi(value)
+		return err
+	})
+	parseEnv(EnvAllowInsecure, func(value string) (err error) {
+		config.AllowInsecure, err = strconv.ParseBool(value)
+		return err
+	})
+
+	if clientID := os.Getenv(EnvOAuth2ClientID); clientID != "" {
+		oauth := OAuth2Config{
+			ClientID:     clientID,
+			ClientSecret: os.Getenv(EnvOAuth2ClientSecret),
+			TokenURL:     os.Getenv(EnvOAuth2TokenURL),
+			AuthURL:      os.Getenv(EnvOAuth2AuthURL),
+			RedirectURL:  os.Getenv(EnvOAuth2RedirectURL),
+			RefreshToken: os.Getenv(EnvOAuth2RefreshToken),
+		}
+		for _, scope := range strings.Split(os.Getenv(EnvOAuth2Scopes), ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				oauth.Scopes = append(oauth.Scopes, scope)
+			}
+		}
+		config.WithOAuth2(oauth)
+	}
+
+	if errs != nil {
+		return nil, ValidationErr("ClientConfig", errs)
+	}
+	if errs := validateClientConfig(config); errs != nil {
+		return nil, ValidationErr("ClientConfig", errs)
+	}
+
+	return config, nil
+}
+
+// NewFireflyClientFromEnv creates a Firefly III API client configured from FIREFLY_ environment variables
+func NewFireflyClientFromEnv() (*FireflyClient, error) {
+	config, err := ClientConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return NewFireflyClientWithConfig(config)
+}
+
+// Merge layers other over c and returns the result as a new configuration; neither c nor other
+// is modified. Non-zero fields of other win, so apps can combine defaults, a config file and
+// runtime settings in order of precedence:
+//
+//	config := DefaultClientConfig().Merge(fileConfig).Merge(flagConfig)
+//
+// The OAuth2 settings are merged field by field. Since false is the zero value, other can turn
+// a boolean option on but not off, and zero durations or limits in other keep the value of c.
+func (c *ClientConfig) Merge(other *ClientConfig) *ClientConfig {
+	merged := &ClientConfig{}
+	if c != nil {
+		mergeConfigFields(reflect.ValueOf(merged).Elem(), reflect.ValueOf(c).Elem())
+	}
+	if other != nil {
+		mergeConfigFields(reflect.ValueOf(merged).Elem(), reflect.ValueOf(other).Elem())
+	}
+	return merged
+}
+
+// mergeConfigFields copies the non-zero fields of the struct src into dst. Structs behind
+// pointers are merged into a copy and slices are copied, so dst shares no memory with src.
+func mergeConfigFields(dst, src reflect.Value) {
+	for i := range src.NumField() {
+		from, to := src.Field(i), dst.Field(i)
+		if !to.CanSet() || from.IsZero() {
+			continue
+		}
+
+		switch {
+		case from.Kind() == reflect.Pointer && from.Elem().Kind() == reflect.Struct:
+			copied := reflect.New(from.Elem().Type())
+			if !to.IsNil() {
+				copied.Elem().Set(to.Elem())
+			}
+			mergeConfigFields(copied.Elem(), from.Elem())
+			to.Set(copied)
+		case from.Kind() == reflect.Slice:
+			to.Set(reflect.AppendSlice(reflect.MakeSlice(from.Type(), 0, from.Len()), from))
+		default:
+			to.Set(from)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package firefly
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfigFile writes a configuration file with the given name to a temporary directory
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// TestLoadClientConfig tests loading YAML and JSON configuration files with environment references
+func TestLoadClientConfig(t *testing.T) {
+	t.Setenv("FIREFLY_TOKEN", `secret-"token"`)
+	t.Setenv("FIREFLY_HOST", "firefly.example.com")
+
+	t.Run("yaml", func(t *testing.T) {
+		path := writeConfigFile(t, "firefly.yaml", `
+base_url: https://${FIREFLY_HOST}/api
+token: ${FIREFLY_TOKEN}
+timeout: 10s
+rate_limit: 120
+verify_attachments: true
+allowed_attachment_types: [application/pdf, image/*]
+`)
+		config, err := LoadClientConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, "https://firefly.example.com/api", config.BaseURL)
+		assert.Equal(t, `secret-"token"`, config.Token)
+		assert.Equal(t, 10*time.Second, config.Timeout)
+		assert.Equal(t, 120, config.RateLimit)
+		assert.True(t, config.VerifyAttachments)
+		assert.Equal(t, []string{"application/pdf", "image/*"}, config.AllowedAttachmentTypes)
+
+		// Unset fields keep their defaults
+		defaults := DefaultClientConfig()
+		assert.Equal(t, defaults.RetryCount, config.RetryCount)
+		assert.Equal(t, defaults.UserAgent, config.UserAgent)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		path := writeConfigFile(t, "firefly.json", `{
+			"base_url": "https://${FIREFLY_HOST}/api",
+			"token": "${FIREFLY_TOKEN}",
+			"retry_delay": "250ms",
+			"max_concurrent_requests": 8
+		}`)
+		config, err := LoadClientConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, "https://firefly.example.com/api", config.BaseURL)
+		assert.Equal(t, `secret-"token"`, config.Token)
+		assert.Equal(t, 250*time.Millisecond, config.RetryDelay)
+		assert.Equal(t, 8, config.MaxConcurrentRequests)
+	})
+
+	t.Run("values are not parsed", func(t *testing.T) {
+		secret := "abc #def: x\nallow_insecure: true"
+		t.Setenv("FIREFLY_SECRET", secret)
+		t.Setenv("FIREFLY_RATE_LIMIT", "30")
+
+		path := writeConfigFile(t, "firefly.yaml", `
+base_url: https://firefly.example.com/api
+token: ${FIREFLY_SECRET}
+rate_limit: ${FIREFLY_RATE_LIMIT}
+`)
+		config, err := LoadClientConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, secret, config.Token)
+		assert.Equal(t, 30, config.RateLimit)
+		assert.False(t, config.AllowInsecure)
+
+		path = writeConfigFile(t, "firefly.json", `{"base_url": "https://firefly.example.com/api", "token": "${FIREFLY_SECRET}"}`)
+		config, err = LoadClientConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, secret, config.Token)
+		assert.False(t, config.AllowInsecure)
+	})
+
+	t.Run("oauth2", func(t *testing.T) {
+		t.Setenv("FIREFLY_CLIENT_SECRET", "client-secret")
+		path := writeConfigFile(t, "firefly.yml", `
+base_url: https://firefly.example.com/api
+oauth2:
+  client_id: "7"
+  client_secret: ${FIREFLY_CLIENT_SECRET}
+  token_url: https://firefly.example.com/oauth/token
+`)
+		config, err := LoadClientConfig(path)
+		require.NoError(t, err)
+		require.NotNil(t, config.OAuth2)
+		assert.Equal(t, "7", config.OAuth2.ClientID)
+		assert.Equal(t, "client-secret", config.OAuth2.ClientSecret)
+	})
+}
+
+// TestLoadClientConfigInvalid tests the errors for invalid configuration files
+func TestLoadClientConfigInvalid(t *testing.T) {
+	t.Setenv("FIREFLY_EMPTY_TOKEN", "")
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		message string
+	}{
+		{"missing base url", "firefly.yaml", "token: abc\n", "Base URL is required"},
+		{"unset token variable", "firefly.yaml", "base_url: https://firefly.example.com\ntoken: ${FIREFLY_EMPTY_TOKEN}\n", "Token or OAuth2 client ID is required"},
+		{"oauth2 without token url", "firefly.yaml", "base_url: https://firefly.example.com\noauth2:\n  client_id: \"7\"\n", "Token URL is required"},
+		{"unknown field", "firefly.yaml", "base_url: https://firefly.example.com\ntoken: abc\nbase_uri: x\n", "base_uri"},
+		{"invalid json", "firefly.json", `{"base_url": "https://firefly.example.com",}`, "invalid JSON"},
+		{"unsupported extension", "firefly.toml", `base_url = "https://firefly.example.com"`, "Unsupported config file extension"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadClientConfig(writeConfigFile(t, tt.file, tt.content))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+
+	_, err := LoadClientConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+// clearFireflyEnv unsets the environment variables read by ClientConfigFromEnv for the test
+func clearFireflyEnv(t *testing.T) {
+	for _, name := range []string{
+		EnvBaseURL, EnvURL, EnvToken, EnvTimeout, EnvRateLimit, EnvRetryCount, EnvRetryDelay,
+		EnvUserAgent, EnvAllowInsecure, EnvOAuth2ClientID, EnvOAuth2ClientSecret,
+		EnvOAuth2TokenURL, EnvOAuth2AuthURL, EnvOAuth2RedirectURL, EnvOAuth2Scopes,
+	} {
+		t.Setenv(name, "")
+	}
+}
+
+// TestClientConfigFromEnv tests building the client configuration from environment variables
+func TestClientConfigFromEnv(t *testing.T) {
+	t.Run("token", func(t *testing.T) {
+		clearFireflyEnv(t)
+		t.Setenv(EnvBaseURL, "https://firefly.example.com/api")
+		t.Setenv(EnvToken, "env-token")
+		t.Setenv(EnvTimeout, "45s")
+		t.Setenv(EnvRateLimit, "300")
+		t.Setenv(EnvRetryCount, "5")
+		t.Setenv(EnvRetryDelay, "2s")
+		t.Setenv(EnvUserAgent, "budget-app/2.0")
+
+		config, err := ClientConfigFromEnv()
+		require.NoError(t, err)
+		assert.Equal(t, "https://firefly.example.com/api", config.BaseURL)
+		assert.Equal(t, "env-token", config.Token)
+		assert.Equal(t, 45*time.Second, config.Timeout)
+		assert.Equal(t, 300, config.RateLimit)
+		assert.Equal(t, 5, config.RetryCount)
+		assert.Equal(t, 2*time.Second, config.RetryDelay)
+		assert.Equal(t, "budget-app/2.0", config.UserAgent)
+		assert.Nil(t, config.OAuth2)
+		assert.Equal(t, DefaultMaxConcurrentRequests, config.MaxConcurrentRequests)
+
+		client, err := NewFireflyClientFromEnv()
+		require.NoError(t, err)
+		assert.Equal(t, "https://firefly.example.com/api", client.baseURL)
+	})
+
+	t.Run("cli url and oauth2", func(t *testing.T) {
+		clearFireflyEnv(t)
+		t.Setenv(EnvURL, "http://firefly.lan")
+		t.Setenv(EnvAllowInsecure, "true")
+		t.Setenv(EnvOAuth2ClientID, "7")
+		t.Setenv(EnvOAuth2ClientSecret, "client-secret")
+		t.Setenv(EnvOAuth2TokenURL, "http://firefly.lan/oauth/token")
+		t.Setenv(EnvOAuth2Scopes, "read, write")
+
+		config, err := ClientConfigFromEnv()
+		require.NoError(t, err)
+		assert.Equal(t, "http://firefly.lan", config.BaseURL)
+		assert.True(t, config.AllowInsecure)
+		assert.Empty(t, config.Token)
+		require.NotNil(t, config.OAuth2)
+		assert.Equal(t, OAuth2Config{
+			ClientID:     "7",
+			ClientSecret: "client-secret",
+			TokenURL:     "http://firefly.lan/oauth/token",
+			Scopes:       []string{"read", "write"},
+		}, *config.OAuth2)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		clearFireflyEnv(t)
+		t.Setenv(EnvToken, "env-token")
+		_, err := ClientConfigFromEnv()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Base URL is required")
+
+		t.Setenv(EnvBaseURL, "https://firefly.example.com/api")
+		t.Setenv(EnvTimeout, "30")
+		t.Setenv(EnvRateLimit, "lots")
+		_, err = NewFireflyClientFromEnv()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), EnvTimeout)
+		assert.Contains(t, err.Error(), EnvRateLimit)
+	})
+}
+
+// TestClientConfigMerge tests that non-zero fields of later configurations take precedence
+func TestClientConfigMerge(t *testing.T) {
+	defaults := DefaultClientConfig()
+	file := &ClientConfig{
+		BaseURL:   "https://firefly.example.com/api",
+		Token:     "file-token",
+		Timeout:   10 * time.Second,
+		RateLimit: 120,
+		OAuth2: &OAuth2Config{
+			ClientID: "7",
+			TokenURL: "https://firefly.example.com/oauth/token",
+			Scopes:   []string{"read"},
+		},
+		AllowedAttachmentTypes: []string{"application/pdf"},
+	}
+	runtime := &ClientConfig{
+		Token:             "runtime-token",
+		VerifyAttachments: true,
+		OAuth2:            &OAuth2Config{ClientSecret: "runtime-secret"},
+	}
+
+	merged := defaults.Merge(file).Merge(runtime)
+
+	assert.Equal(t, "https://firefly.example.com/api", merged.BaseURL, "set by the file only")
+	assert.Equal(t, "runtime-token", merged.Token, "runtime wins over the file")
+	assert.Equal(t, 10*time.Second, merged.Timeout, "file wins over the defaults")
+	assert.Equal(t, 120, merged.RateLimit)
+	assert.Equal(t, defaults.RetryCount, merged.RetryCount, "zero values keep the defaults")
+	assert.Equal(t, defaults.UserAgent, merged.UserAgent)
+	assert.True(t, merged.VerifyAttachments)
+	assert.Equal(t, []string{"application/pdf"}, merged.AllowedAttachmentTypes)
+	assert.Equal(t, &OAuth2Config{
+		ClientID:     "7",
+		ClientSecret: "runtime-secret",
+		TokenURL:     "https://firefly.example.com/oauth/token",
+		Scopes:       []string{"read"},
+	}, merged.OAuth2, "OAuth2 settings are merged field by field")
+
+	// The inputs are not modified and share no memory with the result
+	assert.Equal(t, "file-token", file.Token)
+	assert.Empty(t, file.OAuth2.ClientSecret)
+	assert.Equal(t, 30*time.Second, defaults.Timeout)
+	merged.OAuth2.Scopes[0] = "write"
+	merged.AllowedAttachmentTypes[0] = "image/png"
+	assert.Equal(t, []string{"read"}, file.OAuth2.Scopes)
+	assert.Equal(t, []string{"application/pdf"}, file.AllowedAttachmentTypes)
+
+	// A nil layer leaves a copy of the configuration
+	copied := file.Merge(nil)
+	assert.Equal(t, file, copied)
+	assert.NotSame(t, file, copied)
+	assert.NotSame(t, file.OAuth2, copied.OAuth2)
+
+	var none *ClientConfig
+	assert.Equal(t, "file-token", none.Merge(file).Token)
+}
@@ -0,0 +1,131 @@
+package firefly
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ZanzyTHEbar/errbuilder-go"
+)
+
+// Names of the configuration values of a Firefly III instance that can be changed through the API
+const (
+	ConfigSingleUserMode        = "configuration.single_user_mode"
+	ConfigIsDemoSite            = "configuration.is_demo_site"
+	ConfigPermissionUpdateCheck = "configuration.permission_update_check"
+	ConfigLastUpdateCheck       = "configuration.last_update_check"
+)
+
+// ConfigurationModel represents a configuration value of the Firefly III instance
+type ConfigurationModel struct {
+	Name     string
+	Editable bool // Whether the value can be changed through the API
+
+	// Value holds the JSON value as decoded by encoding/json: a bool, string, float64,
+	// []interface{} or map[string]interface{}, depending on the configuration value
+	Value interface{}
+}
+
+// BoolValue returns the value if it is a boolean
+func (m ConfigurationModel) BoolValue() (bool, bool) {
+	v, ok := m.Value.(bool)
+	return v, ok
+}
+
+// StringValue returns the value if it is a string
+func (m ConfigurationModel) StringValue() (string, bool) {
+	v, ok := m.Value.(string)
+	return v, ok
+}
+
+// IntValue returns the value if it is a whole number, such as the time of the last update check
+func (m ConfigurationModel) IntValue() (int64, bool) {
+	v, ok := m.Value.(float64)
+	if !ok || v != float64(int64(v)) {
+		return 0, false
+	}
+	return int64(v), true
+}
+
+// GetConfiguration retrieves a configuration value of the Firefly III instance by name
+func (c *FireflyClient) GetConfiguration(ctx context.Context, name string) (*ConfigurationModel, error) {
+	// Call the API
+	resp, err := c.clientAPI.GetSingleConfigurationWithResponse(ctx, ConfigValueFilter(name), &GetSingleConfigurationParams{})
+	if err != nil {
+		return nil, APIErr("Failed to get configuration", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK {
+		return nil, adminResponseErr("Configuration", resp.HTTPResponse, resp.Body)
+	}
+
+	// Convert API response to ConfigurationModel
+	if resp.HTTPResponse == nil || len(resp.Body) == 0 {
+		return nil, APIErr("No configuration data found", fmt.Errorf("empty response"))
+	}
+
+	var apiResp ConfigurationSingle
+	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
+		return nil, APIErr("Failed to parse configuration response", err)
+	}
+
+	return configurationModelFromAPI(apiResp.Data)
+}
+
+// SetConfiguration changes a configuration value of the Firefly III instance.
+// The value must encode to a JSON boolean, string, number, array or object; most values are booleans.
+// It requires a token of a user with the owner role.
+func (c *FireflyClient) SetConfiguration(ctx context.Context, name string, value interface{}) error {
+	var errs errbuilder.ErrorMap
+	if name == "" {
+		errs.Set("name", "Name is required")
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		errs.Set("value", fmt.Sprintf("Value cannot be encoded: %v", err))
+	} else if value == nil {
+		errs.Set("value", "Value is required")
+	}
+	if errs != nil {
+		return ValidationErr("Configuration", errs)
+	}
+
+	var update SetConfigurationJSONRequestBody
+	if err := update.Value.UnmarshalJSON(raw); err != nil {
+		return APIErr("Failed to encode configuration value", err)
+	}
+
+	// Call the API
+	resp, err := c.clientAPI.SetConfigurationWithResponse(ctx, ConfigValueUpdateFilter(name), &SetConfigurationParams{}, update)
+	if err != nil {
+		return APIErr("Failed to set configuration", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK {
+		return adminResponseErr("Configuration", resp.HTTPResponse, resp.Body)
+	}
+
+	return nil
+}
+
+// configurationModelFromAPI converts an API configuration value to a ConfigurationModel
+func configurationModelFromAPI(config Configuration) (*ConfigurationModel, error) {
+	raw, err := config.Value.MarshalJSON()
+	if err != nil {
+		return nil, APIErr("Failed to parse configuration value", err)
+	}
+
+	model := &ConfigurationModel{
+		Name:     string(config.Title),
+		Editable: config.Editable,
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &model.Value); err != nil {
+			return nil, APIErr("Failed to parse configuration value", err)
+		}
+	}
+	return model, nil
+}
@@ -0,0 +1,88 @@
+package firefly
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZanzyTHEbar/fireflyiii-client-go/fireflytest"
+)
+
+// TestGetConfiguration tests reading configuration values of different types
+func TestGetConfiguration(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/configuration/configuration.single_user_mode", http.StatusOK,
+		`{"data": {"title": "configuration.single_user_mode", "value": true, "editable": true}}`)
+	server.Handle(http.MethodGet, "/v1/configuration/configuration.last_update_check", http.StatusOK,
+		`{"data": {"title": "configuration.last_update_check", "value": 1712345678, "editable": true}}`)
+	server.Handle(http.MethodGet, "/v1/configuration/firefly.version", http.StatusOK,
+		`{"data": {"title": "firefly.version", "value": "6.2.8", "editable": false}}`)
+	server.Handle(http.MethodGet, "/v1/configuration/firefly.languages", http.StatusOK,
+		`{"data": {"title": "firefly.languages", "value": {"en_US": {"name_locale": "English", "name_english": "English"}}, "editable": false}}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	singleUser, err := client.GetConfiguration(ctx, ConfigSingleUserMode)
+	require.NoError(t, err)
+	assert.Equal(t, ConfigSingleUserMode, singleUser.Name)
+	assert.True(t, singleUser.Editable)
+	enabled, ok := singleUser.BoolValue()
+	assert.True(t, ok)
+	assert.True(t, enabled)
+
+	lastCheck, err := client.GetConfiguration(ctx, ConfigLastUpdateCheck)
+	require.NoError(t, err)
+	timestamp, ok := lastCheck.IntValue()
+	assert.True(t, ok)
+	assert.Equal(t, int64(1712345678), timestamp)
+
+	version, err := client.GetConfiguration(ctx, "firefly.version")
+	require.NoError(t, err)
+	assert.False(t, version.Editable)
+	value, ok := version.StringValue()
+	assert.True(t, ok)
+	assert.Equal(t, "6.2.8", value)
+	_, ok = version.BoolValue()
+	assert.False(t, ok)
+
+	languages, err := client.GetConfiguration(ctx, "firefly.languages")
+	require.NoError(t, err)
+	require.IsType(t, map[string]interface{}{}, languages.Value)
+	assert.Contains(t, languages.Value, "en_US")
+}
+
+// TestSetConfiguration tests writing configuration values
+func TestSetConfiguration(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodPut, "/v1/configuration/configuration.single_user_mode", http.StatusOK,
+		`{"data": {"title": "configuration.single_user_mode", "value": false, "editable": true}}`)
+	server.Handle(http.MethodPut, "/v1/configuration/configuration.is_demo_site", http.StatusForbidden,
+		`{"message": "You need the \"owner\"-role to do this."}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, client.SetConfiguration(ctx, ConfigSingleUserMode, false))
+	requests := server.RequestsTo(http.MethodPut, "/v1/configuration/configuration.single_user_mode")
+	require.Len(t, requests, 1)
+	var body map[string]json.RawMessage
+	require.NoError(t, requests[0].JSON(&body))
+	assert.JSONEq(t, `false`, string(body["value"]))
+
+	err = client.SetConfiguration(ctx, ConfigIsDemoSite, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Admin Permission Required")
+
+	server.Reset()
+	assert.Error(t, client.SetConfiguration(ctx, ConfigSingleUserMode, nil))
+	assert.Error(t, client.SetConfiguration(ctx, "", true))
+	assert.Error(t, client.SetConfiguration(ctx, ConfigSingleUserMode, make(chan int)))
+	assert.Empty(t, server.Requests())
+}
@@ -0,0 +1,179 @@
+package firefly
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CurrencyModel represents a currency in our domain model
+type CurrencyModel struct {
+	ID            string
+	Code          string
+	Name          string
+	Symbol        string
+	DecimalPlaces int32
+	Enabled       bool
+	Default       bool
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// currencyModelFromRead converts an API currency to a CurrencyModel
+func currencyModelFromRead(currencyRead CurrencyRead) CurrencyModel {
+	return CurrencyModel{
+		ID:            currencyRead.Id,
+		Code:          currencyRead.Attributes.Code,
+		Name:          currencyRead.Attributes.Name,
+		Symbol:        currencyRead.Attributes.Symbol,
+		DecimalPlaces: int32Value(currencyRead.Attributes.DecimalPlaces),
+		Enabled:       boolValue(currencyRead.Attributes.Enabled),
+		Default:       boolValue(currencyRead.Attributes.Default) || boolValue(currencyRead.Attributes.Native),
+		CreatedAt:     timeValue(currencyRead.Attributes.CreatedAt),
+		UpdatedAt:     timeValue(currencyRead.Attributes.UpdatedAt),
+	}
+}
+
+// GetDefaultCurrency retrieves the administration's default currency.
+// Firefly III exposes it as the native currency (formerly /currencies/default).
+// The result is cached for the lifetime of the client, separately for each WithToken token.
+func (c *FireflyClient) GetDefaultCurrency(ctx context.Context) (*CurrencyModel, error) {
+	scope := cacheScope(ctx)
+	c.currencyMu.Lock()
+	currency, ok := c.defaultCurrencies[scope]
+	c.currencyMu.Unlock()
+	if ok {
+		return &currency, nil
+	}
+
+	if err := c.requireFeature(ctx, "native currency"); err != nil {
+		return nil, err
+	}
+
+	// Call the API
+	resp, err := c.clientAPI.GetNativeCurrencyWithResponse(ctx, &GetNativeCurrencyParams{})
+	if err != nil {
+		return nil, APIErr("Failed to get default currency", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseErr("Currency", resp.HTTPResponse, resp.Body)
+	}
+
+	// Convert API response to CurrencyModel
+	if resp.HTTPResponse == nil || len(resp.Body) == 0 {
+		return nil, APIErr("No currency data found", fmt.Errorf("empty response"))
+	}
+
+	var apiResp CurrencySingle
+	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
+		return nil, APIErr("Failed to parse currency response", err)
+	}
+
+	currency = currencyModelFromRead(apiResp.Data)
+	c.currencyMu.Lock()
+	if c.defaultCurrencies == nil {
+		c.defaultCurrencies = make(map[string]CurrencyModel)
+	}
+	c.defaultCurrencies[scope] = currency
+	if scope == "" {
+		c.cacheCurrenciesLocked([]CurrencyModel{currency})
+	}
+	c.currencyMu.Unlock()
+
+	result := currency
+	return &result, nil
+}
+
+// ListCurrencies retrieves the currencies of the administration with pagination.
+// The listed currencies are added to the currency metadata cache used by CurrencyInfo, unless
+// they were listed with a WithToken token.
+func (c *FireflyClient) ListCurrencies(ctx context.Context, page, limit int) ([]CurrencyModel, error) {
+	page32, limit32 := pageParams(page, limit)
+
+	// Call the API
+	resp, err := c.clientAPI.ListCurrencyWithResponse(ctx, &ListCurrencyParams{
+		Page:  page32,
+		Limit: limit32,
+	})
+	if err != nil {
+		return nil, APIErr("Failed to list currencies", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseErr("Currency", resp.HTTPResponse, resp.Body)
+	}
+
+	// Convert API response to CurrencyModels
+	if resp.HTTPResponse == nil || len(resp.Body) == 0 {
+		return []CurrencyModel{}, nil
+	}
+
+	var apiResp CurrencyArray
+	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
+		return nil, APIErr("Failed to parse currencies response", err)
+	}
+
+	currencies := make([]CurrencyModel, 0, len(apiResp.Data))
+	for _, currencyRead := range apiResp.Data {
+		currencies = append(currencies, currencyModelFromRead(currencyRead))
+	}
+
+	if cacheScope(ctx) == "" {
+		c.currencyMu.Lock()
+		c.cacheCurrenciesLocked(currencies)
+		c.currencyMu.Unlock()
+	}
+
+	return currencies, nil
+}
+
+// LoadCurrencies fills the currency metadata cache with all currencies of the administration,
+// so CurrencyInfo and FormatAmount know their symbols and decimals
+func (c *FireflyClient) LoadCurrencies(ctx context.Context) error {
+	_, err := listAllPages(ctx, c.ListCurrencies)
+	return err
+}
+
+// cacheCurrenciesLocked adds currencies to the metadata cache; the caller must hold currencyMu
+func (c *FireflyClient) cacheCurrenciesLocked(currencies []CurrencyModel) {
+	if c.currencies == nil {
+		c.currencies = make(map[string]CurrencyModel)
+	}
+	for _, currency := range currencies {
+		c.currencies[strings.ToUpper(currency.Code)] = currency
+	}
+}
+
+// CurrencyInfo returns the cached metadata of the currency with this code. The cache is filled by
+// LoadCurrencies, ListCurrencies and GetDefaultCurrency; CurrencyInfo itself never calls the API.
+func (c *FireflyClient) CurrencyInfo(code string) (*CurrencyModel, bool) {
+	c.currencyMu.Lock()
+	defer c.currencyMu.Unlock()
+
+	currency, ok := c.currencies[strings.ToUpper(strings.TrimSpace(code))]
+	if !ok {
+		return nil, false
+	}
+	return &currency, true
+}
+
+// currencyDecimals returns the decimals of a currency from the metadata cache,
+// or the ISO 4217 decimals of CurrencyDecimals when it is not cached
+func (c *FireflyClient) currencyDecimals(code string) int32 {
+	if currency, ok := c.CurrencyInfo(code); ok {
+		return currency.DecimalPlaces
+	}
+	return CurrencyDecimals(code)
+}
+
+// FormatAmount formats an amount with the decimals of the currency, preferring the decimals
+// Firefly III stores for it over the ISO 4217 defaults
+func (c *FireflyClient) FormatAmount(amount Money, currencyCode string) string {
+	return amount.Round(c.currencyDecimals(currencyCode)).StringFixed()
+}
@@ -0,0 +1,157 @@
+package firefly
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZanzyTHEbar/fireflyiii-client-go/fireflytest"
+)
+
+// TestGetDefaultCurrency tests fetching and caching the default currency
+func TestGetDefaultCurrency(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/v1/currencies/native") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"data": {
+				"id": "1",
+				"type": "currencies",
+				"attributes": {
+					"code": "EUR",
+					"name": "Euro",
+					"symbol": "€",
+					"decimal_places": 2,
+					"enabled": true,
+					"default": true
+				}
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	currency, err := client.GetDefaultCurrency(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "1", currency.ID)
+	assert.Equal(t, "EUR", currency.Code)
+	assert.Equal(t, "€", currency.Symbol)
+	assert.Equal(t, int32(2), currency.DecimalPlaces)
+	assert.True(t, currency.Default)
+
+	// Second call must be served from the cache
+	cached, err := client.GetDefaultCurrency(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, currency, cached)
+	assert.Equal(t, 1, requests)
+}
+
+// TestGetDefaultCurrencyError tests that failures are not cached
+func TestGetDefaultCurrencyError(t *testing.T) {
+	server := mockServer(t, http.StatusInternalServerError, `{"message": "boom"}`)
+	defer server.Close()
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	_, err = client.GetDefaultCurrency(context.Background())
+	assert.Error(t, err)
+	assert.Empty(t, client.defaultCurrencies)
+}
+
+// TestGetDefaultCurrencySlowServer tests that formatting amounts does not wait for the default currency
+func TestGetDefaultCurrencySlowServer(t *testing.T) {
+	release := make(chan struct{})
+	var waiting atomic.Bool
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/about", http.StatusOK, aboutJSON("6.2.8"))
+	server.HandleFunc(http.MethodGet, "/v1/currencies/native", func(w http.ResponseWriter, r *http.Request) {
+		waiting.Store(true)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"type": "currencies", "id": "1", "attributes": {"code": "EUR", "name": "Euro", "symbol": "€", "decimal_places": 2, "enabled": true, "default": true}}}`))
+	})
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	slow := make(chan error, 1)
+	go func() {
+		_, err := client.GetDefaultCurrency(context.Background())
+		slow <- err
+	}()
+	require.Eventually(t, waiting.Load, 5*time.Second, time.Millisecond)
+
+	assert.Equal(t, "1.50", client.FormatAmount(MoneyFromFloat(1.5), "USD"))
+
+	close(release)
+	require.NoError(t, <-slow)
+	currency, ok := client.CurrencyInfo("EUR")
+	require.True(t, ok)
+	assert.Equal(t, "€", currency.Symbol)
+}
+
+// TestCurrencyInfo tests that cached currency metadata is used to format amounts
+func TestCurrencyInfo(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/currencies", http.StatusOK, `{"data": [
+		{"type": "currencies", "id": "1", "attributes": {"code": "EUR", "name": "Euro", "symbol": "€", "decimal_places": 2, "enabled": true}},
+		{"type": "currencies", "id": "2", "attributes": {"code": "JPY", "name": "Japanese yen", "symbol": "¥", "decimal_places": 0, "enabled": true}},
+		{"type": "currencies", "id": "3", "attributes": {"code": "BTC", "name": "Bitcoin", "symbol": "₿", "decimal_places": 8, "enabled": true}}
+	], "meta": {}}`)
+	server.Handle(http.MethodPost, "/v1/transactions", http.StatusOK, `{"data": {"id": "1", "type": "transactions", "attributes": {
+		"transactions": [{"type": "withdrawal", "date": "2024-03-05T00:00:00+00:00", "amount": "0.00012346", "currency_code": "BTC", "description": "Coffee"}]
+	}}}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, ok := client.CurrencyInfo("JPY")
+	assert.False(t, ok, "nothing is cached before loading")
+	assert.Equal(t, "0.12", client.FormatAmount(MoneyFromFloat(0.123456789), "BTC"), "unknown currencies use two decimals")
+
+	require.NoError(t, client.LoadCurrencies(ctx))
+
+	jpy, ok := client.CurrencyInfo("jpy")
+	require.True(t, ok)
+	assert.Equal(t, "Japanese yen", jpy.Name)
+	assert.Equal(t, "¥", jpy.Symbol)
+	assert.Equal(t, int32(0), jpy.DecimalPlaces)
+	assert.Equal(t, "1235", client.FormatAmount(MoneyFromFloat(1234.56), "JPY"))
+	assert.Equal(t, "1234.56", client.FormatAmount(MoneyFromFloat(1234.56), "EUR"))
+	assert.Equal(t, "0.12345679", client.FormatAmount(MoneyFromFloat(0.123456789), "BTC"))
+
+	// Stored amounts are formatted with the cached decimals as well
+	_, err = client.CreateTransaction(ctx, TransactionModel{
+		Currency:        "BTC",
+		Amount:          0.000123456,
+		TransType:       "withdrawal",
+		Description:     "Coffee",
+		Date:            time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC),
+		SourceID:        "1",
+		DestinationName: "Cafe",
+	})
+	require.NoError(t, err)
+	requests := server.RequestsTo(http.MethodPost, "/v1/transactions")
+	require.Len(t, requests, 1)
+	var store TransactionStore
+	require.NoError(t, requests[0].JSON(&store))
+	require.Len(t, store.Transactions, 1)
+	assert.Equal(t, "0.00012346", store.Transactions[0].Amount)
+}
@@ -1,14 +1,19 @@
 package firefly
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
 	"mime/multipart"
 	"net/http"
+	"path"
+	"slices"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/ZanzyTHEbar/errbuilder-go"
@@ -115,14 +120,8 @@ func (c *FireflyClient) CreatePiggyBank(piggyBank PiggyBankModel) error {
 	}
 
 	// Check response
-	if resp.StatusCode() == http.StatusConflict {
-		return DuplicateErr("PiggyBank", fmt.Errorf("piggy bank already exists"))
-	}
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return RateLimitErr(fmt.Errorf("rate limit exceeded"))
-	}
 	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusCreated {
-		return APIErr("Failed to create piggy bank", fmt.Errorf("unexpected status: %s", resp.Status()))
+		return responseErr("PiggyBank", resp.HTTPResponse, resp.Body)
 	}
 
 	return nil
@@ -139,14 +138,8 @@ func (c *FireflyClient) GetPiggyBank(id string) (*PiggyBankModel, error) {
 	}
 
 	// Check response
-	if resp.StatusCode() == http.StatusNotFound {
-		return nil, NotFoundErr("PiggyBank", fmt.Errorf("piggy bank not found: %s", id))
-	}
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return nil, RateLimitErr(fmt.Errorf("rate limit exceeded"))
-	}
 	if resp.StatusCode() != http.StatusOK {
-		return nil, APIErr("Failed to get piggy bank", fmt.Errorf("unexpected status: %s", resp.Status()))
+		return nil, responseErr("PiggyBank", resp.HTTPResponse, resp.Body)
 	}
 
 	// Convert API response to PiggyBankModel
@@ -187,26 +180,25 @@ func (c *FireflyClient) GetPiggyBank(id string) (*PiggyBankModel, error) {
 func (c *FireflyClient) ListPiggyBanks(page, limit int) ([]PiggyBankModel, error) {
 	ctx := context.Background()
 
+	page32, limit32 := pageParams(page, limit)
+
 	// Call the API
 	resp, err := c.clientAPI.ListPiggyBankWithResponse(ctx, &ListPiggyBankParams{
-		Page:  int32Ptr(page),
-		Limit: int32Ptr(limit),
+		Page:  page32,
+		Limit: limit32,
 	})
 	if err != nil {
 		return nil, APIErr("Failed to list piggy banks", err)
 	}
 
 	// Check response
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return nil, RateLimitErr(fmt.Errorf("rate limit exceeded"))
-	}
 	if resp.StatusCode() != http.StatusOK {
-		return nil, APIErr("Failed to list piggy banks", fmt.Errorf("unexpected status: %s", resp.Status()))
+		return nil, responseErr("PiggyBank", resp.HTTPResponse, resp.Body)
 	}
 
 	// Convert API response to PiggyBankModel array
 	if resp.HTTPResponse == nil || len(resp.Body) == 0 {
-		return nil, APIErr("No piggy bank data found", fmt.Errorf("empty response"))
+		return []PiggyBankModel{}, nil
 	}
 
 	var apiResp PiggyBankArray
@@ -269,14 +261,8 @@ func (c *FireflyClient) UpdatePiggyBank(id string, piggyBank PiggyBankModel) err
 	}
 
 	// Check response
-	if resp.StatusCode() == http.StatusNotFound {
-		return NotFoundErr("PiggyBank", fmt.Errorf("piggy bank not found: %s", id))
-	}
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return RateLimitErr(fmt.Errorf("rate limit exceeded"))
-	}
 	if resp.StatusCode() != http.StatusOK {
-		return APIErr("Failed to update piggy bank", fmt.Errorf("unexpected status: %s", resp.Status()))
+		return responseErr("PiggyBank", resp.HTTPResponse, resp.Body)
 	}
 
 	return nil
@@ -293,14 +279,8 @@ func (c *FireflyClient) DeletePiggyBank(id string) error {
 	}
 
 	// Check response
-	if resp.StatusCode() == http.StatusNotFound {
-		return NotFoundErr("PiggyBank", fmt.Errorf("piggy bank not found: %s", id))
-	}
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return RateLimitErr(fmt.Errorf("rate limit exceeded"))
-	}
 	if resp.StatusCode() != http.StatusNoContent {
-		return APIErr("Failed to delete piggy bank", fmt.Errorf("unexpected status: %s", resp.Status()))
+		return responseErr("PiggyBank", resp.HTTPResponse, resp.Body)
 	}
 
 	return nil
@@ -317,19 +297,13 @@ func (c *FireflyClient) GetPiggyBankEvents(piggyBankID string) ([]PiggyBankEvent
 	}
 
 	// Check response
-	if resp.StatusCode() == http.StatusNotFound {
-		return nil, NotFoundErr("PiggyBank", fmt.Errorf("piggy bank not found: %s", piggyBankID))
-	}
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return nil, RateLimitErr(fmt.Errorf("rate limit exceeded"))
-	}
 	if resp.StatusCode() != http.StatusOK {
-		return nil, APIErr("Failed to get piggy bank events", fmt.Errorf("unexpected status: %s", resp.Status()))
+		return nil, responseErr("PiggyBank", resp.HTTPResponse, resp.Body)
 	}
 
 	// Convert API response to PiggyBankEventModel array
 	if resp.HTTPResponse == nil || len(resp.Body) == 0 {
-		return nil, APIErr("No piggy bank event data found", fmt.Errorf("empty response"))
+		return []PiggyBankEventModel{}, nil
 	}
 
 	var apiResp PiggyBankEventArray
@@ -355,26 +329,89 @@ func (c *FireflyClient) GetPiggyBankEvents(piggyBankID string) ([]PiggyBankEvent
 	return events, nil
 }
 
-// ExportData exports data from Firefly III in the specified format
-func (c *FireflyClient) ExportData(dataType DataType, format ExportFormat) ([]byte, error) {
-	ctx := context.Background()
+// ExportData exports data from Firefly III in the specified format and returns the response as is.
+// Exports of several files arrive as a ZIP archive; use ExportFiles to get them one by one.
+func (c *FireflyClient) ExportData(ctx context.Context, dataType DataType, format ExportFormat) ([]byte, error) {
+	data, _, err := c.exportData(ctx, dataType, format)
+	return data, err
+}
+
+// ExportFiles exports data from Firefly III in the specified format and returns the content of
+// every exported file by file name. A ZIP archive is unpacked; a single file is named after the
+// Content-Disposition header of the response, or after the data type and format without one.
+func (c *FireflyClient) ExportFiles(ctx context.Context, dataType DataType, format ExportFormat) (map[string][]byte, error) {
+	data, header, err := c.exportData(ctx, dataType, format)
+	if err != nil {
+		return nil, err
+	}
 
+	if !isZipArchive(data, header.Get("Content-Type")) {
+		name := string(dataType) + "." + string(format)
+		if _, params, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+			name = path.Base(params["filename"])
+		}
+		return map[string][]byte{name: data}, nil
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, APIErr("Failed to read export archive", err)
+	}
+
+	files := make(map[string][]byte, len(archive.File))
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		content, err := readZipFile(file)
+		if err != nil {
+			return nil, APIErr(fmt.Sprintf("Failed to read %s from export archive", file.Name), err)
+		}
+		files[file.Name] = content
+	}
+
+	return files, nil
+}
+
+// isZipArchive reports whether an export response is a ZIP archive, by its content type or signature
+func isZipArchive(data []byte, contentType string) bool {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		switch mediaType {
+		case "application/zip", "application/x-zip-compressed":
+			return true
+		}
+	}
+	return bytes.HasPrefix(data, []byte("PK\x03\x04"))
+}
+
+// readZipFile reads the uncompressed content of a file in a ZIP archive
+func readZipFile(file *zip.File) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// exportData requests an export and returns the response body together with the response headers
+func (c *FireflyClient) exportData(ctx context.Context, dataType DataType, format ExportFormat) ([]byte, http.Header, error) {
 	var errs errbuilder.ErrorMap
 
 	// Validate format
 	if format != ExportFormatCSV {
 		errs.Set("format", fmt.Errorf("unsupported format: %s", format))
-		return nil, ValidationErr("ExportFormat", errs)
+		return nil, nil, ValidationErr("ExportFormat", errs)
 	}
 
 	// Build the export endpoint based on data type
 	endpoint := fmt.Sprintf("/v1/data/export/%s", dataType)
 
 	// Make the request
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+endpoint, nil)
+	req, err := c.newRequest(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		errs.Set("request", fmt.Errorf("failed to create request: %w", err))
-		return nil, ValidationErr("ExportData", errs)
+		return nil, nil, ValidationErr("ExportData", errs)
 	}
 
 	// Add query parameters
@@ -383,66 +420,62 @@ func (c *FireflyClient) ExportData(dataType DataType, format ExportFormat) ([]by
 	req.URL.RawQuery = q.Encode()
 
 	// Add headers
-	req.Header.Set("Authorization", "Bearer "+c.token)
 	req.Header.Set("Accept", "application/octet-stream")
 
 	// Make the request
-	resp, err := c.client.Do(req)
+	resp, err := c.doRequest(req)
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, nil, ContextErr(ctxErr)
+		}
 		errs.Set("request", fmt.Errorf("failed to export data: %w", err))
-		return nil, APIErr("ExportData", errs)
+		return nil, nil, APIErr("ExportData", errs)
 	}
 	defer resp.Body.Close()
 
 	// Check response status
-	switch resp.StatusCode {
-	case http.StatusOK:
-		return nil, nil // TODO: Read response body
-	case http.StatusNotFound:
-		errs.Set("data export", fmt.Errorf("data export not found: %s", dataType))
-		return nil, NotFoundErr("ExportData", errs)
-	case http.StatusTooManyRequests:
-		errs.Set("rate limit", fmt.Errorf("rate limit exceeded"))
-		return nil, RateLimitErr(errs)
-	default:
-		errs.Set("API error", fmt.Errorf("API error (status %d): failed to export data", resp.StatusCode))
-		return nil, APIErr("ExportData", errs)
+	if resp.StatusCode != http.StatusOK {
+		body := readErrorBody(resp)
+		return nil, nil, responseErr("Export", resp, body)
 	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, nil, ContextErr(ctxErr)
+		}
+		return nil, nil, APIErr("Failed to read export", err)
+	}
+
+	return data, resp.Header, nil
 }
 
 // DestroyData permanently deletes data of the specified type
-func (c *FireflyClient) DestroyData(dataType DataType) error {
-	ctx := context.Background()
-
+func (c *FireflyClient) DestroyData(ctx context.Context, dataType DataType) error {
 	// Call the API
 	resp, err := c.clientAPI.DestroyData(ctx, &DestroyDataParams{
 		Objects: DataDestroyObject(dataType),
 	})
 	if err != nil {
-		return fmt.Errorf("failed to destroy data: %w", err)
+		return APIErr("Failed to destroy data", err)
 	}
+	defer resp.Body.Close()
 
 	// Check response
-	switch resp.StatusCode {
-	case http.StatusNoContent:
-		return nil
-	case http.StatusNotFound:
-		return NotFoundErr("DestroyData", fmt.Errorf("data type not found: %s", dataType))
-	case http.StatusTooManyRequests:
-		return RateLimitErr(fmt.Errorf("rate limit exceeded"))
-	default:
-		return APIErr("DestroyData", fmt.Errorf("API error (status %d): failed to destroy data", resp.StatusCode))
+	if resp.StatusCode != http.StatusNoContent {
+		body := readErrorBody(resp)
+		return responseErr("Data", resp, body)
 	}
+
+	return nil
 }
 
 // BulkUpdateTransactions updates multiple transactions based on a query
-func (c *FireflyClient) BulkUpdateTransactions(query map[string]interface{}) error {
-	ctx := context.Background()
-
+func (c *FireflyClient) BulkUpdateTransactions(ctx context.Context, query map[string]interface{}) error {
 	// Convert query to JSON
 	queryJSON, err := json.Marshal(query)
 	if err != nil {
-		return fmt.Errorf("failed to marshal query: %w", err)
+		return APIErr("Failed to marshal query", err)
 	}
 
 	// Call the API
@@ -450,41 +483,35 @@ func (c *FireflyClient) BulkUpdateTransactions(query map[string]interface{}) err
 		Query: json.RawMessage(queryJSON),
 	})
 	if err != nil {
-		return fmt.Errorf("failed to bulk update transactions: %w", err)
+		return APIErr("Failed to bulk update transactions", err)
 	}
+	defer resp.Body.Close()
 
 	// Check response
-	switch resp.StatusCode {
-	case http.StatusOK:
-		return nil
-	case http.StatusBadRequest:
-		return fmt.Errorf("invalid bulk update query")
-	case http.StatusTooManyRequests:
-		return fmt.Errorf("rate limit exceeded")
-	default:
-		return fmt.Errorf("API error (status %d): failed to bulk update transactions", resp.StatusCode)
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		body := readErrorBody(resp)
+		return responseErr("Transaction", resp, body)
 	}
+
+	return nil
 }
 
 // PurgeData permanently removes all previously deleted data
-func (c *FireflyClient) PurgeData() error {
-	ctx := context.Background()
-
+func (c *FireflyClient) PurgeData(ctx context.Context) error {
 	// Call the API
 	resp, err := c.clientAPI.PurgeData(ctx, &PurgeDataParams{})
 	if err != nil {
-		return fmt.Errorf("failed to purge data: %w", err)
+		return APIErr("Failed to purge data", err)
 	}
+	defer resp.Body.Close()
 
 	// Check response
-	switch resp.StatusCode {
-	case http.StatusNoContent:
-		return nil
-	case http.StatusTooManyRequests:
-		return fmt.Errorf("rate limit exceeded")
-	default:
-		return fmt.Errorf("API error (status %d): failed to purge data", resp.StatusCode)
+	if resp.StatusCode != http.StatusNoContent {
+		body := readErrorBody(resp)
+		return responseErr("Data", resp, body)
 	}
+
+	return nil
 }
 
 // CreateTag creates a new tag
@@ -494,20 +521,15 @@ func (c *FireflyClient) CreateTag(tag TagModelStore) error {
 	// Call the API
 	resp, err := c.clientAPI.StoreTagWithResponse(ctx, &StoreTagParams{}, tag)
 	if err != nil {
-		return fmt.Errorf("failed to create tag: %w", err)
+		return APIErr("Failed to create tag", err)
 	}
 
 	// Check response
-	switch resp.StatusCode() {
-	case http.StatusOK, http.StatusCreated:
-		return nil
-	case http.StatusConflict:
-		return fmt.Errorf("tag already exists")
-	case http.StatusTooManyRequests:
-		return fmt.Errorf("rate limit exceeded")
-	default:
-		return fmt.Errorf("API error (status %d): failed to create tag", resp.StatusCode())
+	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusCreated {
+		return responseErr("Tag", resp.HTTPResponse, resp.Body)
 	}
+
+	return nil
 }
 
 // GetTag retrieves a single tag by ID
@@ -517,58 +539,55 @@ func (c *FireflyClient) GetTag(id string) (*TagRead, error) {
 	// Call the API
 	resp, err := c.clientAPI.GetTagWithResponse(ctx, id, &GetTagParams{})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get tag: %w", err)
+		return nil, APIErr("Failed to get tag", err)
 	}
 
 	// Check response
-	switch resp.StatusCode() {
-	case http.StatusOK:
-		if resp.Body == nil {
-			return nil, fmt.Errorf("empty response")
-		}
-		var apiResp TagSingle
-		if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
-			return nil, fmt.Errorf("failed to parse tag response: %w", err)
-		}
-		return &apiResp.Data, nil
-	case http.StatusNotFound:
-		return nil, fmt.Errorf("tag not found: %s", id)
-	case http.StatusTooManyRequests:
-		return nil, fmt.Errorf("rate limit exceeded")
-	default:
-		return nil, fmt.Errorf("API error (status %d): failed to get tag", resp.StatusCode())
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseErr("Tag", resp.HTTPResponse, resp.Body)
+	}
+	if resp.Body == nil {
+		return nil, APIErr("No tag data found", fmt.Errorf("empty response"))
+	}
+	var apiResp TagSingle
+	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
+		return nil, APIErr("Failed to parse tag response", err)
 	}
+
+	return &apiResp.Data, nil
 }
 
 // ListTags retrieves a list of tags with pagination
 func (c *FireflyClient) ListTags(page, limit int) ([]TagRead, error) {
-	ctx := context.Background()
+	return c.listTags(context.Background(), page, limit)
+}
+
+// listTags retrieves a page of tags
+func (c *FireflyClient) listTags(ctx context.Context, page, limit int) ([]TagRead, error) {
+	page32, limit32 := pageParams(page, limit)
 
 	// Call the API
 	resp, err := c.clientAPI.ListTagWithResponse(ctx, &ListTagParams{
-		Page:  int32Ptr(page),
-		Limit: int32Ptr(limit),
+		Page:  page32,
+		Limit: limit32,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to list tags: %w", err)
+		return nil, APIErr("Failed to list tags", err)
 	}
 
 	// Check response
-	switch resp.StatusCode() {
-	case http.StatusOK:
-		if resp.Body == nil {
-			return nil, fmt.Errorf("empty response")
-		}
-		var apiResp TagArray
-		if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
-			return nil, fmt.Errorf("failed to parse tags response: %w", err)
-		}
-		return apiResp.Data, nil
-	case http.StatusTooManyRequests:
-		return nil, fmt.Errorf("rate limit exceeded")
-	default:
-		return nil, fmt.Errorf("API error (status %d): failed to list tags", resp.StatusCode())
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseErr("Tag", resp.HTTPResponse, resp.Body)
+	}
+	if len(resp.Body) == 0 {
+		return []TagRead{}, nil
 	}
+	var apiResp TagArray
+	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
+		return nil, APIErr("Failed to parse tags response", err)
+	}
+
+	return apiResp.Data, nil
 }
 
 // UpdateTag updates an existing tag
@@ -578,20 +597,15 @@ func (c *FireflyClient) UpdateTag(id string, tag TagModelUpdate) error {
 	// Call the API
 	resp, err := c.clientAPI.UpdateTagWithResponse(ctx, id, &UpdateTagParams{}, tag)
 	if err != nil {
-		return fmt.Errorf("failed to update tag: %w", err)
+		return APIErr("Failed to update tag", err)
 	}
 
 	// Check response
-	switch resp.StatusCode() {
-	case http.StatusOK:
-		return nil
-	case http.StatusNotFound:
-		return fmt.Errorf("tag not found: %s", id)
-	case http.StatusTooManyRequests:
-		return fmt.Errorf("rate limit exceeded")
-	default:
-		return fmt.Errorf("API error (status %d): failed to update tag", resp.StatusCode())
+	if resp.StatusCode() != http.StatusOK {
+		return responseErr("Tag", resp.HTTPResponse, resp.Body)
 	}
+
+	return nil
 }
 
 // DeleteTag deletes a tag
@@ -601,20 +615,15 @@ func (c *FireflyClient) DeleteTag(id string) error {
 	// Call the API
 	resp, err := c.clientAPI.DeleteTagWithResponse(ctx, id, &DeleteTagParams{})
 	if err != nil {
-		return fmt.Errorf("failed to delete tag: %w", err)
+		return APIErr("Failed to delete tag", err)
 	}
 
 	// Check response
-	switch resp.StatusCode() {
-	case http.StatusNoContent:
-		return nil
-	case http.StatusNotFound:
-		return fmt.Errorf("tag not found: %s", id)
-	case http.StatusTooManyRequests:
-		return fmt.Errorf("rate limit exceeded")
-	default:
-		return fmt.Errorf("API error (status %d): failed to delete tag", resp.StatusCode())
+	if resp.StatusCode() != http.StatusNoContent {
+		return responseErr("Tag", resp.HTTPResponse, resp.Body)
 	}
+
+	return nil
 }
 
 // ChartType represents the type of chart to generate
@@ -636,15 +645,16 @@ const (
 	ChartPeriodYearly  ChartPeriod = "1Y"
 )
 
-// GenerateChart generates a chart of the specified type and period
+// GenerateChart generates a chart of the specified type and period. Servers without the chart
+// endpoint return an error matching ErrUnsupportedEndpointSentinel.
 func (c *FireflyClient) GenerateChart(chartType ChartType, period ChartPeriod, start, end time.Time) ([]byte, error) {
 	ctx := context.Background()
 
 	// Build the request manually since charts are not in the OpenAPI spec
-	endpoint := fmt.Sprintf("/api/v1/chart/%s", chartType)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+endpoint, nil)
+	endpoint := fmt.Sprintf("/v1/chart/%s", chartType)
+	req, err := c.newRequest(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, APIErr("Failed to create request", err)
 	}
 
 	// Add query parameters
@@ -655,27 +665,26 @@ func (c *FireflyClient) GenerateChart(chartType ChartType, period ChartPeriod, s
 	req.URL.RawQuery = q.Encode()
 
 	// Add headers
-	req.Header.Set("Authorization", "Bearer "+c.token)
 	req.Header.Set("Accept", "image/png")
 
 	// Make the request
-	resp, err := c.client.Do(req)
+	resp, err := c.doRequest(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate chart: %w", err)
+		return nil, APIErr("Failed to generate chart", err)
 	}
 	defer resp.Body.Close()
 
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, APIErr("Failed to read chart response", err)
+	}
+
 	// Check response
-	switch resp.StatusCode {
-	case http.StatusOK:
-		return io.ReadAll(resp.Body)
-	case http.StatusNotFound:
-		return nil, fmt.Errorf("chart type not found: %s", chartType)
-	case http.StatusTooManyRequests:
-		return nil, fmt.Errorf("rate limit exceeded")
-	default:
-		return nil, fmt.Errorf("API error (status %d): failed to generate chart", resp.StatusCode)
+	if resp.StatusCode != http.StatusOK {
+		return nil, optionalResponseErr("Chart", resp, body)
 	}
+
+	return body, nil
 }
 
 // ReportType represents the type of report to generate
@@ -690,15 +699,16 @@ const (
 	ReportTypeIncome   ReportType = "income"
 )
 
-// GenerateReport generates a report of the specified type
+// GenerateReport generates a report of the specified type. Servers without the report endpoint
+// return an error matching ErrUnsupportedEndpointSentinel.
 func (c *FireflyClient) GenerateReport(reportType ReportType, start, end time.Time, accounts []string) ([]byte, error) {
 	ctx := context.Background()
 
 	// Build the request manually since reports are not in the OpenAPI spec
-	endpoint := fmt.Sprintf("/api/v1/report/%s", reportType)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+endpoint, nil)
+	endpoint := fmt.Sprintf("/v1/report/%s", reportType)
+	req, err := c.newRequest(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, APIErr("Failed to create request", err)
 	}
 
 	// Add query parameters
@@ -711,27 +721,26 @@ func (c *FireflyClient) GenerateReport(reportType ReportType, start, end time.Ti
 	req.URL.RawQuery = q.Encode()
 
 	// Add headers
-	req.Header.Set("Authorization", "Bearer "+c.token)
 	req.Header.Set("Accept", "application/json")
 
 	// Make the request
-	resp, err := c.client.Do(req)
+	resp, err := c.doRequest(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate report: %w", err)
+		return nil, APIErr("Failed to generate report", err)
 	}
 	defer resp.Body.Close()
 
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, APIErr("Failed to read report response", err)
+	}
+
 	// Check response
-	switch resp.StatusCode {
-	case http.StatusOK:
-		return io.ReadAll(resp.Body)
-	case http.StatusNotFound:
-		return nil, fmt.Errorf("report type not found: %s", reportType)
-	case http.StatusTooManyRequests:
-		return nil, fmt.Errorf("rate limit exceeded")
-	default:
-		return nil, fmt.Errorf("API error (status %d): failed to generate report", resp.StatusCode)
+	if resp.StatusCode != http.StatusOK {
+		return nil, optionalResponseErr("Report", resp, body)
 	}
+
+	return body, nil
 }
 
 // CreateBill creates a new bill
@@ -754,20 +763,15 @@ func (c *FireflyClient) CreateBill(bill BillModel) error {
 	// Call the API
 	resp, err := c.clientAPI.StoreBillWithResponse(ctx, &StoreBillParams{}, request)
 	if err != nil {
-		return fmt.Errorf("failed to create bill: %w", err)
+		return APIErr("Failed to create bill", err)
 	}
 
 	// Check response
-	switch resp.StatusCode() {
-	case http.StatusOK, http.StatusCreated:
-		return nil
-	case http.StatusConflict:
-		return fmt.Errorf("bill already exists")
-	case http.StatusTooManyRequests:
-		return fmt.Errorf("rate limit exceeded")
-	default:
-		return fmt.Errorf("API error (status %d): failed to create bill", resp.StatusCode())
+	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusCreated {
+		return responseErr("Bill", resp.HTTPResponse, resp.Body)
 	}
+
+	return nil
 }
 
 // GetBill retrieves a single bill by ID
@@ -777,101 +781,93 @@ func (c *FireflyClient) GetBill(id string) (*BillModel, error) {
 	// Call the API
 	resp, err := c.clientAPI.GetBillWithResponse(ctx, id, &GetBillParams{})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get bill: %w", err)
+		return nil, APIErr("Failed to get bill", err)
 	}
 
 	// Check response
-	switch resp.StatusCode() {
-	case http.StatusOK:
-		if resp.Body == nil {
-			return nil, fmt.Errorf("empty response")
-		}
-		var apiResp BillSingle
-		if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
-			return nil, fmt.Errorf("failed to parse bill response: %w", err)
-		}
-
-		// Convert API response to BillModel
-		bill := &BillModel{
-			ID:                    apiResp.Data.Id,
-			Name:                  apiResp.Data.Attributes.Name,
-			AmountMin:             apiResp.Data.Attributes.AmountMin,
-			AmountMax:             apiResp.Data.Attributes.AmountMax,
-			Date:                  apiResp.Data.Attributes.Date,
-			EndDate:               apiResp.Data.Attributes.EndDate,
-			ExtensionDate:         apiResp.Data.Attributes.ExtensionDate,
-			CurrencyCode:          apiResp.Data.Attributes.CurrencyCode,
-			CurrencyID:            apiResp.Data.Attributes.CurrencyId,
-			CurrencySymbol:        apiResp.Data.Attributes.CurrencySymbol,
-			CurrencyDecimalPlaces: apiResp.Data.Attributes.CurrencyDecimalPlaces,
-			NativeAmountMax:       apiResp.Data.Attributes.NativeAmountMax,
-			Active:                apiResp.Data.Attributes.Active,
-			CreatedAt:             apiResp.Data.Attributes.CreatedAt,
-			UpdatedAt:             apiResp.Data.Attributes.UpdatedAt,
-		}
-
-		return bill, nil
-	case http.StatusNotFound:
-		return nil, fmt.Errorf("bill not found: %s", id)
-	case http.StatusTooManyRequests:
-		return nil, fmt.Errorf("rate limit exceeded")
-	default:
-		return nil, fmt.Errorf("API error (status %d): failed to get bill", resp.StatusCode())
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseErr("Bill", resp.HTTPResponse, resp.Body)
+	}
+	if resp.Body == nil {
+		return nil, APIErr("No bill data found", fmt.Errorf("empty response"))
 	}
+	var apiResp BillSingle
+	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
+		return nil, APIErr("Failed to parse bill response", err)
+	}
+
+	// Convert API response to BillModel
+	bill := &BillModel{
+		ID:                    apiResp.Data.Id,
+		Name:                  apiResp.Data.Attributes.Name,
+		AmountMin:             apiResp.Data.Attributes.AmountMin,
+		AmountMax:             apiResp.Data.Attributes.AmountMax,
+		Date:                  apiResp.Data.Attributes.Date,
+		EndDate:               apiResp.Data.Attributes.EndDate,
+		ExtensionDate:         apiResp.Data.Attributes.ExtensionDate,
+		CurrencyCode:          apiResp.Data.Attributes.CurrencyCode,
+		CurrencyID:            apiResp.Data.Attributes.CurrencyId,
+		CurrencySymbol:        apiResp.Data.Attributes.CurrencySymbol,
+		CurrencyDecimalPlaces: apiResp.Data.Attributes.CurrencyDecimalPlaces,
+		NativeAmountMax:       apiResp.Data.Attributes.NativeAmountMax,
+		Active:                apiResp.Data.Attributes.Active,
+		CreatedAt:             apiResp.Data.Attributes.CreatedAt,
+		UpdatedAt:             apiResp.Data.Attributes.UpdatedAt,
+	}
+
+	return bill, nil
 }
 
 // ListBills retrieves a list of bills with pagination
 func (c *FireflyClient) ListBills(page, limit int) ([]BillModel, error) {
 	ctx := context.Background()
 
+	page32, limit32 := pageParams(page, limit)
+
 	// Call the API
 	resp, err := c.clientAPI.ListBillWithResponse(ctx, &ListBillParams{
-		Page:  int32Ptr(page),
-		Limit: int32Ptr(limit),
+		Page:  page32,
+		Limit: limit32,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to list bills: %w", err)
+		return nil, APIErr("Failed to list bills", err)
 	}
 
 	// Check response
-	switch resp.StatusCode() {
-	case http.StatusOK:
-		if resp.Body == nil {
-			return nil, fmt.Errorf("empty response")
-		}
-		var apiResp BillArray
-		if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
-			return nil, fmt.Errorf("failed to parse bills response: %w", err)
-		}
-
-		bills := make([]BillModel, 0, len(apiResp.Data))
-		for _, billRead := range apiResp.Data {
-			bill := BillModel{
-				ID:                    billRead.Id,
-				Name:                  billRead.Attributes.Name,
-				AmountMin:             billRead.Attributes.AmountMin,
-				AmountMax:             billRead.Attributes.AmountMax,
-				Date:                  billRead.Attributes.Date,
-				EndDate:               billRead.Attributes.EndDate,
-				ExtensionDate:         billRead.Attributes.ExtensionDate,
-				CurrencyCode:          billRead.Attributes.CurrencyCode,
-				CurrencyID:            billRead.Attributes.CurrencyId,
-				CurrencySymbol:        billRead.Attributes.CurrencySymbol,
-				CurrencyDecimalPlaces: billRead.Attributes.CurrencyDecimalPlaces,
-				NativeAmountMax:       billRead.Attributes.NativeAmountMax,
-				Active:                billRead.Attributes.Active,
-				CreatedAt:             billRead.Attributes.CreatedAt,
-				UpdatedAt:             billRead.Attributes.UpdatedAt,
-			}
-			bills = append(bills, bill)
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseErr("Bill", resp.HTTPResponse, resp.Body)
+	}
+	if len(resp.Body) == 0 {
+		return []BillModel{}, nil
+	}
+	var apiResp BillArray
+	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
+		return nil, APIErr("Failed to parse bills response", err)
+	}
+
+	bills := make([]BillModel, 0, len(apiResp.Data))
+	for _, billRead := range apiResp.Data {
+		bill := BillModel{
+			ID:                    billRead.Id,
+			Name:                  billRead.Attributes.Name,
+			AmountMin:             billRead.Attributes.AmountMin,
+			AmountMax:             billRead.Attributes.AmountMax,
+			Date:                  billRead.Attributes.Date,
+			EndDate:               billRead.Attributes.EndDate,
+			ExtensionDate:         billRead.Attributes.ExtensionDate,
+			CurrencyCode:          billRead.Attributes.CurrencyCode,
+			CurrencyID:            billRead.Attributes.CurrencyId,
+			CurrencySymbol:        billRead.Attributes.CurrencySymbol,
+			CurrencyDecimalPlaces: billRead.Attributes.CurrencyDecimalPlaces,
+			NativeAmountMax:       billRead.Attributes.NativeAmountMax,
+			Active:                billRead.Attributes.Active,
+			CreatedAt:             billRead.Attributes.CreatedAt,
+			UpdatedAt:             billRead.Attributes.UpdatedAt,
 		}
-
-		return bills, nil
-	case http.StatusTooManyRequests:
-		return nil, fmt.Errorf("rate limit exceeded")
-	default:
-		return nil, fmt.Errorf("API error (status %d): failed to list bills", resp.StatusCode())
+		bills = append(bills, bill)
 	}
+
+	return bills, nil
 }
 
 // UpdateBill updates an existing bill
@@ -896,20 +892,15 @@ func (c *FireflyClient) UpdateBill(id string, bill BillModel) error {
 	// Call the API
 	resp, err := c.clientAPI.UpdateBillWithResponse(ctx, id, &UpdateBillParams{}, update)
 	if err != nil {
-		return fmt.Errorf("failed to update bill: %w", err)
+		return APIErr("Failed to update bill", err)
 	}
 
 	// Check response
-	switch resp.StatusCode() {
-	case http.StatusOK:
-		return nil
-	case http.StatusNotFound:
-		return fmt.Errorf("bill not found: %s", id)
-	case http.StatusTooManyRequests:
-		return fmt.Errorf("rate limit exceeded")
-	default:
-		return fmt.Errorf("API error (status %d): failed to update bill", resp.StatusCode())
+	if resp.StatusCode() != http.StatusOK {
+		return responseErr("Bill", resp.HTTPResponse, resp.Body)
 	}
+
+	return nil
 }
 
 // DeleteBill deletes a bill
@@ -919,20 +910,15 @@ func (c *FireflyClient) DeleteBill(id string) error {
 	// Call the API
 	resp, err := c.clientAPI.DeleteBillWithResponse(ctx, id, &DeleteBillParams{})
 	if err != nil {
-		return fmt.Errorf("failed to delete bill: %w", err)
+		return APIErr("Failed to delete bill", err)
 	}
 
 	// Check response
-	switch resp.StatusCode() {
-	case http.StatusNoContent:
-		return nil
-	case http.StatusNotFound:
-		return fmt.Errorf("bill not found: %s", id)
-	case http.StatusTooManyRequests:
-		return fmt.Errorf("rate limit exceeded")
-	default:
-		return fmt.Errorf("API error (status %d): failed to delete bill", resp.StatusCode())
+	if resp.StatusCode() != http.StatusNoContent {
+		return responseErr("Bill", resp.HTTPResponse, resp.Body)
 	}
+
+	return nil
 }
 
 // ImportFormat represents the format for data import
@@ -965,6 +951,24 @@ type ImportOptions struct {
 	Headers            []string
 	Delimiter          string
 	DateFormat         string
+	ImportTag          string // Tag added to every imported transaction, e.g. the import source
+	ExternalID         string // External ID set on imported transactions without one, e.g. a batch ID
+	ExternalURL        string // External URL set on imported transactions without one
+}
+
+// apply marks a transaction with the import tag and external reference of the options.
+// The transaction's own external ID and URL take precedence.
+func (o *ImportOptions) apply(tx TransactionModel) TransactionModel {
+	if o.ImportTag != "" && !slices.Contains(tx.Tags, o.ImportTag) {
+		tx.Tags = append(slices.Clone(tx.Tags), o.ImportTag)
+	}
+	if tx.ExternalID == "" {
+		tx.ExternalID = o.ExternalID
+	}
+	if tx.ExternalURL == "" {
+		tx.ExternalURL = o.ExternalURL
+	}
+	return tx
 }
 
 // ImportResult represents the result of an import operation
@@ -975,9 +979,25 @@ type ImportResult struct {
 	Errors     []string
 }
 
+// maxPooledImportBuffer is the largest import buffer kept for reuse, so one large import does not
+// pin its memory for the lifetime of the process
+const maxPooledImportBuffer = 4 << 20
+
+// importBufferPool holds the buffers ImportData builds its multipart requests in
+var importBufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// putImportBuffer returns a buffer to importBufferPool unless it grew too large
+func putImportBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledImportBuffer {
+		return
+	}
+	importBufferPool.Put(buf)
+}
+
 // ImportData imports data into Firefly III from the specified format
-func (c *FireflyClient) ImportData(dataType ImportType, format ImportFormat, data []byte, options *ImportOptions) (*ImportResult, error) {
-	ctx := context.Background()
+func (c *FireflyClient) ImportData(ctx context.Context, dataType ImportType, format ImportFormat, data []byte, options *ImportOptions) (*ImportResult, error) {
 	var errs errbuilder.ErrorMap
 
 	// Validate format
@@ -989,8 +1009,11 @@ func (c *FireflyClient) ImportData(dataType ImportType, format ImportFormat, dat
 	// Build the import endpoint based on data type
 	endpoint := fmt.Sprintf("/v1/data/import/%s", dataType)
 
-	// Create multipart form data
-	body := &bytes.Buffer{}
+	// Create multipart form data in a pooled buffer. The buffer goes back to the pool once the
+	// response has been read, when the request body, or a retry of it, is no longer in use.
+	body := importBufferPool.Get().(*bytes.Buffer)
+	body.Reset()
+	defer putImportBuffer(body)
 	writer := multipart.NewWriter(body)
 
 	// Add the file
@@ -1041,6 +1064,24 @@ func (c *FireflyClient) ImportData(dataType ImportType, format ImportFormat, dat
 				return nil, ValidationErr("ImportData", errs)
 			}
 		}
+		if options.ImportTag != "" {
+			if err := writer.WriteField("import_tag", options.ImportTag); err != nil {
+				errs.Set("options", fmt.Errorf("failed to write import_tag: %w", err))
+				return nil, ValidationErr("ImportData", errs)
+			}
+		}
+		if options.ExternalID != "" {
+			if err := writer.WriteField("external_id", options.ExternalID); err != nil {
+				errs.Set("options", fmt.Errorf("failed to write external_id: %w", err))
+				return nil, ValidationErr("ImportData", errs)
+			}
+		}
+		if options.ExternalURL != "" {
+			if err := writer.WriteField("external_url", options.ExternalURL); err != nil {
+				errs.Set("options", fmt.Errorf("failed to write external_url: %w", err))
+				return nil, ValidationErr("ImportData", errs)
+			}
+		}
 	}
 
 	if err := writer.Close(); err != nil {
@@ -1049,7 +1090,7 @@ func (c *FireflyClient) ImportData(dataType ImportType, format ImportFormat, dat
 	}
 
 	// Create request
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+endpoint, body)
+	req, err := c.newRequest(ctx, http.MethodPost, endpoint, body)
 	if err != nil {
 		errs.Set("request", fmt.Errorf("failed to create request: %w", err))
 		return nil, ValidationErr("ImportData", errs)
@@ -1057,12 +1098,14 @@ func (c *FireflyClient) ImportData(dataType ImportType, format ImportFormat, dat
 
 	// Add headers
 	req.Header.Set("Content-Type", writer.FormDataContentType())
-	req.Header.Set("Authorization", "Bearer "+c.token)
 	req.Header.Set("Accept", "application/json")
 
 	// Make the request
-	resp, err := c.client.Do(req)
+	resp, err := c.doRequest(req)
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ContextErr(ctxErr)
+		}
 		errs.Set("request", fmt.Errorf("failed to import data: %w", err))
 		return nil, APIErr("ImportData", errs)
 	}
@@ -1087,14 +1130,7 @@ func (c *FireflyClient) ImportData(dataType ImportType, format ImportFormat, dat
 	case http.StatusBadRequest:
 		errs.Set("validation", fmt.Errorf("invalid import data: %s", string(respBody)))
 		return nil, ValidationErr("ImportData", errs)
-	case http.StatusNotFound:
-		errs.Set("data import", fmt.Errorf("import type not found: %s", dataType))
-		return nil, NotFoundErr("ImportData", errs)
-	case http.StatusTooManyRequests:
-		errs.Set("rate limit", fmt.Errorf("rate limit exceeded"))
-		return nil, RateLimitErr(errs)
 	default:
-		errs.Set("API error", fmt.Errorf("API error (status %d): failed to import data: %s", resp.StatusCode, string(respBody)))
-		return nil, APIErr("ImportData", errs)
+		return nil, responseErr("Import", resp, respBody)
 	}
 }
@@ -1,7 +1,12 @@
 package firefly
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -144,3 +149,135 @@ func TestDataManagementAPIOperations(t *testing.T) {
 
 	t.Log("Data management API operations test placeholder")
 }
+
+// TestManualRequestsTrailingSlash tests that base URLs with and without a trailing slash build identical request paths
+func TestManualRequestsTrailingSlash(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	run := func(baseURL string) []string {
+		paths = nil
+		client, err := NewFireflyClient(baseURL, "test-token")
+		require.NoError(t, err)
+
+		_, err = client.GenerateChart(ChartTypeDefault, ChartPeriodMonthly, start, end)
+		require.NoError(t, err)
+		_, err = client.GenerateReport(ReportTypeDefault, start, end, nil)
+		require.NoError(t, err)
+		_, err = client.ExportData(context.Background(), DataTypeTransactions, ExportFormatCSV)
+		require.NoError(t, err)
+		_, err = client.ImportData(context.Background(), ImportTypeTransactions, ImportFormatCSV, []byte("a,b\n"), nil)
+		require.NoError(t, err)
+
+		return paths
+	}
+
+	withoutSlash := run(server.URL + "/api")
+	withSlash := run(server.URL + "/api/")
+
+	assert.Equal(t, withoutSlash, withSlash)
+	assert.Equal(t, []string{
+		"/api/v1/chart/default",
+		"/api/v1/report/default",
+		"/api/v1/data/export/transactions",
+		"/api/v1/data/import/transactions",
+	}, withSlash)
+}
+
+// TestImportDataReusesBuffers tests that sequential imports each send only their own data, although
+// they build their requests in reused buffers
+func TestImportDataReusesBuffers(t *testing.T) {
+	var files []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("file")
+		require.NoError(t, err)
+		data, err := io.ReadAll(file)
+		require.NoError(t, err)
+		files = append(files, string(data))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, err := NewFireflyClient(server.URL+"/api", "test-token")
+	require.NoError(t, err)
+
+	imports := []string{
+		"date,description,amount\n2024-01-01,A much longer first import,100.00\n",
+		"date,amount\n",
+		"date,description,amount\n2024-01-02,Third,3.00\n",
+	}
+	for _, data := range imports {
+		_, err := client.ImportData(context.Background(), ImportTypeTransactions, ImportFormatCSV, []byte(data), nil)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, imports, files)
+}
+
+// TestExportData tests that the exported CSV is returned as sent
+func TestExportData(t *testing.T) {
+	const csv = "date,description,amount\n2024-03-01,Groceries,-12.50\n2024-03-02,Salary,2500.00\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/data/export/transactions", r.URL.Path)
+		assert.Equal(t, "csv", r.URL.Query().Get("format"))
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="2024_03_transactions.csv"`)
+		w.Write([]byte(csv))
+	}))
+	defer server.Close()
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	data, err := client.ExportData(ctx, DataTypeTransactions, ExportFormatCSV)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(csv), data)
+
+	files, err := client.ExportFiles(ctx, DataTypeTransactions, ExportFormatCSV)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"2024_03_transactions.csv": []byte(csv)}, files)
+}
+
+// TestExportFilesArchive tests that an export of several files is unpacked from its ZIP archive
+func TestExportFilesArchive(t *testing.T) {
+	want := map[string][]byte{
+		"accounts.csv":     []byte("id,name\n1,Checking\n"),
+		"transactions.csv": []byte("id,amount\n7,-12.50\n"),
+	}
+
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	for name, content := range want {
+		file, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = file.Write(content)
+		require.NoError(t, err)
+	}
+	_, err := writer.Create("empty/")
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(archive.Bytes())
+	}))
+	defer server.Close()
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	files, err := client.ExportFiles(context.Background(), DataTypeAccounts, ExportFormatCSV)
+	require.NoError(t, err)
+	assert.Equal(t, want, files)
+}
@@ -2,8 +2,16 @@ package firefly
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ZanzyTHEbar/errbuilder-go"
@@ -28,6 +36,31 @@ const (
 	ErrOAuth2             = "oauth2_error"
 )
 
+// Sentinel errors wrapped by the matching error constructors, for use with errors.Is
+var (
+	ErrNotFoundSentinel     = errors.New("firefly: not found")
+	ErrRateLimitSentinel    = errors.New("firefly: rate limit exceeded")
+	ErrDuplicateSentinel    = errors.New("firefly: duplicate entry")
+	ErrUnauthorizedSentinel = errors.New("firefly: unauthorized")
+	ErrChecksumSentinel     = errors.New("firefly: checksum mismatch")
+
+	ErrUnsupportedVersionSentinel = errors.New("firefly: unsupported on this server version")
+	ErrImporterRunningSentinel    = errors.New("firefly: importer already running")
+
+	// ErrUnsupportedEndpointSentinel is matched when an optional endpoint, such as charts, reports
+	// or insights, does not exist on the server. It does not match ErrNotFoundSentinel, which means
+	// a missing resource.
+	ErrUnsupportedEndpointSentinel = errors.New("firefly: endpoint not available on this server")
+)
+
+// withSentinel wraps err so that errors.Is matches both the sentinel and the original cause
+func withSentinel(sentinel, err error) error {
+	if err == nil {
+		return sentinel
+	}
+	return fmt.Errorf("%w: %w", sentinel, err)
+}
+
 // HTTPError represents an HTTP-specific error with detailed context
 type HTTPError struct {
 	StatusCode   int               `json:"status_code"`
@@ -47,8 +80,86 @@ type OAuth2Error struct {
 	State            string `json:"state,omitempty"`
 }
 
+// ValidationError holds the validation error envelope Firefly III returns with 422 responses
+type ValidationError struct {
+	Message string              `json:"message"`
+	Fields  map[string][]string `json:"fields"` // Messages per readable field name, e.g. "split 1 amount"
+}
+
+// Error implements the error interface for ValidationError
+func (v *ValidationError) Error() string {
+	fields := make([]string, 0, len(v.Fields))
+	for field := range v.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		parts = append(parts, field+": "+strings.Join(v.Fields[field], "; "))
+	}
+	if len(parts) == 0 {
+		return v.Message
+	}
+	return v.Message + " (" + strings.Join(parts, ", ") + ")"
+}
+
+// parseValidationError parses a 422 body such as
+// {"message": "...", "errors": {"transactions.0.amount": ["..."]}}.
+// It returns nil if body is not a validation error envelope.
+func parseValidationError(body []byte) *ValidationError {
+	var envelope struct {
+		Message string                     `json:"message"`
+		Errors  map[string]json.RawMessage `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil || len(envelope.Errors) == 0 {
+		return nil
+	}
+
+	validationErr := &ValidationError{
+		Message: envelope.Message,
+		Fields:  make(map[string][]string, len(envelope.Errors)),
+	}
+	for key, raw := range envelope.Errors {
+		// Messages are usually a list, but accept a single string too
+		var messages []string
+		if err := json.Unmarshal(raw, &messages); err != nil {
+			var message string
+			if err := json.Unmarshal(raw, &message); err != nil {
+				continue
+			}
+			messages = []string{message}
+		}
+		field := validationFieldName(key)
+		validationErr.Fields[field] = append(validationErr.Fields[field], messages...)
+	}
+	return validationErr
+}
+
+// validationFieldName turns a dotted Firefly field key into a readable name,
+// e.g. "transactions.0.source_id" becomes "split 1 source id"
+func validationFieldName(key string) string {
+	segments := strings.Split(key, ".")
+	names := make([]string, 0, len(segments))
+	for i, segment := range segments {
+		index, err := strconv.Atoi(segment)
+		switch {
+		case err != nil:
+			names = append(names, strings.ReplaceAll(segment, "_", " "))
+		case i > 0 && segments[i-1] == "transactions":
+			names[len(names)-1] = "split " + strconv.Itoa(index+1)
+		default:
+			names = append(names, "#"+strconv.Itoa(index+1))
+		}
+	}
+	return strings.Join(names, " ")
+}
+
 // Error implements the error interface for HTTPError
 func (h *HTTPError) Error() string {
+	if h.ResponseTime == 0 {
+		return fmt.Sprintf("HTTP %d: %s %s", h.StatusCode, h.Method, h.URL)
+	}
 	return fmt.Sprintf("HTTP %d: %s %s (took %v)", h.StatusCode, h.Method, h.URL, h.ResponseTime)
 }
 
@@ -85,6 +196,11 @@ func (h *HTTPError) WithBody(body string) *HTTPError {
 
 // HTTPErrorFromResponse creates an HTTPError from an http.Response
 func HTTPErrorFromResponse(resp *http.Response, method, url string, responseTime time.Duration) error {
+	return statusErr("Resource", newHTTPErrorFromResponse(resp, method, url, responseTime))
+}
+
+// newHTTPErrorFromResponse builds an HTTPError carrying the relevant response headers
+func newHTTPErrorFromResponse(resp *http.Response, method, url string, responseTime time.Duration) *HTTPError {
 	httpErr := NewHTTPError(resp.StatusCode, method, url, responseTime)
 
 	// Add relevant headers
@@ -98,23 +214,98 @@ func HTTPErrorFromResponse(resp *http.Response, method, url string, responseTime
 		httpErr.WithHeaders(headers)
 	}
 
-	// Determine error type based on status code
-	switch resp.StatusCode {
+	return httpErr
+}
+
+// maxErrorBodySize is the number of bytes of an error response body that errors keep.
+// Longer bodies, such as HTML error pages, are truncated and end in truncatedBodySuffix.
+const maxErrorBodySize = 64 << 10
+
+// truncatedBodySuffix marks an error body that was cut at maxErrorBodySize
+const truncatedBodySuffix = "... (truncated)"
+
+// readErrorBody reads the body of an unsuccessful response for responseErr, at most one byte
+// more than maxErrorBodySize so the error can tell that it was truncated. A body that ends
+// early, such as a broken chunked stream, is returned as far as it could be read.
+func readErrorBody(resp *http.Response) []byte {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize+1))
+	return body
+}
+
+// responseErr maps an unsuccessful API response to a typed error.
+// Every resource goes through this mapping, so the same HTTP status always produces the same error code.
+// resource names the affected entity (e.g. "Transaction") and body is the already read response body.
+func responseErr(resource string, resp *http.Response, body []byte) error {
+	if resp == nil {
+		return APIErr("No response from Firefly III", fmt.Errorf("%s request returned no response", resource))
+	}
+
+	method, url := "", ""
+	if resp.Request != nil {
+		method = resp.Request.Method
+		url = safeURL(resp.Request.URL)
+	}
+
+	httpErr := newHTTPErrorFromResponse(resp, method, url, 0)
+	if len(body) > maxErrorBodySize {
+		httpErr.WithBody(strings.ToValidUTF8(string(body[:maxErrorBodySize]), "") + truncatedBodySuffix)
+	} else if len(body) > 0 {
+		httpErr.WithBody(string(body))
+	}
+
+	return statusErr(resource, httpErr)
+}
+
+// adminResponseErr maps an unsuccessful response of an administration endpoint to a typed error.
+// Forbidden responses mean the token does not belong to a user with the owner role.
+func adminResponseErr(resource string, resp *http.Response, body []byte) error {
+	err := responseErr(resource, resp, body)
+	if resp != nil && resp.StatusCode == http.StatusForbidden {
+		return AdminRequiredErr(err)
+	}
+	return err
+}
+
+// optionalResponseErr maps an unsuccessful response of an optional endpoint to a typed error.
+// Not found responses mean the server does not offer the endpoint rather than a missing resource.
+func optionalResponseErr(resource string, resp *http.Response, body []byte) error {
+	err := responseErr(resource, resp, body)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) {
+			return UnsupportedEndpointErr(resource, httpErr)
+		}
+		return UnsupportedEndpointErr(resource, nil)
+	}
+	return err
+}
+
+// statusErr returns the typed error matching the status code of an HTTPError
+func statusErr(resource string, httpErr *HTTPError) error {
+	switch httpErr.StatusCode {
 	case http.StatusUnauthorized:
 		return AuthenticationErr(httpErr)
 	case http.StatusForbidden:
 		return AuthorizationErr(httpErr)
 	case http.StatusNotFound:
-		return NotFoundErr("Resource", httpErr)
+		return NotFoundErr(resource, httpErr)
+	case http.StatusConflict:
+		return DuplicateErr(resource, httpErr)
 	case http.StatusTooManyRequests:
 		return RateLimitErr(httpErr)
-	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable:
-		return ServerErr(httpErr)
+	case http.StatusUnprocessableEntity:
+		if validationErr := parseValidationError([]byte(httpErr.Body)); validationErr != nil {
+			return APIValidationErr(resource, validationErr, httpErr)
+		}
+		return ClientErr(httpErr)
 	default:
-		if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+		if httpErr.StatusCode >= 400 && httpErr.StatusCode < 500 {
 			return ClientErr(httpErr)
 		}
-		return ServerErr(httpErr)
+		if httpErr.StatusCode >= 500 {
+			return ServerErr(httpErr)
+		}
+		return APIErr("Unexpected "+resource+" response", httpErr)
 	}
 }
 
@@ -128,7 +319,7 @@ func AuthenticationErr(err error) error {
 		WithCode(errbuilder.CodeUnauthenticated).
 		WithMsg("Authentication Failed").
 		WithDetails(errbuilder.NewErrDetails(errs)).
-		WithCause(err)
+		WithCause(withSentinel(ErrUnauthorizedSentinel, err))
 }
 
 // AuthorizationErr returns an authorization error
@@ -144,6 +335,19 @@ func AuthorizationErr(err error) error {
 		WithCause(err)
 }
 
+// AdminRequiredErr returns an authorization error for administration endpoints called without an owner token
+func AdminRequiredErr(err error) error {
+	errs := make(errbuilder.ErrorMap)
+	errs.Set("error_type", ErrAuthorization)
+	errs.Set("help", "This endpoint requires a token of a user with the owner role")
+
+	return errbuilder.NewErrBuilder().
+		WithCode(errbuilder.CodePermissionDenied).
+		WithMsg("Admin Permission Required").
+		WithDetails(errbuilder.NewErrDetails(errs)).
+		WithCause(err)
+}
+
 // NetworkErr returns a network error
 func NetworkErr(err error) error {
 	errs := make(errbuilder.ErrorMap)
@@ -198,13 +402,13 @@ func ClientErr(err error) error {
 
 // ContextErr returns a context-related error
 func ContextErr(err error) error {
-	if err == context.Canceled {
+	if errors.Is(err, context.Canceled) {
 		return errbuilder.NewErrBuilder().
 			WithCode(errbuilder.CodeCanceled).
 			WithMsg("Request Cancelled").
 			WithCause(err)
 	}
-	if err == context.DeadlineExceeded {
+	if errors.Is(err, context.DeadlineExceeded) {
 		return TimeoutErr(err)
 	}
 	return errbuilder.NewErrBuilder().
@@ -268,8 +472,75 @@ func AttachmentValidationErr(errors errbuilder.ErrorMap) error {
 		WithDetails(errbuilder.NewErrDetails(errors))
 }
 
+// AttachmentIntegrityErr returns an error for downloaded attachment content that does not match the stored hash
+func AttachmentIntegrityErr(attachmentID, want, got string) error {
+	errs := make(errbuilder.ErrorMap)
+	errs.Set("attachment_id", attachmentID)
+	errs.Set("expected_hash", want)
+	errs.Set("actual_hash", got)
+
+	return errbuilder.NewErrBuilder().
+		WithCode(errbuilder.CodeDataLoss).
+		WithMsg("Attachment Integrity Check Failed").
+		WithDetails(errbuilder.NewErrDetails(errs)).
+		WithCause(ErrChecksumSentinel)
+}
+
+// UnsupportedVersionErr returns an error for a feature that needs a newer Firefly III server
+func UnsupportedVersionErr(feature, required, actual string) error {
+	errs := make(errbuilder.ErrorMap)
+	if feature != "" {
+		errs.Set("feature", feature)
+	}
+	errs.Set("required_version", required)
+	errs.Set("server_version", actual)
+
+	return errbuilder.NewErrBuilder().
+		WithCode(errbuilder.CodeFailedPrecondition).
+		WithMsg(fmt.Sprintf("Unsupported on this server version: requires Firefly III %s, server runs %s", required, actual)).
+		WithDetails(errbuilder.NewErrDetails(errs)).
+		WithCause(ErrUnsupportedVersionSentinel)
+}
+
+// UnsupportedEndpointErr returns an error for an optional endpoint the server does not offer
+func UnsupportedEndpointErr(feature string, err error) error {
+	errs := make(errbuilder.ErrorMap)
+	errs.Set("feature", feature)
+
+	return errbuilder.NewErrBuilder().
+		WithCode(errbuilder.CodeUnimplemented).
+		WithMsg(feature + " Not Available On This Server").
+		WithDetails(errbuilder.NewErrDetails(errs)).
+		WithCause(withSentinel(ErrUnsupportedEndpointSentinel, err))
+}
+
+// ImporterRunningErr returns an error for starting an importer that is still running
+func ImporterRunningErr(name string) error {
+	errs := make(errbuilder.ErrorMap)
+	errs.Set("importer", name)
+
+	return errbuilder.NewErrBuilder().
+		WithCode(errbuilder.CodeAborted).
+		WithMsg(fmt.Sprintf("Importer %s is already running", name)).
+		WithDetails(errbuilder.NewErrDetails(errs)).
+		WithCause(ErrImporterRunningSentinel)
+}
+
 // APIErr returns an error for API failures
+// Cancelled or timed out requests are reported as context errors instead.
 func APIErr(msg string, err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return ContextErr(err)
+	}
+
+	// Transport errors quote the request URL, which must not leak credentials
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		if u, parseErr := url.Parse(urlErr.URL); parseErr == nil {
+			urlErr.URL = safeURL(u)
+		}
+	}
+
 	return errbuilder.NewErrBuilder().
 		WithCode(errbuilder.CodeInternal).
 		WithMsg(msg).
@@ -281,7 +552,7 @@ func NotFoundErr(resourceType string, err error) error {
 	return errbuilder.NewErrBuilder().
 		WithCode(errbuilder.CodeNotFound).
 		WithMsg(resourceType + " Not Found").
-		WithCause(err)
+		WithCause(withSentinel(ErrNotFoundSentinel, err))
 }
 
 // DuplicateErr returns a duplicate entry error
@@ -289,7 +560,7 @@ func DuplicateErr(resourceType string, err error) error {
 	return errbuilder.NewErrBuilder().
 		WithCode(errbuilder.CodeAlreadyExists).
 		WithMsg("Duplicate " + resourceType).
-		WithCause(err)
+		WithCause(withSentinel(ErrDuplicateSentinel, err))
 }
 
 // RateLimitErr returns a rate limit error
@@ -297,7 +568,7 @@ func RateLimitErr(err error) error {
 	return errbuilder.NewErrBuilder().
 		WithCode(errbuilder.CodeResourceExhausted).
 		WithMsg("Rate Limit Exceeded").
-		WithCause(err)
+		WithCause(withSentinel(ErrRateLimitSentinel, err))
 }
 
 // ValidationErr creates a generic validation error
@@ -308,21 +579,67 @@ func ValidationErr(entity string, errs errbuilder.ErrorMap) error {
 		WithDetails(errbuilder.NewErrDetails(errs))
 }
 
+// APIValidationErr returns the validation error of a rejected API request.
+// The details hold the messages per field and errors.As finds the *ValidationError.
+func APIValidationErr(resource string, validationErr *ValidationError, httpErr *HTTPError) error {
+	var errs errbuilder.ErrorMap
+	for field, messages := range validationErr.Fields {
+		errs.Set(field, strings.Join(messages, "; "))
+	}
+
+	msg := resource + " validation failed"
+	if validationErr.Message != "" {
+		msg += ": " + validationErr.Message
+	}
+
+	return errbuilder.NewErrBuilder().
+		WithCode(errbuilder.CodeInvalidArgument).
+		WithMsg(msg).
+		WithDetails(errbuilder.NewErrDetails(errs)).
+		WithCause(fmt.Errorf("%w: %w", validationErr, httpErr))
+}
+
+// BatchErr aggregates the per-item errors of a batch operation, keyed by item ID.
+// The item errors stay reachable through errors.Is and errors.As.
+func BatchErr(operation string, errs map[string]error) error {
+	details := make(errbuilder.ErrorMap)
+	causes := make([]error, 0, len(errs))
+	for id, err := range errs {
+		details.Set(id, err)
+		causes = append(causes, fmt.Errorf("%s: %w", id, err))
+	}
+
+	return errbuilder.NewErrBuilder().
+		WithCode(errbuilder.CodeAborted).
+		WithMsg(fmt.Sprintf("%s failed for %d item(s)", operation, len(errs))).
+		WithDetails(errbuilder.NewErrDetails(details)).
+		WithCause(errors.Join(causes...))
+}
+
 // validateTransaction validates a transaction and returns an error map
 func validateTransaction(tx TransactionModel) errbuilder.ErrorMap {
 	var errs errbuilder.ErrorMap
 
-	if tx.Amount <= 0 {
+	if !(tx.Amount > 0) || math.IsInf(tx.Amount, 0) {
 		errs.Set("amount", "Amount must be greater than 0")
+	} else if _, err := moneyFromFloat(tx.Amount); err != nil {
+		errs.Set("amount", "Amount is too large")
 	}
-	if tx.Currency == "" {
-		errs.Set("currency", "Currency is required")
+	if tx.ForeignAmount != nil {
+		if _, err := moneyFromFloat(*tx.ForeignAmount); err != nil {
+			errs.Set("foreign_amount", "Foreign amount must be a finite number")
+		}
+	}
+	if tx.Currency == "" && tx.CurrencyID == "" {
+		errs.Set("currency", "Currency or currency ID is required")
 	}
 	if tx.Description == "" {
 		errs.Set("description", "Description is required")
 	}
 	if tx.TransType == "" {
 		errs.Set("type", "Transaction type is required")
+	} else if _, ok := ParseTransactionKind(tx.TransType); !ok {
+		errs.Set("type", fmt.Sprintf("Invalid transaction type: %s", tx.TransType))
 	}
 	if tx.Date.IsZero() {
 		errs.Set("date", "Date is required")
@@ -331,6 +648,37 @@ func validateTransaction(tx TransactionModel) errbuilder.ErrorMap {
 	return errs
 }
 
+// validateTransactionGroup validates the splits of one transaction group and returns an error map
+// with keys such as "splits[1].currency". Besides each split on its own, the splits must be in the
+// same currency, as the splits of a withdrawal share its source account, those of a deposit its
+// destination account and those of a transfer both. Amounts in another currency go in
+// ForeignAmount and ForeignCurrency.
+func validateTransactionGroup(splits []TransactionModel) errbuilder.ErrorMap {
+	var errs errbuilder.ErrorMap
+
+	for i, split := range splits {
+		for field, err := range validateTransaction(split) {
+			errs.Set(fmt.Sprintf("splits[%d].%s", i, field), err)
+		}
+	}
+	if len(splits) < 2 {
+		return errs
+	}
+
+	first := splits[0]
+	for i := 1; i < len(splits); i++ {
+		split := splits[i]
+		switch {
+		case split.Currency != "" && first.Currency != "" && !strings.EqualFold(split.Currency, first.Currency):
+			errs.Set(fmt.Sprintf("splits[%d].currency", i), fmt.Sprintf("Currency %s differs from %s of split 0, use the foreign amount for other currencies", split.Currency, first.Currency))
+		case split.Currency == "" && first.Currency == "" && split.CurrencyID != first.CurrencyID:
+			errs.Set(fmt.Sprintf("splits[%d].currency", i), fmt.Sprintf("Currency ID %s differs from %s of split 0, use the foreign amount for other currencies", split.CurrencyID, first.CurrencyID))
+		}
+	}
+
+	return errs
+}
+
 // validateAccount validates an account and returns an error map
 func validateAccount(account AccountModel) errbuilder.ErrorMap {
 	var errs errbuilder.ErrorMap
@@ -344,6 +692,16 @@ func validateAccount(account AccountModel) errbuilder.ErrorMap {
 	if account.Currency == "" {
 		errs.Set("currency", "Currency is required")
 	}
+	if account.Type == string(ShortAccountTypePropertyAsset) {
+		switch AccountRoleProperty(account.Role) {
+		case AccountRolePropertyDefaultAsset, AccountRolePropertySharedAsset, AccountRolePropertySavingAsset,
+			AccountRolePropertyCcAsset, AccountRolePropertyCashWalletAsset:
+		case "":
+			errs.Set("role", "Account role is required for asset accounts")
+		default:
+			errs.Set("role", fmt.Sprintf("Invalid account role %q", account.Role))
+		}
+	}
 
 	return errs
 }
@@ -1,11 +1,21 @@
 package firefly
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
 	"errors"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/ZanzyTHEbar/errbuilder-go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZanzyTHEbar/fireflyiii-client-go/fireflytest"
 )
 
 // TestCustomErrorHandling tests custom error types and handling
@@ -120,6 +130,191 @@ func TestHTTPErrorCodes(t *testing.T) {
 	}
 }
 
+// TestResponseErrorConsistency tests that different resources map the same status to the same error code
+func TestResponseErrorConsistency(t *testing.T) {
+	testCases := []struct {
+		status int
+		code   errbuilder.ErrCode
+	}{
+		{http.StatusNotFound, errbuilder.CodeNotFound},
+		{http.StatusTooManyRequests, errbuilder.CodeResourceExhausted},
+		{http.StatusUnauthorized, errbuilder.CodeUnauthenticated},
+		{http.StatusInternalServerError, errbuilder.CodeInternal},
+	}
+
+	for _, tc := range testCases {
+		t.Run(http.StatusText(tc.status), func(t *testing.T) {
+			server := mockServer(t, tc.status, `{"message":"error"}`)
+			defer server.Close()
+
+			client, err := NewFireflyClient(server.URL, "test-token")
+			require.NoError(t, err)
+
+			_, tagErr := client.GetTag("1")
+			_, txErr := client.GetTransaction(context.Background(), "1")
+			require.Error(t, tagErr)
+			require.Error(t, txErr)
+
+			assert.Equal(t, tc.code, errbuilder.CodeOf(tagErr))
+			assert.Equal(t, errbuilder.CodeOf(tagErr), errbuilder.CodeOf(txErr))
+
+			var httpErr *HTTPError
+			require.True(t, errors.As(tagErr, &httpErr))
+			assert.Equal(t, tc.status, httpErr.StatusCode)
+		})
+	}
+}
+
+// TestSentinelErrors tests that the error constructors wrap their sentinels
+func TestSentinelErrors(t *testing.T) {
+	cause := errors.New("cause")
+
+	testCases := []struct {
+		name     string
+		err      error
+		sentinel error
+	}{
+		{"not found", NotFoundErr("Transaction", cause), ErrNotFoundSentinel},
+		{"rate limit", RateLimitErr(cause), ErrRateLimitSentinel},
+		{"duplicate", DuplicateErr("Account", cause), ErrDuplicateSentinel},
+		{"unauthorized", AuthenticationErr(cause), ErrUnauthorizedSentinel},
+		{"nil cause", NotFoundErr("Tag", nil), ErrNotFoundSentinel},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ErrorIs(t, tc.err, tc.sentinel)
+			if tc.name != "nil cause" {
+				assert.ErrorIs(t, tc.err, cause)
+			}
+		})
+	}
+
+	t.Run("from response", func(t *testing.T) {
+		server := mockServer(t, http.StatusNotFound, `{"message":"Resource not found"}`)
+		defer server.Close()
+
+		client, err := NewFireflyClient(server.URL, "test-token")
+		require.NoError(t, err)
+
+		_, err = client.GetTag("1")
+		assert.ErrorIs(t, err, ErrNotFoundSentinel)
+		assert.NotErrorIs(t, err, ErrRateLimitSentinel)
+	})
+}
+
+// TestValidationErrorEnvelope tests that 422 bodies are flattened into a ValidationError
+func TestValidationErrorEnvelope(t *testing.T) {
+	body := `{
+		"message": "The given data was invalid.",
+		"errors": {
+			"transactions.0.amount": ["The amount must be more than zero."],
+			"transactions.1.source_id": ["This value is invalid for this field.", "The source account does not exist."],
+			"group_title": ["A group title is mandatory when there is more than one split."]
+		}
+	}`
+	server := mockServer(t, http.StatusUnprocessableEntity, body)
+	defer server.Close()
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	tx := TransactionModel{
+		Currency:    "EUR",
+		Amount:      12.5,
+		TransType:   "withdrawal",
+		Description: "Coffee",
+		Date:        time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for name, err := range map[string]error{
+		"create": client.ImportTransaction(context.Background(), tx),
+		"update": client.UpdateTransaction(context.Background(), "1", tx),
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Error(t, err)
+			assert.Equal(t, errbuilder.CodeInvalidArgument, errbuilder.CodeOf(err))
+
+			var validationErr *ValidationError
+			require.True(t, errors.As(err, &validationErr))
+			assert.Equal(t, "The given data was invalid.", validationErr.Message)
+			assert.Equal(t, map[string][]string{
+				"split 1 amount":    {"The amount must be more than zero."},
+				"split 2 source id": {"This value is invalid for this field.", "The source account does not exist."},
+				"group title":       {"A group title is mandatory when there is more than one split."},
+			}, validationErr.Fields)
+			assert.Contains(t, err.Error(), "split 1 amount: The amount must be more than zero.")
+
+			var httpErr *HTTPError
+			require.True(t, errors.As(err, &httpErr))
+			assert.Equal(t, http.StatusUnprocessableEntity, httpErr.StatusCode)
+		})
+	}
+
+	// Other 422 bodies stay client errors
+	assert.Nil(t, parseValidationError([]byte(`{"message": "Unprocessable"}`)))
+	assert.Nil(t, parseValidationError([]byte(`not json`)))
+}
+
+// TestRedactSensitive tests that errors never carry the Authorization header and that
+// RedactSensitive strips the body and query of error responses
+func TestRedactSensitive(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.HandleFunc(http.MethodGet, "/v1/search/transactions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Authorization", r.Header.Get("Authorization"))
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"message": "Server error", "exception": "QueryException", "request": {"authorization": "` +
+			r.Header.Get("Authorization") + `", "email": "jane@example.com"}}`))
+	})
+	server.Handle(http.MethodPut, "/v1/transactions/1", http.StatusUnprocessableEntity,
+		`{"message": "The given data was invalid.", "errors": {"transactions.0.amount": ["The amount must be more than zero."]}, "input": {"notes": "private"}}`)
+
+	httpErrOf := func(t *testing.T, err error) *HTTPError {
+		t.Helper()
+		var httpErr *HTTPError
+		require.True(t, errors.As(err, &httpErr))
+		return httpErr
+	}
+
+	t.Run("default", func(t *testing.T) {
+		client, err := NewFireflyClient(server.URL, "test-token")
+		require.NoError(t, err)
+
+		_, err = client.SearchTransactions(context.Background(), "jane doe")
+		require.Error(t, err)
+		httpErr := httpErrOf(t, err)
+		assert.NotContains(t, httpErr.Headers, "Authorization")
+		assert.NotContains(t, httpErr.Headers, "Set-Cookie")
+		assert.NotContains(t, err.Error(), "Bearer test-token")
+	})
+
+	t.Run("redacted", func(t *testing.T) {
+		config := DefaultClientConfig().WithRedactSensitive()
+		config.BaseURL = server.URL
+		config.Token = "test-token"
+		client, err := NewFireflyClientWithConfig(config)
+		require.NoError(t, err)
+
+		_, err = client.SearchTransactions(context.Background(), "jane doe")
+		require.Error(t, err)
+		httpErr := httpErrOf(t, err)
+		assert.NotContains(t, httpErr.Headers, "Authorization")
+		assert.JSONEq(t, `{"message": "Server error"}`, httpErr.Body)
+		assert.NotContains(t, httpErr.URL, "jane")
+		assert.Contains(t, httpErr.URL, "query=REDACTED")
+		assert.NotContains(t, err.Error(), "test-token")
+
+		// Validation errors are kept
+		tx := TransactionModel{Currency: "EUR", Amount: 1, TransType: "withdrawal", Description: "Coffee", Date: time.Now()}
+		err = client.UpdateTransaction(context.Background(), "1", tx)
+		var validationErr *ValidationError
+		require.True(t, errors.As(err, &validationErr))
+		assert.Contains(t, validationErr.Fields, "split 1 amount")
+		assert.NotContains(t, httpErrOf(t, err).Body, "private")
+	})
+}
+
 // TestErrorRecovery tests error recovery mechanisms
 func TestErrorRecovery(t *testing.T) {
 	// TODO: Test error recovery when available
@@ -155,3 +350,87 @@ func TestNetworkErrorHandling(t *testing.T) {
 	// TODO: Test network error handling when available
 	t.Log("Network error handling test placeholder")
 }
+
+// TestGzipErrorBody tests that gzip encoded error bodies are decoded like successful responses,
+// also when the Accept-Encoding header was set by the caller and net/http leaves them encoded
+func TestGzipErrorBody(t *testing.T) {
+	gzipped := func(body string) []byte {
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		writer.Write([]byte(body))
+		writer.Close()
+		return buf.Bytes()
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		if r.URL.Path == "/v1/about" {
+			w.Write(gzipped(`{"data": {"version": "6.1.0", "api_version": "2.1.0", "os": "Linux", "php_version": "8.3.0"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(gzipped(`{"message": "Database connection lost"}`))
+	}))
+	defer server.Close()
+
+	for _, tt := range []struct {
+		name           string
+		acceptEncoding string
+	}{
+		{"decoded by net/http", ""},
+		{"Accept-Encoding set by the caller", "gzip"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewFireflyClient(server.URL, "test-token")
+			require.NoError(t, err)
+			if tt.acceptEncoding != "" {
+				client.AddMiddleware(headerMiddleware{name: "Accept-Encoding", value: tt.acceptEncoding})
+			}
+			ctx := context.Background()
+
+			_, err = client.GetAccount(ctx, "1")
+			require.Error(t, err)
+			var httpErr *HTTPError
+			require.True(t, errors.As(err, &httpErr))
+			assert.Equal(t, http.StatusInternalServerError, httpErr.StatusCode)
+			assert.JSONEq(t, `{"message": "Database connection lost"}`, httpErr.Body)
+
+			_, err = client.Do(ctx, http.MethodGet, "/v1/accounts/1", nil, nil)
+			require.True(t, errors.As(err, &httpErr))
+			assert.JSONEq(t, `{"message": "Database connection lost"}`, httpErr.Body)
+
+			var about struct {
+				Data struct {
+					Version string `json:"version"`
+				} `json:"data"`
+			}
+			_, err = client.Do(ctx, http.MethodGet, "/v1/about", nil, &about)
+			require.NoError(t, err)
+			assert.Equal(t, "6.1.0", about.Data.Version)
+		})
+	}
+}
+
+// TestLargeErrorBodyTruncated tests that errors keep only the start of very large error bodies
+func TestLargeErrorBodyTruncated(t *testing.T) {
+	page := "<html>" + strings.Repeat("stack frame\n", maxErrorBodySize) + "</html>"
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/accounts/1", http.StatusBadGateway, page)
+	server.Handle(http.MethodGet, "/v1/data/export/transactions", http.StatusBadGateway, page)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = client.GetAccount(ctx, "1")
+	var httpErr *HTTPError
+	require.True(t, errors.As(err, &httpErr))
+	assert.Len(t, httpErr.Body, maxErrorBodySize+len(truncatedBodySuffix))
+	assert.True(t, strings.HasPrefix(httpErr.Body, "<html>stack frame"))
+	assert.True(t, strings.HasSuffix(httpErr.Body, truncatedBodySuffix))
+
+	_, err = client.ExportData(ctx, DataTypeTransactions, ExportFormatCSV)
+	require.True(t, errors.As(err, &httpErr))
+	assert.Len(t, httpErr.Body, maxErrorBodySize+len(truncatedBodySuffix))
+}
@@ -0,0 +1,229 @@
+package firefly
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ZanzyTHEbar/errbuilder-go"
+)
+
+// ExchangeRateModel represents an exchange rate between two currencies on a date
+type ExchangeRateModel struct {
+	ID   string
+	From string  // Code of the base currency
+	To   string  // Code of the destination currency
+	Rate float64 // Amount of To currency for 1 From currency
+	Date time.Time
+}
+
+// exchangeRateList holds the rates of one currency pair as listed on a day
+type exchangeRateList struct {
+	listed string // Day the rates were listed; on later days they are listed again
+	rates  []ExchangeRateModel
+}
+
+// exchangeRateModelFromRead converts an API exchange rate to an ExchangeRateModel
+func exchangeRateModelFromRead(rateRead CurrencyExchangeRateRead) (ExchangeRateModel, error) {
+	rate := ExchangeRateModel{
+		ID:   rateRead.Id,
+		From: stringValue(rateRead.Attributes.FromCurrencyCode),
+		To:   stringValue(rateRead.Attributes.ToCurrencyCode),
+		Date: timeValue(rateRead.Attributes.Date),
+	}
+	if rateRead.Attributes.Rate != nil {
+		value, err := strconv.ParseFloat(*rateRead.Attributes.Rate, 64)
+		if err != nil {
+			return ExchangeRateModel{}, APIErr("Failed to parse exchange rate", err)
+		}
+		rate.Rate = value
+	}
+	return rate, nil
+}
+
+// ListExchangeRates retrieves the exchange rates from one currency to another with pagination.
+// Servers older than Firefly III 6.2.0 have no exchange rates and return an error
+// matching ErrUnsupportedVersionSentinel. Unknown currency codes are a validation error.
+func (c *FireflyClient) ListExchangeRates(ctx context.Context, from, to string, page, limit int) ([]ExchangeRateModel, error) {
+	if err := c.requireFeature(ctx, "exchange rates"); err != nil {
+		return nil, err
+	}
+
+	page32, limit32 := pageParams(page, limit)
+
+	// Call the API
+	resp, err := c.clientAPI.ListSpecificCurrencyExchangeRatesWithResponse(ctx, from, to, &ListSpecificCurrencyExchangeRatesParams{
+		Page:  page32,
+		Limit: limit32,
+	})
+	if err != nil {
+		return nil, APIErr("Failed to list exchange rates", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK {
+		if resp.StatusCode() == http.StatusNotFound {
+			// Unknown currencies are not found as well, so only report servers that are too old
+			if err := c.requireVersion(ctx, "exchange rates", featureMinVersions["exchange rates"]); errors.Is(err, ErrUnsupportedVersionSentinel) {
+				return nil, err
+			}
+			var errs errbuilder.ErrorMap
+			errs.Set("currency", fmt.Sprintf("Unknown currency in %s to %s", from, to))
+			return nil, ValidationErr("ExchangeRate", errs)
+		}
+		return nil, responseErr("Exchange rate", resp.HTTPResponse, resp.Body)
+	}
+
+	// Convert API response to ExchangeRateModels
+	if resp.HTTPResponse == nil || len(resp.Body) == 0 {
+		return []ExchangeRateModel{}, nil
+	}
+
+	var apiResp CurrencyExchangeRateArray
+	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
+		return nil, APIErr("Failed to parse exchange rates response", err)
+	}
+
+	rates := make([]ExchangeRateModel, 0, len(apiResp.Data))
+	for _, rateRead := range apiResp.Data {
+		rate, err := exchangeRateModelFromRead(rateRead)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, nil
+}
+
+// GetExchangeRate returns how many units of currency to one unit of currency from is worth on date.
+// It uses the most recent rate on or before date, and the inverse of the to/from rate when only
+// that one is stored. Rates are cached per day for the lifetime of the client, separately for
+// each WithToken token, and the rates of a currency pair are listed at most once a day.
+// Without a rate the error matches ErrNotFoundSentinel; unknown currencies are a validation error.
+func (c *FireflyClient) GetExchangeRate(ctx context.Context, from, to string, date time.Time) (float64, error) {
+	from, to = strings.ToUpper(strings.TrimSpace(from)), strings.ToUpper(strings.TrimSpace(to))
+
+	var errs errbuilder.ErrorMap
+	if from == "" {
+		errs.Set("from", "From currency is required")
+	}
+	if to == "" {
+		errs.Set("to", "To currency is required")
+	}
+	if errs != nil {
+		return 0, ValidationErr("ExchangeRate", errs)
+	}
+	if from == to {
+		return 1, nil
+	}
+
+	day := date.Format(time.DateOnly)
+	scope := cacheScope(ctx)
+	key := scope + from + "/" + to + "/" + day
+
+	c.rateMu.Lock()
+	rate, ok := c.exchangeRates[key]
+	c.rateMu.Unlock()
+	if ok {
+		return rate, nil
+	}
+
+	rate, err := c.findExchangeRate(ctx, from, to, day)
+	if errors.Is(err, ErrNotFoundSentinel) {
+		// The from/to listing succeeded, so a failed inverse listing still means no rate
+		inverse, inverseErr := c.findExchangeRate(ctx, to, from, day)
+		switch {
+		case inverseErr == nil:
+			rate, err = 1/inverse, nil
+		case errbuilder.CodeOf(inverseErr) != errbuilder.CodeInvalidArgument:
+			err = inverseErr
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	c.rateMu.Lock()
+	if c.exchangeRates == nil {
+		c.exchangeRates = make(map[string]float64)
+	}
+	c.exchangeRates[key] = rate
+	c.exchangeRates[scope+to+"/"+from+"/"+day] = 1 / rate
+	c.rateMu.Unlock()
+
+	return rate, nil
+}
+
+// findExchangeRate returns the most recent from/to rate dated on or before day
+func (c *FireflyClient) findExchangeRate(ctx context.Context, from, to, day string) (float64, error) {
+	rates, err := c.pairExchangeRates(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	var best *ExchangeRateModel
+	for i, rate := range rates {
+		if rate.Rate <= 0 || rate.Date.Format(time.DateOnly) > day {
+			continue
+		}
+		if best == nil || rate.Date.After(best.Date) {
+			best = &rates[i]
+		}
+	}
+	if best == nil {
+		return 0, NotFoundErr("Exchange rate", fmt.Errorf("no exchange rate from %s to %s on or before %s", from, to, day))
+	}
+
+	return best.Rate, nil
+}
+
+// pairExchangeRates returns all rates from one currency to another, listing them at most once a day.
+// The cache is not locked during the listing, so concurrent first lookups may each list the rates.
+func (c *FireflyClient) pairExchangeRates(ctx context.Context, from, to string) ([]ExchangeRateModel, error) {
+	key := cacheScope(ctx) + from + "/" + to
+	today := time.Now().Format(time.DateOnly)
+
+	c.rateMu.Lock()
+	list, ok := c.rateLists[key]
+	c.rateMu.Unlock()
+	if ok && list.listed == today {
+		return list.rates, nil
+	}
+
+	rates, err := listAllPages(ctx, func(ctx context.Context, page, limit int) ([]ExchangeRateModel, error) {
+		return c.ListExchangeRates(ctx, from, to, page, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.rateMu.Lock()
+	if c.rateLists == nil {
+		c.rateLists = make(map[string]exchangeRateList)
+	}
+	c.rateLists[key] = exchangeRateList{listed: today, rates: rates}
+	c.rateMu.Unlock()
+
+	return rates, nil
+}
+
+// ConvertAmount converts an amount from one currency to another with the exchange rate on date,
+// rounded to the decimals of the destination currency
+func (c *FireflyClient) ConvertAmount(ctx context.Context, amount float64, from, to string, date time.Time) (float64, error) {
+	rate, err := c.GetExchangeRate(ctx, from, to, date)
+	if err != nil {
+		return 0, err
+	}
+
+	converted, err := moneyFromFloat(amount * rate)
+	if err != nil {
+		return 0, err
+	}
+	return converted.Round(c.currencyDecimals(to)).Float64(), nil
+}
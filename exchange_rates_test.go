@@ -0,0 +1,159 @@
+package firefly
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ZanzyTHEbar/errbuilder-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZanzyTHEbar/fireflyiii-client-go/fireflytest"
+)
+
+// eurUSDRatesJSON holds two EUR to USD rates, returned out of date order
+const eurUSDRatesJSON = `{"data": [
+	{"type": "exchange-rates", "id": "2", "attributes": {"from_currency_code": "EUR", "to_currency_code": "USD", "rate": "1.100000000000", "date": "2024-03-01T00:00:00+00:00"}},
+	{"type": "exchange-rates", "id": "1", "attributes": {"from_currency_code": "EUR", "to_currency_code": "USD", "rate": "1.080000000000", "date": "2024-02-01T00:00:00+00:00"}}
+], "meta": {}}`
+
+// TestGetExchangeRate tests looking up and caching exchange rates
+func TestGetExchangeRate(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/exchange-rates/rates/EUR/USD", http.StatusOK, eurUSDRatesJSON)
+	server.Handle(http.MethodGet, "/v1/exchange-rates/rates/USD/JPY", http.StatusOK, `{"data": [], "meta": {}}`)
+	server.Handle(http.MethodGet, "/v1/exchange-rates/rates/JPY/USD", http.StatusOK, `{"data": [
+		{"type": "exchange-rates", "id": "3", "attributes": {"from_currency_code": "JPY", "to_currency_code": "USD", "rate": "0.0064", "date": "2024-02-10T00:00:00+00:00"}}
+	], "meta": {}}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("most recent rate", func(t *testing.T) {
+		rate, err := client.GetExchangeRate(ctx, "eur", "USD", time.Date(2024, 2, 20, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		assert.Equal(t, 1.08, rate)
+
+		rate, err = client.GetExchangeRate(ctx, "EUR", "USD", time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		assert.Equal(t, 1.1, rate)
+	})
+
+	t.Run("cache hit", func(t *testing.T) {
+		before := len(server.RequestsTo(http.MethodGet, "/v1/exchange-rates/rates/EUR/USD"))
+		rate, err := client.GetExchangeRate(ctx, "EUR", "USD", time.Date(2024, 2, 20, 12, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		assert.Equal(t, 1.08, rate)
+
+		// The inverse rate of the same day is cached as well
+		inverse, err := client.GetExchangeRate(ctx, "USD", "EUR", time.Date(2024, 2, 20, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		assert.InDelta(t, 1/1.08, inverse, 1e-12)
+
+		assert.Len(t, server.RequestsTo(http.MethodGet, "/v1/exchange-rates/rates/EUR/USD"), before)
+		assert.Empty(t, server.RequestsTo(http.MethodGet, "/v1/exchange-rates/rates/USD/EUR"))
+	})
+
+	t.Run("inverse rate and conversion", func(t *testing.T) {
+		amount, err := client.ConvertAmount(ctx, 10, "USD", "JPY", time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		assert.Equal(t, 1563.0, amount, "rounded to the zero decimals of JPY")
+
+		amount, err = client.ConvertAmount(ctx, 12.34, "EUR", "EUR", time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, 12.34, amount)
+	})
+
+	t.Run("pair listed once", func(t *testing.T) {
+		before := len(server.RequestsTo(http.MethodGet, "/v1/exchange-rates/rates/EUR/USD"))
+		for day := 2; day <= 5; day++ {
+			rate, err := client.GetExchangeRate(ctx, "EUR", "USD", time.Date(2024, 3, day, 0, 0, 0, 0, time.UTC))
+			require.NoError(t, err)
+			assert.Equal(t, 1.1, rate)
+		}
+		assert.Len(t, server.RequestsTo(http.MethodGet, "/v1/exchange-rates/rates/EUR/USD"), before)
+	})
+
+	t.Run("no rate", func(t *testing.T) {
+		_, err := client.GetExchangeRate(ctx, "EUR", "USD", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrNotFoundSentinel)
+
+		_, err = client.GetExchangeRate(ctx, "", "USD", time.Now())
+		assert.Error(t, err)
+	})
+}
+
+// TestGetExchangeRateSlowServer tests that a slow rate listing does not block other currency pairs
+func TestGetExchangeRateSlowServer(t *testing.T) {
+	release := make(chan struct{})
+	var waiting atomic.Bool
+	server := fireflytest.NewServer(t)
+	server.HandleFunc(http.MethodGet, "/v1/exchange-rates/rates/EUR/USD", func(w http.ResponseWriter, r *http.Request) {
+		waiting.Store(true)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(eurUSDRatesJSON))
+	})
+	server.Handle(http.MethodGet, "/v1/exchange-rates/rates/JPY/USD", http.StatusOK, `{"data": [
+		{"type": "exchange-rates", "id": "3", "attributes": {"from_currency_code": "JPY", "to_currency_code": "USD", "rate": "0.0064", "date": "2024-02-10T00:00:00+00:00"}}
+	], "meta": {}}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+	date := time.Date(2024, 2, 20, 0, 0, 0, 0, time.UTC)
+
+	slow := make(chan error, 1)
+	go func() {
+		_, err := client.GetExchangeRate(ctx, "EUR", "USD", date)
+		slow <- err
+	}()
+	require.Eventually(t, waiting.Load, 5*time.Second, time.Millisecond)
+
+	rate, err := client.GetExchangeRate(ctx, "JPY", "USD", date)
+	require.NoError(t, err)
+	assert.Equal(t, 0.0064, rate)
+
+	close(release)
+	require.NoError(t, <-slow)
+}
+
+// TestGetExchangeRateUnsupported tests that servers without exchange rates are reported as too old
+func TestGetExchangeRateUnsupported(t *testing.T) {
+	ctx := context.Background()
+	date := time.Date(2024, 2, 20, 0, 0, 0, 0, time.UTC)
+
+	t.Run("old server", func(t *testing.T) {
+		server := fireflytest.NewServer(t)
+		server.Handle(http.MethodGet, "/v1/about", http.StatusOK, aboutJSON("6.1.24"))
+
+		client, err := NewFireflyClient(server.URL, "test-token")
+		require.NoError(t, err)
+
+		_, err = client.GetExchangeRate(ctx, "EUR", "USD", date)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrUnsupportedVersionSentinel)
+		assert.Len(t, server.RequestsTo(http.MethodGet, "/v1/exchange-rates/rates/EUR/USD"), 1)
+		assert.Empty(t, server.RequestsTo(http.MethodGet, "/v1/exchange-rates/rates/USD/EUR"))
+	})
+
+	t.Run("unknown currency", func(t *testing.T) {
+		server := fireflytest.NewServer(t)
+		server.Handle(http.MethodGet, "/v1/about", http.StatusOK, aboutJSON("6.2.8"))
+
+		client, err := NewFireflyClient(server.URL, "test-token")
+		require.NoError(t, err)
+
+		_, err = client.GetExchangeRate(ctx, "EUR", "XYZ", date)
+		require.Error(t, err)
+		assert.Equal(t, errbuilder.CodeInvalidArgument, errbuilder.CodeOf(err))
+		assert.NotErrorIs(t, err, ErrNotFoundSentinel)
+		assert.NotErrorIs(t, err, ErrUnsupportedVersionSentinel)
+		assert.Empty(t, server.RequestsTo(http.MethodGet, "/v1/exchange-rates/rates/XYZ/EUR"), "no inverse lookup")
+	})
+}
@@ -1,10 +1,12 @@
 package firefly
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -15,6 +17,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ZanzyTHEbar/errbuilder-go"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
 	"golang.org/x/time/rate"
@@ -38,9 +41,24 @@ type FireflyClientInterface interface {
 	// It takes a slice of TransactionModel and returns an error if the operation fails.
 	ImportTransactions(ctx context.Context, transactions []TransactionModel) error
 
+	// ImportTransactionsWithOptions is like ImportTransactions but tags each transaction
+	// with the import tag and external reference of options.
+	ImportTransactionsWithOptions(ctx context.Context, transactions []TransactionModel, options *ImportOptions) error
+
+	// CreateTransaction creates a transaction and returns it with its ID and split journal ID.
+	CreateTransaction(ctx context.Context, tx TransactionModel) (*TransactionModel, error)
+
+	// CreateTransactionGroup creates a transaction with several splits and returns it with the journal ID of every split.
+	CreateTransactionGroup(ctx context.Context, group TransactionGroupModel) (*TransactionGroupModel, error)
+
+	// CloneTransaction copies a transaction group with all of its splits to newDate and returns the copy.
+	CloneTransaction(ctx context.Context, id string, newDate time.Time) (*TransactionModel, error)
+
 	// GetTransaction retrieves a transaction by its ID.
 	// It returns the transaction model and an error if the operation fails.
 	GetTransaction(ctx context.Context, id string) (*TransactionModel, error)
+	GetTransactions(ctx context.Context, ids []string) ([]TransactionModel, error)
+	GetTransactionGroup(ctx context.Context, id string) (*TransactionGroupModel, error)
 
 	// ListTransactions retrieves a paginated list of transactions.
 	// page: The page number to retrieve (starts at 1)
@@ -48,11 +66,40 @@ type FireflyClientInterface interface {
 	// It returns a slice of transactions and an error if the operation fails.
 	ListTransactions(ctx context.Context, page, limit int) ([]TransactionModel, error)
 
+	// ListTransactionsFiltered retrieves transactions matching the filter, ordered by filter.Sort.
+	// It returns a slice of transactions and an error if the operation fails.
+	ListTransactionsFiltered(ctx context.Context, filter TransactionFilter) ([]TransactionModel, error)
+
+	// ListAllTransactions retrieves every transaction, following the pagination to the last page.
+	ListAllTransactions(ctx context.Context) ([]TransactionModel, error)
+
+	// IterateTransactions calls fn for every transaction, loading one page at a time.
+	// An error returned by fn stops the iteration and is returned.
+	IterateTransactions(ctx context.Context, fn func(TransactionModel) error) error
+
+	// ListTransactionGroups retrieves transaction groups matching the filter with all of their splits.
+	ListTransactionGroups(ctx context.Context, filter TransactionFilter) ([]TransactionGroupModel, error)
+
+	// ListTransactionsUpdatedSince retrieves the transactions on a page that changed after since,
+	// for incremental syncs. It returns the changes and the timestamp for the next sync.
+	ListTransactionsUpdatedSince(ctx context.Context, since time.Time, page, limit int) (*TransactionChanges, error)
+
+	// ListTransactionIDs retrieves the ID and update time of every transaction between start and end,
+	// a lightweight listing for diffing against a local copy.
+	ListTransactionIDs(ctx context.Context, start, end time.Time) ([]TransactionRef, error)
+
 	// UpdateTransaction updates an existing transaction identified by id.
 	// It takes the transaction ID and a TransactionModel with the updated values.
 	// Returns an error if the operation fails.
 	UpdateTransaction(ctx context.Context, id string, tx TransactionModel) error
 
+	// PatchTransaction changes only the fields set in changes and leaves all other fields as stored.
+	PatchTransaction(ctx context.Context, id string, changes TransactionPatch) error
+
+	// DeleteTransactionSplit removes one split from a transaction group, keeping the other splits.
+	// It returns an error if the split is the last one of the group.
+	DeleteTransactionSplit(ctx context.Context, groupID, journalID string) error
+
 	// DeleteTransaction removes a transaction from Firefly III.
 	// It takes the transaction ID and returns an error if the operation fails.
 	DeleteTransaction(ctx context.Context, id string) error
@@ -70,12 +117,18 @@ type FireflyClientInterface interface {
 	// Returns an error if the operation fails.
 	CreateAccount(ctx context.Context, name, accountType, currency string) error
 
+	// CreateAccountFull creates an account and returns it with the ID Firefly III assigned.
+	CreateAccountFull(ctx context.Context, account AccountModel) (*AccountModel, error)
+
 	// UpdateBalance updates the balance of an account.
 	// accountID: The ID of the account to update
 	// balance: The new balance information
 	// Returns an error if the operation fails.
 	UpdateBalance(ctx context.Context, accountID string, balance Balance) error
 
+	// SetAccountIncludeNetWorth sets whether an account counts towards the net worth.
+	SetAccountIncludeNetWorth(ctx context.Context, accountID string, include bool) error
+
 	// GetAccount retrieves an account by its ID.
 	// It returns the account model and an error if the operation fails.
 	GetAccount(ctx context.Context, id string) (*AccountModel, error)
@@ -86,6 +139,13 @@ type FireflyClientInterface interface {
 	// It returns a slice of accounts and an error if the operation fails.
 	ListAccounts(ctx context.Context, page, limit int) ([]AccountModel, error)
 
+	// ListAccountsFiltered retrieves accounts matching the filter, ordered by filter.Sort.
+	// It returns a slice of accounts and an error if the operation fails.
+	ListAccountsFiltered(ctx context.Context, filter AccountFilter) ([]AccountModel, error)
+
+	// ListAllAccounts retrieves every account, following the pagination to the last page.
+	ListAllAccounts(ctx context.Context) ([]AccountModel, error)
+
 	// DeleteAccount removes an account from Firefly III.
 	// It takes the account ID and returns an error if the operation fails.
 	DeleteAccount(ctx context.Context, id string) error
@@ -94,6 +154,9 @@ type FireflyClientInterface interface {
 	// It returns a slice of matching accounts and an error if the operation fails.
 	SearchAccounts(ctx context.Context, query string) ([]AccountModel, error)
 
+	// ListAccountsByBalanceThreshold retrieves the asset accounts in currency with a balance below the threshold.
+	ListAccountsByBalanceThreshold(ctx context.Context, below float64, currency string) ([]AccountModel, error)
+
 	// Category Operations
 
 	// CreateCategory creates a new category in Firefly III.
@@ -127,6 +190,10 @@ type FireflyClientInterface interface {
 	// It returns the category model and an error if the operation fails.
 	GetCategoryByName(ctx context.Context, name string) (*CategoryModel, error)
 
+	// ListCategoryTransactions retrieves the transactions of a category matching the filter.
+	// It returns a slice of transactions and an error if the operation fails.
+	ListCategoryTransactions(ctx context.Context, categoryID string, filter TransactionFilter) ([]TransactionModel, error)
+
 	// Attachment Operations
 
 	// AddCategoryAttachment adds an attachment to a category.
@@ -158,34 +225,82 @@ type FireflyClientInterface interface {
 	// Returns an error if the operation fails.
 	UpdateCategoryAttachment(ctx context.Context, attachmentID string, filename, title, notes string) error
 
+	// GetTransactionAttachments retrieves all attachments of a transaction group.
+	GetTransactionAttachments(ctx context.Context, transactionID string) ([]AttachmentModel, error)
+
+	// AddTransactionAttachment adds an attachment, such as a receipt, to the first split of a transaction group.
+	AddTransactionAttachment(ctx context.Context, transactionID string, filename string, file []byte, title, notes string) (*AttachmentModel, error)
+
+	// AddAttachment attaches a file to any attachable Firefly III object.
+	AddAttachment(ctx context.Context, upload AttachmentUpload) (*AttachmentModel, error)
+
+	// GetAttachment retrieves the metadata of an attachment.
+	GetAttachment(ctx context.Context, attachmentID string) (*AttachmentModel, error)
+
+	// DownloadAttachment downloads the content and filename of an attachment.
+	DownloadAttachment(ctx context.Context, attachmentID string) ([]byte, string, error)
+
+	// StreamAttachment downloads the content of an attachment without buffering it. The caller must close the reader.
+	StreamAttachment(ctx context.Context, attachmentID string) (io.ReadCloser, error)
+
 	// Budget Operations
-	CreateBudget(budget BudgetModel) error
-	GetBudget(id string) (*BudgetModel, error)
-	ListBudgets(page, limit int) ([]BudgetModel, error)
-	UpdateBudget(id string, budget BudgetModel) error
-	DeleteBudget(id string) error
-	SearchBudgets(query string) ([]BudgetModel, error)
+	CreateBudget(ctx context.Context, budget BudgetModel) error
+	GetBudget(ctx context.Context, id string) (*BudgetModel, error)
+	ListBudgets(ctx context.Context, page, limit int) ([]BudgetModel, error)
+	ListBudgetsForPeriod(ctx context.Context, start, end time.Time, page, limit int) ([]BudgetModel, error)
+	UpdateBudget(ctx context.Context, id string, budget BudgetModel) error
+	DeleteBudget(ctx context.Context, id string) error
+	SearchBudgets(ctx context.Context, query string) ([]BudgetModel, error)
 
 	// Budget Limit Operations
-	SetBudgetLimit(budgetID string, limit BudgetLimitModel) error
-	GetBudgetLimits(budgetID string) ([]BudgetLimitModel, error)
-	UpdateBudgetLimit(limitID string, limit BudgetLimitModel) error
-	DeleteBudgetLimit(limitID string) error
+	SetBudgetLimit(ctx context.Context, budgetID string, limit BudgetLimitModel) error
+	GetBudgetLimits(ctx context.Context, budgetID string) ([]BudgetLimitModel, error)
+	ListBudgetLimits(ctx context.Context, budgetID string, start, end time.Time) ([]BudgetLimitModel, error)
+	GetAllBudgetLimits(ctx context.Context, start, end time.Time) ([]BudgetLimitModel, error)
+	UpdateBudgetLimit(ctx context.Context, limitID string, limit BudgetLimitModel) error
+	DeleteBudgetLimit(ctx context.Context, limitID string) error
 
 	// Data Management Operations
-	ExportData(dataType DataType, format ExportFormat) ([]byte, error)
-	ImportData(dataType ImportType, format ImportFormat, data []byte, options *ImportOptions) (*ImportResult, error)
-	DestroyData(dataType DataType) error
-	BulkUpdateTransactions(query map[string]interface{}) error
-	PurgeData() error
-
-	// Importer Operations
+	ExportData(ctx context.Context, dataType DataType, format ExportFormat) ([]byte, error)
+	ExportFiles(ctx context.Context, dataType DataType, format ExportFormat) (map[string][]byte, error)
+	ImportData(ctx context.Context, dataType ImportType, format ImportFormat, data []byte, options *ImportOptions) (*ImportResult, error)
+	DestroyData(ctx context.Context, dataType DataType) error
+	BulkUpdateTransactions(ctx context.Context, query map[string]interface{}) error
+	PurgeData(ctx context.Context) error
+
+	// Importer Operations, registering and looking up importers is local and takes no context
 	RegisterImporter(importer importers.Importer) error
+	RegisterImporterWithConfig(importer importers.Importer, config importers.ImporterConfig) error
 	GetImporter(name string) (importers.Importer, error)
 	ListImporters() []importers.Importer
-	RunImporter(name string, options importers.ImportOptions) (*importers.ImportResult, error)
-	GetImporterProgress(name string) (*importers.ImportProgress, error)
-	CancelImporter(name string) error
+	RunImporter(ctx context.Context, name string, options importers.ImportOptions) (*importers.ImportResult, error)
+	RunImporterWithConfig(ctx context.Context, name string, config importers.ImporterConfig, options importers.ImportOptions) (*importers.ImportResult, error)
+	GetImporterProgress(ctx context.Context, name string) (*importers.ImportProgress, error)
+	CancelImporter(ctx context.Context, name string) error
+
+	// User Operations, these require a token of a user with the owner role
+	ListUsers(ctx context.Context, page, limit int) ([]UserModel, error)
+	GetUser(ctx context.Context, id string) (*UserModel, error)
+	CreateUser(ctx context.Context, user UserModel) (*UserModel, error)
+	UpdateUser(ctx context.Context, id string, user UserModel) error
+	DeleteUser(ctx context.Context, id string) error
+
+	// Configuration Operations
+	GetConfiguration(ctx context.Context, name string) (*ConfigurationModel, error)
+	SetConfiguration(ctx context.Context, name string, value interface{}) error
+
+	// Server Operations
+
+	// GetAbout retrieves the version information of the server, cached per client.
+	GetAbout(ctx context.Context) (*AboutModel, error)
+
+	// RequireMinVersion returns an error if the server is older than the given version.
+	RequireMinVersion(ctx context.Context, version string) error
+
+	// Raw API Access
+
+	// Do calls an endpoint the client does not wrap and decodes the JSON response into out.
+	Do(ctx context.Context, method, path string, body interface{}, out interface{}) (*http.Response, error)
 }
 
 // Middleware defines the interface for request/response middleware
@@ -214,7 +329,7 @@ func NewLoggingMiddleware(logger func(format string, args ...interface{})) *Logg
 
 // ProcessRequest logs the outgoing request
 func (l *LoggingMiddleware) ProcessRequest(ctx context.Context, req *http.Request) (*http.Request, error) {
-	l.logger("HTTP Request: %s %s", req.Method, req.URL.String())
+	l.logger("HTTP Request: %s %s", req.Method, safeURL(req.URL))
 	return req, nil
 }
 
@@ -224,7 +339,8 @@ func (l *LoggingMiddleware) ProcessResponse(ctx context.Context, resp *http.Resp
 	return resp, nil
 }
 
-// RetryMiddleware implements retry logic as middleware
+// RetryMiddleware implements retry logic as middleware. Only requests that do not change data
+// are retried; POST, PUT, PATCH and DELETE requests are retried by ClientConfig.AutoRetry alone.
 type RetryMiddleware struct {
 	config *RetryConfig
 }
@@ -244,19 +360,26 @@ func (r *RetryMiddleware) ProcessRequest(ctx context.Context, req *http.Request)
 
 // ProcessResponse handles retry logic based on response
 func (r *RetryMiddleware) ProcessResponse(ctx context.Context, resp *http.Response) (*http.Response, error) {
+	if resp.StatusCode < 400 {
+		return resp, nil
+	}
+
 	// Create an HTTPError from the response for retry decision
-	if resp.StatusCode >= 400 {
-		httpErr := &HTTPError{
-			StatusCode: resp.StatusCode,
-			Method:     resp.Request.Method,
-			URL:        resp.Request.URL.String(),
-			Timestamp:  time.Now(),
-		}
+	httpErr := &HTTPError{
+		StatusCode: resp.StatusCode,
+		Method:     resp.Request.Method,
+		URL:        safeURL(resp.Request.URL),
+		Timestamp:  time.Now(),
+	}
 
-		// If this is a retryable error, return the error to trigger retry
-		if r.config.isRetryableError(httpErr) {
-			return nil, httpErr
-		}
+	retryable := r.config.isRetryableError(httpErr)
+	if r.config.ShouldRetry != nil {
+		retryable = r.config.retryOn(resp, nil)
+	}
+
+	// If this is a retryable error, return the error to trigger retry
+	if retryable {
+		return nil, httpErr
 	}
 
 	return resp, nil
@@ -340,6 +463,19 @@ func (m *MiddlewareChain) ProcessResponse(ctx context.Context, resp *http.Respon
 	return currentResp, nil
 }
 
+// retryConfig returns the settings of the first RetryMiddleware in the chain, or nil without one
+func (m *MiddlewareChain) retryConfig() *RetryConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, middleware := range m.middlewares {
+		if retry, ok := middleware.(*RetryMiddleware); ok {
+			return retry.config
+		}
+	}
+	return nil
+}
+
 // WebhookEvent represents a webhook event from Firefly III
 type WebhookEvent struct {
 	ID        string                 `json:"id"`
@@ -347,6 +483,8 @@ type WebhookEvent struct {
 	Timestamp time.Time              `json:"timestamp"`
 	Data      map[string]interface{} `json:"data"`
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+
+	timestampErr error // Why the timestamp could not be read, if it was replaced by the receive time
 }
 
 // WebhookHandler defines the interface for handling webhook events
@@ -366,6 +504,9 @@ func (f WebhookHandlerFunc) HandleEvent(ctx context.Context, event *WebhookEvent
 type WebhookManager struct {
 	handlers map[string][]WebhookHandler
 	mu       sync.RWMutex
+	slots    chan struct{} // Bounds concurrently running handlers, nil means unbounded
+	dedup    *webhookDedup // Drops redelivered events, nil when disabled
+	logger   func(format string, args ...interface{})
 }
 
 // NewWebhookManager creates a new webhook manager
@@ -375,6 +516,51 @@ func NewWebhookManager() *WebhookManager {
 	}
 }
 
+// SetMaxConcurrency limits how many handlers run at the same time; n <= 0 removes the limit
+func (w *WebhookManager) SetMaxConcurrency(n int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if n <= 0 {
+		w.slots = nil
+		return
+	}
+	w.slots = make(chan struct{}, n)
+}
+
+// SetDeduplication drops events delivered again within the configured window, such as
+// redeliveries by Firefly III. Events whose handlers fail are not remembered, so a redelivery retries them.
+// A window of zero or less disables deduplication.
+func (w *WebhookManager) SetDeduplication(config WebhookDedupConfig) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if config.Window <= 0 {
+		w.dedup = nil
+		return
+	}
+	w.dedup = newWebhookDedup(config)
+}
+
+// SetLogger sets a function that receives warnings about events, such as unreadable timestamps
+func (w *WebhookManager) SetLogger(logger func(format string, args ...interface{})) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.logger = logger
+}
+
+// logf passes a warning to the logger, if any
+func (w *WebhookManager) logf(format string, args ...interface{}) {
+	w.mu.RLock()
+	logger := w.logger
+	w.mu.RUnlock()
+
+	if logger != nil {
+		logger(format, args...)
+	}
+}
+
 // RegisterHandler registers a handler for a specific event type
 func (w *WebhookManager) RegisterHandler(eventType string, handler WebhookHandler) {
 	w.mu.Lock()
@@ -394,9 +580,14 @@ func (w *WebhookManager) ProcessWebhook(ctx context.Context, payload []byte) err
 	if err := json.Unmarshal(payload, &event); err != nil {
 		return fmt.Errorf("failed to unmarshal webhook payload: %w", err)
 	}
+	if event.timestampErr != nil {
+		w.logf("Webhook event %s has an unreadable timestamp, using the time it was received: %v", event.ID, event.timestampErr)
+	}
 
 	w.mu.RLock()
 	handlers, exists := w.handlers[event.Type]
+	slots := w.slots
+	dedup := w.dedup
 	w.mu.RUnlock()
 
 	if !exists {
@@ -404,10 +595,28 @@ func (w *WebhookManager) ProcessWebhook(ctx context.Context, payload []byte) err
 		return nil
 	}
 
+	// Drop redelivered events
+	var dedupKeys []string
+	if dedup != nil {
+		dedupKeys = dedup.keys(&event, payload)
+		if dedup.Seen(dedupKeys) {
+			return nil
+		}
+	}
+
 	// Process handlers concurrently
 	errChan := make(chan error, len(handlers))
 	for _, handler := range handlers {
 		go func(h WebhookHandler) {
+			if slots != nil {
+				select {
+				case slots <- struct{}{}:
+					defer func() { <-slots }()
+				case <-ctx.Done():
+					errChan <- ctx.Err()
+					return
+				}
+			}
 			errChan <- h.HandleEvent(ctx, &event)
 		}(handler)
 	}
@@ -421,6 +630,9 @@ func (w *WebhookManager) ProcessWebhook(ctx context.Context, payload []byte) err
 	}
 
 	if len(errs) > 0 {
+		if dedup != nil {
+			dedup.Forget(dedupKeys)
+		}
 		return fmt.Errorf("webhook processing errors: %v", errs)
 	}
 
@@ -429,13 +641,15 @@ func (w *WebhookManager) ProcessWebhook(ctx context.Context, payload []byte) err
 
 // WebhookServer provides an HTTP server for receiving webhooks
 type WebhookServer struct {
-	manager *WebhookManager
-	server  *http.Server
-	secret  string
-	path    string
+	manager   *WebhookManager
+	server    *http.Server
+	secret    string
+	path      string
+	tolerance time.Duration // Maximum age of a signature timestamp, zero accepts any age
 }
 
-// NewWebhookServer creates a new webhook server
+// NewWebhookServer creates a new webhook server. With a secret, deliveries must carry a valid
+// Firefly III signature in the Signature header; unsigned or wrongly signed requests are rejected.
 func NewWebhookServer(addr, path, secret string, manager *WebhookManager) *WebhookServer {
 	if manager == nil {
 		manager = NewWebhookManager()
@@ -453,6 +667,12 @@ func NewWebhookServer(addr, path, secret string, manager *WebhookManager) *Webho
 	}
 }
 
+// SetSignatureTolerance rejects signed deliveries whose signature timestamp differs from the
+// current time by more than tolerance, to guard against replayed requests. Zero disables the check.
+func (ws *WebhookServer) SetSignatureTolerance(tolerance time.Duration) {
+	ws.tolerance = tolerance
+}
+
 // Start starts the webhook server
 func (ws *WebhookServer) Start(ctx context.Context) error {
 	mux := http.NewServeMux()
@@ -489,10 +709,17 @@ func (ws *WebhookServer) handleWebhook(w http.ResponseWriter, r *http.Request) {
 
 	body := json.RawMessage(bodyBytes)
 
-	// TODO: Implement webhook signature verification if secret is provided
+	// Verify the signature before the payload is trusted
 	if ws.secret != "" {
-		// Verify webhook signature here
-		// This would typically involve checking HMAC signature in headers
+		err := verifyWebhookSignature(r.Header.Get(WebhookSignatureHeader), bodyBytes, ws.secret, ws.tolerance, time.Now())
+		switch {
+		case errors.Is(err, errWebhookSignatureMalformed):
+			http.Error(w, "Malformed webhook signature", http.StatusBadRequest)
+			return
+		case err != nil:
+			http.Error(w, "Invalid webhook signature", http.StatusUnauthorized)
+			return
+		}
 	}
 
 	ctx := r.Context()
@@ -515,19 +742,137 @@ type FireflyClient struct {
 	config     *ClientConfig // Store configuration for advanced client
 	middleware *MiddlewareChain
 	webhookMgr *WebhookManager
+
+	tokenSource *refreshingTokenSource // OAuth2 access tokens, nil when using a static token
+	oauthStates *oauthStateStore       // OAuth2 states issued for the authorization code flow
+
+	deviceIntervals sync.Map // Device code -> poll interval announced by the device authorization endpoint
+
+	importerConfigs map[string]importers.ImporterConfig // Configuration each importer was registered with
+
+	importerMu       sync.Mutex          // Guards runningImporters
+	runningImporters map[string]struct{} // Importers with a run in progress, by name
+
+	requestSlots chan struct{} // Client-wide semaphore for concurrent helper requests
+
+	accountMu       sync.Mutex               // Guards the account caches, never held during requests
+	accountCache    map[string]struct{}      // Accounts known to exist, keyed by cache scope, type and lowercased name
+	accountsPending map[string]chan struct{} // Accounts being looked up or created, closed when done
+
+	categoryMu    sync.Mutex               // Serializes category upserts
+	categoryCache map[string]CategoryModel // Categories known to exist, keyed by cache scope and lowercased name
+
+	tagMu    sync.Mutex                     // Guards tagCache, never held during requests
+	tagCache map[string]map[string]struct{} // Tags known to exist by cache scope, keyed by lowercased tag
+
+	currencyMu        sync.Mutex               // Guards currency caches, never held during requests
+	defaultCurrencies map[string]CurrencyModel // Cached default currency, fetched once per cache scope
+	currencies        map[string]CurrencyModel // Cached currency metadata of the configured credentials, keyed by upper case code
+
+	aboutMu sync.Mutex            // Guards abouts, never held during requests
+	abouts  map[string]AboutModel // Cached server information, fetched once per cache scope
+
+	rateMu        sync.Mutex                  // Guards the exchange rate caches, never held during requests
+	exchangeRates map[string]float64          // Cached exchange rates, keyed by cache scope and "FROM/TO/date"
+	rateLists     map[string]exchangeRateList // Listed rates of currency pairs, keyed by cache scope and "FROM/TO"
 }
 
-// TransactionModel represents a financial transaction in our domain model
+// TransactionModel represents a financial transaction in our domain model. A split transaction
+// is described by its group title and its first split; use TransactionGroupModel, from
+// GetTransactionGroup or ListTransactionGroups, to read every split.
 type TransactionModel struct {
 	ID              string
+	JournalID       string // ID of the split within its transaction group, read-only
 	Currency        string
+	CurrencyID      string // ID of the currency (takes precedence over Currency)
 	Amount          float64
-	TransType       string // "deposit" or "withdrawal"
+	AmountRaw       string // Amount exactly as stored by Firefly III, such as "100.00"; only set WithDecimalAmounts
+	TransType       string // One of the TransactionKind values, matched case-insensitively
 	Description     string
 	Date            time.Time
 	Category        string
 	ForeignAmount   *float64
 	ForeignCurrency *string
+	SourceID        string // ID of the source account (takes precedence over SourceName)
+	SourceName      string // Name of the source account
+	DestinationID   string // ID of the destination account (takes precedence over DestinationName)
+	DestinationName string // Name of the destination account
+	Tags            []string
+	ExternalID      string     // Reference to the transaction in another system
+	ExternalURL     string     // Link to the transaction in another system
+	Notes           string     // Markdown notes, sent and read verbatim
+	ProcessDate     *time.Time // When the bank processed the transaction, nil if not set
+	BookDate        *time.Time // When the transaction was booked, nil if not set
+	DueDate         *time.Time // When the transaction is due, nil if not set
+	PaymentDate     *time.Time // When the transaction was paid, nil if not set
+	Latitude        *float64   // Location of the transaction, read-only as the API cannot set it
+	Longitude       *float64   // Location of the transaction, read-only
+	ZoomLevel       *int32     // Map zoom level of the location, read-only
+	CreatedAt       time.Time  // When the transaction was stored in Firefly III, read-only
+	UpdatedAt       time.Time  // When the transaction was last changed in Firefly III, read-only
+}
+
+// TransactionGroupModel represents a transaction group with all of its splits
+type TransactionGroupModel struct {
+	ID     string
+	Title  string // Group title, only set for groups with more than one split
+	Splits []TransactionModel
+}
+
+// Totals returns the net amount of the group per currency code, or per currency ID for splits without a code.
+// Withdrawals count as negative and all other splits as positive, except transfers: they move money
+// between own accounts and count as zero, so a transfer's currency is present with a zero total.
+// Foreign amounts are not included.
+func (g *TransactionGroupModel) Totals() map[string]Money {
+	totals := make(map[string]Money)
+	for _, split := range g.Splits {
+		currency := split.Currency
+		if currency == "" {
+			currency = split.CurrencyID
+		}
+
+		amount := MoneyFromFloat(split.Amount)
+		switch kind, _ := ParseTransactionKind(split.TransType); kind {
+		case TransactionKindWithdrawal:
+			amount = amount.Neg()
+		case TransactionKindTransfer:
+			amount = Money{}
+		}
+		totals[currency] = totals[currency].Add(amount)
+	}
+	return totals
+}
+
+// TransactionKind is a transaction type as understood by Firefly III
+type TransactionKind string
+
+const (
+	TransactionKindDeposit        TransactionKind = "deposit"
+	TransactionKindWithdrawal     TransactionKind = "withdrawal"
+	TransactionKindTransfer       TransactionKind = "transfer"
+	TransactionKindReconciliation TransactionKind = "reconciliation"
+	TransactionKindOpeningBalance TransactionKind = "opening balance"
+)
+
+// ParseTransactionKind normalizes a transaction type such as "Withdrawal" or "opening_balance".
+// It reports false if the type is not known to Firefly III.
+func ParseTransactionKind(s string) (TransactionKind, bool) {
+	normalized := strings.NewReplacer("_", " ", "-", " ").Replace(strings.ToLower(strings.TrimSpace(s)))
+
+	switch kind := TransactionKind(normalized); kind {
+	case TransactionKindDeposit, TransactionKindWithdrawal, TransactionKindTransfer,
+		TransactionKindReconciliation, TransactionKindOpeningBalance:
+		return kind, true
+	}
+	return "", false
+}
+
+// transactionTypeProperty returns the API transaction type for a TransType, normalized when known
+func transactionTypeProperty(transType string) TransactionTypeProperty {
+	if kind, ok := ParseTransactionKind(transType); ok {
+		return TransactionTypeProperty(kind)
+	}
+	return TransactionTypeProperty(transType)
 }
 
 // AccountModel represents a financial account
@@ -539,10 +884,12 @@ type AccountModel struct {
 	Balance  float64
 	IBAN     string
 	Number   string
-	BankName string
 	Active   bool
 	Role     string
-	Include  bool
+
+	// IncludeNetWorth reports whether the account counts towards the net worth. It is the only
+	// include flag Firefly III has; whether an account counts in sums follows from its type.
+	IncludeNetWorth bool
 }
 
 // CategorySpentModel represents spending data for a category
@@ -637,6 +984,18 @@ type OAuth2Config struct {
 	RedirectURL  string   `yaml:"redirect_url" json:"redirect_url"`
 	AuthURL      string   `yaml:"auth_url" json:"auth_url"`
 	TokenURL     string   `yaml:"token_url" json:"token_url"`
+
+	// DeviceAuthURL is the device authorization endpoint used by StartDeviceAuth
+	DeviceAuthURL string `yaml:"device_auth_url" json:"device_auth_url"`
+
+	// RefreshToken, if set, is exchanged for access tokens instead of the client credentials,
+	// such as a refresh token stored after ExchangeOAuth2Code or device authorization.
+	// Rotated refresh tokens are used from then on and passed to ClientConfig.OnTokenRefresh.
+	RefreshToken string `yaml:"refresh_token" json:"refresh_token"`
+
+	// StateTTL is how long a state issued by GenerateOAuth2AuthURL is accepted by ExchangeOAuth2Code.
+	// Zero means DefaultOAuth2StateTTL.
+	StateTTL time.Duration `yaml:"state_ttl" json:"state_ttl"`
 }
 
 // ClientConfig holds configuration for the Firefly client
@@ -650,8 +1009,70 @@ type ClientConfig struct {
 	OAuth2     *OAuth2Config `yaml:"oauth2,omitempty" json:"oauth2,omitempty"`
 	UserAgent  string        `yaml:"user_agent" json:"user_agent"`
 	DebugMode  bool          `yaml:"debug_mode" json:"debug_mode"`
+
+	// AutoCreateAccounts makes ImportTransaction and ImportTransactions create
+	// missing expense/revenue accounts (referenced by name) before storing transactions
+	AutoCreateAccounts bool `yaml:"auto_create_accounts" json:"auto_create_accounts"`
+
+	// AllowInsecure permits plain http base URLs for hosts other than localhost.
+	// Without it the client refuses to send the token unencrypted over the network.
+	AllowInsecure bool `yaml:"allow_insecure" json:"allow_insecure"`
+
+	// ResponseInterceptor, if set, receives the raw body of every API response
+	ResponseInterceptor ResponseInterceptor `yaml:"-" json:"-"`
+
+	// OnTokenRefresh, if set, is called with every new OAuth2 token the client obtains,
+	// so callers can persist it (including a rotated refresh token)
+	OnTokenRefresh func(*oauth2.Token) `yaml:"-" json:"-"`
+
+	// MaxConcurrentRequests bounds the parallelism of batch helpers and webhook handlers.
+	// Zero means DefaultMaxConcurrentRequests.
+	MaxConcurrentRequests int `yaml:"max_concurrent_requests" json:"max_concurrent_requests"`
+
+	// MaxAttachmentSize rejects attachment uploads larger than this many bytes. Zero means no limit.
+	MaxAttachmentSize int64 `yaml:"max_attachment_size" json:"max_attachment_size"`
+
+	// AllowedAttachmentTypes restricts attachment uploads to these MIME types, such as "application/pdf"
+	// or "image/*". Empty allows any type.
+	AllowedAttachmentTypes []string `yaml:"allowed_attachment_types" json:"allowed_attachment_types"`
+
+	// VerifyAttachments checks downloaded attachment content against the hash stored by Firefly III
+	VerifyAttachments bool `yaml:"verify_attachments" json:"verify_attachments"`
+
+	// CheckServerVersion makes methods that need a recent Firefly III release check the
+	// server version first, failing with ErrUnsupportedVersionSentinel on older servers
+	CheckServerVersion bool `yaml:"check_server_version" json:"check_server_version"`
+
+	// Logger, if set, receives warnings about data the client skipped, such as listed
+	// transactions with a malformed amount
+	Logger func(format string, args ...interface{}) `yaml:"-" json:"-"`
+
+	// AutoRetry retries POST, PUT, PATCH and DELETE requests that fail with a network error,
+	// 408, 429 or a 5xx status, up to RetryCount times with exponential backoff from RetryDelay.
+	// A retried create may be stored twice if the server stored it before failing.
+	AutoRetry bool `yaml:"auto_retry" json:"auto_retry"`
+
+	// RetryOn, if set, replaces the default decision of which failures are retried, for AutoRetry,
+	// RetryOperation and the retry middleware of EnableDefaultMiddleware. See RetryConfig.ShouldRetry.
+	RetryOn func(resp *http.Response, err error) bool `yaml:"-" json:"-"`
+
+	// DecimalAmounts keeps the amount strings of the server in TransactionModel.AmountRaw next to
+	// the parsed Amount, so display code can show exactly what Firefly III stored
+	DecimalAmounts bool `yaml:"decimal_amounts" json:"decimal_amounts"`
+
+	// RedactSensitive strips data that may be sensitive from error responses before errors keep
+	// them: bodies are reduced to Firefly III's message and validation errors, credential headers
+	// are removed and query values in the reported URL are replaced
+	RedactSensitive bool `yaml:"redact_sensitive" json:"redact_sensitive"`
+
+	// CoalesceRequests lets concurrent identical GET requests share one request to the server.
+	// If the request that is sent is cancelled, the requests waiting for it fail too.
+	CoalesceRequests bool `yaml:"coalesce_requests" json:"coalesce_requests"`
 }
 
+// DefaultMaxConcurrentRequests is the parallelism used when ClientConfig.MaxConcurrentRequests is not set
+const DefaultMaxConcurrentRequests = 4
+
 // DefaultClientConfig returns a default client configuration
 func DefaultClientConfig() *ClientConfig {
 	return &ClientConfig{
@@ -661,6 +1082,8 @@ func DefaultClientConfig() *ClientConfig {
 		RateLimit:  60, // requests per minute
 		UserAgent:  "firefly-client-go/1.0.0",
 		DebugMode:  false,
+
+		MaxConcurrentRequests: DefaultMaxConcurrentRequests,
 	}
 }
 
@@ -689,29 +1112,110 @@ func (c *ClientConfig) WithRateLimit(limit int) *ClientConfig {
 	return c
 }
 
+// WithMaxConcurrentRequests bounds how many requests batch helpers and webhook handlers run in parallel
+func (c *ClientConfig) WithMaxConcurrentRequests(max int) *ClientConfig {
+	c.MaxConcurrentRequests = max
+	return c
+}
+
+// WithAttachmentLimits restricts the size and MIME types of attachment uploads
+func (c *ClientConfig) WithAttachmentLimits(maxSize int64, allowedTypes ...string) *ClientConfig {
+	c.MaxAttachmentSize = maxSize
+	c.AllowedAttachmentTypes = allowedTypes
+	return c
+}
+
+// WithAttachmentVerification makes attachment downloads fail when the content does not match the stored hash
+func (c *ClientConfig) WithAttachmentVerification() *ClientConfig {
+	c.VerifyAttachments = true
+	return c
+}
+
+// WithServerVersionCheck makes version dependent methods check the server version before calling the API
+func (c *ClientConfig) WithServerVersionCheck() *ClientConfig {
+	c.CheckServerVersion = true
+	return c
+}
+
+// WithAutoRetry makes the client retry failed mutating requests with the configured retry settings
+func (c *ClientConfig) WithAutoRetry() *ClientConfig {
+	c.AutoRetry = true
+	return c
+}
+
+// WithRetryOn sets the predicate that decides which failed requests are retried
+func (c *ClientConfig) WithRetryOn(shouldRetry func(resp *http.Response, err error) bool) *ClientConfig {
+	c.RetryOn = shouldRetry
+	return c
+}
+
+// WithDecimalAmounts keeps the amount strings of the server in TransactionModel.AmountRaw
+func (c *ClientConfig) WithDecimalAmounts() *ClientConfig {
+	c.DecimalAmounts = true
+	return c
+}
+
+// WithRedactSensitive strips potentially sensitive data from the responses errors carry
+func (c *ClientConfig) WithRedactSensitive() *ClientConfig {
+	c.RedactSensitive = true
+	return c
+}
+
+// WithRequestCoalescing lets concurrent identical GET requests share one request to the server
+func (c *ClientConfig) WithRequestCoalescing() *ClientConfig {
+	c.CoalesceRequests = true
+	return c
+}
+
+// WithLogger sets a function that receives the client's warnings
+func (c *ClientConfig) WithLogger(logger func(format string, args ...interface{})) *ClientConfig {
+	c.Logger = logger
+	return c
+}
+
+// WithOnTokenRefresh sets a callback that receives every new OAuth2 token
+func (c *ClientConfig) WithOnTokenRefresh(onRefresh func(*oauth2.Token)) *ClientConfig {
+	c.OnTokenRefresh = onRefresh
+	return c
+}
+
+// WithResponseInterceptor sets a hook that receives the raw body of every API response
+func (c *ClientConfig) WithResponseInterceptor(interceptor ResponseInterceptor) *ClientConfig {
+	c.ResponseInterceptor = interceptor
+	return c
+}
+
 // NewFireflyClient creates a new Firefly III API client
+// Plain http base URLs are only accepted for loopback hosts, use NewFireflyClientWithConfig
+// with AllowInsecure to connect to a remote instance without TLS.
 func NewFireflyClient(baseURL, token string) (*FireflyClient, error) {
-	// Create HTTP client with auth header
-	client := &http.Client{}
+	if err := checkInsecureBaseURL(baseURL, false); err != nil {
+		return nil, err
+	}
+
+	c := &FireflyClient{
+		baseURL:     baseURL,
+		token:       token,
+		importers:   make(map[string]importers.Importer),
+		middleware:  NewMiddlewareChain(),
+		webhookMgr:  NewWebhookManager(),
+		oauthStates: newOAuthStateStore(DefaultOAuth2StateTTL),
+	}
+	c.setMaxConcurrentRequests(DefaultMaxConcurrentRequests)
+
+	// Send every request through the middleware chain
+	c.client = &http.Client{
+		Transport: &middlewareTransport{base: wrapTransport(http.DefaultTransport, nil, nil), chain: c.middleware},
+	}
 
 	// Create the generated client with responses and auth
-	clientAPI, err := NewClientWithResponses(baseURL, WithHTTPClient(client), WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
-		req.Header.Set("Authorization", "Bearer "+token)
-		return nil
-	}))
+	clientAPI, err := NewClientWithResponses(baseURL, WithHTTPClient(c.client), WithRequestEditorFn(c.editRequest))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Firefly III client: %w", err)
 	}
+	c.clientAPI = clientAPI
 
-	return &FireflyClient{
-		baseURL:    baseURL,
-		token:      token,
-		client:     client,
-		clientAPI:  clientAPI,
-		importers:  make(map[string]importers.Importer),
-		middleware: NewMiddlewareChain(),
-		webhookMgr: NewWebhookManager(),
-	}, nil
+	return c, nil
 }
 
 // NewFireflyClientWithConfig creates a new Firefly III API client with advanced configuration
@@ -724,62 +1228,124 @@ func NewFireflyClientWithConfig(config *ClientConfig) (*FireflyClient, error) {
 		return nil, fmt.Errorf("base URL is required")
 	}
 
-	// Create HTTP client with timeout and transport configuration
-	client := &http.Client{
-		Timeout: config.Timeout,
-		Transport: &http.Transport{
-			MaxIdleConns:       10,
-			IdleConnTimeout:    30 * time.Second,
-			DisableCompression: false,
-		},
+	if err := checkInsecureBaseURL(config.BaseURL, config.AllowInsecure); err != nil {
+		return nil, err
 	}
 
-	// Create request editor function for authentication and headers
-	requestEditor := func(ctx context.Context, req *http.Request) error {
-		// Add authentication
-		if config.OAuth2 != nil {
-			// TODO: Implement OAuth2 token refresh logic here
-			// For now, fall back to token if available
-			if config.Token != "" {
-				req.Header.Set("Authorization", "Bearer "+config.Token)
-			}
-		} else if config.Token != "" {
-			req.Header.Set("Authorization", "Bearer "+config.Token)
-		}
+	c := &FireflyClient{
+		baseURL:    config.BaseURL,
+		token:      config.Token,
+		importers:  make(map[string]importers.Importer),
+		config:     config, // Store configuration for later use
+		middleware: NewMiddlewareChain(),
+		webhookMgr: NewWebhookManager(),
+	}
+	c.webhookMgr.SetLogger(c.logf)
 
-		// Add user agent
-		if config.UserAgent != "" {
-			req.Header.Set("User-Agent", config.UserAgent)
-		}
+	c.setMaxConcurrentRequests(config.MaxConcurrentRequests)
 
-		// Add debug headers if enabled
-		if config.DebugMode {
-			req.Header.Set("X-Debug", "true")
-		}
+	// Use an OAuth2 token source when client credentials are configured
+	c.oauthStates = newOAuthStateStore(DefaultOAuth2StateTTL)
+	if config.OAuth2 != nil {
+		c.tokenSource = newOAuth2TokenSource(config.OAuth2, config.Timeout, config.OnTokenRefresh)
+		c.oauthStates = newOAuthStateStore(config.OAuth2.StateTTL)
+	}
 
-		return nil
+	// Create HTTP client with timeout and transport configuration. The middleware chain
+	// wraps all other transport features, so it sees every request once.
+	transport := wrapTransport(&http.Transport{
+		MaxIdleConns:       10,
+		IdleConnTimeout:    30 * time.Second,
+		DisableCompression: false,
+	}, config, c.tokenSource)
+	c.client = &http.Client{
+		Timeout:   config.Timeout,
+		Transport: &middlewareTransport{base: transport, chain: c.middleware},
 	}
 
 	// Create the generated client with responses and auth
-	clientAPI, err := NewClientWithResponses(config.BaseURL, WithHTTPClient(client), WithRequestEditorFn(requestEditor))
+	clientAPI, err := NewClientWithResponses(config.BaseURL, WithHTTPClient(c.client), WithRequestEditorFn(c.editRequest))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Firefly III client: %w", err)
 	}
+	c.clientAPI = clientAPI
 
-	return &FireflyClient{
-		baseURL:    config.BaseURL,
-		token:      config.Token,
-		client:     client,
-		clientAPI:  clientAPI,
-		importers:  make(map[string]importers.Importer),
-		config:     config, // Store configuration for later use
-		middleware: NewMiddlewareChain(),
-		webhookMgr: NewWebhookManager(),
-	}, nil
+	return c, nil
 }
 
 // GetTransaction retrieves a single transaction by ID
 func (c *FireflyClient) GetTransaction(ctx context.Context, id string) (*TransactionModel, error) {
+	txRead, err := c.getTransactionRead(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := c.transactionModel(*txRead)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tx, nil
+}
+
+// GetTransactionGroup retrieves a transaction group by ID with all of its splits
+func (c *FireflyClient) GetTransactionGroup(ctx context.Context, id string) (*TransactionGroupModel, error) {
+	txRead, err := c.getTransactionRead(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := c.transactionGroupModel(*txRead)
+	if err != nil {
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+// GetTransactions retrieves several transactions concurrently, at most MaxConcurrentRequests at a time.
+// Results keep the order of ids. Transactions that could not be fetched are left out of the
+// results and reported together in a BatchErr keyed by ID.
+func (c *FireflyClient) GetTransactions(ctx context.Context, ids []string) ([]TransactionModel, error) {
+	fetched := make([]*TransactionModel, len(ids))
+	failures := make([]error, len(ids))
+
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+
+			release, err := c.acquireSlot(ctx)
+			if err != nil {
+				failures[i] = err
+				return
+			}
+			defer release()
+
+			fetched[i], failures[i] = c.GetTransaction(ctx, id)
+		}(i, id)
+	}
+	wg.Wait()
+
+	transactions := make([]TransactionModel, 0, len(ids))
+	errs := make(map[string]error)
+	for i, tx := range fetched {
+		if failures[i] != nil {
+			errs[ids[i]] = failures[i]
+			continue
+		}
+		transactions = append(transactions, *tx)
+	}
+
+	if len(errs) > 0 {
+		return transactions, BatchErr("GetTransactions", errs)
+	}
+	return transactions, nil
+}
+
+// getTransactionRead retrieves the raw API representation of a transaction group by ID
+func (c *FireflyClient) getTransactionRead(ctx context.Context, id string) (*TransactionRead, error) {
 	// Call the API
 	resp, err := c.clientAPI.GetTransactionWithResponse(ctx, id, &GetTransactionParams{})
 	if err != nil {
@@ -787,17 +1353,11 @@ func (c *FireflyClient) GetTransaction(ctx context.Context, id string) (*Transac
 	}
 
 	// Check response
-	if resp.StatusCode() == http.StatusNotFound {
-		return nil, NotFoundErr("Transaction", fmt.Errorf("transaction not found: %s", id))
-	}
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return nil, RateLimitErr(fmt.Errorf("rate limit exceeded"))
-	}
 	if resp.StatusCode() != http.StatusOK {
-		return nil, APIErr("Failed to get transaction", fmt.Errorf("unexpected status: %s", resp.Status()))
+		return nil, responseErr("Transaction", resp.HTTPResponse, resp.Body)
 	}
 
-	// Convert API response to TransactionModel
+	// Parse API response
 	if resp.HTTPResponse == nil || len(resp.Body) == 0 {
 		return nil, APIErr("No transaction data found", fmt.Errorf("empty response"))
 	}
@@ -807,120 +1367,372 @@ func (c *FireflyClient) GetTransaction(ctx context.Context, id string) (*Transac
 		return nil, APIErr("Failed to parse transaction response", err)
 	}
 
-	tx := &TransactionModel{
-		ID:              apiResp.Data.Id,
-		Description:     stringValue(apiResp.Data.Attributes.GroupTitle),
-		Date:            *apiResp.Data.Attributes.CreatedAt,
-		TransType:       apiResp.Data.Type,
-		Category:        "",
-		Currency:        "",
-		Amount:          0,
-		ForeignAmount:   nil,
-		ForeignCurrency: nil,
+	return &apiResp.Data, nil
+}
+
+// transactionModelFromRead converts an API transaction group into a TransactionModel.
+// Date, category, amount, currency and account details are taken from the first split;
+// transactionGroupModelFromRead keeps every split.
+// locationFromRead returns a location read from the API, or nils when the latitude or longitude
+// is missing or out of range. A negative zoom level is dropped on its own.
+func locationFromRead(latitude, longitude *float64, zoomLevel *int32) (*float64, *float64, *int32) {
+	if latitude == nil || longitude == nil ||
+		!(*latitude >= -90 && *latitude <= 90) || !(*longitude >= -180 && *longitude <= 180) {
+		return nil, nil, nil
+	}
+	if zoomLevel != nil && *zoomLevel < 0 {
+		zoomLevel = nil
 	}
+	return latitude, longitude, zoomLevel
+}
+
+func transactionModelFromRead(txRead TransactionRead) (TransactionModel, error) {
+	tx := TransactionModel{
+		ID:          txRead.Id,
+		Description: stringValue(txRead.Attributes.GroupTitle),
+		Date:        timeValue(txRead.Attributes.CreatedAt),
+		TransType:   txRead.Type,
+		CreatedAt:   timeValue(txRead.Attributes.CreatedAt),
+		UpdatedAt:   timeValue(txRead.Attributes.UpdatedAt),
+	}
+
+	// Handle the first split, which carries the date, type and category of the transaction
+	if len(txRead.Attributes.Transactions) > 0 {
+		split := txRead.Attributes.Transactions[0]
+		tx.JournalID = stringValue(split.TransactionJournalId)
+		tx.Date = split.Date
+		tx.TransType = string(split.Type)
+		tx.Category = stringValue(split.CategoryName)
+
+		// Single split transactions have no group title
+		if tx.Description == "" {
+			tx.Description = split.Description
+		}
 
-	// Handle amount and currency
-	if len(apiResp.Data.Attributes.Transactions) > 0 {
-		split := apiResp.Data.Attributes.Transactions[0]
-		amount, err := strconv.ParseFloat(split.Amount, 64)
+		// Handle amount and currency
+		amount, err := ParseMoney(split.Amount)
 		if err != nil {
-			return nil, APIErr("Failed to parse amount", err)
+			return TransactionModel{}, APIErr("Failed to parse amount", err)
 		}
-		tx.Amount = amount
+		tx.Amount = amount.Float64()
 		if split.CurrencyCode != nil {
 			tx.Currency = *split.CurrencyCode
 		}
+		tx.CurrencyID = stringValue(split.CurrencyId)
 
 		// Handle foreign amount if present
 		if split.ForeignAmount != nil {
-			foreignAmount, err := strconv.ParseFloat(*split.ForeignAmount, 64)
+			foreignAmount, err := ParseMoney(*split.ForeignAmount)
 			if err != nil {
-				return nil, APIErr("Failed to parse foreign amount", err)
+				return TransactionModel{}, APIErr("Failed to parse foreign amount", err)
 			}
-			tx.ForeignAmount = float64Ptr(foreignAmount)
+			tx.ForeignAmount = float64Ptr(foreignAmount.Float64())
 		}
 		if split.ForeignCurrencyCode != nil {
 			tx.ForeignCurrency = split.ForeignCurrencyCode
 		}
+
+		// Handle source and destination accounts
+		tx.SourceID = stringValue(split.SourceId)
+		tx.SourceName = stringValue(split.SourceName)
+		tx.DestinationID = stringValue(split.DestinationId)
+		tx.DestinationName = stringValue(split.DestinationName)
+
+		// Handle tags and external references
+		if split.Tags != nil {
+			tx.Tags = *split.Tags
+		}
+		tx.ExternalID = stringValue(split.ExternalId)
+		tx.ExternalURL = stringValue(split.ExternalUrl)
+		tx.Notes = stringValue(split.Notes)
+
+		// Handle optional dates
+		tx.ProcessDate = split.ProcessDate
+		tx.BookDate = split.BookDate
+		tx.DueDate = split.DueDate
+		tx.PaymentDate = split.PaymentDate
+
+		// Handle location, which is only kept when the coordinates are valid
+		tx.Latitude, tx.Longitude, tx.ZoomLevel = locationFromRead(split.Latitude, split.Longitude, split.ZoomLevel)
 	}
 
 	return tx, nil
 }
 
-// ListTransactions retrieves a list of transactions with pagination
+// transactionGroupModelFromRead converts an API transaction group to a TransactionGroupModel with one model per split
+func transactionGroupModelFromRead(txRead TransactionRead) (TransactionGroupModel, error) {
+	group := TransactionGroupModel{
+		ID:     txRead.Id,
+		Title:  stringValue(txRead.Attributes.GroupTitle),
+		Splits: make([]TransactionModel, 0, len(txRead.Attributes.Transactions)),
+	}
+
+	for _, split := range txRead.Attributes.Transactions {
+		// Convert each split on its own, described by the split rather than the group
+		splitRead := txRead
+		splitRead.Attributes.GroupTitle = nil
+		splitRead.Attributes.Transactions = []TransactionSplit{split}
+
+		tx, err := transactionModelFromRead(splitRead)
+		if err != nil {
+			return TransactionGroupModel{}, err
+		}
+		group.Splits = append(group.Splits, tx)
+	}
+
+	return group, nil
+}
+
+// transactionModel converts an API transaction group into a TransactionModel,
+// keeping the amount string of the server when DecimalAmounts is enabled
+func (c *FireflyClient) transactionModel(txRead TransactionRead) (TransactionModel, error) {
+	tx, err := transactionModelFromRead(txRead)
+	if err != nil {
+		return TransactionModel{}, err
+	}
+	if c.config != nil && c.config.DecimalAmounts && len(txRead.Attributes.Transactions) > 0 {
+		tx.AmountRaw = txRead.Attributes.Transactions[0].Amount
+	}
+	return tx, nil
+}
+
+// transactionGroupModel converts an API transaction group into a TransactionGroupModel,
+// keeping the amount strings of the server when DecimalAmounts is enabled
+func (c *FireflyClient) transactionGroupModel(txRead TransactionRead) (TransactionGroupModel, error) {
+	group, err := transactionGroupModelFromRead(txRead)
+	if err != nil {
+		return TransactionGroupModel{}, err
+	}
+	if c.config != nil && c.config.DecimalAmounts {
+		for i, split := range txRead.Attributes.Transactions {
+			group.Splits[i].AmountRaw = split.Amount
+		}
+	}
+	return group, nil
+}
+
+// transactionModelsFromReads converts listed transaction groups to TransactionModels. Groups that
+// cannot be converted, such as splits with a malformed amount, are skipped with a logged warning
+// so one bad row does not fail the whole list.
+func (c *FireflyClient) transactionModelsFromReads(txReads []TransactionRead) []TransactionModel {
+	transactions := make([]TransactionModel, 0, len(txReads))
+	for _, txRead := range txReads {
+		tx, err := c.transactionModel(txRead)
+		if err != nil {
+			c.logf("Skipping transaction %s: %v", txRead.Id, err)
+			continue
+		}
+		transactions = append(transactions, tx)
+	}
+	return transactions
+}
+
+// ListTransactionGroups retrieves the transaction groups matching the filter with all of their splits,
+// in the requested order
+func (c *FireflyClient) ListTransactionGroups(ctx context.Context, filter TransactionFilter) ([]TransactionGroupModel, error) {
+	txReads, err := c.listTransactionReads(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]TransactionGroupModel, 0, len(txReads))
+	for _, txRead := range txReads {
+		group, err := c.transactionGroupModel(txRead)
+		if err != nil {
+			c.logf("Skipping transaction %s: %v", txRead.Id, err)
+			continue
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+// ListTransactions retrieves a list of transactions with pagination
 func (c *FireflyClient) ListTransactions(ctx context.Context, page, limit int) ([]TransactionModel, error) {
-	// Call the API
-	resp, err := c.clientAPI.ListTransactionWithResponse(ctx, &ListTransactionParams{
-		Page:  int32Ptr(page),
-		Limit: int32Ptr(limit),
-	})
+	return c.ListTransactionsFiltered(ctx, TransactionFilter{Page: page, Limit: limit})
+}
+
+// ListTransactionsFiltered retrieves a list of transactions matching the filter, in the requested order
+func (c *FireflyClient) ListTransactionsFiltered(ctx context.Context, filter TransactionFilter) ([]TransactionModel, error) {
+	txReads, err := c.listTransactionReads(ctx, filter)
 	if err != nil {
-		return nil, APIErr("Failed to list transactions", err)
+		return nil, err
 	}
 
-	// Check response
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return nil, RateLimitErr(fmt.Errorf("rate limit exceeded"))
+	return c.transactionModelsFromReads(txReads), nil
+}
+
+// TransactionChanges is a page of transactions changed since the last sync
+type TransactionChanges struct {
+	Transactions []TransactionModel
+
+	// NextSince is the latest update time seen on the page, or the requested time if nothing changed.
+	// Once all pages are read, pass the latest NextSince to the next sync.
+	NextSince time.Time
+
+	// HasMore reports that the listed page was full and the next page may hold more changes
+	HasMore bool
+}
+
+// ListTransactionsUpdatedSince retrieves the transactions on a page that were created or updated after since.
+// The API cannot filter on the update time, so the page is listed in full and filtered by updated_at.
+func (c *FireflyClient) ListTransactionsUpdatedSince(ctx context.Context, since time.Time, page, limit int) (*TransactionChanges, error) {
+	txReads, err := c.listTransactionReads(ctx, TransactionFilter{Page: page, Limit: limit})
+	if err != nil {
+		return nil, err
 	}
-	if resp.StatusCode() != http.StatusOK {
-		return nil, APIErr("Failed to list transactions", fmt.Errorf("unexpected status: %s", resp.Status()))
+
+	_, limit32 := pageParams(page, limit)
+	changes := &TransactionChanges{
+		Transactions: []TransactionModel{},
+		NextSince:    since,
+		HasMore:      len(txReads) == int(*limit32),
 	}
 
-	// Convert API response to TransactionModels
-	if resp.HTTPResponse == nil || len(resp.Body) == 0 {
-		return []TransactionModel{}, nil
+	for _, txRead := range txReads {
+		updatedAt := txRead.Attributes.UpdatedAt
+		if updatedAt == nil {
+			updatedAt = txRead.Attributes.CreatedAt
+		}
+		if updatedAt == nil || !updatedAt.After(since) {
+			continue
+		}
+
+		tx, err := c.transactionModel(txRead)
+		if err != nil {
+			c.logf("Skipping transaction %s: %v", txRead.Id, err)
+			continue
+		}
+		changes.Transactions = append(changes.Transactions, tx)
+		if updatedAt.After(changes.NextSince) {
+			changes.NextSince = *updatedAt
+		}
 	}
 
-	var apiResp TransactionArray
-	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
-		return nil, APIErr("Failed to parse transactions response", err)
+	return changes, nil
+}
+
+// TransactionRef identifies a transaction and when it last changed
+type TransactionRef struct {
+	ID        string
+	UpdatedAt time.Time // When the transaction was last changed, or created if it never was
+}
+
+// ListTransactionIDs retrieves the ID and update time of every transaction between start and end,
+// reading all pages. The API always returns full transactions, so the payload is not smaller, but
+// only the IDs and timestamps are decoded, which makes diffing large ledgers much cheaper than
+// listing TransactionModels. Zero dates leave that side of the range open.
+func (c *FireflyClient) ListTransactionIDs(ctx context.Context, start, end time.Time) ([]TransactionRef, error) {
+	return listAllPages(ctx, func(ctx context.Context, page, limit int) ([]TransactionRef, error) {
+		return c.listTransactionRefs(ctx, TransactionFilter{Page: page, Limit: limit, Start: start, End: end})
+	})
+}
+
+// listTransactionRefs lists the IDs and update times of the transaction groups matching the filter
+func (c *FireflyClient) listTransactionRefs(ctx context.Context, filter TransactionFilter) ([]TransactionRef, error) {
+	// Call the API
+	resp, err := c.clientAPI.ListTransaction(ctx, listTransactionParams(filter), sortEditor(filter.Sort))
+	if err != nil {
+		return nil, APIErr("Failed to list transactions", err)
+	}
+
+	// Check and parse the response while it is read, skipping everything but the timestamps
+	var apiResp struct {
+		Data []struct {
+			Id         string `json:"id"`
+			Attributes struct {
+				CreatedAt *time.Time `json:"created_at"`
+				UpdatedAt *time.Time `json:"updated_at"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	if _, err := decodeListResponse(resp, "Transaction", "transactions", &apiResp); err != nil {
+		return nil, err
 	}
 
-	transactions := make([]TransactionModel, 0, len(apiResp.Data))
+	refs := make([]TransactionRef, 0, len(apiResp.Data))
 	for _, txRead := range apiResp.Data {
-		tx := TransactionModel{
-			ID:              txRead.Id,
-			Description:     stringValue(txRead.Attributes.GroupTitle),
-			Date:            *txRead.Attributes.CreatedAt,
-			TransType:       txRead.Type,
-			Category:        "",
-			Currency:        "",
-			Amount:          0,
-			ForeignAmount:   nil,
-			ForeignCurrency: nil,
+		updatedAt := txRead.Attributes.UpdatedAt
+		if updatedAt == nil {
+			updatedAt = txRead.Attributes.CreatedAt
 		}
+		refs = append(refs, TransactionRef{ID: txRead.Id, UpdatedAt: timeValue(updatedAt)})
+	}
 
-		// Handle amount and currency
-		if len(txRead.Attributes.Transactions) > 0 {
-			split := txRead.Attributes.Transactions[0]
-			amount, err := strconv.ParseFloat(split.Amount, 64)
-			if err != nil {
-				return nil, APIErr("Failed to parse amount", err)
-			}
-			tx.Amount = amount
-			if split.CurrencyCode != nil {
-				tx.Currency = *split.CurrencyCode
-			}
+	return refs, nil
+}
 
-			// Handle foreign amount if present
-			if split.ForeignAmount != nil {
-				foreignAmount, err := strconv.ParseFloat(*split.ForeignAmount, 64)
-				if err != nil {
-					return nil, APIErr("Failed to parse foreign amount", err)
-				}
-				tx.ForeignAmount = float64Ptr(foreignAmount)
-			}
-			if split.ForeignCurrencyCode != nil {
-				tx.ForeignCurrency = split.ForeignCurrencyCode
-			}
-		}
+// listTransactionParams converts a filter into the query parameters of the transaction list
+func listTransactionParams(filter TransactionFilter) *ListTransactionParams {
+	page32, limit32 := pageParams(filter.Page, filter.Limit)
 
-		transactions = append(transactions, tx)
+	params := &ListTransactionParams{
+		Page:  page32,
+		Limit: limit32,
+		Start: dateToAPIDate(optionalDate(filter.Start)),
+		End:   dateToAPIDate(optionalDate(filter.End)),
 	}
+	if filter.Type != "" {
+		txType := TransactionTypeFilter(filter.Type)
+		params.Type = &txType
+	}
+	return params
+}
+
+// listTransactionReads lists the API transaction groups matching the filter
+func (c *FireflyClient) listTransactionReads(ctx context.Context, filter TransactionFilter) ([]TransactionRead, error) {
+	txReads, _, err := c.listTransactionPage(ctx, filter)
+	return txReads, err
+}
 
+// listTransactionPage retrieves a page of transactions as returned by the API, with its pagination meta
+func (c *FireflyClient) listTransactionPage(ctx context.Context, filter TransactionFilter) ([]TransactionRead, Meta, error) {
+	// Call the API
+	resp, err := c.clientAPI.ListTransaction(ctx, listTransactionParams(filter), sortEditor(filter.Sort))
+	if err != nil {
+		return nil, Meta{}, APIErr("Failed to list transactions", err)
+	}
+
+	// Check and parse the response while it is read
+	var apiResp TransactionArray
+	found, err := decodeListResponse(resp, "Transaction", "transactions", &apiResp)
+	if err != nil {
+		return nil, Meta{}, err
+	}
+	if !found {
+		return []TransactionRead{}, Meta{}, nil
+	}
+
+	return apiResp.Data, apiResp.Meta, nil
+}
+
+// ListAllTransactions retrieves all transactions, following the pagination of the API to the last page.
+// Use IterateTransactions to handle large histories without holding them in memory.
+func (c *FireflyClient) ListAllTransactions(ctx context.Context) ([]TransactionModel, error) {
+	transactions := []TransactionModel{}
+	err := c.IterateTransactions(ctx, func(tx TransactionModel) error {
+		transactions = append(transactions, tx)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 	return transactions, nil
 }
 
+// IterateTransactions calls fn for every transaction, one page at a time, until the last page.
+// An error returned by fn stops the iteration and is returned.
+func (c *FireflyClient) IterateTransactions(ctx context.Context, fn func(TransactionModel) error) error {
+	return iteratePages(ctx, func(ctx context.Context, page, limit int) ([]TransactionModel, Meta, error) {
+		txReads, meta, err := c.listTransactionPage(ctx, TransactionFilter{Page: page, Limit: limit})
+		if err != nil {
+			return nil, Meta{}, err
+		}
+		return c.transactionModelsFromReads(txReads), meta, nil
+	}, fn)
+}
+
 // UpdateTransaction updates an existing transaction
 func (c *FireflyClient) UpdateTransaction(ctx context.Context, id string, tx TransactionModel) error {
 	// Validate transaction
@@ -928,7 +1740,7 @@ func (c *FireflyClient) UpdateTransaction(ctx context.Context, id string, tx Tra
 		return TransactionValidationErr(errs)
 	}
 
-	txType := TransactionTypeProperty(tx.TransType)
+	txType := transactionTypeProperty(tx.TransType)
 
 	// Convert our transaction to the API format
 	apiTx := UpdateTransactionJSONRequestBody{
@@ -936,19 +1748,51 @@ func (c *FireflyClient) UpdateTransaction(ctx context.Context, id string, tx Tra
 		Transactions: &[]TransactionSplitUpdate{{
 			Type:         &txType,
 			Date:         timePtr(tx.Date),
-			Amount:       stringPtr(fmt.Sprintf("%.2f", tx.Amount)),
+			Amount:       stringPtr(c.FormatAmount(MoneyFromFloat(tx.Amount), tx.Currency)),
 			Description:  stringPtr(tx.Description),
-			CurrencyCode: stringPtr(tx.Currency),
 			CategoryName: &tx.Category,
 		}},
 	}
 
+	// Handle currency, preferring the ID over the code
+	if tx.CurrencyID != "" {
+		(*apiTx.Transactions)[0].CurrencyId = stringPtr(tx.CurrencyID)
+	} else {
+		(*apiTx.Transactions)[0].CurrencyCode = stringPtr(tx.Currency)
+	}
+
 	// Handle foreign amount if present
 	if tx.ForeignAmount != nil && tx.ForeignCurrency != nil {
-		(*apiTx.Transactions)[0].ForeignAmount = stringPtr(fmt.Sprintf("%.2f", *tx.ForeignAmount))
+		(*apiTx.Transactions)[0].ForeignAmount = stringPtr(c.FormatAmount(MoneyFromFloat(*tx.ForeignAmount), *tx.ForeignCurrency))
 		(*apiTx.Transactions)[0].ForeignCurrencyCode = tx.ForeignCurrency
 	}
 
+	// Handle source and destination accounts if present
+	if tx.SourceID != "" {
+		(*apiTx.Transactions)[0].SourceId = stringPtr(tx.SourceID)
+	} else if tx.SourceName != "" {
+		(*apiTx.Transactions)[0].SourceName = stringPtr(tx.SourceName)
+	}
+	if tx.DestinationID != "" {
+		(*apiTx.Transactions)[0].DestinationId = stringPtr(tx.DestinationID)
+	} else if tx.DestinationName != "" {
+		(*apiTx.Transactions)[0].DestinationName = stringPtr(tx.DestinationName)
+	}
+
+	// Notes are replaced like the other fields, so empty notes clear them
+	(*apiTx.Transactions)[0].Notes = &tx.Notes
+
+	// Handle optional dates, which are cleared when nil
+	(*apiTx.Transactions)[0].ProcessDate = tx.ProcessDate
+	(*apiTx.Transactions)[0].BookDate = tx.BookDate
+	(*apiTx.Transactions)[0].DueDate = tx.DueDate
+	(*apiTx.Transactions)[0].PaymentDate = tx.PaymentDate
+
+	return c.updateTransactionGroup(ctx, id, apiTx)
+}
+
+// updateTransactionGroup sends an update for the transaction group with the given ID
+func (c *FireflyClient) updateTransactionGroup(ctx context.Context, id string, apiTx UpdateTransactionJSONRequestBody) error {
 	// Call the API
 	resp, err := c.clientAPI.UpdateTransactionWithResponse(ctx, id, &UpdateTransactionParams{}, apiTx)
 	if err != nil {
@@ -956,19 +1800,302 @@ func (c *FireflyClient) UpdateTransaction(ctx context.Context, id string, tx Tra
 	}
 
 	// Check response
-	if resp.StatusCode() == http.StatusNotFound {
-		return NotFoundErr("Transaction", fmt.Errorf("transaction not found: %s", id))
-	}
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return RateLimitErr(fmt.Errorf("rate limit exceeded"))
-	}
 	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusCreated {
-		return APIErr("Failed to update transaction", fmt.Errorf("unexpected status: %s", resp.Status()))
+		return responseErr("Transaction", resp.HTTPResponse, resp.Body)
 	}
 
 	return nil
 }
 
+// MoveTransaction changes the source and/or destination account of a transaction.
+// Empty IDs leave the corresponding account unchanged. The transaction is fetched first
+// and sent back with only the accounts changed, so all other fields are preserved.
+func (c *FireflyClient) MoveTransaction(ctx context.Context, id, newSourceID, newDestinationID string) error {
+	if newSourceID == "" && newDestinationID == "" {
+		var errs errbuilder.ErrorMap
+		errs.Set("accounts", "A new source or destination account is required")
+		return TransactionValidationErr(errs)
+	}
+
+	// Fetch the current transaction so every other field can be sent back unchanged
+	txRead, err := c.getTransactionRead(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	splits := make([]TransactionSplitUpdate, 0, len(txRead.Attributes.Transactions))
+	for _, split := range txRead.Attributes.Transactions {
+		update := transactionSplitUpdateFromSplit(split)
+		if newSourceID != "" {
+			update.SourceId = stringPtr(newSourceID)
+			update.SourceName = nil
+			update.SourceIban = nil
+		}
+		if newDestinationID != "" {
+			update.DestinationId = stringPtr(newDestinationID)
+			update.DestinationName = nil
+			update.DestinationIban = nil
+		}
+		splits = append(splits, update)
+	}
+	if len(splits) == 0 {
+		return APIErr("Failed to move transaction", fmt.Errorf("transaction %s has no splits", id))
+	}
+
+	return c.updateTransactionGroup(ctx, id, UpdateTransactionJSONRequestBody{
+		ApplyRules:   boolPtr(false),
+		Transactions: &splits,
+	})
+}
+
+// TransactionPatch lists the changes of a partial transaction update. Nil fields are left unchanged.
+type TransactionPatch struct {
+	JournalID     string // Split to change, the first split of the group if empty
+	Description   *string
+	Amount        *float64
+	Date          *time.Time
+	Category      *string
+	SourceID      *string
+	DestinationID *string
+	Tags          *[]string
+	Notes         *string
+	ExternalID    *string
+	ExternalURL   *string
+	ApplyRules    *bool // Whether Firefly III applies its rules to the changed transaction, false if nil
+}
+
+// PatchTransaction changes only the fields set in changes. The transaction is fetched first and
+// sent back with the changes applied, so all other fields, including the amounts of other splits,
+// are preserved as stored.
+func (c *FireflyClient) PatchTransaction(ctx context.Context, id string, changes TransactionPatch) error {
+	// Validate the changes
+	var errs errbuilder.ErrorMap
+	if changes.Description != nil && *changes.Description == "" {
+		errs.Set("description", "Description cannot be empty")
+	}
+	if changes.Amount != nil {
+		if !(*changes.Amount > 0) || math.IsInf(*changes.Amount, 0) {
+			errs.Set("amount", "Amount must be greater than 0")
+		} else if _, err := moneyFromFloat(*changes.Amount); err != nil {
+			errs.Set("amount", "Amount is too large")
+		}
+	}
+	if changes.Date != nil && changes.Date.IsZero() {
+		errs.Set("date", "Date cannot be empty")
+	}
+	if errs != nil {
+		return TransactionValidationErr(errs)
+	}
+
+	// Fetch the current transaction so every other field can be sent back unchanged
+	txRead, err := c.getTransactionRead(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(txRead.Attributes.Transactions) == 0 {
+		return APIErr("Failed to patch transaction", fmt.Errorf("transaction %s has no splits", id))
+	}
+
+	found := false
+	splits := make([]TransactionSplitUpdate, 0, len(txRead.Attributes.Transactions))
+	for i, split := range txRead.Attributes.Transactions {
+		update := transactionSplitUpdateFromSplit(split)
+		if changes.JournalID == stringValue(split.TransactionJournalId) || (changes.JournalID == "" && i == 0) {
+			changes.apply(&update, c.currencyDecimals(stringValue(split.CurrencyCode)))
+			found = true
+		}
+		splits = append(splits, update)
+	}
+	if !found {
+		return NotFoundErr("Transaction split", fmt.Errorf("transaction %s has no split with journal ID %s", id, changes.JournalID))
+	}
+
+	applyRules := false
+	if changes.ApplyRules != nil {
+		applyRules = *changes.ApplyRules
+	}
+
+	return c.updateTransactionGroup(ctx, id, UpdateTransactionJSONRequestBody{
+		ApplyRules:   boolPtr(applyRules),
+		Transactions: &splits,
+	})
+}
+
+// apply sets the changed fields on a split update. Amounts are rounded to the decimals of the split's currency.
+func (p TransactionPatch) apply(update *TransactionSplitUpdate, decimals int32) {
+	if p.Description != nil {
+		update.Description = p.Description
+	}
+	if p.Amount != nil {
+		update.Amount = stringPtr(MoneyFromFloat(*p.Amount).Round(decimals).StringFixed())
+	}
+	if p.Date != nil {
+		update.Date = p.Date
+	}
+	if p.Category != nil {
+		update.CategoryName = p.Category
+		update.CategoryId = nil
+	}
+	if p.SourceID != nil {
+		update.SourceId = p.SourceID
+		update.SourceName = nil
+		update.SourceIban = nil
+	}
+	if p.DestinationID != nil {
+		update.DestinationId = p.DestinationID
+		update.DestinationName = nil
+		update.DestinationIban = nil
+	}
+	if p.Tags != nil {
+		update.Tags = p.Tags
+	}
+	if p.Notes != nil {
+		update.Notes = p.Notes
+	}
+	if p.ExternalID != nil {
+		update.ExternalId = p.ExternalID
+	}
+	if p.ExternalURL != nil {
+		update.ExternalUrl = p.ExternalURL
+	}
+}
+
+// DeleteTransactionSplit removes one split, identified by its journal ID, from a transaction group.
+// The group is sent back without the split, with all other splits unchanged. The last split of
+// a group cannot be removed, use DeleteTransaction to delete the whole group instead.
+func (c *FireflyClient) DeleteTransactionSplit(ctx context.Context, groupID, journalID string) error {
+	if journalID == "" {
+		var errs errbuilder.ErrorMap
+		errs.Set("journal_id", "Journal ID is required")
+		return TransactionValidationErr(errs)
+	}
+
+	// Fetch the current transaction so the remaining splits can be sent back unchanged
+	txRead, err := c.getTransactionRead(ctx, groupID)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	splits := make([]TransactionSplitUpdate, 0, len(txRead.Attributes.Transactions))
+	for _, split := range txRead.Attributes.Transactions {
+		if stringValue(split.TransactionJournalId) == journalID {
+			found = true
+			continue
+		}
+		splits = append(splits, transactionSplitUpdateFromSplit(split))
+	}
+	if !found {
+		return NotFoundErr("Transaction split", fmt.Errorf("transaction %s has no split with journal ID %s", groupID, journalID))
+	}
+	if len(splits) == 0 {
+		var errs errbuilder.ErrorMap
+		errs.Set("journal_id", "Cannot remove the last split of a transaction, delete the transaction instead")
+		return TransactionValidationErr(errs)
+	}
+
+	return c.updateTransactionGroup(ctx, groupID, UpdateTransactionJSONRequestBody{
+		ApplyRules:   boolPtr(false),
+		Transactions: &splits,
+	})
+}
+
+// transactionSplitUpdateFromSplit copies the writable fields of an existing split into an update.
+// The update API treats omitted nullable fields as null, so a partial update would clear them.
+func transactionSplitUpdateFromSplit(split TransactionSplit) TransactionSplitUpdate {
+	return TransactionSplitUpdate{
+		TransactionJournalId: split.TransactionJournalId,
+		Type:                 &split.Type,
+		Date:                 timePtr(split.Date),
+		Amount:               stringPtr(split.Amount),
+		Description:          stringPtr(split.Description),
+		Order:                split.Order,
+		CurrencyId:           split.CurrencyId,
+		CurrencyCode:         split.CurrencyCode,
+		ForeignAmount:        split.ForeignAmount,
+		ForeignCurrencyId:    split.ForeignCurrencyId,
+		ForeignCurrencyCode:  split.ForeignCurrencyCode,
+		BudgetId:             split.BudgetId,
+		BudgetName:           split.BudgetName,
+		CategoryId:           split.CategoryId,
+		CategoryName:         split.CategoryName,
+		SourceId:             split.SourceId,
+		SourceName:           split.SourceName,
+		SourceIban:           split.SourceIban,
+		DestinationId:        split.DestinationId,
+		DestinationName:      split.DestinationName,
+		DestinationIban:      split.DestinationIban,
+		Reconciled:           split.Reconciled,
+		BillId:               split.BillId,
+		BillName:             split.BillName,
+		Tags:                 split.Tags,
+		Notes:                split.Notes,
+		InternalReference:    split.InternalReference,
+		ExternalId:           split.ExternalId,
+		ExternalUrl:          split.ExternalUrl,
+		BunqPaymentId:        split.BunqPaymentId,
+		SepaCc:               split.SepaCc,
+		SepaCtOp:             split.SepaCtOp,
+		SepaCtId:             split.SepaCtId,
+		SepaDb:               split.SepaDb,
+		SepaCountry:          split.SepaCountry,
+		SepaEp:               split.SepaEp,
+		SepaCi:               split.SepaCi,
+		SepaBatchId:          split.SepaBatchId,
+		InterestDate:         split.InterestDate,
+		BookDate:             split.BookDate,
+		ProcessDate:          split.ProcessDate,
+		DueDate:              split.DueDate,
+		PaymentDate:          split.PaymentDate,
+		InvoiceDate:          split.InvoiceDate,
+	}
+}
+
+// transactionSplitStoreFromSplit copies the fields of an existing split that a new split can take over.
+// The external ID, the bunq payment ID and the reconciled flag are left out, they belong to the original.
+func transactionSplitStoreFromSplit(split TransactionSplit) TransactionSplitStore {
+	return TransactionSplitStore{
+		Type:                split.Type,
+		Date:                split.Date,
+		Amount:              split.Amount,
+		Description:         split.Description,
+		Order:               split.Order,
+		CurrencyId:          split.CurrencyId,
+		CurrencyCode:        split.CurrencyCode,
+		ForeignAmount:       split.ForeignAmount,
+		ForeignCurrencyId:   split.ForeignCurrencyId,
+		ForeignCurrencyCode: split.ForeignCurrencyCode,
+		BudgetId:            split.BudgetId,
+		BudgetName:          split.BudgetName,
+		CategoryId:          split.CategoryId,
+		CategoryName:        split.CategoryName,
+		SourceId:            split.SourceId,
+		SourceName:          split.SourceName,
+		DestinationId:       split.DestinationId,
+		DestinationName:     split.DestinationName,
+		BillId:              split.BillId,
+		BillName:            split.BillName,
+		Tags:                split.Tags,
+		Notes:               split.Notes,
+		InternalReference:   split.InternalReference,
+		ExternalUrl:         split.ExternalUrl,
+		SepaCc:              split.SepaCc,
+		SepaCtOp:            split.SepaCtOp,
+		SepaCtId:            split.SepaCtId,
+		SepaDb:              split.SepaDb,
+		SepaCountry:         split.SepaCountry,
+		SepaEp:              split.SepaEp,
+		SepaCi:              split.SepaCi,
+		SepaBatchId:         split.SepaBatchId,
+		InterestDate:        split.InterestDate,
+		BookDate:            split.BookDate,
+		ProcessDate:         split.ProcessDate,
+		DueDate:             split.DueDate,
+		PaymentDate:         split.PaymentDate,
+		InvoiceDate:         split.InvoiceDate,
+	}
+}
+
 // DeleteTransaction deletes a transaction by ID
 func (c *FireflyClient) DeleteTransaction(ctx context.Context, id string) error {
 	// Call the API
@@ -978,14 +2105,8 @@ func (c *FireflyClient) DeleteTransaction(ctx context.Context, id string) error
 	}
 
 	// Check response
-	if resp.StatusCode() == http.StatusNotFound {
-		return NotFoundErr("Transaction", fmt.Errorf("transaction not found: %s", id))
-	}
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return RateLimitErr(fmt.Errorf("rate limit exceeded"))
-	}
 	if resp.StatusCode() != http.StatusNoContent {
-		return APIErr("Failed to delete transaction", fmt.Errorf("unexpected status: %s", resp.Status()))
+		return responseErr("Transaction", resp.HTTPResponse, resp.Body)
 	}
 
 	return nil
@@ -994,226 +2115,488 @@ func (c *FireflyClient) DeleteTransaction(ctx context.Context, id string) error
 // SearchTransactions searches for transactions matching the query
 func (c *FireflyClient) SearchTransactions(ctx context.Context, query string) ([]TransactionModel, error) {
 	// Call the API
-	resp, err := c.clientAPI.SearchTransactionsWithResponse(ctx, &SearchTransactionsParams{
+	resp, err := c.clientAPI.SearchTransactions(ctx, &SearchTransactionsParams{
 		Query: query,
 	})
 	if err != nil {
 		return nil, APIErr("Failed to search transactions", err)
 	}
 
-	// Check response
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return nil, RateLimitErr(fmt.Errorf("rate limit exceeded"))
-	}
-	if resp.StatusCode() != http.StatusOK {
-		return nil, APIErr("Failed to search transactions", fmt.Errorf("unexpected status: %s", resp.Status()))
+	// Check and parse the response while it is read
+	var apiResp TransactionArray
+	found, err := decodeListResponse(resp, "Transaction", "transactions", &apiResp)
+	if err != nil {
+		return nil, err
 	}
-
-	// Convert API response to TransactionModels
-	if resp.HTTPResponse == nil || len(resp.Body) == 0 {
+	if !found {
 		return []TransactionModel{}, nil
 	}
 
-	var apiResp TransactionArray
-	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
-		return nil, APIErr("Failed to parse transactions response", err)
+	return c.transactionModelsFromReads(apiResp.Data), nil
+}
+
+// ImportTransaction imports a single transaction
+func (c *FireflyClient) ImportTransaction(ctx context.Context, tx TransactionModel) error {
+	// Validate transaction
+	if errs := validateTransaction(tx); errs != nil {
+		return TransactionValidationErr(errs)
 	}
 
-	transactions := make([]TransactionModel, 0, len(apiResp.Data))
-	for _, txRead := range apiResp.Data {
-		tx := TransactionModel{
-			ID:              txRead.Id,
-			Description:     stringValue(txRead.Attributes.GroupTitle),
-			Date:            *txRead.Attributes.CreatedAt,
-			TransType:       txRead.Type,
-			Category:        "",
-			Currency:        "",
-			Amount:          0,
-			ForeignAmount:   nil,
-			ForeignCurrency: nil,
-		}
+	_, err := c.storeTransactionGroup(ctx, "", []TransactionModel{tx}, "Failed to import transaction")
+	return err
+}
 
-		// Handle amount and currency
-		if len(txRead.Attributes.Transactions) > 0 {
-			split := txRead.Attributes.Transactions[0]
-			amount, err := strconv.ParseFloat(split.Amount, 64)
-			if err != nil {
-				return nil, APIErr("Failed to parse amount", err)
-			}
-			tx.Amount = amount
-			if split.CurrencyCode != nil {
-				tx.Currency = *split.CurrencyCode
-			}
+// ImportTransactions imports multiple transactions in batch
+func (c *FireflyClient) ImportTransactions(ctx context.Context, transactions []TransactionModel) error {
+	return c.ImportTransactionsWithOptions(ctx, transactions, nil)
+}
 
-			// Handle foreign amount if present
-			if split.ForeignAmount != nil {
-				foreignAmount, err := strconv.ParseFloat(*split.ForeignAmount, 64)
-				if err != nil {
-					return nil, APIErr("Failed to parse foreign amount", err)
-				}
-				tx.ForeignAmount = float64Ptr(foreignAmount)
-			}
-			if split.ForeignCurrencyCode != nil {
-				tx.ForeignCurrency = split.ForeignCurrencyCode
-			}
-		}
+// ImportTransactionsWithOptions imports multiple transactions in batch and marks each of them
+// with the import tag and external reference of options, so their provenance can be traced.
+// Only the provenance fields of options are used.
+func (c *FireflyClient) ImportTransactionsWithOptions(ctx context.Context, transactions []TransactionModel, options *ImportOptions) error {
+	// Validate all transactions first, they are stored as one group
+	if errs := validateTransactionGroup(transactions); errs != nil {
+		return TransactionValidationErr(errs)
+	}
 
-		transactions = append(transactions, tx)
+	if options != nil {
+		marked := make([]TransactionModel, len(transactions))
+		for i, tx := range transactions {
+			marked[i] = options.apply(tx)
+		}
+		transactions = marked
 	}
 
-	return transactions, nil
+	_, err := c.storeTransactionGroup(ctx, "", transactions, "Failed to import transactions")
+	return err
 }
 
-// ImportTransaction imports a single transaction
-func (c *FireflyClient) ImportTransaction(ctx context.Context, tx TransactionModel) error {
+// CreateTransaction creates a transaction and returns it as stored by Firefly III,
+// including its ID and the journal ID of its split
+func (c *FireflyClient) CreateTransaction(ctx context.Context, tx TransactionModel) (*TransactionModel, error) {
 	// Validate transaction
 	if errs := validateTransaction(tx); errs != nil {
-		return TransactionValidationErr(errs)
+		return nil, TransactionValidationErr(errs)
 	}
 
-	txType := TransactionTypeProperty(tx.TransType)
+	body, err := c.storeTransactionGroup(ctx, "", []TransactionModel{tx}, "Failed to create transaction")
+	if err != nil {
+		return nil, err
+	}
 
-	// Convert our transaction to the API format
-	apiTx := StoreTransactionJSONRequestBody{
-		ErrorIfDuplicateHash: boolPtr(true),
-		ApplyRules:           boolPtr(true),
-		Transactions: []TransactionSplitStore{
-			{
-				Type:         txType,
-				Date:         tx.Date,
-				Amount:       fmt.Sprintf("%.2f", tx.Amount),
-				Description:  tx.Description,
-				CurrencyCode: stringPtr(tx.Currency),
-				CategoryName: &tx.Category,
-			},
-		},
+	txRead, err := transactionReadFromBody(body)
+	if err != nil {
+		return nil, err
+	}
+	created, err := c.transactionModel(*txRead)
+	if err != nil {
+		return nil, err
 	}
+	return &created, nil
+}
 
-	// Handle foreign amount if present
-	if tx.ForeignAmount != nil && tx.ForeignCurrency != nil {
-		apiTx.Transactions[0].ForeignAmount = stringPtr(fmt.Sprintf("%.2f", *tx.ForeignAmount))
-		apiTx.Transactions[0].ForeignCurrencyCode = tx.ForeignCurrency
+// CreateTransactionGroup creates a transaction group with one split per model in group.Splits.
+// Groups with more than one split need a title. The returned group holds the journal ID
+// of every split, in the order of group.Splits, for later edits of single splits.
+func (c *FireflyClient) CreateTransactionGroup(ctx context.Context, group TransactionGroupModel) (*TransactionGroupModel, error) {
+	// Validate the group and its splits
+	var errs errbuilder.ErrorMap
+	if len(group.Splits) == 0 {
+		errs.Set("splits", "At least one split is required")
+	}
+	if len(group.Splits) > 1 && group.Title == "" {
+		errs.Set("title", "Title is required for transactions with more than one split")
+	}
+	for field, err := range validateTransactionGroup(group.Splits) {
+		errs.Set(field, err)
+	}
+	if errs != nil {
+		return nil, TransactionValidationErr(errs)
 	}
 
-	// Call the API
-	resp, err := c.clientAPI.StoreTransactionWithResponse(ctx, &StoreTransactionParams{}, apiTx)
+	body, err := c.storeTransactionGroup(ctx, group.Title, group.Splits, "Failed to create transaction")
 	if err != nil {
-		return APIErr("Failed to import transaction", err)
+		return nil, err
 	}
 
-	// Check response
-	if resp.StatusCode() == http.StatusConflict {
-		return DuplicateErr("Transaction", fmt.Errorf("transaction already exists"))
+	txRead, err := transactionReadFromBody(body)
+	if err != nil {
+		return nil, err
 	}
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return RateLimitErr(fmt.Errorf("rate limit exceeded"))
+	created, err := c.transactionGroupModel(*txRead)
+	if err != nil {
+		return nil, err
 	}
-	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusCreated {
-		return APIErr("Failed to import transaction", fmt.Errorf("unexpected status: %s", resp.Status()))
+	return &created, nil
+}
+
+// CloneTransaction creates a copy of a transaction group on newDate and returns its first split.
+// All splits are copied with their amounts, accounts, category, budget, tags and notes. Firefly III
+// assigns new IDs; the external ID and the reconciled flag are not copied, as they belong to the
+// original. Rules are not applied to the copy.
+func (c *FireflyClient) CloneTransaction(ctx context.Context, id string, newDate time.Time) (*TransactionModel, error) {
+	if newDate.IsZero() {
+		var errs errbuilder.ErrorMap
+		errs.Set("date", "Date is required")
+		return nil, TransactionValidationErr(errs)
 	}
 
-	return nil
-}
+	txRead, err := c.getTransactionRead(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(txRead.Attributes.Transactions) == 0 {
+		return nil, APIErr("Failed to clone transaction", fmt.Errorf("transaction %s has no splits", id))
+	}
 
-// ImportTransactions imports multiple transactions in batch
-func (c *FireflyClient) ImportTransactions(ctx context.Context, transactions []TransactionModel) error {
-	// Validate all transactions first
-	for _, tx := range transactions {
-		if errs := validateTransaction(tx); errs != nil {
-			return TransactionValidationErr(errs)
-		}
+	splits := make([]TransactionSplitStore, 0, len(txRead.Attributes.Transactions))
+	for _, split := range txRead.Attributes.Transactions {
+		store := transactionSplitStoreFromSplit(split)
+		store.Date = newDate
+		splits = append(splits, store)
 	}
 
-	// Convert transactions to API format
+	// Call the API
+	resp, err := c.clientAPI.StoreTransactionWithResponse(ctx, &StoreTransactionParams{}, StoreTransactionJSONRequestBody{
+		ErrorIfDuplicateHash: boolPtr(true),
+		ApplyRules:           boolPtr(false),
+		GroupTitle:           txRead.Attributes.GroupTitle,
+		Transactions:         splits,
+	})
+	if err != nil {
+		return nil, APIErr("Failed to clone transaction", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusCreated {
+		return nil, responseErr("Transaction", resp.HTTPResponse, resp.Body)
+	}
+
+	created, err := transactionReadFromBody(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	clone, err := c.transactionModel(*created)
+	if err != nil {
+		return nil, err
+	}
+	return &clone, nil
+}
+
+// storeTransactionGroup stores validated transactions as the splits of one transaction group,
+// creating missing accounts if enabled. It returns the body of the response.
+func (c *FireflyClient) storeTransactionGroup(ctx context.Context, title string, transactions []TransactionModel, msg string) ([]byte, error) {
+	// Convert transactions to API format, creating missing accounts if enabled
 	splits := make([]TransactionSplitStore, len(transactions))
 	for i, tx := range transactions {
-		txType := TransactionTypeProperty(tx.TransType)
-		splits[i] = TransactionSplitStore{
-			Type:         txType,
-			Date:         tx.Date,
-			Amount:       fmt.Sprintf("%.2f", tx.Amount),
-			Description:  tx.Description,
-			CurrencyCode: stringPtr(tx.Currency),
-			CategoryName: &tx.Category,
-		}
-
-		// Handle foreign amount if present
-		if tx.ForeignAmount != nil && tx.ForeignCurrency != nil {
-			splits[i].ForeignAmount = stringPtr(fmt.Sprintf("%.2f", *tx.ForeignAmount))
-			splits[i].ForeignCurrencyCode = tx.ForeignCurrency
+		if err := c.ensureTransactionAccounts(ctx, tx); err != nil {
+			return nil, err
 		}
+		splits[i] = c.transactionSplitStore(tx)
 	}
 
-	// Create batch request
 	apiTx := StoreTransactionJSONRequestBody{
 		ErrorIfDuplicateHash: boolPtr(true),
 		ApplyRules:           boolPtr(true),
 		Transactions:         splits,
 	}
+	if title != "" {
+		apiTx.GroupTitle = stringPtr(title)
+	}
 
 	// Call the API
 	resp, err := c.clientAPI.StoreTransactionWithResponse(ctx, &StoreTransactionParams{}, apiTx)
 	if err != nil {
-		return APIErr("Failed to import transactions", err)
+		return nil, APIErr(msg, err)
 	}
 
 	// Check response
-	if resp.StatusCode() == http.StatusConflict {
-		return DuplicateErr("Transaction", fmt.Errorf("one or more transactions already exist"))
+	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusCreated {
+		return nil, responseErr("Transaction", resp.HTTPResponse, resp.Body)
 	}
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return RateLimitErr(fmt.Errorf("rate limit exceeded"))
+
+	return resp.Body, nil
+}
+
+// transactionReadFromBody parses a single transaction group response
+func transactionReadFromBody(body []byte) (*TransactionRead, error) {
+	if len(body) == 0 {
+		return nil, APIErr("No transaction data found", fmt.Errorf("empty response"))
 	}
-	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusCreated {
-		return APIErr("Failed to import transactions", fmt.Errorf("unexpected status: %s", resp.Status()))
+
+	var apiResp TransactionSingle
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, APIErr("Failed to parse transaction response", err)
+	}
+
+	return &apiResp.Data, nil
+}
+
+// CreateForeignTransfer creates a transfer between two accounts held in different currencies.
+// amount is debited from the source account in currency, foreignAmount is credited to the
+// destination account in foreignCurrency.
+func (c *FireflyClient) CreateForeignTransfer(ctx context.Context, from, to string, amount float64, currency string, foreignAmount float64, foreignCurrency string, description string, date time.Time) error {
+	// Validate the foreign side of the transfer
+	var errs errbuilder.ErrorMap
+	if from == "" {
+		errs.Set("source", "Source account is required")
+	}
+	if to == "" {
+		errs.Set("destination", "Destination account is required")
+	}
+	if foreignAmount <= 0 {
+		errs.Set("foreign_amount", "Foreign amount must be greater than 0")
+	}
+	if foreignCurrency == "" {
+		errs.Set("foreign_currency", "Foreign currency is required")
+	} else if strings.EqualFold(currency, foreignCurrency) {
+		errs.Set("foreign_currency", "Foreign currency must differ from currency")
+	}
+	if errs != nil {
+		return TransactionValidationErr(errs)
+	}
+
+	tx := TransactionModel{
+		Currency:        currency,
+		Amount:          amount,
+		TransType:       "transfer",
+		Description:     description,
+		Date:            date,
+		ForeignAmount:   float64Ptr(foreignAmount),
+		ForeignCurrency: stringPtr(foreignCurrency),
+		SourceID:        from,
+		DestinationID:   to,
+	}
+
+	return c.ImportTransaction(ctx, tx)
+}
+
+// transactionSplitStore converts a TransactionModel to the API split format used when storing transactions
+func (c *FireflyClient) transactionSplitStore(tx TransactionModel) TransactionSplitStore {
+	split := TransactionSplitStore{
+		Type:         transactionTypeProperty(tx.TransType),
+		Date:         tx.Date,
+		Amount:       c.FormatAmount(MoneyFromFloat(tx.Amount), tx.Currency),
+		Description:  tx.Description,
+		CategoryName: stringPtr(tx.Category),
+	}
+
+	// Handle currency, preferring the ID over the code
+	if tx.CurrencyID != "" {
+		split.CurrencyId = stringPtr(tx.CurrencyID)
+	} else {
+		split.CurrencyCode = stringPtr(tx.Currency)
+	}
+
+	// Handle foreign amount if present
+	if tx.ForeignAmount != nil && tx.ForeignCurrency != nil {
+		split.ForeignAmount = stringPtr(c.FormatAmount(MoneyFromFloat(*tx.ForeignAmount), *tx.ForeignCurrency))
+		split.ForeignCurrencyCode = tx.ForeignCurrency
+	}
+
+	// Handle source and destination accounts, preferring IDs over names
+	if tx.SourceID != "" {
+		split.SourceId = stringPtr(tx.SourceID)
+	} else if tx.SourceName != "" {
+		split.SourceName = stringPtr(tx.SourceName)
+	}
+	if tx.DestinationID != "" {
+		split.DestinationId = stringPtr(tx.DestinationID)
+	} else if tx.DestinationName != "" {
+		split.DestinationName = stringPtr(tx.DestinationName)
+	}
+
+	// Handle tags and external references
+	if len(tx.Tags) > 0 {
+		split.Tags = &tx.Tags
+	}
+	if tx.ExternalID != "" {
+		split.ExternalId = stringPtr(tx.ExternalID)
+	}
+	if tx.ExternalURL != "" {
+		split.ExternalUrl = stringPtr(tx.ExternalURL)
+	}
+	if tx.Notes != "" {
+		split.Notes = stringPtr(tx.Notes)
+	}
+
+	// Handle optional dates
+	split.ProcessDate = tx.ProcessDate
+	split.BookDate = tx.BookDate
+	split.DueDate = tx.DueDate
+	split.PaymentDate = tx.PaymentDate
+
+	return split
+}
+
+// ensureTransactionAccounts creates the expense or revenue account a transaction
+// refers to by name when ClientConfig.AutoCreateAccounts is enabled.
+// Withdrawals need an expense account as destination, deposits a revenue account as source.
+func (c *FireflyClient) ensureTransactionAccounts(ctx context.Context, tx TransactionModel) error {
+	if c.config == nil || !c.config.AutoCreateAccounts {
+		return nil
+	}
+
+	switch kind, _ := ParseTransactionKind(tx.TransType); kind {
+	case TransactionKindWithdrawal:
+		if tx.DestinationID == "" && tx.DestinationName != "" {
+			return c.ensureAccount(ctx, tx.DestinationName, "expense", tx.Currency)
+		}
+	case TransactionKindDeposit:
+		if tx.SourceID == "" && tx.SourceName != "" {
+			return c.ensureAccount(ctx, tx.SourceName, "revenue", tx.Currency)
+		}
 	}
 
 	return nil
 }
 
+// ensureAccount makes sure an account with the given name and type exists, creating it if necessary.
+// Known accounts are cached so a batch referencing the same account only creates it once. Only one
+// lookup runs per account; other callers for the same account wait for it until their ctx is done.
+func (c *FireflyClient) ensureAccount(ctx context.Context, name, accountType, currency string) error {
+	key := cacheScope(ctx) + accountType + ":" + strings.ToLower(name)
+
+	for {
+		c.accountMu.Lock()
+		if _, ok := c.accountCache[key]; ok {
+			c.accountMu.Unlock()
+			return nil
+		}
+		pending, ok := c.accountsPending[key]
+		if !ok {
+			break
+		}
+		c.accountMu.Unlock()
+
+		// Check the cache again once the other lookup is done, it may have failed
+		select {
+		case <-pending:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	done := make(chan struct{})
+	if c.accountsPending == nil {
+		c.accountsPending = make(map[string]chan struct{})
+	}
+	c.accountsPending[key] = done
+	c.accountMu.Unlock()
+
+	err := c.findOrCreateAccount(ctx, name, accountType, currency)
+
+	c.accountMu.Lock()
+	delete(c.accountsPending, key)
+	if err == nil {
+		if c.accountCache == nil {
+			c.accountCache = make(map[string]struct{})
+		}
+		c.accountCache[key] = struct{}{}
+	}
+	c.accountMu.Unlock()
+	close(done)
+
+	return err
+}
+
+// findOrCreateAccount creates an account unless one with the exact name and type exists
+func (c *FireflyClient) findOrCreateAccount(ctx context.Context, name, accountType, currency string) error {
+	accounts, err := c.SearchAccounts(ctx, name)
+	if err != nil {
+		return err
+	}
+	for _, account := range accounts {
+		if account.Type == accountType && strings.EqualFold(account.Name, name) {
+			return nil
+		}
+	}
+
+	return c.CreateAccount(ctx, name, accountType, currency)
+}
+
 // CreateAccount creates a new account
 func (c *FireflyClient) CreateAccount(ctx context.Context, name, accountType, currency string) error {
+	_, err := c.CreateAccountFull(ctx, AccountModel{
+		Name:            name,
+		Type:            accountType,
+		Currency:        currency,
+		Active:          true,
+		IncludeNetWorth: true,
+	})
+	return err
+}
+
+// CreateAccountFull creates an account and returns it as stored by Firefly III, including its ID.
+// Name, type, currency, role, IBAN, account number, Active and IncludeNetWorth are taken from
+// account; Balance is ignored. Asset accounts without a role get the default asset role.
+func (c *FireflyClient) CreateAccountFull(ctx context.Context, account AccountModel) (*AccountModel, error) {
 	// Validate account
-	account := AccountModel{
-		Name:     name,
-		Type:     accountType,
-		Currency: currency,
+	if account.Type == string(ShortAccountTypePropertyAsset) && account.Role == "" {
+		// Firefly III rejects asset accounts without a role
+		account.Role = string(AccountRolePropertyDefaultAsset)
 	}
 	if errs := validateAccount(account); errs != nil {
-		return AccountValidationErr(errs)
+		return nil, AccountValidationErr(errs)
 	}
 
 	// Create account request
 	accountRequest := StoreAccountJSONRequestBody{
-		Name:         name,
-		Type:         ShortAccountTypeProperty(accountType),
-		CurrencyCode: stringPtr(currency),
+		Name:            account.Name,
+		Type:            ShortAccountTypeProperty(account.Type),
+		CurrencyCode:    stringPtr(account.Currency),
+		Active:          boolPtr(account.Active),
+		IncludeNetWorth: boolPtr(account.IncludeNetWorth),
+	}
+	if account.Role != "" {
+		role := AccountRoleProperty(account.Role)
+		accountRequest.AccountRole = &role
+	}
+	if account.IBAN != "" {
+		accountRequest.Iban = stringPtr(account.IBAN)
+	}
+	if account.Number != "" {
+		accountRequest.AccountNumber = stringPtr(account.Number)
 	}
 
 	// Call the API
 	resp, err := c.clientAPI.StoreAccountWithResponse(ctx, &StoreAccountParams{}, accountRequest)
 	if err != nil {
-		return APIErr("Failed to create account", err)
+		return nil, APIErr("Failed to create account", err)
 	}
 
 	// Check response
-	if resp.StatusCode() == http.StatusConflict {
-		return DuplicateErr("Account", fmt.Errorf("account already exists"))
+	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusCreated {
+		return nil, responseErr("Account", resp.HTTPResponse, resp.Body)
 	}
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return RateLimitErr(fmt.Errorf("rate limit exceeded"))
+
+	if len(resp.Body) == 0 {
+		return nil, APIErr("No account data found", fmt.Errorf("empty response"))
 	}
-	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusCreated {
-		return APIErr("Failed to create account", fmt.Errorf("unexpected status: %s", resp.Status()))
+
+	var apiResp AccountSingle
+	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
+		return nil, APIErr("Failed to parse account response", err)
 	}
 
-	return nil
+	created, err := accountModelFromRead(apiResp.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
 }
 
 // UpdateBalance updates an account's balance
 func (c *FireflyClient) UpdateBalance(ctx context.Context, accountID string, balance Balance) error {
 	// Convert float64 to string for API
-	balanceStr := fmt.Sprintf("%.2f", balance.Amount)
+	amount, err := moneyFromFloat(balance.Amount)
+	if err != nil {
+		var errs errbuilder.ErrorMap
+		errs.Set("amount", "Balance must be a finite number")
+		return AccountValidationErr(errs)
+	}
+	balanceStr := c.FormatAmount(amount, balance.Currency)
 
 	// Create balance update request
 	update := UpdateAccountJSONRequestBody{
@@ -1228,14 +2611,31 @@ func (c *FireflyClient) UpdateBalance(ctx context.Context, accountID string, bal
 	}
 
 	// Check response
-	if resp.StatusCode() == http.StatusNotFound {
-		return NotFoundErr("Account", fmt.Errorf("account not found: %s", accountID))
+	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusCreated {
+		return responseErr("Account", resp.HTTPResponse, resp.Body)
 	}
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return RateLimitErr(fmt.Errorf("rate limit exceeded"))
+
+	return nil
+}
+
+// SetAccountIncludeNetWorth sets whether an account counts towards the net worth
+func (c *FireflyClient) SetAccountIncludeNetWorth(ctx context.Context, accountID string, include bool) error {
+	// AccountUpdate always sends the name, IBAN and account number, which would clear them,
+	// so only the flag is sent
+	body, err := json.Marshal(map[string]bool{"include_net_worth": include})
+	if err != nil {
+		return APIErr("Failed to encode account update", err)
 	}
-	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusCreated {
-		return APIErr("Failed to update balance", fmt.Errorf("unexpected status: %s", resp.Status()))
+
+	// Call the API
+	resp, err := c.clientAPI.UpdateAccountWithBodyWithResponse(ctx, accountID, &UpdateAccountParams{}, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return APIErr("Failed to update account", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK {
+		return responseErr("Account", resp.HTTPResponse, resp.Body)
 	}
 
 	return nil
@@ -1250,14 +2650,8 @@ func (c *FireflyClient) GetAccount(ctx context.Context, id string) (*AccountMode
 	}
 
 	// Check response
-	if resp.StatusCode() == http.StatusNotFound {
-		return nil, NotFoundErr("Account", fmt.Errorf("account not found: %s", id))
-	}
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return nil, RateLimitErr(fmt.Errorf("rate limit exceeded"))
-	}
 	if resp.StatusCode() != http.StatusOK {
-		return nil, APIErr("Failed to get account", fmt.Errorf("unexpected status: %s", resp.Status()))
+		return nil, responseErr("Account", resp.HTTPResponse, resp.Body)
 	}
 
 	// Convert API response to AccountModel
@@ -1270,103 +2664,113 @@ func (c *FireflyClient) GetAccount(ctx context.Context, id string) (*AccountMode
 		return nil, APIErr("Failed to parse account response", err)
 	}
 
+	account, err := accountModelFromRead(apiResp.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+// accountModelFromRead converts an API account to an AccountModel
+func accountModelFromRead(accountRead AccountRead) (AccountModel, error) {
 	// Parse balance
 	balance := float64(0)
-	if apiResp.Data.Attributes.CurrentBalance != nil {
-		var err error
-		balance, err = strconv.ParseFloat(*apiResp.Data.Attributes.CurrentBalance, 64)
+	if accountRead.Attributes.CurrentBalance != nil {
+		amount, err := ParseMoney(*accountRead.Attributes.CurrentBalance)
 		if err != nil {
-			return nil, APIErr("Failed to parse balance", err)
+			return AccountModel{}, APIErr("Failed to parse balance", err)
 		}
+		balance = amount.Float64()
 	}
 
 	// Get account role
 	role := ""
-	if apiResp.Data.Attributes.AccountRole != nil {
-		role = string(*apiResp.Data.Attributes.AccountRole)
+	if accountRead.Attributes.AccountRole != nil {
+		role = string(*accountRead.Attributes.AccountRole)
 	}
 
-	account := &AccountModel{
-		ID:       apiResp.Data.Id,
-		Name:     apiResp.Data.Attributes.Name,
-		Type:     string(apiResp.Data.Attributes.Type),
-		Currency: stringValue(apiResp.Data.Attributes.CurrencyCode),
+	return AccountModel{
+		ID:       accountRead.Id,
+		Name:     accountRead.Attributes.Name,
+		Type:     string(accountRead.Attributes.Type),
+		Currency: stringValue(accountRead.Attributes.CurrencyCode),
 		Balance:  balance,
-		IBAN:     stringValue(apiResp.Data.Attributes.Iban),
-		Number:   stringValue(apiResp.Data.Attributes.AccountNumber),
-		BankName: "", // Not available in API
-		Active:   boolValue(apiResp.Data.Attributes.Active),
+		IBAN:     stringValue(accountRead.Attributes.Iban),
+		Number:   stringValue(accountRead.Attributes.AccountNumber),
+		Active:   boolValue(accountRead.Attributes.Active),
 		Role:     role,
-		Include:  boolValue(apiResp.Data.Attributes.IncludeNetWorth),
-	}
 
-	return account, nil
+		IncludeNetWorth: boolValue(accountRead.Attributes.IncludeNetWorth),
+	}, nil
 }
 
 // ListAccounts retrieves a list of accounts with pagination
 func (c *FireflyClient) ListAccounts(ctx context.Context, page, limit int) ([]AccountModel, error) {
-	// Call the API
-	resp, err := c.clientAPI.ListAccountWithResponse(ctx, &ListAccountParams{
-		Page:  int32Ptr(page),
-		Limit: int32Ptr(limit),
+	return c.ListAccountsFiltered(ctx, AccountFilter{Page: page, Limit: limit})
+}
+
+// ListAccountsFiltered retrieves a list of accounts matching the filter, in the requested order
+func (c *FireflyClient) ListAccountsFiltered(ctx context.Context, filter AccountFilter) ([]AccountModel, error) {
+	accounts, _, err := c.listAccountPage(ctx, filter)
+	return accounts, err
+}
+
+// ListAllAccounts retrieves all accounts, following the pagination of the API to the last page
+func (c *FireflyClient) ListAllAccounts(ctx context.Context) ([]AccountModel, error) {
+	accounts := []AccountModel{}
+	err := iteratePages(ctx, func(ctx context.Context, page, limit int) ([]AccountModel, Meta, error) {
+		return c.listAccountPage(ctx, AccountFilter{Page: page, Limit: limit})
+	}, func(account AccountModel) error {
+		accounts = append(accounts, account)
+		return nil
 	})
 	if err != nil {
-		return nil, APIErr("Failed to list accounts", err)
+		return nil, err
 	}
+	return accounts, nil
+}
 
-	// Check response
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return nil, RateLimitErr(fmt.Errorf("rate limit exceeded"))
+// listAccountPage retrieves a page of accounts matching the filter, with its pagination meta
+func (c *FireflyClient) listAccountPage(ctx context.Context, filter AccountFilter) ([]AccountModel, Meta, error) {
+	page32, limit32 := pageParams(filter.Page, filter.Limit)
+
+	params := &ListAccountParams{
+		Page:  page32,
+		Limit: limit32,
+		Date:  dateToAPIDate(optionalDate(filter.Date)),
 	}
-	if resp.StatusCode() != http.StatusOK {
-		return nil, APIErr("Failed to list accounts", fmt.Errorf("unexpected status: %s", resp.Status()))
+	if filter.Type != "" {
+		accountType := AccountTypeFilter(filter.Type)
+		params.Type = &accountType
 	}
 
-	// Convert API response to AccountModels
-	if resp.HTTPResponse == nil || len(resp.Body) == 0 {
-		return []AccountModel{}, nil
+	// Call the API
+	resp, err := c.clientAPI.ListAccount(ctx, params, sortEditor(filter.Sort))
+	if err != nil {
+		return nil, Meta{}, APIErr("Failed to list accounts", err)
 	}
 
+	// Check and parse the response while it is read
 	var apiResp AccountArray
-	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
-		return nil, APIErr("Failed to parse accounts response", err)
+	found, err := decodeListResponse(resp, "Account", "accounts", &apiResp)
+	if err != nil {
+		return nil, Meta{}, err
+	}
+	if !found {
+		return []AccountModel{}, Meta{}, nil
 	}
 
 	accounts := make([]AccountModel, 0, len(apiResp.Data))
 	for _, accountRead := range apiResp.Data {
-		// Parse balance
-		balance := float64(0)
-		if accountRead.Attributes.CurrentBalance != nil {
-			var err error
-			balance, err = strconv.ParseFloat(*accountRead.Attributes.CurrentBalance, 64)
-			if err != nil {
-				return nil, APIErr("Failed to parse balance", err)
-			}
-		}
-
-		// Get account role
-		role := ""
-		if accountRead.Attributes.AccountRole != nil {
-			role = string(*accountRead.Attributes.AccountRole)
-		}
-
-		account := AccountModel{
-			ID:       accountRead.Id,
-			Name:     accountRead.Attributes.Name,
-			Type:     string(accountRead.Attributes.Type),
-			Currency: stringValue(accountRead.Attributes.CurrencyCode),
-			Balance:  balance,
-			IBAN:     stringValue(accountRead.Attributes.Iban),
-			Number:   stringValue(accountRead.Attributes.AccountNumber),
-			BankName: "", // Not available in API
-			Active:   boolValue(accountRead.Attributes.Active),
-			Role:     role,
-			Include:  boolValue(accountRead.Attributes.IncludeNetWorth),
+		account, err := accountModelFromRead(accountRead)
+		if err != nil {
+			return nil, Meta{}, err
 		}
 		accounts = append(accounts, account)
 	}
 
-	return accounts, nil
+	return accounts, apiResp.Meta, nil
 }
 
 // DeleteAccount deletes an account by ID
@@ -1379,7 +2783,7 @@ func (c *FireflyClient) DeleteAccount(ctx context.Context, id string) error {
 
 	// Check response
 	if resp.StatusCode() != http.StatusNoContent {
-		return APIErr("Failed to delete account", fmt.Errorf("unexpected status: %s", resp.Status()))
+		return responseErr("Account", resp.HTTPResponse, resp.Body)
 	}
 
 	return nil
@@ -1388,7 +2792,7 @@ func (c *FireflyClient) DeleteAccount(ctx context.Context, id string) error {
 // SearchAccounts searches for accounts matching the query
 func (c *FireflyClient) SearchAccounts(ctx context.Context, query string) ([]AccountModel, error) {
 	// Call the API
-	resp, err := c.clientAPI.SearchAccountsWithResponse(ctx, &SearchAccountsParams{
+	resp, err := c.clientAPI.SearchAccounts(ctx, &SearchAccountsParams{
 		Query: query,
 		Field: AccountSearchFieldFilter("all"), // Search in all fields
 	})
@@ -1396,59 +2800,52 @@ func (c *FireflyClient) SearchAccounts(ctx context.Context, query string) ([]Acc
 		return nil, APIErr("Failed to search accounts", err)
 	}
 
-	// Check response
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return nil, RateLimitErr(fmt.Errorf("rate limit exceeded"))
-	}
-	if resp.StatusCode() != http.StatusOK {
-		return nil, APIErr("Failed to search accounts", fmt.Errorf("unexpected status: %s", resp.Status()))
+	// Check and parse the response while it is read
+	var apiResp AccountArray
+	found, err := decodeListResponse(resp, "Account", "accounts", &apiResp)
+	if err != nil {
+		return nil, err
 	}
-
-	// Convert API response to AccountModels
-	if resp.HTTPResponse == nil || len(resp.Body) == 0 {
+	if !found {
 		return []AccountModel{}, nil
 	}
 
-	var apiResp AccountArray
-	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
-		return nil, APIErr("Failed to parse accounts response", err)
-	}
-
 	accounts := make([]AccountModel, 0, len(apiResp.Data))
 	for _, accountRead := range apiResp.Data {
-		// Parse balance
-		balance := float64(0)
-		if accountRead.Attributes.CurrentBalance != nil {
-			var err error
-			balance, err = strconv.ParseFloat(*accountRead.Attributes.CurrentBalance, 64)
-			if err != nil {
-				return nil, APIErr("Failed to parse balance", err)
-			}
+		account, err := accountModelFromRead(accountRead)
+		if err != nil {
+			return nil, err
 		}
+		accounts = append(accounts, account)
+	}
 
-		// Get account role
-		role := ""
-		if accountRead.Attributes.AccountRole != nil {
-			role = string(*accountRead.Attributes.AccountRole)
-		}
+	return accounts, nil
+}
+
+// ListAccountsByBalanceThreshold retrieves the asset accounts in currency whose current balance is below the threshold.
+// Accounts in other currencies are skipped; the currency code is matched case-insensitively.
+func (c *FireflyClient) ListAccountsByBalanceThreshold(ctx context.Context, below float64, currency string) ([]AccountModel, error) {
+	if strings.TrimSpace(currency) == "" {
+		var errs errbuilder.ErrorMap
+		errs.Set("currency", "Currency is required")
+		return nil, ValidationErr("Account", errs)
+	}
+
+	accounts, err := listAllPages(ctx, func(ctx context.Context, page, limit int) ([]AccountModel, error) {
+		return c.ListAccountsFiltered(ctx, AccountFilter{Page: page, Limit: limit, Type: string(AccountTypeFilterAsset)})
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		account := AccountModel{
-			ID:       accountRead.Id,
-			Name:     accountRead.Attributes.Name,
-			Type:     string(accountRead.Attributes.Type),
-			Currency: stringValue(accountRead.Attributes.CurrencyCode),
-			Balance:  balance,
-			IBAN:     stringValue(accountRead.Attributes.Iban),
-			Number:   stringValue(accountRead.Attributes.AccountNumber),
-			BankName: "", // Not available in API
-			Active:   boolValue(accountRead.Attributes.Active),
-			Role:     role,
-			Include:  boolValue(accountRead.Attributes.IncludeNetWorth),
+	results := []AccountModel{}
+	for _, account := range accounts {
+		if strings.EqualFold(account.Currency, strings.TrimSpace(currency)) && account.Balance < below {
+			results = append(results, account)
 		}
-		accounts = append(accounts, account)
 	}
 
-	return accounts, nil
+	return results, nil
 }
 
 // CreateCategory creates a new category
@@ -1472,14 +2869,8 @@ func (c *FireflyClient) CreateCategory(ctx context.Context, category CategoryMod
 	}
 
 	// Check response
-	if resp.StatusCode() == http.StatusConflict {
-		return DuplicateErr("Category", fmt.Errorf("category already exists"))
-	}
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return RateLimitErr(fmt.Errorf("rate limit exceeded"))
-	}
 	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusCreated {
-		return APIErr("Failed to create category", fmt.Errorf("unexpected status: %s", resp.Status()))
+		return responseErr("Category", resp.HTTPResponse, resp.Body)
 	}
 
 	return nil
@@ -1492,14 +2883,8 @@ func (c *FireflyClient) GetCategory(ctx context.Context, id string) (*CategoryMo
 		return nil, APIErr("Failed to get category", err)
 	}
 
-	if response.StatusCode() == http.StatusNotFound {
-		return nil, NotFoundErr("Category", fmt.Errorf("category not found: %s", id))
-	}
-	if response.StatusCode() == http.StatusTooManyRequests {
-		return nil, RateLimitErr(fmt.Errorf("rate limit exceeded"))
-	}
 	if response.StatusCode() != http.StatusOK {
-		return nil, APIErr("Failed to get category", fmt.Errorf("unexpected status: %s", response.Status()))
+		return nil, responseErr("Category", response.HTTPResponse, response.Body)
 	}
 
 	if response.HTTPResponse == nil || len(response.Body) == 0 {
@@ -1550,57 +2935,51 @@ func (c *FireflyClient) GetCategory(ctx context.Context, id string) (*CategoryMo
 
 // ListCategories retrieves a list of categories with pagination
 func (c *FireflyClient) ListCategories(ctx context.Context, page, limit int) ([]CategoryModel, error) {
-	// Convert page and limit to int32
-	page32 := int32(page)
-	limit32 := int32(limit)
+	page32, limit32 := pageParams(page, limit)
 
 	// Call the API
-	resp, err := c.clientAPI.ListCategoryWithResponse(ctx, &ListCategoryParams{
-		Page:  &page32,
-		Limit: &limit32,
+	resp, err := c.clientAPI.ListCategory(ctx, &ListCategoryParams{
+		Page:  page32,
+		Limit: limit32,
 	})
 	if err != nil {
 		return nil, APIErr("Failed to list categories", err)
 	}
 
-	// Check response
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return nil, RateLimitErr(fmt.Errorf("rate limit exceeded"))
-	}
-	if resp.StatusCode() != http.StatusOK {
-		return nil, APIErr("Failed to list categories", fmt.Errorf("unexpected status: %s", resp.Status()))
-	}
-
-	// Convert API response to CategoryModel array
-	if resp.HTTPResponse == nil || len(resp.Body) == 0 {
-		return nil, APIErr("No category data found", fmt.Errorf("empty response"))
-	}
-
+	// Check and parse the response while it is read
 	var apiResp CategoryArray
-	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
-		return nil, APIErr("Failed to parse categories response", err)
+	found, err := decodeListResponse(resp, "Category", "categories", &apiResp)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return []CategoryModel{}, nil
 	}
 
 	categories := make([]CategoryModel, 0, len(apiResp.Data))
 	for _, categoryRead := range apiResp.Data {
-		category := CategoryModel{
-			ID:                  categoryRead.Id,
-			Name:                categoryRead.Attributes.Name,
-			Notes:               stringValue(categoryRead.Attributes.Notes),
-			CreatedAt:           timeValue(categoryRead.Attributes.CreatedAt),
-			UpdatedAt:           timeValue(categoryRead.Attributes.UpdatedAt),
-			Spent:               []CategorySpentModel{},
-			Earned:              []CategoryEarnedModel{},
-			NativeCurrency:      stringValue(categoryRead.Attributes.NativeCurrencyCode),
-			NativeDecimalPlaces: int32Value(categoryRead.Attributes.NativeCurrencyDecimalPlaces),
-			NativeSymbol:        stringValue(categoryRead.Attributes.NativeCurrencySymbol),
-		}
-		categories = append(categories, category)
+		categories = append(categories, categoryModelFromRead(categoryRead))
 	}
 
 	return categories, nil
 }
 
+// categoryModelFromRead converts an API category into a CategoryModel without spent and earned amounts
+func categoryModelFromRead(categoryRead CategoryRead) CategoryModel {
+	return CategoryModel{
+		ID:                  categoryRead.Id,
+		Name:                categoryRead.Attributes.Name,
+		Notes:               stringValue(categoryRead.Attributes.Notes),
+		CreatedAt:           timeValue(categoryRead.Attributes.CreatedAt),
+		UpdatedAt:           timeValue(categoryRead.Attributes.UpdatedAt),
+		Spent:               []CategorySpentModel{},
+		Earned:              []CategoryEarnedModel{},
+		NativeCurrency:      stringValue(categoryRead.Attributes.NativeCurrencyCode),
+		NativeDecimalPlaces: int32Value(categoryRead.Attributes.NativeCurrencyDecimalPlaces),
+		NativeSymbol:        stringValue(categoryRead.Attributes.NativeCurrencySymbol),
+	}
+}
+
 // UpdateCategory updates an existing category
 func (c *FireflyClient) UpdateCategory(ctx context.Context, id string, category CategoryModel) error {
 	// Validate category
@@ -1621,17 +3000,8 @@ func (c *FireflyClient) UpdateCategory(ctx context.Context, id string, category
 	}
 
 	// Check response
-	if resp.StatusCode() == http.StatusNotFound {
-		return NotFoundErr("Category", fmt.Errorf("category not found: %s", id))
-	}
-	if resp.StatusCode() == http.StatusConflict {
-		return DuplicateErr("Category", fmt.Errorf("category with this name already exists"))
-	}
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return RateLimitErr(fmt.Errorf("rate limit exceeded"))
-	}
 	if resp.StatusCode() != http.StatusOK {
-		return APIErr("Failed to update category", fmt.Errorf("unexpected status: %s", resp.Status()))
+		return responseErr("Category", resp.HTTPResponse, resp.Body)
 	}
 
 	return nil
@@ -1646,14 +3016,8 @@ func (c *FireflyClient) DeleteCategory(ctx context.Context, id string) error {
 	}
 
 	// Check response
-	if resp.StatusCode() == http.StatusNotFound {
-		return NotFoundErr("Category", fmt.Errorf("category not found: %s", id))
-	}
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return RateLimitErr(fmt.Errorf("rate limit exceeded"))
-	}
 	if resp.StatusCode() != http.StatusNoContent {
-		return APIErr("Failed to delete category", fmt.Errorf("unexpected status: %s", resp.Status()))
+		return responseErr("Category", resp.HTTPResponse, resp.Body)
 	}
 
 	return nil
@@ -1661,8 +3025,8 @@ func (c *FireflyClient) DeleteCategory(ctx context.Context, id string) error {
 
 // SearchCategories searches for categories matching the query
 func (c *FireflyClient) SearchCategories(ctx context.Context, query string) ([]CategoryModel, error) {
-	// Get all categories (with a reasonable limit)
-	categories, err := c.ListCategories(ctx, 1, 100)
+	// Get all categories
+	categories, err := listAllPages(ctx, c.ListCategories)
 	if err != nil {
 		return nil, APIErr("Failed to search categories", err)
 	}
@@ -1682,8 +3046,8 @@ func (c *FireflyClient) SearchCategories(ctx context.Context, query string) ([]C
 
 // GetCategoryByName retrieves a category by its exact name (case-insensitive)
 func (c *FireflyClient) GetCategoryByName(ctx context.Context, name string) (*CategoryModel, error) {
-	// Get all categories (with a reasonable limit)
-	categories, err := c.ListCategories(ctx, 1, 100)
+	// Get all categories
+	categories, err := listAllPages(ctx, c.ListCategories)
 	if err != nil {
 		return nil, APIErr("Failed to get category by name", err)
 	}
@@ -1699,15 +3063,116 @@ func (c *FireflyClient) GetCategoryByName(ctx context.Context, name string) (*Ca
 	return nil, NotFoundErr("Category", fmt.Errorf("category not found: %s", name))
 }
 
+// ListCategoryTransactions retrieves the transactions of a category matching the filter
+func (c *FireflyClient) ListCategoryTransactions(ctx context.Context, categoryID string, filter TransactionFilter) ([]TransactionModel, error) {
+	txReads, err := c.listCategoryTransactionReads(ctx, categoryID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.transactionModelsFromReads(txReads), nil
+}
+
+// listCategoryTransactionReads lists the API transaction groups of a category matching the filter
+func (c *FireflyClient) listCategoryTransactionReads(ctx context.Context, categoryID string, filter TransactionFilter) ([]TransactionRead, error) {
+	page32, limit32 := pageParams(filter.Page, filter.Limit)
+
+	params := &ListTransactionByCategoryParams{
+		Page:  page32,
+		Limit: limit32,
+		Start: dateToAPIDate(optionalDate(filter.Start)),
+		End:   dateToAPIDate(optionalDate(filter.End)),
+	}
+	if filter.Type != "" {
+		txType := TransactionTypeFilter(filter.Type)
+		params.Type = &txType
+	}
+
+	// Call the API
+	resp, err := c.clientAPI.ListTransactionByCategory(ctx, categoryID, params, sortEditor(filter.Sort))
+	if err != nil {
+		return nil, APIErr("Failed to list category transactions", err)
+	}
+
+	// Check and parse the response while it is read
+	var apiResp TransactionArray
+	found, err := decodeListResponse(resp, "Category", "category transactions", &apiResp)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return []TransactionRead{}, nil
+	}
+
+	return apiResp.Data, nil
+}
+
+// EnsureCategory returns the category with the given name (case-insensitive), creating it with notes if it does not exist.
+// Categories are cached per client and WithToken token; if the category is created elsewhere in the meantime,
+// the duplicate error is resolved by looking it up again.
+func (c *FireflyClient) EnsureCategory(ctx context.Context, name, notes string) (*CategoryModel, error) {
+	if errs := validateCategory(CategoryModel{Name: name}); errs != nil {
+		return nil, CategoryValidationErr(errs)
+	}
+
+	c.categoryMu.Lock()
+	defer c.categoryMu.Unlock()
+
+	key := cacheScope(ctx) + strings.ToLower(name)
+	if category, ok := c.categoryCache[key]; ok {
+		return &category, nil
+	}
+	if c.categoryCache == nil {
+		c.categoryCache = make(map[string]CategoryModel)
+	}
+
+	category, err := c.GetCategoryByName(ctx, name)
+	if errors.Is(err, ErrNotFoundSentinel) {
+		category, err = c.storeCategory(ctx, name, notes)
+		if errors.Is(err, ErrDuplicateSentinel) {
+			category, err = c.GetCategoryByName(ctx, name)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.categoryCache[key] = *category
+	return category, nil
+}
+
+// storeCategory creates a category and returns it as stored by the API
+func (c *FireflyClient) storeCategory(ctx context.Context, name, notes string) (*CategoryModel, error) {
+	// Call the API
+	resp, err := c.clientAPI.StoreCategoryWithResponse(ctx, &StoreCategoryParams{}, StoreCategoryJSONRequestBody{
+		Name:  name,
+		Notes: stringPtr(notes),
+	})
+	if err != nil {
+		return nil, APIErr("Failed to create category", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusCreated {
+		return nil, responseErr("Category", resp.HTTPResponse, resp.Body)
+	}
+
+	var apiResp CategorySingle
+	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
+		return nil, APIErr("Failed to parse category response", err)
+	}
+
+	category := categoryModelFromRead(apiResp.Data)
+	return &category, nil
+}
+
 // CreateBudget creates a new budget
-func (c *FireflyClient) CreateBudget(budget BudgetModel) error {
+func (c *FireflyClient) CreateBudget(ctx context.Context, budget BudgetModel) error {
 	// Validate budget
 	if errs := validateBudget(budget); errs != nil {
 		return BudgetValidationErr(errs)
 	}
 
-	ctx := context.Background()
-
 	// Create budget request
 	budgetRequest := StoreBudgetJSONRequestBody{
 		Name:             budget.Name,
@@ -1726,23 +3191,15 @@ func (c *FireflyClient) CreateBudget(budget BudgetModel) error {
 	}
 
 	// Check response
-	if resp.StatusCode() == http.StatusConflict {
-		return DuplicateErr("Budget", fmt.Errorf("budget already exists"))
-	}
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return RateLimitErr(fmt.Errorf("rate limit exceeded"))
-	}
 	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusCreated {
-		return APIErr("Failed to create budget", fmt.Errorf("unexpected status: %s", resp.Status()))
+		return responseErr("Budget", resp.HTTPResponse, resp.Body)
 	}
 
 	return nil
 }
 
 // GetBudget retrieves a single budget by ID
-func (c *FireflyClient) GetBudget(id string) (*BudgetModel, error) {
-	ctx := context.Background()
-
+func (c *FireflyClient) GetBudget(ctx context.Context, id string) (*BudgetModel, error) {
 	// Call the API
 	resp, err := c.clientAPI.GetBudgetWithResponse(ctx, id, &GetBudgetParams{})
 	if err != nil {
@@ -1750,14 +3207,8 @@ func (c *FireflyClient) GetBudget(id string) (*BudgetModel, error) {
 	}
 
 	// Check response
-	if resp.StatusCode() == http.StatusNotFound {
-		return nil, NotFoundErr("Budget", fmt.Errorf("budget not found: %s", id))
-	}
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return nil, RateLimitErr(fmt.Errorf("rate limit exceeded"))
-	}
 	if resp.StatusCode() != http.StatusOK {
-		return nil, APIErr("Failed to get budget", fmt.Errorf("unexpected status: %s", resp.Status()))
+		return nil, responseErr("Budget", resp.HTTPResponse, resp.Body)
 	}
 
 	// Convert API response to BudgetModel
@@ -1770,84 +3221,97 @@ func (c *FireflyClient) GetBudget(id string) (*BudgetModel, error) {
 		return nil, APIErr("Failed to parse budget response", err)
 	}
 
-	budget := &BudgetModel{
-		ID:               apiResp.Data.Id,
-		Name:             apiResp.Data.Attributes.Name,
-		Active:           boolValue(apiResp.Data.Attributes.Active),
-		Notes:            apiResp.Data.Attributes.Notes,
-		Order:            apiResp.Data.Attributes.Order,
-		AutoBudgetAmount: apiResp.Data.Attributes.AutoBudgetAmount,
-		AutoBudgetPeriod: apiResp.Data.Attributes.AutoBudgetPeriod,
-		AutoBudgetType:   apiResp.Data.Attributes.AutoBudgetType,
-		Spent:            apiResp.Data.Attributes.Spent,
-		CreatedAt:        timeValue(apiResp.Data.Attributes.CreatedAt),
-		UpdatedAt:        timeValue(apiResp.Data.Attributes.UpdatedAt),
-	}
+	budget := budgetModelFromRead(apiResp.Data)
+	return &budget, nil
+}
 
-	return budget, nil
+// budgetModelFromRead converts an API budget to a BudgetModel
+func budgetModelFromRead(budgetRead BudgetRead) BudgetModel {
+	return BudgetModel{
+		ID:               budgetRead.Id,
+		Name:             budgetRead.Attributes.Name,
+		Active:           boolValue(budgetRead.Attributes.Active),
+		Notes:            budgetRead.Attributes.Notes,
+		Order:            budgetRead.Attributes.Order,
+		AutoBudgetAmount: budgetRead.Attributes.AutoBudgetAmount,
+		AutoBudgetPeriod: budgetRead.Attributes.AutoBudgetPeriod,
+		AutoBudgetType:   budgetRead.Attributes.AutoBudgetType,
+		Spent:            budgetRead.Attributes.Spent,
+		CreatedAt:        timeValue(budgetRead.Attributes.CreatedAt),
+		UpdatedAt:        timeValue(budgetRead.Attributes.UpdatedAt),
+	}
 }
 
 // ListBudgets retrieves a list of budgets with pagination
-func (c *FireflyClient) ListBudgets(page, limit int) ([]BudgetModel, error) {
-	ctx := context.Background()
+func (c *FireflyClient) ListBudgets(ctx context.Context, page, limit int) ([]BudgetModel, error) {
+	page32, limit32 := pageParams(page, limit)
 
-	// Call the API
-	resp, err := c.clientAPI.ListBudgetWithResponse(ctx, &ListBudgetParams{
-		Page:  int32Ptr(page),
-		Limit: int32Ptr(limit),
+	return c.listBudgets(ctx, &ListBudgetParams{
+		Page:  page32,
+		Limit: limit32,
 	})
-	if err != nil {
-		return nil, APIErr("Failed to list budgets", err)
-	}
+}
 
-	// Check response
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return nil, RateLimitErr(fmt.Errorf("rate limit exceeded"))
+// ListBudgetsForPeriod retrieves a list of budgets with their spent amounts populated for the given period
+func (c *FireflyClient) ListBudgetsForPeriod(ctx context.Context, start, end time.Time, page, limit int) ([]BudgetModel, error) {
+	// Validate period, the API requires both start and end
+	var errs errbuilder.ErrorMap
+	if start.IsZero() {
+		errs.Set("start", "Start date is required")
 	}
-	if resp.StatusCode() != http.StatusOK {
-		return nil, APIErr("Failed to list budgets", fmt.Errorf("unexpected status: %s", resp.Status()))
+	if end.IsZero() {
+		errs.Set("end", "End date is required")
+	}
+	if !start.IsZero() && !end.IsZero() && end.Before(start) {
+		errs.Set("end", "End date must not be before start date")
+	}
+	if errs != nil {
+		return nil, BudgetValidationErr(errs)
 	}
 
-	// Convert API response to BudgetModel array
-	if resp.HTTPResponse == nil || len(resp.Body) == 0 {
-		return nil, APIErr("No budget data found", fmt.Errorf("empty response"))
+	page32, limit32 := pageParams(page, limit)
+
+	return c.listBudgets(ctx, &ListBudgetParams{
+		Page:  page32,
+		Limit: limit32,
+		Start: dateToAPIDate(&start),
+		End:   dateToAPIDate(&end),
+	})
+}
+
+// listBudgets retrieves budgets using the given list parameters
+func (c *FireflyClient) listBudgets(ctx context.Context, params *ListBudgetParams) ([]BudgetModel, error) {
+	// Call the API
+	resp, err := c.clientAPI.ListBudget(ctx, params)
+	if err != nil {
+		return nil, APIErr("Failed to list budgets", err)
 	}
 
+	// Check and parse the response while it is read
 	var apiResp BudgetArray
-	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
-		return nil, APIErr("Failed to parse budgets response", err)
+	found, err := decodeListResponse(resp, "Budget", "budgets", &apiResp)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return []BudgetModel{}, nil
 	}
 
 	budgets := make([]BudgetModel, 0, len(apiResp.Data))
 	for _, budgetRead := range apiResp.Data {
-		budget := BudgetModel{
-			ID:               budgetRead.Id,
-			Name:             budgetRead.Attributes.Name,
-			Active:           boolValue(budgetRead.Attributes.Active),
-			Notes:            budgetRead.Attributes.Notes,
-			Order:            budgetRead.Attributes.Order,
-			AutoBudgetAmount: budgetRead.Attributes.AutoBudgetAmount,
-			AutoBudgetPeriod: budgetRead.Attributes.AutoBudgetPeriod,
-			AutoBudgetType:   budgetRead.Attributes.AutoBudgetType,
-			Spent:            budgetRead.Attributes.Spent,
-			CreatedAt:        timeValue(budgetRead.Attributes.CreatedAt),
-			UpdatedAt:        timeValue(budgetRead.Attributes.UpdatedAt),
-		}
-		budgets = append(budgets, budget)
+		budgets = append(budgets, budgetModelFromRead(budgetRead))
 	}
 
 	return budgets, nil
 }
 
 // UpdateBudget updates an existing budget
-func (c *FireflyClient) UpdateBudget(id string, budget BudgetModel) error {
+func (c *FireflyClient) UpdateBudget(ctx context.Context, id string, budget BudgetModel) error {
 	// Validate budget
 	if errs := validateBudget(budget); errs != nil {
 		return BudgetValidationErr(errs)
 	}
 
-	ctx := context.Background()
-
 	// Create budget update request
 	update := UpdateBudgetJSONRequestBody{
 		Name:             budget.Name,
@@ -1866,23 +3330,15 @@ func (c *FireflyClient) UpdateBudget(id string, budget BudgetModel) error {
 	}
 
 	// Check response
-	if resp.StatusCode() == http.StatusNotFound {
-		return NotFoundErr("Budget", fmt.Errorf("budget not found: %s", id))
-	}
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return RateLimitErr(fmt.Errorf("rate limit exceeded"))
-	}
 	if resp.StatusCode() != http.StatusOK {
-		return APIErr("Failed to update budget", fmt.Errorf("unexpected status: %s", resp.Status()))
+		return responseErr("Budget", resp.HTTPResponse, resp.Body)
 	}
 
 	return nil
 }
 
 // DeleteBudget deletes a budget
-func (c *FireflyClient) DeleteBudget(id string) error {
-	ctx := context.Background()
-
+func (c *FireflyClient) DeleteBudget(ctx context.Context, id string) error {
 	// Call the API
 	resp, err := c.clientAPI.DeleteBudgetWithResponse(ctx, id, &DeleteBudgetParams{})
 	if err != nil {
@@ -1890,28 +3346,41 @@ func (c *FireflyClient) DeleteBudget(id string) error {
 	}
 
 	// Check response
-	if resp.StatusCode() == http.StatusNotFound {
-		return NotFoundErr("Budget", fmt.Errorf("budget not found: %s", id))
-	}
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return RateLimitErr(fmt.Errorf("rate limit exceeded"))
-	}
 	if resp.StatusCode() != http.StatusNoContent {
-		return APIErr("Failed to delete budget", fmt.Errorf("unexpected status: %s", resp.Status()))
+		return responseErr("Budget", resp.HTTPResponse, resp.Body)
 	}
 
 	return nil
 }
 
+// SearchBudgets searches for budgets whose name or notes contain the query (case-insensitive)
+func (c *FireflyClient) SearchBudgets(ctx context.Context, query string) ([]BudgetModel, error) {
+	// Get all budgets
+	budgets, err := listAllPages(ctx, c.ListBudgets)
+	if err != nil {
+		return nil, APIErr("Failed to search budgets", err)
+	}
+
+	// Filter budgets based on the query (case-insensitive)
+	query = strings.ToLower(query)
+	var results []BudgetModel
+	for _, budget := range budgets {
+		if strings.Contains(strings.ToLower(budget.Name), query) ||
+			strings.Contains(strings.ToLower(stringValue(budget.Notes)), query) {
+			results = append(results, budget)
+		}
+	}
+
+	return results, nil
+}
+
 // SetBudgetLimit sets a budget limit
-func (c *FireflyClient) SetBudgetLimit(budgetID string, limit BudgetLimitModel) error {
+func (c *FireflyClient) SetBudgetLimit(ctx context.Context, budgetID string, limit BudgetLimitModel) error {
 	// Validate budget limit
 	if errs := validateBudgetLimit(limit); errs != nil {
 		return BudgetValidationErr(errs)
 	}
 
-	ctx := context.Background()
-
 	// Create budget limit update request
 	update := UpdateBudgetLimitJSONRequestBody{
 		Amount: limit.Amount,
@@ -1928,43 +3397,125 @@ func (c *FireflyClient) SetBudgetLimit(budgetID string, limit BudgetLimitModel)
 	}
 
 	// Check response
-	if resp.StatusCode() == http.StatusNotFound {
-		return NotFoundErr("Budget Limit", fmt.Errorf("budget limit not found: %s", limit.ID))
-	}
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return RateLimitErr(fmt.Errorf("rate limit exceeded"))
-	}
 	if resp.StatusCode() != http.StatusOK {
-		return APIErr("Failed to update budget limit", fmt.Errorf("unexpected status: %s", resp.Status()))
+		return responseErr("Budget Limit", resp.HTTPResponse, resp.Body)
 	}
 
 	return nil
 }
 
-// GetBudgetLimits retrieves all budget limits for a budget
-func (c *FireflyClient) GetBudgetLimits(budgetID string) ([]BudgetLimitModel, error) {
-	ctx := context.Background()
+// GetBudgetLimits retrieves all budget limits for a budget, or of all budgets if budgetID is empty
+func (c *FireflyClient) GetBudgetLimits(ctx context.Context, budgetID string) ([]BudgetLimitModel, error) {
+	return c.ListBudgetLimits(ctx, budgetID, time.Time{}, time.Time{})
+}
 
-	// Call the API
-	resp, err := c.clientAPI.ListBudgetLimitWithResponse(ctx, &ListBudgetLimitParams{})
+// Bounds sent for an open side of the period to /v1/budget-limits, which requires both dates
+var (
+	openPeriodStart = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+	openPeriodEnd   = time.Date(2999, 12, 31, 0, 0, 0, 0, time.UTC)
+)
+
+// ListBudgetLimits retrieves the budget limits of a budget, or of all budgets if budgetID is empty,
+// that overlap the period from start to end. Dates are compared by day and a zero start or end
+// leaves that side of the period open.
+func (c *FireflyClient) ListBudgetLimits(ctx context.Context, budgetID string, start, end time.Time) ([]BudgetLimitModel, error) {
+	if !start.IsZero() && !end.IsZero() && end.Before(start) {
+		var errs errbuilder.ErrorMap
+		errs.Set("end", "End date must not be before start date")
+		return nil, BudgetValidationErr(errs)
+	}
+
+	// Call the API, letting the server drop the limits outside the period
+	var resp *http.Response
+	var err error
+	if budgetID != "" {
+		resp, err = c.clientAPI.ListBudgetLimitByBudget(ctx, budgetID, &ListBudgetLimitByBudgetParams{
+			Start: dateToAPIDate(optionalDate(start)),
+			End:   dateToAPIDate(optionalDate(end)),
+		})
+	} else {
+		from, to := start, end
+		if from.IsZero() {
+			from = openPeriodStart
+		}
+		if to.IsZero() {
+			to = openPeriodEnd
+		}
+		resp, err = c.clientAPI.ListBudgetLimit(ctx, &ListBudgetLimitParams{
+			Start: *dateToAPIDate(&from),
+			End:   *dateToAPIDate(&to),
+		})
+	}
 	if err != nil {
 		return nil, APIErr("Failed to list budget limits", err)
 	}
 
-	// Check response
-	if resp.StatusCode() == http.StatusNotFound {
-		return nil, NotFoundErr("Budget", fmt.Errorf("budget not found: %s", budgetID))
+	// Check and parse the response while it is read
+	var apiResp BudgetLimitArray
+	if _, err := decodeListResponse(resp, "Budget Limit", "budget limits", &apiResp); err != nil {
+		return nil, err
+	}
+
+	limits := make([]BudgetLimitModel, 0, len(apiResp.Data))
+	for _, limitRead := range apiResp.Data {
+		limit := budgetLimitModelFromRead(limitRead)
+		// Filter here as well, so the result does not depend on how the server matches periods
+		if !budgetLimitOverlaps(limit, start, end) {
+			continue
+		}
+		limits = append(limits, limit)
+	}
+
+	return limits, nil
+}
+
+// budgetLimitOverlaps reports whether the period of limit shares a day with the period from start
+// to end, where a zero start or end is open
+func budgetLimitOverlaps(limit BudgetLimitModel, start, end time.Time) bool {
+	if !start.IsZero() && !limit.End.IsZero() && limit.End.Format(time.DateOnly) < start.Format(time.DateOnly) {
+		return false
+	}
+	if !end.IsZero() && !limit.Start.IsZero() && limit.Start.Format(time.DateOnly) > end.Format(time.DateOnly) {
+		return false
+	}
+	return true
+}
+
+// GetAllBudgetLimits retrieves the budget limits of all budgets within a period in a single request.
+// The limits are grouped by budget, keeping the API order within each budget.
+func (c *FireflyClient) GetAllBudgetLimits(ctx context.Context, start, end time.Time) ([]BudgetLimitModel, error) {
+	// Validate period, the API requires both start and end
+	var errs errbuilder.ErrorMap
+	if start.IsZero() {
+		errs.Set("start", "Start date is required")
+	}
+	if end.IsZero() {
+		errs.Set("end", "End date is required")
+	}
+	if !start.IsZero() && !end.IsZero() && end.Before(start) {
+		errs.Set("end", "End date must not be before start date")
+	}
+	if errs != nil {
+		return nil, BudgetValidationErr(errs)
 	}
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return nil, RateLimitErr(fmt.Errorf("rate limit exceeded"))
+
+	// Call the API
+	resp, err := c.clientAPI.ListBudgetLimitWithResponse(ctx, &ListBudgetLimitParams{
+		Start: *dateToAPIDate(&start),
+		End:   *dateToAPIDate(&end),
+	})
+	if err != nil {
+		return nil, APIErr("Failed to list budget limits", err)
 	}
+
+	// Check response
 	if resp.StatusCode() != http.StatusOK {
-		return nil, APIErr("Failed to list budget limits", fmt.Errorf("unexpected status: %s", resp.Status()))
+		return nil, responseErr("Budget Limit", resp.HTTPResponse, resp.Body)
 	}
 
 	// Convert API response to BudgetLimitModel array
 	if resp.HTTPResponse == nil || len(resp.Body) == 0 {
-		return nil, APIErr("No budget limit data found", fmt.Errorf("empty response"))
+		return []BudgetLimitModel{}, nil
 	}
 
 	var apiResp BudgetLimitArray
@@ -1972,35 +3523,49 @@ func (c *FireflyClient) GetBudgetLimits(budgetID string) ([]BudgetLimitModel, er
 		return nil, APIErr("Failed to parse budget limits response", err)
 	}
 
-	limits := make([]BudgetLimitModel, 0, len(apiResp.Data))
+	// Group by budget in order of first appearance
+	var budgetIDs []string
+	byBudget := make(map[string][]BudgetLimitModel)
 	for _, limitRead := range apiResp.Data {
-		limit := BudgetLimitModel{
-			ID:        limitRead.Id,
-			BudgetID:  limitRead.Attributes.BudgetId,
-			Amount:    limitRead.Attributes.Amount,
-			Period:    stringValue(limitRead.Attributes.Period),
-			Start:     limitRead.Attributes.Start,
-			End:       limitRead.Attributes.End,
-			Spent:     limitRead.Attributes.Spent,
-			Notes:     limitRead.Attributes.Notes,
-			CreatedAt: timeValue(limitRead.Attributes.CreatedAt),
-			UpdatedAt: timeValue(limitRead.Attributes.UpdatedAt),
+		limit := budgetLimitModelFromRead(limitRead)
+		budgetID := stringValue(limit.BudgetID)
+		if _, ok := byBudget[budgetID]; !ok {
+			budgetIDs = append(budgetIDs, budgetID)
 		}
-		limits = append(limits, limit)
+		byBudget[budgetID] = append(byBudget[budgetID], limit)
+	}
+
+	limits := make([]BudgetLimitModel, 0, len(apiResp.Data))
+	for _, budgetID := range budgetIDs {
+		limits = append(limits, byBudget[budgetID]...)
 	}
 
 	return limits, nil
 }
 
+// budgetLimitModelFromRead converts an API budget limit to a BudgetLimitModel
+func budgetLimitModelFromRead(limitRead BudgetLimitRead) BudgetLimitModel {
+	return BudgetLimitModel{
+		ID:        limitRead.Id,
+		BudgetID:  limitRead.Attributes.BudgetId,
+		Amount:    limitRead.Attributes.Amount,
+		Period:    stringValue(limitRead.Attributes.Period),
+		Start:     limitRead.Attributes.Start,
+		End:       limitRead.Attributes.End,
+		Spent:     limitRead.Attributes.Spent,
+		Notes:     limitRead.Attributes.Notes,
+		CreatedAt: timeValue(limitRead.Attributes.CreatedAt),
+		UpdatedAt: timeValue(limitRead.Attributes.UpdatedAt),
+	}
+}
+
 // UpdateBudgetLimit updates an existing budget limit
-func (c *FireflyClient) UpdateBudgetLimit(limitID string, limit BudgetLimitModel) error {
+func (c *FireflyClient) UpdateBudgetLimit(ctx context.Context, limitID string, limit BudgetLimitModel) error {
 	// Validate budget limit
 	if errs := validateBudgetLimit(limit); errs != nil {
 		return BudgetValidationErr(errs)
 	}
 
-	ctx := context.Background()
-
 	// Create budget limit update request
 	update := UpdateBudgetLimitJSONRequestBody{
 		Amount: limit.Amount,
@@ -2017,25 +3582,17 @@ func (c *FireflyClient) UpdateBudgetLimit(limitID string, limit BudgetLimitModel
 	}
 
 	// Check response
-	if resp.StatusCode() == http.StatusNotFound {
-		return NotFoundErr("Budget Limit", fmt.Errorf("budget limit not found: %s", limitID))
-	}
-	if resp.StatusCode() == http.StatusTooManyRequests {
-		return RateLimitErr(fmt.Errorf("rate limit exceeded"))
-	}
 	if resp.StatusCode() != http.StatusOK {
-		return APIErr("Failed to update budget limit", fmt.Errorf("unexpected status: %s", resp.Status()))
+		return responseErr("Budget Limit", resp.HTTPResponse, resp.Body)
 	}
 
 	return nil
 }
 
 // DeleteBudgetLimit deletes a budget limit
-func (c *FireflyClient) DeleteBudgetLimit(limitID string) error {
-	ctx := context.Background()
-
+func (c *FireflyClient) DeleteBudgetLimit(ctx context.Context, limitID string) error {
 	// Get the budget limit first to get its budget ID
-	limits, err := c.GetBudgetLimits("")
+	limits, err := c.GetBudgetLimits(ctx, "")
 	if err != nil {
 		return fmt.Errorf("failed to get budget limit info: %w", err)
 	}
@@ -2060,28 +3617,30 @@ func (c *FireflyClient) DeleteBudgetLimit(limitID string) error {
 	}
 
 	// Check response
-	switch resp.StatusCode() {
-	case http.StatusNotFound:
-		return NotFoundErr("Budget Limit", fmt.Errorf("budget limit not found: %s", limitID))
-	case http.StatusTooManyRequests:
-		return RateLimitErr(fmt.Errorf("rate limit exceeded"))
-	case http.StatusNoContent:
-		// Successful response, continue
-	default:
-		return APIErr("Failed to delete budget limit", fmt.Errorf("unexpected status: %s", resp.Status()))
+	if resp.StatusCode() != http.StatusNoContent {
+		return responseErr("Budget Limit", resp.HTTPResponse, resp.Body)
 	}
 
 	return nil
 }
 
-// RegisterImporter registers a new importer
+// RegisterImporter registers a new importer with an empty configuration
 func (c *FireflyClient) RegisterImporter(importer importers.Importer) error {
-	config := importers.ImporterConfig{}
+	return c.RegisterImporterWithConfig(importer, importers.ImporterConfig{})
+}
+
+// RegisterImporterWithConfig registers an importer under config.Name. The configuration is
+// validated now and passed to the importer's Initialize every time it is run.
+func (c *FireflyClient) RegisterImporterWithConfig(importer importers.Importer, config importers.ImporterConfig) error {
 	if err := importer.ValidateConfig(config); err != nil {
 		return fmt.Errorf("invalid importer configuration: %w", err)
 	}
 
+	if c.importerConfigs == nil {
+		c.importerConfigs = make(map[string]importers.ImporterConfig)
+	}
 	c.importers[config.Name] = importer
+	c.importerConfigs[config.Name] = config
 	return nil
 }
 
@@ -2103,36 +3662,78 @@ func (c *FireflyClient) ListImporters() []importers.Importer {
 	return importerList
 }
 
-// RunImporter runs an importer with the given options
-func (c *FireflyClient) RunImporter(name string, options importers.ImportOptions) (*importers.ImportResult, error) {
+// RunImporter initializes an importer with the configuration it was registered with
+// and runs it with the given options. The import is aborted if the connection test fails.
+func (c *FireflyClient) RunImporter(ctx context.Context, name string, options importers.ImportOptions) (*importers.ImportResult, error) {
+	importer, err := c.GetImporter(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.runImporter(ctx, name, importer, c.importerConfigs[name], options)
+}
+
+// RunImporterWithConfig runs an importer like RunImporter, but initializes it with config
+// for this run instead of the configuration it was registered with
+func (c *FireflyClient) RunImporterWithConfig(ctx context.Context, name string, config importers.ImporterConfig, options importers.ImportOptions) (*importers.ImportResult, error) {
 	importer, err := c.GetImporter(name)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx := context.Background()
+	if err := importer.ValidateConfig(config); err != nil {
+		return nil, fmt.Errorf("invalid importer configuration: %w", err)
+	}
+
+	return c.runImporter(ctx, name, importer, config, options)
+}
+
+// runImporter initializes the importer, tests its connection and imports, stopping at the first error.
+// An importer runs at most once at a time, since a run resets its progress.
+func (c *FireflyClient) runImporter(ctx context.Context, name string, importer importers.Importer, config importers.ImporterConfig, options importers.ImportOptions) (*importers.ImportResult, error) {
+	c.importerMu.Lock()
+	if _, running := c.runningImporters[name]; running {
+		c.importerMu.Unlock()
+		return nil, ImporterRunningErr(name)
+	}
+	if c.runningImporters == nil {
+		c.runningImporters = make(map[string]struct{})
+	}
+	c.runningImporters[name] = struct{}{}
+	c.importerMu.Unlock()
+
+	defer func() {
+		c.importerMu.Lock()
+		delete(c.runningImporters, name)
+		c.importerMu.Unlock()
+	}()
+
+	if err := importer.Initialize(ctx, config); err != nil {
+		return nil, fmt.Errorf("failed to initialize importer %s: %w", name, err)
+	}
+	if err := importer.TestConnection(ctx); err != nil {
+		return nil, fmt.Errorf("connection test of importer %s failed: %w", name, err)
+	}
 	return importer.Import(ctx, options)
 }
 
 // GetImporterProgress gets the progress of an importer
-func (c *FireflyClient) GetImporterProgress(name string) (*importers.ImportProgress, error) {
+func (c *FireflyClient) GetImporterProgress(ctx context.Context, name string) (*importers.ImportProgress, error) {
 	importer, err := c.GetImporter(name)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx := context.Background()
 	return importer.GetProgress(ctx)
 }
 
 // CancelImporter cancels an importer's operation
-func (c *FireflyClient) CancelImporter(name string) error {
+func (c *FireflyClient) CancelImporter(ctx context.Context, name string) error {
 	importer, err := c.GetImporter(name)
 	if err != nil {
 		return err
 	}
 
-	ctx := context.Background()
 	return importer.Cancel(ctx)
 }
 
@@ -2197,6 +3798,53 @@ func (c *FireflyClient) GetOAuth2ClientCredentialsToken(ctx context.Context) (*O
 	}, nil
 }
 
+// newOAuth2TokenSource creates a cached token source that uses the configured refresh token, or
+// the client credentials flow without one. It returns nil when the configuration allows neither.
+func newOAuth2TokenSource(oauth2Config *OAuth2Config, timeout time.Duration, onRefresh func(*oauth2.Token)) *refreshingTokenSource {
+	if oauth2Config.ClientID == "" || oauth2Config.TokenURL == "" {
+		return nil
+	}
+
+	// Token requests use their own HTTP client so they bypass the API transport
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Timeout: timeout})
+
+	if oauth2Config.RefreshToken != "" {
+		config := &oauth2.Config{
+			ClientID:     oauth2Config.ClientID,
+			ClientSecret: oauth2Config.ClientSecret,
+			Scopes:       oauth2Config.Scopes,
+			Endpoint:     oauth2.Endpoint{TokenURL: oauth2Config.TokenURL},
+		}
+
+		// Every call redeems the latest refresh token; the refreshing source serializes the calls
+		refreshToken := oauth2Config.RefreshToken
+		return newRefreshingTokenSource(tokenSourceFunc(func() (*oauth2.Token, error) {
+			token, err := config.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+			if err != nil {
+				return nil, err
+			}
+			refreshToken = token.RefreshToken
+			return token, nil
+		}), onRefresh)
+	}
+
+	if oauth2Config.ClientSecret == "" {
+		return nil
+	}
+
+	config := &clientcredentials.Config{
+		ClientID:     oauth2Config.ClientID,
+		ClientSecret: oauth2Config.ClientSecret,
+		TokenURL:     oauth2Config.TokenURL,
+		Scopes:       oauth2Config.Scopes,
+	}
+
+	// config.Token fetches a new token on every call; caching is left to the refreshing source
+	return newRefreshingTokenSource(tokenSourceFunc(func() (*oauth2.Token, error) {
+		return config.Token(ctx)
+	}), onRefresh)
+}
+
 // GenerateOAuth2AuthURL generates an authorization URL for OAuth2 authorization code flow
 func (c *FireflyClient) GenerateOAuth2AuthURL(state string) (string, error) {
 	if c.config == nil || c.config.OAuth2 == nil {
@@ -2237,6 +3885,9 @@ func (c *FireflyClient) GenerateOAuth2AuthURL(state string) (string, error) {
 		state = base64.URLEncoding.EncodeToString(bytes)
 	}
 
+	// Remember the state so ExchangeOAuth2Code can verify the callback
+	c.oauthStates.Add(state)
+
 	return config.AuthCodeURL(state, oauth2.AccessTypeOffline), nil
 }
 
@@ -2249,6 +3900,15 @@ func (c *FireflyClient) ExchangeOAuth2Code(ctx context.Context, code, state stri
 		})
 	}
 
+	// Reject states that were not issued by GenerateOAuth2AuthURL or have expired
+	if !c.oauthStates.Consume(state) {
+		return nil, OAuth2Err(&OAuth2Error{
+			ErrorCode:        "invalid_state",
+			ErrorDescription: "OAuth2 state is unknown or expired",
+			State:            state,
+		})
+	}
+
 	oauth2Config := c.config.OAuth2
 	config := &oauth2.Config{
 		ClientID:     oauth2Config.ClientID,
@@ -2336,6 +3996,12 @@ type RetryConfig struct {
 	MaxDelay        time.Duration
 	BackoffFactor   float64
 	RetryableErrors []string
+
+	// ShouldRetry, if set, decides which failures are retried instead of the default classification.
+	// It gets the error response, or the error of a request that got none; successful responses
+	// are never retried. It may read up to 64 KiB of the response body, which is still available
+	// to the caller afterwards. RetryOperation calls it with a nil response.
+	ShouldRetry func(resp *http.Response, err error) bool
 }
 
 // DefaultRetryConfig returns a default retry configuration
@@ -2391,6 +4057,52 @@ func (r *RetryConfig) isRetryableError(err error) bool {
 	return false
 }
 
+// retryOn reports whether ShouldRetry asks for a request that got resp or err to be retried.
+// Successful responses are never retried and are left untouched, so their bodies keep streaming.
+// Of an error response, at most maxErrorBodySize bytes are buffered and shown to ShouldRetry;
+// afterwards the caller reads the whole body again, including the error if reading it failed.
+func (r *RetryConfig) retryOn(resp *http.Response, err error) bool {
+	if resp == nil {
+		return r.ShouldRetry(nil, err)
+	}
+	if resp.StatusCode < 400 {
+		return false
+	}
+	if resp.Body == nil {
+		return r.ShouldRetry(resp, err)
+	}
+
+	body := resp.Body
+	prefix, readErr := io.ReadAll(io.LimitReader(body, maxErrorBodySize+1))
+	var rest io.Reader = body
+	if readErr != nil {
+		rest = failedReader{readErr}
+	}
+	defer func() {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(prefix), rest), body}
+	}()
+
+	var shown io.Reader = bytes.NewReader(prefix)
+	if readErr != nil {
+		shown = io.MultiReader(shown, rest)
+	}
+	resp.Body = io.NopCloser(shown)
+	return r.ShouldRetry(resp, err)
+}
+
+// failedReader is an io.Reader that returns the error that stopped an earlier read
+type failedReader struct {
+	err error
+}
+
+// Read returns the error
+func (f failedReader) Read(p []byte) (int, error) {
+	return 0, f.err
+}
+
 // calculateBackoffDelay calculates the delay for the next retry using exponential backoff
 func (r *RetryConfig) calculateBackoffDelay(attempt int) time.Duration {
 	if attempt <= 0 {
@@ -2415,6 +4127,7 @@ func (c *FireflyClient) RetryOperation(ctx context.Context, operation func(ctx c
 	if c.config != nil {
 		retryConfig.MaxRetries = c.config.RetryCount
 		retryConfig.InitialDelay = c.config.RetryDelay
+		retryConfig.ShouldRetry = c.config.RetryOn
 	}
 
 	var lastErr error
@@ -2440,7 +4153,11 @@ func (c *FireflyClient) RetryOperation(ctx context.Context, operation func(ctx c
 		}
 
 		// Check if the error is retryable
-		if !retryConfig.isRetryableError(err) {
+		retryable := retryConfig.isRetryableError(err)
+		if retryConfig.ShouldRetry != nil {
+			retryable = retryConfig.ShouldRetry(nil, err)
+		}
+		if !retryable {
 			return err // Not retryable, return immediately
 		}
 
@@ -2459,6 +4176,13 @@ func (c *FireflyClient) RetryOperation(ctx context.Context, operation func(ctx c
 	return lastErr
 }
 
+// logf passes a warning to the configured Logger, if any
+func (c *FireflyClient) logf(format string, args ...interface{}) {
+	if c.config != nil && c.config.Logger != nil {
+		c.config.Logger(format, args...)
+	}
+}
+
 // AddMiddleware adds middleware to the client's middleware chain
 func (c *FireflyClient) AddMiddleware(middleware Middleware) {
 	c.middleware.Add(middleware)
@@ -2469,6 +4193,62 @@ func (c *FireflyClient) GetWebhookManager() *WebhookManager {
 	return c.webhookMgr
 }
 
+// TransactionEventHandler handles a webhook event together with the transaction it refers to
+type TransactionEventHandler func(ctx context.Context, event *WebhookEvent, tx *TransactionModel) error
+
+// AccountEventHandler handles a webhook event together with the account it refers to
+type AccountEventHandler func(ctx context.Context, event *WebhookEvent, account *AccountModel) error
+
+// RegisterTransactionHandler registers a handler for a transaction event type on the client's webhook manager.
+// The transaction referenced by the event's transaction_id is fetched before the handler is called.
+func (c *FireflyClient) RegisterTransactionHandler(eventType string, handler TransactionEventHandler) {
+	c.webhookMgr.RegisterHandlerFunc(eventType, func(ctx context.Context, event *WebhookEvent) error {
+		id, err := eventResourceID(event, "transaction_id")
+		if err != nil {
+			return err
+		}
+
+		tx, err := c.GetTransaction(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to fetch transaction %s of webhook event %s: %w", id, event.ID, err)
+		}
+		return handler(ctx, event, tx)
+	})
+}
+
+// RegisterAccountHandler registers a handler for an account event type on the client's webhook manager.
+// The account referenced by the event's account_id is fetched before the handler is called.
+func (c *FireflyClient) RegisterAccountHandler(eventType string, handler AccountEventHandler) {
+	c.webhookMgr.RegisterHandlerFunc(eventType, func(ctx context.Context, event *WebhookEvent) error {
+		id, err := eventResourceID(event, "account_id")
+		if err != nil {
+			return err
+		}
+
+		account, err := c.GetAccount(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to fetch account %s of webhook event %s: %w", id, event.ID, err)
+		}
+		return handler(ctx, event, account)
+	})
+}
+
+// eventResourceID returns the ID of the resource a webhook event refers to, read from the
+// given data key or from "id". IDs may be sent as strings or numbers.
+func eventResourceID(event *WebhookEvent, key string) (string, error) {
+	for _, k := range []string{key, "id"} {
+		switch id := event.Data[k].(type) {
+		case string:
+			if id != "" {
+				return id, nil
+			}
+		case float64:
+			return strconv.FormatFloat(id, 'f', -1, 64), nil
+		}
+	}
+	return "", fmt.Errorf("webhook event %s has no %s", event.ID, key)
+}
+
 // EnableDefaultMiddleware enables commonly used middleware with default configurations
 func (c *FireflyClient) EnableDefaultMiddleware() {
 	// Add rate limiting middleware with default or configured rate
@@ -2500,6 +4280,7 @@ func (c *FireflyClient) EnableDefaultMiddleware() {
 				ErrServerError,
 				ErrRateLimit,
 			},
+			ShouldRetry: c.config.RetryOn,
 		}
 		c.AddMiddleware(NewRetryMiddleware(retryConfig))
 	}
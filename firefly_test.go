@@ -3,15 +3,27 @@ package firefly
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/ZanzyTHEbar/errbuilder-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"golang.org/x/time/rate"
+
+	"github.com/ZanzyTHEbar/fireflyiii-client-go/fireflytest"
+	"github.com/ZanzyTHEbar/fireflyiii-client-go/importers"
 )
 
 // FireflyClientTestSuite defines the test suite for FireflyClient
@@ -201,8 +213,8 @@ func mockServer(t *testing.T, status int, body string) *httptest.Server {
 
 // TestListAccounts tests the ListAccounts method
 func TestListAccounts(t *testing.T) {
-	// Create a mock server that returns a JSON response
-	mockResp := `{
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/accounts", http.StatusOK, `{
 		"data": [
 			{
 				"id": "1",
@@ -224,52 +236,54 @@ func TestListAccounts(t *testing.T) {
 				"total_pages": 1
 			}
 		}
-	}`
-
-	server := mockServer(t, http.StatusOK, mockResp)
-	defer server.Close()
+	}`)
 
 	// Create a client pointing to the mock server
 	client, err := NewFireflyClient(server.URL, "test-token")
 	require.NoError(t, err)
 
-	// Call the method being tested
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	// TODO: Implement actual ListAccounts method call when available
-	// This is a placeholder test structure
-	t.Logf("ListAccounts test placeholder - method implementation pending. Client: %v, Context: %v", client != nil, ctx != nil)
+	accounts, err := client.ListAccounts(ctx, 2, 50)
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	assert.Equal(t, "1", accounts[0].ID)
+	assert.Equal(t, "Test Account", accounts[0].Name)
+	assert.Equal(t, "USD", accounts[0].Currency)
+	assert.Equal(t, 1000.0, accounts[0].Balance)
+
+	requests := server.RequestsTo(http.MethodGet, "/v1/accounts")
+	require.Len(t, requests, 1)
+	assert.Equal(t, "2", requests[0].Query.Get("page"))
+	assert.Equal(t, "Bearer test-token", requests[0].Header.Get("Authorization"))
 }
 
 // TestErrorHandling tests how the client handles API errors
 func TestErrorHandling(t *testing.T) {
-	// Create a mock server that returns an error response
-	mockResp := `{
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/accounts/1", http.StatusNotFound, `{
 		"message": "Resource not found",
 		"errors": {
 			"id": ["Invalid ID provided"]
 		}
-	}`
-
-	server := mockServer(t, http.StatusNotFound, mockResp)
-	defer server.Close()
+	}`)
 
 	// Create a client pointing to the mock server
 	client, err := NewFireflyClient(server.URL, "test-token")
 	require.NoError(t, err)
 
-	// Call a method that should fail
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	// TODO: Implement actual API call when available
-	// For now, test basic client creation and error handling structure
-	t.Log("Error handling test placeholder - API method implementation pending")
+	_, err = client.GetAccount(ctx, "1")
+	require.Error(t, err)
+	assert.Equal(t, errbuilder.CodeNotFound, errbuilder.CodeOf(err))
+	assert.ErrorIs(t, err, ErrNotFoundSentinel)
 
-	// Verify client was created successfully
-	assert.NotNil(t, client)
-	assert.NotNil(t, ctx) // Verify context was created
+	var httpErr *HTTPError
+	require.True(t, errors.As(err, &httpErr))
+	assert.Contains(t, httpErr.Body, "Invalid ID provided")
 }
 
 // Test suite methods
@@ -327,3 +341,2823 @@ func (suite *FireflyClientTestSuite) TestClientImporters() {
 	suite.T().Log("Importers test placeholder")
 	suite.Assert().NotNil(suite.client.importers)
 }
+
+// TestCreateAccount tests that asset accounts are created with a default role
+func TestCreateAccount(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.HandleFunc(http.MethodPost, "/v1/accounts", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || (body["type"] == "asset" && body["account_role"] == nil) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"message": "The given data was invalid.", "errors": {"account_role": ["The account role field is required."]}}`))
+			return
+		}
+		w.Write([]byte(`{"data": {"id": "5", "type": "accounts", "attributes": {"name": "Checking", "type": "asset"}}}`))
+	})
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, client.CreateAccount(ctx, "Checking", "asset", "EUR"))
+	require.NoError(t, client.CreateAccount(ctx, "Corner Shop", "expense", "EUR"))
+
+	requests := server.RequestsTo(http.MethodPost, "/v1/accounts")
+	require.Len(t, requests, 2)
+	var asset, expense map[string]interface{}
+	require.NoError(t, requests[0].JSON(&asset))
+	require.NoError(t, requests[1].JSON(&expense))
+	assert.Equal(t, "defaultAsset", asset["account_role"])
+	assert.Nil(t, expense["account_role"])
+
+	errs := validateAccount(AccountModel{Name: "Checking", Type: "asset", Currency: "EUR"})
+	assert.Contains(t, errs.Error(), "Account role is required")
+	errs = validateAccount(AccountModel{Name: "Checking", Type: "asset", Currency: "EUR", Role: "piggyAsset"})
+	assert.Contains(t, errs.Error(), "Invalid account role")
+}
+
+// TestCreateAccountFull tests that the created account is returned with its ID
+func TestCreateAccountFull(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodPost, "/v1/accounts", http.StatusOK, `{"data": {"id": "12", "type": "accounts", "attributes": {
+		"name": "Savings", "type": "asset", "account_role": "savingAsset", "currency_code": "EUR",
+		"iban": "NL02ABNA0123456789", "account_number": "0123456789", "current_balance": "0.00",
+		"active": true, "include_net_worth": true
+	}}}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	account, err := client.CreateAccountFull(ctx, AccountModel{
+		Name:     "Savings",
+		Type:     "asset",
+		Currency: "EUR",
+		Role:     "savingAsset",
+		IBAN:     "NL02ABNA0123456789",
+		Number:   "0123456789",
+		Active:   true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, AccountModel{
+		ID:              "12",
+		Name:            "Savings",
+		Type:            "asset",
+		Currency:        "EUR",
+		IBAN:            "NL02ABNA0123456789",
+		Number:          "0123456789",
+		Active:          true,
+		Role:            "savingAsset",
+		IncludeNetWorth: true,
+	}, *account)
+
+	requests := server.RequestsTo(http.MethodPost, "/v1/accounts")
+	require.Len(t, requests, 1)
+	var body map[string]interface{}
+	require.NoError(t, requests[0].JSON(&body))
+	assert.Equal(t, "savingAsset", body["account_role"])
+	assert.Equal(t, "NL02ABNA0123456789", body["iban"])
+	assert.Equal(t, "0123456789", body["account_number"])
+	assert.Equal(t, true, body["active"])
+	assert.Equal(t, false, body["include_net_worth"], "the flag is sent even when false")
+
+	// CreateAccount creates active accounts that count towards the net worth
+	require.NoError(t, client.CreateAccount(ctx, "Corner Shop", "expense", "EUR"))
+	requests = server.RequestsTo(http.MethodPost, "/v1/accounts")
+	require.Len(t, requests, 2)
+	require.NoError(t, requests[1].JSON(&body))
+	assert.Equal(t, true, body["active"])
+	assert.Equal(t, true, body["include_net_worth"])
+
+	// Invalid accounts are not sent
+	_, err = client.CreateAccountFull(ctx, AccountModel{Type: "expense", Currency: "EUR"})
+	require.Error(t, err)
+	assert.Len(t, server.RequestsTo(http.MethodPost, "/v1/accounts"), 2)
+}
+
+// TestAutoRetry tests that mutating requests are retried with their body on 503 when AutoRetry is enabled
+func TestAutoRetry(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	failures := 2
+	server.HandleFunc(http.MethodPost, "/v1/accounts", func(w http.ResponseWriter, r *http.Request) {
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"message": "Service unavailable"}`))
+			return
+		}
+		w.Write([]byte(`{"data": {"id": "5", "type": "accounts", "attributes": {"name": "Corner Shop", "type": "expense"}}}`))
+	})
+	server.Handle(http.MethodGet, "/v1/accounts/5", http.StatusServiceUnavailable, `{"message": "Service unavailable"}`)
+
+	config := DefaultClientConfig().WithRetry(3, time.Millisecond).WithAutoRetry()
+	config.BaseURL = server.URL
+	config.Token = "test-token"
+	client, err := NewFireflyClientWithConfig(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, client.CreateAccount(ctx, "Corner Shop", "expense", "EUR"))
+	requests := server.RequestsTo(http.MethodPost, "/v1/accounts")
+	require.Len(t, requests, 3)
+	for _, req := range requests {
+		var body map[string]interface{}
+		require.NoError(t, req.JSON(&body), "the body is replayed on every attempt")
+		assert.Equal(t, "Corner Shop", body["name"])
+	}
+
+	// Reads are not retried
+	_, err = client.GetAccount(ctx, "5")
+	require.Error(t, err)
+	assert.Len(t, server.RequestsTo(http.MethodGet, "/v1/accounts/5"), 1)
+
+	t.Run("gives up after the retry count", func(t *testing.T) {
+		server.Reset()
+		failures = 10
+		err := client.CreateAccount(ctx, "Corner Shop", "expense", "EUR")
+		require.Error(t, err)
+		assert.Len(t, server.RequestsTo(http.MethodPost, "/v1/accounts"), 4)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		server.Reset()
+		failures = 1
+		client, err := NewFireflyClient(server.URL, "test-token")
+		require.NoError(t, err)
+		require.Error(t, client.CreateAccount(ctx, "Corner Shop", "expense", "EUR"))
+		assert.Len(t, server.RequestsTo(http.MethodPost, "/v1/accounts"), 1)
+	})
+}
+
+// TestRetryOn tests that a custom retry predicate replaces the default classification
+func TestRetryOn(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	var conflicts atomic.Int32
+	lockedHandler := func(w http.ResponseWriter, r *http.Request) {
+		if conflicts.Add(-1) >= 0 {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"message": "The ledger is locked, try again."}`))
+			return
+		}
+		w.Write([]byte(`{"data": {"id": "5", "type": "accounts", "attributes": {"name": "Corner Shop", "type": "expense"}}}`))
+	}
+	server.HandleFunc(http.MethodPost, "/v1/accounts", lockedHandler)
+	server.HandleFunc(http.MethodGet, "/v1/accounts/5", lockedHandler)
+	ctx := context.Background()
+
+	// Retries a 409 only when its body says the ledger is locked
+	retryLocked := func(resp *http.Response, err error) bool {
+		if resp == nil || resp.StatusCode != http.StatusConflict {
+			return false
+		}
+		body, err := io.ReadAll(resp.Body)
+		return err == nil && strings.Contains(string(body), "locked")
+	}
+
+	t.Run("default does not retry 409", func(t *testing.T) {
+		server.Reset()
+		conflicts.Store(1)
+		config := DefaultClientConfig().WithRetry(3, time.Millisecond).WithAutoRetry()
+		config.BaseURL = server.URL
+		config.Token = "test-token"
+		client, err := NewFireflyClientWithConfig(config)
+		require.NoError(t, err)
+
+		err = client.CreateAccount(ctx, "Corner Shop", "expense", "EUR")
+		require.Error(t, err)
+		assert.Len(t, server.RequestsTo(http.MethodPost, "/v1/accounts"), 1)
+	})
+
+	t.Run("AutoRetry", func(t *testing.T) {
+		server.Reset()
+		conflicts.Store(2)
+		config := DefaultClientConfig().WithRetry(3, time.Millisecond).WithAutoRetry().WithRetryOn(retryLocked)
+		config.BaseURL = server.URL
+		config.Token = "test-token"
+		client, err := NewFireflyClientWithConfig(config)
+		require.NoError(t, err)
+
+		require.NoError(t, client.CreateAccount(ctx, "Corner Shop", "expense", "EUR"))
+		assert.Len(t, server.RequestsTo(http.MethodPost, "/v1/accounts"), 3)
+	})
+
+	t.Run("RetryMiddleware", func(t *testing.T) {
+		server.Reset()
+		conflicts.Store(10)
+		client, err := NewFireflyClient(server.URL, "test-token")
+		require.NoError(t, err)
+		client.AddMiddleware(NewRetryMiddleware(&RetryConfig{
+			MaxRetries:    2,
+			InitialDelay:  time.Millisecond,
+			MaxDelay:      time.Millisecond,
+			BackoffFactor: 2.0,
+			ShouldRetry:   retryLocked,
+		}))
+
+		// The body read by the predicate still reaches the error
+		_, err = client.GetAccount(ctx, "5")
+		var httpErr *HTTPError
+		require.True(t, errors.As(err, &httpErr))
+		assert.Contains(t, httpErr.Body, "locked")
+		assert.Len(t, server.RequestsTo(http.MethodGet, "/v1/accounts/5"), 3)
+	})
+
+	t.Run("RetryOperation", func(t *testing.T) {
+		config := DefaultClientConfig().WithRetry(3, time.Millisecond)
+		config.RetryOn = func(resp *http.Response, err error) bool {
+			return resp == nil && strings.Contains(err.Error(), "locked")
+		}
+		config.BaseURL = server.URL
+		config.Token = "test-token"
+		client, err := NewFireflyClientWithConfig(config)
+		require.NoError(t, err)
+
+		attempts := 0
+		err = client.RetryOperation(ctx, func(ctx context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return errors.New("ledger locked")
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+	})
+}
+
+// TestRetryOnBody tests that the retry predicate only buffers a bounded part of error responses
+func TestRetryOnBody(t *testing.T) {
+	var calls int
+	var seen []byte
+	config := &RetryConfig{ShouldRetry: func(resp *http.Response, err error) bool {
+		calls++
+		seen, err = io.ReadAll(resp.Body)
+		return err == nil
+	}}
+
+	// Successful responses are not retried and their body is not read
+	body := io.NopCloser(strings.NewReader("streamed"))
+	resp := &http.Response{StatusCode: http.StatusOK, Body: body}
+	assert.False(t, config.retryOn(resp, nil))
+	assert.Zero(t, calls)
+	assert.Equal(t, body, resp.Body)
+
+	// Large error bodies are shown to the predicate up to the limit and kept whole for the caller
+	large := strings.Repeat("x", 2*maxErrorBodySize)
+	resp = &http.Response{StatusCode: http.StatusInternalServerError, Body: io.NopCloser(strings.NewReader(large))}
+	assert.True(t, config.retryOn(resp, nil))
+	assert.Len(t, seen, maxErrorBodySize+1)
+	rest, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, large, string(rest))
+
+	// A body that fails while being read is not passed on as complete
+	errBroken := errors.New("connection reset")
+	resp = &http.Response{StatusCode: http.StatusBadGateway, Body: io.NopCloser(io.MultiReader(strings.NewReader("partial"), failedReader{errBroken}))}
+	assert.False(t, config.retryOn(resp, nil))
+	assert.Equal(t, "partial", string(seen))
+	rest, err = io.ReadAll(resp.Body)
+	assert.ErrorIs(t, err, errBroken)
+	assert.Equal(t, "partial", string(rest))
+}
+
+// TestRequestCoalescing tests that concurrent identical GETs share one request to the server
+func TestRequestCoalescing(t *testing.T) {
+	const callers = 50
+
+	var started sync.WaitGroup
+	started.Add(callers)
+	var upstream atomic.Int32
+	server := fireflytest.NewServer(t)
+	server.HandleFunc(http.MethodGet, "/v1/accounts/1", func(w http.ResponseWriter, r *http.Request) {
+		upstream.Add(1)
+		// Hold the response until every caller is waiting for it
+		started.Wait()
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(`{"data": {"id": "1", "type": "accounts", "attributes": {"name": "Checking", "type": "asset", "current_balance": "100.00"}}}`))
+	})
+
+	config := DefaultClientConfig().WithRequestCoalescing()
+	config.BaseURL = server.URL
+	config.Token = "test-token"
+	client, err := NewFireflyClientWithConfig(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	accounts := make([]*AccountModel, callers)
+	errs := make([]error, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			started.Done()
+			accounts[i], errs[i] = client.GetAccount(ctx, "1")
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), upstream.Load())
+	for i := range accounts {
+		require.NoError(t, errs[i])
+		assert.Equal(t, "Checking", accounts[i].Name)
+		assert.Equal(t, 100.0, accounts[i].Balance)
+	}
+
+	// Requests that are not in flight at the same time are sent again
+	_, err = client.GetAccount(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), upstream.Load())
+}
+
+// TestRequestCoalescingCancel tests that cancelling the caller that started a shared request
+// does not fail the other callers waiting for it
+func TestRequestCoalescingCancel(t *testing.T) {
+	release := make(chan struct{})
+	var upstream atomic.Int32
+	server := fireflytest.NewServer(t)
+	server.HandleFunc(http.MethodGet, "/v1/accounts/1", func(w http.ResponseWriter, r *http.Request) {
+		upstream.Add(1)
+		<-release
+		w.Write([]byte(`{"data": {"id": "1", "type": "accounts", "attributes": {"name": "Checking", "type": "asset"}}}`))
+	})
+
+	config := DefaultClientConfig().WithRequestCoalescing()
+	config.BaseURL = server.URL
+	config.Token = "test-token"
+	client, err := NewFireflyClientWithConfig(config)
+	require.NoError(t, err)
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leader := make(chan error, 1)
+	go func() {
+		_, err := client.GetAccount(leaderCtx, "1")
+		leader <- err
+	}()
+	require.Eventually(t, func() bool { return upstream.Load() == 1 }, 5*time.Second, time.Millisecond)
+
+	type result struct {
+		account *AccountModel
+		err     error
+	}
+	follower := make(chan result, 1)
+	go func() {
+		account, err := client.GetAccount(context.Background(), "1")
+		follower <- result{account, err}
+	}()
+	// Give the follower time to join the request in flight
+	time.Sleep(50 * time.Millisecond)
+
+	cancelLeader()
+	assert.ErrorIs(t, <-leader, context.Canceled)
+
+	close(release)
+	res := <-follower
+	require.NoError(t, res.err)
+	assert.Equal(t, "Checking", res.account.Name)
+	assert.Equal(t, int32(1), upstream.Load())
+}
+
+// TestImportTransactionsAutoCreatesAccounts tests that a missing expense account is created once per batch
+func TestImportTransactionsAutoCreatesAccounts(t *testing.T) {
+	var accountCreates, transactionStores int
+	var storedDestination string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/v1/search/accounts"):
+			w.Write([]byte(`{"data": [], "meta": {}}`))
+		case strings.HasSuffix(r.URL.Path, "/v1/accounts") && r.Method == http.MethodPost:
+			accountCreates++
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "Corner Shop", body["name"])
+			assert.Equal(t, "expense", body["type"])
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"data": {"id": "10", "type": "accounts", "attributes": {"name": "Corner Shop", "type": "expense"}}}`))
+		case strings.HasSuffix(r.URL.Path, "/v1/transactions") && r.Method == http.MethodPost:
+			transactionStores++
+			var body StoreTransactionJSONRequestBody
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			storedDestination = stringValue(body.Transactions[0].DestinationName)
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"data": {"id": "1", "type": "transactions", "attributes": {"transactions": []}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultClientConfig()
+	config.BaseURL = server.URL
+	config.Token = "test-token"
+	config.AutoCreateAccounts = true
+
+	client, err := NewFireflyClientWithConfig(config)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	tx := TransactionModel{
+		Currency:        "USD",
+		Amount:          12.50,
+		TransType:       "withdrawal",
+		Description:     "Groceries",
+		Date:            time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		SourceID:        "1",
+		DestinationName: "Corner Shop",
+	}
+
+	require.NoError(t, client.ImportTransaction(ctx, tx))
+	require.NoError(t, client.ImportTransactions(ctx, []TransactionModel{tx, tx}))
+
+	assert.Equal(t, 1, accountCreates)
+	assert.Equal(t, 2, transactionStores)
+	assert.Equal(t, "Corner Shop", storedDestination)
+}
+
+// TestEnsureAccountConcurrent tests that a slow account creation only blocks callers for the same account
+func TestEnsureAccountConcurrent(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	creates := make(map[string]int)
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/search/accounts", http.StatusOK, `{"data": [], "meta": {}}`)
+	server.HandleFunc(http.MethodPost, "/v1/accounts", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		name := body["name"].(string)
+		mu.Lock()
+		creates[name]++
+		mu.Unlock()
+		if name == "Slow Shop" {
+			<-release
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(fmt.Sprintf(`{"data": {"id": "10", "type": "accounts", "attributes": {"name": %q, "type": "expense"}}}`, name)))
+	})
+	createsOf := func(name string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return creates[name]
+	}
+
+	config := DefaultClientConfig()
+	config.BaseURL = server.URL
+	config.Token = "test-token"
+	config.AutoCreateAccounts = true
+	client, err := NewFireflyClientWithConfig(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	slow := make(chan error, 2)
+	go func() { slow <- client.ensureAccount(ctx, "Slow Shop", "expense", "USD") }()
+	require.Eventually(t, func() bool { return createsOf("Slow Shop") == 1 }, 5*time.Second, time.Millisecond)
+	go func() { slow <- client.ensureAccount(ctx, "slow shop", "expense", "USD") }()
+
+	// Other accounts are created while the slow one is in flight
+	tx := TransactionModel{TransType: "Withdrawal ", SourceID: "1", DestinationName: "Corner Shop", Currency: "USD"}
+	require.NoError(t, client.ensureTransactionAccounts(ctx, tx))
+	assert.Equal(t, 1, createsOf("Corner Shop"))
+
+	// A caller waiting for the same account gives up with its own context
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, client.ensureAccount(waitCtx, "Slow Shop", "expense", "USD"), context.DeadlineExceeded)
+
+	close(release)
+	require.NoError(t, <-slow)
+	require.NoError(t, <-slow)
+	assert.Equal(t, 1, createsOf("Slow Shop"))
+}
+
+// TestImportTransactionWithoutAutoCreate tests that accounts are not created unless enabled
+func TestImportTransactionWithoutAutoCreate(t *testing.T) {
+	var accountRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "accounts") {
+			accountRequests++
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"id": "1", "type": "transactions", "attributes": {"transactions": []}}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	err = client.ImportTransaction(context.Background(), TransactionModel{
+		Currency:        "USD",
+		Amount:          1,
+		TransType:       "withdrawal",
+		Description:     "Coffee",
+		Date:            time.Now(),
+		DestinationName: "Cafe",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, accountRequests)
+}
+
+// TestCreateForeignTransfer tests a USD to EUR transfer sets both amounts
+func TestCreateForeignTransfer(t *testing.T) {
+	var stored TransactionSplitStore
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body StoreTransactionJSONRequestBody
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Len(t, body.Transactions, 1)
+		stored = body.Transactions[0]
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"id": "1", "type": "transactions", "attributes": {"transactions": []}}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	err = client.CreateForeignTransfer(context.Background(), "1", "2", 100, "USD", 92.35, "EUR", "Move savings", date)
+	require.NoError(t, err)
+
+	assert.Equal(t, Transfer, stored.Type)
+	assert.Equal(t, "100.00", stored.Amount)
+	assert.Equal(t, "USD", stringValue(stored.CurrencyCode))
+	assert.Equal(t, "92.35", stringValue(stored.ForeignAmount))
+	assert.Equal(t, "EUR", stringValue(stored.ForeignCurrencyCode))
+	assert.Equal(t, "1", stringValue(stored.SourceId))
+	assert.Equal(t, "2", stringValue(stored.DestinationId))
+}
+
+// TestCreateForeignTransferSameCurrency tests that matching currencies are rejected
+func TestCreateForeignTransferSameCurrency(t *testing.T) {
+	client, err := NewFireflyClient("http://localhost:8080", "test-token")
+	require.NoError(t, err)
+
+	err = client.CreateForeignTransfer(context.Background(), "1", "2", 100, "USD", 100, "usd", "Move savings", time.Now())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "foreign_currency")
+}
+
+// TestTransactionKindValidation tests that transaction types are validated and normalized
+func TestTransactionKindValidation(t *testing.T) {
+	for input, expected := range map[string]TransactionKind{
+		"withdrawal":      TransactionKindWithdrawal,
+		"Deposit":         TransactionKindDeposit,
+		" TRANSFER ":      TransactionKindTransfer,
+		"opening_balance": TransactionKindOpeningBalance,
+		"Opening Balance": TransactionKindOpeningBalance,
+		"reconciliation":  TransactionKindReconciliation,
+	} {
+		kind, ok := ParseTransactionKind(input)
+		assert.True(t, ok, input)
+		assert.Equal(t, expected, kind)
+	}
+
+	var stored TransactionSplitStore
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body StoreTransactionJSONRequestBody
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		stored = body.Transactions[0]
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"id": "1", "type": "transactions", "attributes": {"transactions": []}}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	tx := TransactionModel{
+		Currency:    "USD",
+		Amount:      12.5,
+		TransType:   "Withdrawal",
+		Description: "Coffee",
+		Date:        time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, client.ImportTransaction(context.Background(), tx))
+	assert.Equal(t, Withdrawal, stored.Type)
+
+	tx.TransType = "withdrawl"
+	err = client.ImportTransaction(context.Background(), tx)
+	require.Error(t, err)
+	assert.Equal(t, errbuilder.CodeInvalidArgument, errbuilder.CodeOf(err))
+	assert.Contains(t, err.Error(), "Invalid transaction type")
+}
+
+// TestImportTransactionCurrencyID tests that a currency ID is sent instead of the code
+func TestImportTransactionCurrencyID(t *testing.T) {
+	var stored TransactionSplitStore
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body StoreTransactionJSONRequestBody
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		stored = body.Transactions[0]
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"id": "1", "type": "transactions", "attributes": {"transactions": []}}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	tx := TransactionModel{
+		CurrencyID:  "12",
+		Amount:      12.5,
+		TransType:   "withdrawal",
+		Description: "Coffee",
+		Date:        time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, client.ImportTransaction(context.Background(), tx))
+	assert.Equal(t, "12", stringValue(stored.CurrencyId))
+	assert.Nil(t, stored.CurrencyCode)
+
+	// The ID wins when both are set
+	tx.Currency = "USD"
+	require.NoError(t, client.ImportTransaction(context.Background(), tx))
+	assert.Equal(t, "12", stringValue(stored.CurrencyId))
+	assert.Nil(t, stored.CurrencyCode)
+
+	// The code is used without an ID
+	tx.CurrencyID = ""
+	require.NoError(t, client.ImportTransaction(context.Background(), tx))
+	assert.Nil(t, stored.CurrencyId)
+	assert.Equal(t, "USD", stringValue(stored.CurrencyCode))
+}
+
+// TestImportTransactionsWithOptions tests that imported transactions carry the import tag and external reference
+func TestImportTransactionsWithOptions(t *testing.T) {
+	var stored []TransactionSplitStore
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body StoreTransactionJSONRequestBody
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		stored = body.Transactions
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"id": "1", "type": "transactions", "attributes": {"transactions": []}}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	transactions := []TransactionModel{
+		{
+			Currency:    "EUR",
+			Amount:      12.5,
+			TransType:   "withdrawal",
+			Description: "Coffee",
+			Date:        time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			Tags:        []string{"food"},
+		},
+		{
+			Currency:    "EUR",
+			Amount:      1200,
+			TransType:   "deposit",
+			Description: "Salary",
+			Date:        time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			ExternalID:  "bank-tx-42",
+		},
+	}
+	options := &ImportOptions{
+		ImportTag:   "import:bank-csv",
+		ExternalID:  "batch-2024-03",
+		ExternalURL: "https://bank.example.com/statements/2024-03",
+	}
+	require.NoError(t, client.ImportTransactionsWithOptions(context.Background(), transactions, options))
+
+	require.Len(t, stored, 2)
+	require.NotNil(t, stored[0].Tags)
+	assert.Equal(t, []string{"food", "import:bank-csv"}, *stored[0].Tags)
+	assert.Equal(t, "batch-2024-03", stringValue(stored[0].ExternalId))
+	assert.Equal(t, "https://bank.example.com/statements/2024-03", stringValue(stored[0].ExternalUrl))
+
+	require.NotNil(t, stored[1].Tags)
+	assert.Equal(t, []string{"import:bank-csv"}, *stored[1].Tags)
+	assert.Equal(t, "bank-tx-42", stringValue(stored[1].ExternalId), "the transaction's own external ID is kept")
+
+	// The caller's transactions are left untouched
+	assert.Equal(t, []string{"food"}, transactions[0].Tags)
+	assert.Empty(t, transactions[1].Tags)
+
+	// Without options no tags are sent
+	require.NoError(t, client.ImportTransactions(context.Background(), transactions[1:]))
+	require.Len(t, stored, 1)
+	assert.Nil(t, stored[0].Tags)
+}
+
+// TestImportTransactionsInconsistentGroup tests that a batch whose splits disagree on the currency is
+// rejected before it is sent, naming the offending split
+func TestImportTransactionsInconsistentGroup(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodPost, "/v1/transactions", http.StatusOK, `{"data": {"id": "1", "type": "transactions", "attributes": {"transactions": []}}}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	split := func(description, currency string) TransactionModel {
+		return TransactionModel{
+			Currency:    currency,
+			Amount:      10,
+			TransType:   "withdrawal",
+			Description: description,
+			Date:        time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		}
+	}
+
+	err = client.ImportTransactions(ctx, []TransactionModel{split("Train", "EUR"), split("Lunch", "CHF"), split("Museum", "eur")})
+	require.Error(t, err)
+	assert.Equal(t, errbuilder.CodeInvalidArgument, errbuilder.CodeOf(err))
+	assert.Contains(t, err.Error(), "splits[1].currency")
+	assert.Contains(t, err.Error(), "CHF differs from EUR")
+	assert.NotContains(t, err.Error(), "splits[2]", "currency codes are compared case-insensitively")
+
+	// Invalid splits are named by their index as well
+	missing := split("", "EUR")
+	err = client.ImportTransactions(ctx, []TransactionModel{split("Train", "EUR"), missing})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "splits[1].description")
+
+	// Currency IDs are compared when no codes are set
+	byID := func(id string) TransactionModel {
+		tx := split("Train", "")
+		tx.CurrencyID = id
+		return tx
+	}
+	err = client.ImportTransactions(ctx, []TransactionModel{byID("1"), byID("2")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "splits[1].currency")
+
+	_, err = client.CreateTransactionGroup(ctx, TransactionGroupModel{Title: "Trip", Splits: []TransactionModel{split("Train", "EUR"), split("Lunch", "CHF")}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "splits[1].currency")
+	assert.Empty(t, server.RequestsTo(http.MethodPost, "/v1/transactions"))
+
+	// Amounts in another currency are foreign amounts of a split in the group currency
+	lunch := split("Lunch", "EUR")
+	lunch.ForeignAmount = float64Ptr(9.5)
+	lunch.ForeignCurrency = stringPtr("CHF")
+	require.NoError(t, client.ImportTransactions(ctx, []TransactionModel{split("Train", "EUR"), lunch}))
+	assert.Len(t, server.RequestsTo(http.MethodPost, "/v1/transactions"), 1)
+}
+
+// TestCreateTransactionGroup tests that created splits are returned with their journal IDs
+func TestCreateTransactionGroup(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodPost, "/v1/transactions", http.StatusOK, `{"data": {"id": "700", "type": "transactions", "attributes": {
+		"created_at": "2024-03-05T18:01:12+01:00",
+		"group_title": "Hardware store",
+		"transactions": [
+			{"transaction_journal_id": "801", "type": "withdrawal", "date": "2024-03-05T00:00:00+01:00", "amount": "19.99", "description": "Paint", "currency_code": "EUR"},
+			{"transaction_journal_id": "802", "type": "withdrawal", "date": "2024-03-05T00:00:00+01:00", "amount": "5.00", "description": "Brushes", "currency_code": "EUR"}
+		]
+	}}}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	split := func(description string, amount float64) TransactionModel {
+		return TransactionModel{
+			Currency:        "EUR",
+			Amount:          amount,
+			TransType:       "withdrawal",
+			Description:     description,
+			Date:            time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC),
+			SourceID:        "1",
+			DestinationName: "Praxis",
+		}
+	}
+
+	group, err := client.CreateTransactionGroup(ctx, TransactionGroupModel{
+		Title:  "Hardware store",
+		Splits: []TransactionModel{split("Paint", 19.99), split("Brushes", 5)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "700", group.ID)
+	require.Len(t, group.Splits, 2)
+	assert.Equal(t, "801", group.Splits[0].JournalID)
+	assert.Equal(t, "Paint", group.Splits[0].Description)
+	assert.Equal(t, "802", group.Splits[1].JournalID)
+	assert.Equal(t, "Brushes", group.Splits[1].Description)
+
+	requests := server.RequestsTo(http.MethodPost, "/v1/transactions")
+	require.Len(t, requests, 1)
+	var store TransactionStore
+	require.NoError(t, requests[0].JSON(&store))
+	assert.Equal(t, "Hardware store", stringValue(store.GroupTitle))
+	assert.Len(t, store.Transactions, 2)
+
+	// A single transaction is returned with the journal ID of its split
+	tx, err := client.CreateTransaction(ctx, split("Paint", 19.99))
+	require.NoError(t, err)
+	assert.Equal(t, "700", tx.ID)
+	assert.Equal(t, "801", tx.JournalID)
+
+	// Split groups need a title
+	server.Reset()
+	_, err = client.CreateTransactionGroup(ctx, TransactionGroupModel{Splits: []TransactionModel{split("Paint", 19.99), split("Brushes", 5)}})
+	assert.Error(t, err)
+	assert.Empty(t, server.Requests())
+}
+
+// TestEnsureCategory tests returning existing categories and creating missing ones
+func TestEnsureCategory(t *testing.T) {
+	// categoryServer serves a category list and creates categories on POST.
+	// With conflict set, creates fail with 409 after another writer stored the category.
+	categoryServer := func(t *testing.T, existing []string, conflict bool) (*httptest.Server, *int32, *int32) {
+		var mu sync.Mutex
+		var lists, creates int32
+		names := append([]string(nil), existing...)
+		category := func(id int, name string) map[string]interface{} {
+			return map[string]interface{}{
+				"id":         fmt.Sprintf("%d", id),
+				"type":       "categories",
+				"attributes": map[string]interface{}{"name": name},
+			}
+		}
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+
+			if r.Method == http.MethodPost {
+				atomic.AddInt32(&creates, 1)
+				var body StoreCategoryJSONRequestBody
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				names = append(names, body.Name)
+				if conflict {
+					w.WriteHeader(http.StatusConflict)
+					return
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{"data": category(len(names), body.Name)})
+				return
+			}
+
+			atomic.AddInt32(&lists, 1)
+			data := make([]interface{}, 0, len(names))
+			for i, name := range names {
+				data = append(data, category(i+1, name))
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": data, "meta": map[string]interface{}{}})
+		}))
+		return server, &lists, &creates
+	}
+	ctx := context.Background()
+
+	t.Run("existing", func(t *testing.T) {
+		server, _, creates := categoryServer(t, []string{"Groceries"}, false)
+		defer server.Close()
+		client, err := NewFireflyClient(server.URL, "test-token")
+		require.NoError(t, err)
+
+		category, err := client.EnsureCategory(ctx, "groceries", "")
+		require.NoError(t, err)
+		assert.Equal(t, "1", category.ID)
+		assert.Equal(t, "Groceries", category.Name)
+		assert.Equal(t, int32(0), atomic.LoadInt32(creates))
+	})
+
+	t.Run("new", func(t *testing.T) {
+		server, lists, creates := categoryServer(t, []string{"Groceries"}, false)
+		defer server.Close()
+		client, err := NewFireflyClient(server.URL, "test-token")
+		require.NoError(t, err)
+
+		category, err := client.EnsureCategory(ctx, "Travel", "Trips")
+		require.NoError(t, err)
+		assert.Equal(t, "2", category.ID)
+		assert.Equal(t, "Travel", category.Name)
+
+		// Cached afterwards
+		again, err := client.EnsureCategory(ctx, "travel", "")
+		require.NoError(t, err)
+		assert.Equal(t, category.ID, again.ID)
+		assert.Equal(t, int32(1), atomic.LoadInt32(creates))
+		assert.Equal(t, int32(1), atomic.LoadInt32(lists))
+	})
+
+	t.Run("concurrent create", func(t *testing.T) {
+		server, _, creates := categoryServer(t, nil, false)
+		defer server.Close()
+		client, err := NewFireflyClient(server.URL, "test-token")
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		ids := make([]string, 5)
+		for i := range ids {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				category, err := client.EnsureCategory(ctx, "Travel", "")
+				require.NoError(t, err)
+				ids[i] = category.ID
+			}(i)
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(creates))
+		for _, id := range ids {
+			assert.Equal(t, "1", id)
+		}
+	})
+
+	t.Run("created elsewhere", func(t *testing.T) {
+		server, _, creates := categoryServer(t, nil, true)
+		defer server.Close()
+		client, err := NewFireflyClient(server.URL, "test-token")
+		require.NoError(t, err)
+
+		category, err := client.EnsureCategory(ctx, "Travel", "")
+		require.NoError(t, err)
+		assert.Equal(t, "1", category.ID)
+		assert.Equal(t, int32(1), atomic.LoadInt32(creates))
+	})
+}
+
+// headerMiddleware sets a request header, to check that middleware is applied
+type headerMiddleware struct{ name, value string }
+
+func (m headerMiddleware) ProcessRequest(ctx context.Context, req *http.Request) (*http.Request, error) {
+	req.Header.Set(m.name, m.value)
+	return req, nil
+}
+
+func (m headerMiddleware) ProcessResponse(ctx context.Context, resp *http.Response) (*http.Response, error) {
+	return resp, nil
+}
+
+// TestDo tests calling endpoints the client does not wrap
+func TestDo(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodPost, "/v1/custom/reports", http.StatusOK, `{"data": {"id": "7", "status": "queued"}}`)
+	server.Handle(http.MethodGet, "/v1/custom/missing", http.StatusNotFound, `{"message": "Resource not found"}`)
+
+	config := DefaultClientConfig()
+	config.UserAgent = "budget-app/1.0"
+	config.BaseURL = server.URL + "/"
+	config.Token = "test-token"
+	client, err := NewFireflyClientWithConfig(config)
+	require.NoError(t, err)
+	client.AddMiddleware(headerMiddleware{name: "X-Trace", value: "abc"})
+	ctx := context.Background()
+
+	var out struct {
+		Data struct {
+			ID     string `json:"id"`
+			Status string `json:"status"`
+		} `json:"data"`
+	}
+	resp, err := client.Do(ctx, http.MethodPost, "/v1/custom/reports?period=month", map[string]string{"name": "Monthly"}, &out)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "7", out.Data.ID)
+	assert.Equal(t, "queued", out.Data.Status)
+	raw, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"data": {"id": "7", "status": "queued"}}`, string(raw))
+
+	requests := server.RequestsTo(http.MethodPost, "/v1/custom/reports")
+	require.Len(t, requests, 1)
+	assert.Equal(t, "Bearer test-token", requests[0].Header.Get("Authorization"))
+	assert.Equal(t, "budget-app/1.0", requests[0].Header.Get("User-Agent"))
+	assert.Equal(t, "abc", requests[0].Header.Get("X-Trace"))
+	assert.Equal(t, "application/json", requests[0].Header.Get("Content-Type"))
+	assert.Equal(t, "month", requests[0].Query.Get("period"))
+	var body map[string]string
+	require.NoError(t, requests[0].JSON(&body))
+	assert.Equal(t, "Monthly", body["name"])
+
+	resp, err = client.Do(ctx, http.MethodGet, "v1/custom/missing", nil, &out)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrNotFoundSentinel)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	_, err = client.Do(ctx, http.MethodPost, "/v1/custom/reports", make(chan int), nil)
+	assert.Error(t, err)
+	assert.Len(t, server.RequestsTo(http.MethodPost, "/v1/custom/reports"), 1)
+}
+
+// countingMiddleware counts the requests and responses it sees
+type countingMiddleware struct {
+	requests  atomic.Int32
+	responses atomic.Int32
+}
+
+func (m *countingMiddleware) ProcessRequest(ctx context.Context, req *http.Request) (*http.Request, error) {
+	m.requests.Add(1)
+	return req, nil
+}
+
+func (m *countingMiddleware) ProcessResponse(ctx context.Context, resp *http.Response) (*http.Response, error) {
+	m.responses.Add(1)
+	return resp, nil
+}
+
+// TestMiddlewareRunsOnAPICalls tests that middleware runs on the requests of wrapped methods
+func TestMiddlewareRunsOnAPICalls(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/accounts", http.StatusOK, `{"data": [{"id": "1", "type": "accounts", "attributes": {"name": "Checking", "type": "asset"}}], "meta": {}}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	counter := &countingMiddleware{}
+	client.AddMiddleware(counter)
+	client.AddMiddleware(headerMiddleware{name: "X-Trace", value: "abc"})
+
+	accounts, err := client.ListAccounts(context.Background(), 1, 50)
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+
+	assert.Equal(t, int32(1), counter.requests.Load())
+	assert.Equal(t, int32(1), counter.responses.Load())
+	requests := server.RequestsTo(http.MethodGet, "/v1/accounts")
+	require.Len(t, requests, 1)
+	assert.Equal(t, "abc", requests[0].Header.Get("X-Trace"))
+
+	// Middleware errors fail the request before it is sent
+	client.AddMiddleware(NewRateLimitMiddleware(rate.NewLimiter(rate.Limit(1), 1)))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.ListAccounts(ctx, 1, 50)
+	assert.Error(t, err)
+	assert.Len(t, server.RequestsTo(http.MethodGet, "/v1/accounts"), 1)
+}
+
+// TestRetryMiddlewareRetriesRequests tests that RetryMiddleware sends requests again on retryable
+// status codes and reports the last response once the retries run out
+func TestRetryMiddlewareRetriesRequests(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	var calls atomic.Int32
+	server.HandleFunc(http.MethodGet, "/v1/tags/holiday", func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"data": {"id": "4", "type": "tags", "attributes": {"tag": "holiday"}}}`)
+	})
+	server.Handle(http.MethodGet, "/v1/accounts/1", http.StatusBadGateway, `{"message": "Bad gateway"}`)
+	server.Handle(http.MethodPost, "/v1/tags", http.StatusInternalServerError, `{"message": "Server error"}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	client.AddMiddleware(NewRetryMiddleware(&RetryConfig{
+		MaxRetries:    3,
+		InitialDelay:  time.Millisecond,
+		MaxDelay:      time.Millisecond,
+		BackoffFactor: 2.0,
+	}))
+	counter := &countingMiddleware{}
+	client.AddMiddleware(counter)
+	ctx := context.Background()
+
+	resp, err := client.Do(ctx, http.MethodGet, "/v1/tags/holiday", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), counter.requests.Load())
+	assert.Len(t, server.RequestsTo(http.MethodGet, "/v1/tags/holiday"), 3)
+
+	// The server may have stored a create before failing, so it is not sent again
+	_, err = client.Do(ctx, http.MethodPost, "/v1/tags", map[string]string{"tag": "holiday"}, nil)
+	require.Error(t, err)
+	assert.Len(t, server.RequestsTo(http.MethodPost, "/v1/tags"), 1)
+
+	_, err = client.GetAccount(ctx, "1")
+	require.Error(t, err)
+	var httpErr *HTTPError
+	require.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusBadGateway, httpErr.StatusCode)
+	assert.Len(t, server.RequestsTo(http.MethodGet, "/v1/accounts/1"), 4)
+
+	t.Run("auto retry", func(t *testing.T) {
+		server.Reset()
+		config := DefaultClientConfig().WithAutoRetry()
+		config.BaseURL = server.URL
+		config.Token = "test-token"
+		config.RetryCount = 2
+		config.RetryDelay = time.Millisecond
+		client, err := NewFireflyClientWithConfig(config)
+		require.NoError(t, err)
+		client.EnableDefaultMiddleware()
+
+		// Mutating requests are retried by AutoRetry alone, not once more per middleware retry
+		_, err = client.Do(ctx, http.MethodPost, "/v1/tags", map[string]string{"tag": "holiday"}, nil)
+		require.Error(t, err)
+		requests := server.RequestsTo(http.MethodPost, "/v1/tags")
+		require.Len(t, requests, 3)
+		for _, request := range requests {
+			assert.JSONEq(t, `{"tag": "holiday"}`, string(request.Body), "retries replay the body")
+		}
+	})
+}
+
+// TestWithToken tests that a token in the request context overrides the configured authentication
+func TestWithToken(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/accounts/1", http.StatusOK, `{"data": {"id": "1", "type": "accounts", "attributes": {"name": "Checking", "type": "asset"}}}`)
+
+	client, err := NewFireflyClient(server.URL, "default-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = client.GetAccount(WithToken(ctx, "tenant-token"), "1")
+	require.NoError(t, err)
+	_, err = client.GetAccount(ctx, "1")
+	require.NoError(t, err)
+	_, err = client.Do(WithToken(ctx, "admin-token"), http.MethodGet, "/v1/accounts/1", nil, nil)
+	require.NoError(t, err)
+	_, err = client.GetAccount(WithToken(ctx, ""), "1")
+	require.NoError(t, err)
+
+	var authHeaders []string
+	for _, req := range server.RequestsTo(http.MethodGet, "/v1/accounts/1") {
+		authHeaders = append(authHeaders, req.Header.Get("Authorization"))
+	}
+	assert.Equal(t, []string{"Bearer tenant-token", "Bearer default-token", "Bearer admin-token", "Bearer default-token"}, authHeaders)
+
+	t.Run("oauth2", func(t *testing.T) {
+		var tokenRequests int
+		tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tokenRequests++
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"access_token": "oauth-token", "token_type": "Bearer", "expires_in": 3600}`))
+		}))
+		defer tokenServer.Close()
+
+		server := fireflytest.NewServer(t)
+		server.Handle(http.MethodGet, "/v1/accounts/1", http.StatusUnauthorized, `{"message": "Unauthenticated."}`)
+
+		config := DefaultClientConfig().WithOAuth2(OAuth2Config{ClientID: "test-client", TokenURL: tokenServer.URL})
+		config.BaseURL = server.URL
+		client, err := NewFireflyClientWithConfig(config)
+		require.NoError(t, err)
+
+		// A rejected context token is not replaced by the client's OAuth2 token
+		_, err = client.GetAccount(WithToken(ctx, "expired-token"), "1")
+		require.Error(t, err)
+		requests := server.RequestsTo(http.MethodGet, "/v1/accounts/1")
+		require.Len(t, requests, 1)
+		assert.Equal(t, "Bearer expired-token", requests[0].Header.Get("Authorization"))
+		assert.Zero(t, tokenRequests)
+	})
+}
+
+// TestWithTokenCaches tests that cached lookups of one context token are not served to another
+func TestWithTokenCaches(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/about", http.StatusOK, aboutJSON("6.2.8"))
+	server.HandleFunc(http.MethodGet, "/v1/categories", func(w http.ResponseWriter, r *http.Request) {
+		data := `[]`
+		if r.Header.Get("Authorization") == "Bearer tenant-a" {
+			data = `[{"id": "1", "type": "categories", "attributes": {"name": "Groceries"}}]`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": ` + data + `, "meta": {}}`))
+	})
+	server.Handle(http.MethodPost, "/v1/categories", http.StatusOK, `{"data": {"id": "2", "type": "categories", "attributes": {"name": "Groceries"}}}`)
+	server.HandleFunc(http.MethodGet, "/v1/currencies/native", func(w http.ResponseWriter, r *http.Request) {
+		code := "EUR"
+		if r.Header.Get("Authorization") == "Bearer tenant-b" {
+			code = "USD"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"id": "1", "type": "currencies", "attributes": {"code": "` + code + `", "name": "` + code + `", "symbol": "$", "decimal_places": 2}}}`))
+	})
+
+	client, err := NewFireflyClient(server.URL, "default-token")
+	require.NoError(t, err)
+	tenantA := WithToken(context.Background(), "tenant-a")
+	tenantB := WithToken(context.Background(), "tenant-b")
+
+	category, err := client.EnsureCategory(tenantA, "Groceries", "")
+	require.NoError(t, err)
+	assert.Equal(t, "1", category.ID)
+
+	// Tenant B does not have the category yet, so it is created instead of taken from tenant A
+	category, err = client.EnsureCategory(tenantB, "Groceries", "")
+	require.NoError(t, err)
+	assert.Equal(t, "2", category.ID)
+	stores := server.RequestsTo(http.MethodPost, "/v1/categories")
+	require.Len(t, stores, 1)
+	assert.Equal(t, "Bearer tenant-b", stores[0].Header.Get("Authorization"))
+
+	// Each token keeps its own cache
+	category, err = client.EnsureCategory(tenantA, "Groceries", "")
+	require.NoError(t, err)
+	assert.Equal(t, "1", category.ID)
+	assert.Len(t, server.RequestsTo(http.MethodGet, "/v1/categories"), 2)
+
+	currency, err := client.GetDefaultCurrency(tenantA)
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", currency.Code)
+	currency, err = client.GetDefaultCurrency(tenantB)
+	require.NoError(t, err)
+	assert.Equal(t, "USD", currency.Code)
+	currency, err = client.GetDefaultCurrency(tenantA)
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", currency.Code)
+	assert.Len(t, server.RequestsTo(http.MethodGet, "/v1/currencies/native"), 2)
+
+	// Currencies seen with a context token do not change the metadata of the configured credentials
+	_, ok := client.CurrencyInfo("USD")
+	assert.False(t, ok)
+}
+
+// TestResponseInterceptor tests that raw response bodies are handed to the interceptor
+func TestResponseInterceptor(t *testing.T) {
+	mockResp := `{"data": [{"id": "1", "type": "accounts", "attributes": {"name": "Checking", "type": "asset", "currency_code": "USD"}}], "meta": {}}`
+	server := mockServer(t, http.StatusOK, mockResp)
+	defer server.Close()
+
+	var ops []string
+	var statuses []int
+	var bodies [][]byte
+	config := DefaultClientConfig().WithResponseInterceptor(func(op string, status int, body []byte) {
+		ops = append(ops, op)
+		statuses = append(statuses, status)
+		bodies = append(bodies, body)
+	})
+	config.BaseURL = server.URL
+	config.Token = "test-token"
+
+	client, err := NewFireflyClientWithConfig(config)
+	require.NoError(t, err)
+
+	accounts, err := client.ListAccounts(context.Background(), 1, 10)
+	require.NoError(t, err)
+
+	// The body is still decoded after being intercepted
+	require.Len(t, accounts, 1)
+	assert.Equal(t, "Checking", accounts[0].Name)
+
+	require.Len(t, bodies, 1)
+	assert.Equal(t, "GET /v1/accounts", ops[0])
+	assert.Equal(t, http.StatusOK, statuses[0])
+	assert.JSONEq(t, mockResp, string(bodies[0]))
+}
+
+// TestListTransactionsFilteredSort tests that the sort and filter query params are sent
+func TestListTransactionsFilteredSort(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": [], "meta": {}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	_, err = client.ListTransactionsFiltered(context.Background(), TransactionFilter{
+		Page:  1,
+		Limit: 25,
+		Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Type:  "withdrawal",
+		Sort:  &Sort{Field: SortByDate, Direction: SortDescending},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "-date", query.Get("sort"))
+	assert.Equal(t, "2024-01-01", query.Get("start"))
+	assert.Equal(t, "withdrawal", query.Get("type"))
+	assert.Equal(t, "25", query.Get("limit"))
+	assert.False(t, query.Has("end"))
+}
+
+// TestListAccountsFilteredSort tests ascending sort and that no sort param is sent by default
+func TestListAccountsFilteredSort(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": [], "meta": {}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = client.ListAccountsFiltered(ctx, AccountFilter{
+		Page:  1,
+		Limit: 10,
+		Type:  "asset",
+		Sort:  &Sort{Field: SortByName, Direction: SortAscending},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "name", query.Get("sort"))
+	assert.Equal(t, "asset", query.Get("type"))
+
+	_, err = client.ListAccounts(ctx, 1, 10)
+	require.NoError(t, err)
+	assert.False(t, query.Has("sort"))
+}
+
+// TestListAccountsByBalanceThreshold tests finding asset accounts below a balance in one currency
+func TestListAccountsByBalanceThreshold(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/accounts", http.StatusOK, `{"data": [`+strings.Join([]string{
+		accountJSON("1", "asset", "49.99", "EUR", true),
+		accountJSON("2", "asset", "50.00", "EUR", true),
+		accountJSON("3", "asset", "-120.00", "EUR", true),
+		accountJSON("4", "asset", "10.00", "USD", true),
+		accountJSON("5", "asset", "1000.00", "EUR", true),
+	}, ", ")+`], "meta": {}}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	accounts, err := client.ListAccountsByBalanceThreshold(ctx, 50, "eur")
+	require.NoError(t, err)
+	var ids []string
+	for _, account := range accounts {
+		ids = append(ids, account.ID)
+	}
+	assert.Equal(t, []string{"1", "3"}, ids)
+
+	requests := server.RequestsTo(http.MethodGet, "/v1/accounts")
+	require.Len(t, requests, 1)
+	assert.Equal(t, "asset", requests[0].Query.Get("type"))
+
+	accounts, err = client.ListAccountsByBalanceThreshold(ctx, 0, "GBP")
+	require.NoError(t, err)
+	assert.Empty(t, accounts)
+
+	_, err = client.ListAccountsByBalanceThreshold(ctx, 50, "")
+	assert.Error(t, err)
+}
+
+// TestListResponseDecoding tests that streamed list responses tell empty, malformed and failed responses apart
+func TestListResponseDecoding(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/transactions", http.StatusOK, "")
+	server.Handle(http.MethodGet, "/v1/accounts", http.StatusOK, `{"data": [{"id": "1", "type": "accounts"`)
+	server.Handle(http.MethodGet, "/v1/categories", http.StatusInternalServerError, `{"message": "Server error"}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	transactions, err := client.ListTransactions(ctx, 1, 50)
+	require.NoError(t, err)
+	assert.NotNil(t, transactions)
+	assert.Empty(t, transactions)
+
+	_, err = client.ListAccounts(ctx, 1, 50)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Failed to parse accounts response")
+
+	_, err = client.ListCategories(ctx, 1, 50)
+	require.Error(t, err)
+	var httpErr *HTTPError
+	require.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusInternalServerError, httpErr.StatusCode)
+	assert.Contains(t, httpErr.Body, "Server error")
+}
+
+// TestAccountIncludeNetWorth tests that the net worth flag round-trips between Firefly III and AccountModel
+func TestAccountIncludeNetWorth(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/accounts/1", http.StatusOK, `{"data": {"type": "accounts", "id": "1",
+		"attributes": {"name": "Savings", "type": "asset", "current_balance": "10.00", "include_net_worth": false}}}`)
+	server.Handle(http.MethodGet, "/v1/accounts/2", http.StatusOK, `{"data": {"type": "accounts", "id": "2",
+		"attributes": {"name": "Checking", "type": "asset", "current_balance": "10.00", "include_net_worth": true}}}`)
+	server.Handle(http.MethodPut, "/v1/accounts/1", http.StatusOK, `{"data": {"type": "accounts", "id": "1",
+		"attributes": {"name": "Savings", "type": "asset", "include_net_worth": true}}}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	savings, err := client.GetAccount(ctx, "1")
+	require.NoError(t, err)
+	assert.False(t, savings.IncludeNetWorth)
+
+	checking, err := client.GetAccount(ctx, "2")
+	require.NoError(t, err)
+	assert.True(t, checking.IncludeNetWorth)
+
+	require.NoError(t, client.SetAccountIncludeNetWorth(ctx, "1", !savings.IncludeNetWorth))
+
+	requests := server.RequestsTo(http.MethodPut, "/v1/accounts/1")
+	require.Len(t, requests, 1)
+	var body map[string]interface{}
+	require.NoError(t, requests[0].JSON(&body))
+	assert.Equal(t, map[string]interface{}{"include_net_worth": true}, body)
+
+	err = client.SetAccountIncludeNetWorth(ctx, "99", true)
+	assert.ErrorIs(t, err, ErrNotFoundSentinel)
+}
+
+// TestListTransactionsSkipsBadAmounts tests that a transaction with a malformed amount is skipped with a warning
+func TestListTransactionsSkipsBadAmounts(t *testing.T) {
+	row := func(id, amount string) string {
+		return fmt.Sprintf(`{"type": "transactions", "id": %q, "attributes": {"transactions": [{"type": "withdrawal", "date": "2024-01-15T00:00:00+00:00", "amount": %q, "description": "Row %s"}]}}`, id, amount, id)
+	}
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/transactions", http.StatusOK,
+		`{"data": [`+strings.Join([]string{row("1", "10.00"), row("2", ""), row("3", "12,5O"), row("4", "7.25")}, ", ")+`], "meta": {}}`)
+
+	var warnings []string
+	config := DefaultClientConfig().WithLogger(func(format string, args ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	})
+	config.BaseURL = server.URL
+	config.Token = "test-token"
+	client, err := NewFireflyClientWithConfig(config)
+	require.NoError(t, err)
+
+	transactions, err := client.ListTransactions(context.Background(), 1, 10)
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, "1", transactions[0].ID)
+	assert.Equal(t, 10.0, transactions[0].Amount)
+	assert.Equal(t, "4", transactions[1].ID)
+	assert.Equal(t, 7.25, transactions[1].Amount)
+
+	require.Len(t, warnings, 2)
+	assert.Contains(t, warnings[0], "Skipping transaction 2")
+	assert.Contains(t, warnings[1], "Skipping transaction 3")
+
+	// Without a logger the rows are skipped silently
+	client, err = NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	transactions, err = client.ListTransactions(context.Background(), 1, 10)
+	require.NoError(t, err)
+	assert.Len(t, transactions, 2)
+}
+
+// TestListPageSize tests that list methods default and clamp the page size
+func TestListPageSize(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/transactions", http.StatusOK, `{"data": [], "meta": {}}`)
+	server.Handle(http.MethodGet, "/v1/budgets", http.StatusOK, `{"data": [], "meta": {}}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = client.ListTransactions(ctx, 0, 0)
+	require.NoError(t, err)
+	_, err = client.ListTransactions(ctx, 3, MaxPageSize+100)
+	require.NoError(t, err)
+	_, err = client.ListBudgets(ctx, 2, 10)
+	require.NoError(t, err)
+
+	requests := server.Requests()
+	require.Len(t, requests, 3)
+	assert.Equal(t, "1", requests[0].Query.Get("page"))
+	assert.Equal(t, strconv.Itoa(DefaultPageSize), requests[0].Query.Get("limit"))
+	assert.Equal(t, "3", requests[1].Query.Get("page"))
+	assert.Equal(t, strconv.Itoa(MaxPageSize), requests[1].Query.Get("limit"))
+	assert.Equal(t, "2", requests[2].Query.Get("page"))
+	assert.Equal(t, "10", requests[2].Query.Get("limit"))
+}
+
+// TestListTransactionsUpdatedSince tests that incremental syncs only return transactions changed after the given time
+func TestListTransactionsUpdatedSince(t *testing.T) {
+	transaction := func(id, createdAt, updatedAt string) string {
+		return `{"id": "` + id + `", "type": "transactions", "attributes": {"created_at": "` + createdAt + `", "updated_at": "` + updatedAt + `",
+			"transactions": [{"type": "withdrawal", "date": "2024-03-01T00:00:00+00:00", "amount": "10.00", "description": "Transaction ` + id + `"}]}}`
+	}
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/transactions", http.StatusOK, `{"data": [`+
+		transaction("1", "2024-03-01T08:00:00+00:00", "2024-03-01T08:00:00+00:00")+`,`+
+		transaction("2", "2024-03-01T08:00:00+00:00", "2024-03-05T12:30:00+00:00")+`,`+
+		transaction("3", "2024-03-04T09:00:00+00:00", "2024-03-04T09:00:00+00:00")+`,`+
+		transaction("4", "2024-03-02T10:00:00+00:00", "2024-03-03T00:00:00+00:00")+
+		`], "meta": {}}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	since := time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)
+	changes, err := client.ListTransactionsUpdatedSince(ctx, since, 1, 10)
+	require.NoError(t, err)
+
+	ids := make([]string, 0, len(changes.Transactions))
+	for _, tx := range changes.Transactions {
+		ids = append(ids, tx.ID)
+	}
+	assert.Equal(t, []string{"2", "3"}, ids, "changes at exactly since were seen by the previous sync")
+	assert.True(t, changes.NextSince.Equal(time.Date(2024, 3, 5, 12, 30, 0, 0, time.UTC)))
+	assert.False(t, changes.HasMore)
+
+	// A sync from the returned timestamp finds nothing new
+	changes, err = client.ListTransactionsUpdatedSince(ctx, changes.NextSince, 1, 10)
+	require.NoError(t, err)
+	assert.Empty(t, changes.Transactions)
+	assert.True(t, changes.NextSince.Equal(time.Date(2024, 3, 5, 12, 30, 0, 0, time.UTC)))
+
+	// A full page means the next page has to be read as well
+	changes, err = client.ListTransactionsUpdatedSince(ctx, since, 1, 4)
+	require.NoError(t, err)
+	assert.True(t, changes.HasMore)
+}
+
+// TestListTransactionIDs tests that transaction IDs and update times are listed from all pages in the range
+func TestListTransactionIDs(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.HandleFunc(http.MethodGet, "/v1/transactions", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		count := MaxPageSize
+		if page == 2 {
+			count = 2
+		}
+		data := make([]map[string]interface{}, 0, count)
+		for i := range count {
+			id := (page-1)*MaxPageSize + i + 1
+			attributes := map[string]interface{}{
+				"created_at": "2024-03-01T08:00:00+00:00",
+				"transactions": []map[string]interface{}{{
+					"type": "withdrawal", "date": "2024-03-01T00:00:00+00:00", "amount": "10.00", "description": "Groceries",
+				}},
+			}
+			if id%2 == 0 {
+				attributes["updated_at"] = "2024-03-05T12:30:00+00:00"
+			}
+			data = append(data, map[string]interface{}{"id": strconv.Itoa(id), "type": "transactions", "attributes": attributes})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data, "meta": map[string]interface{}{}})
+	})
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	refs, err := client.ListTransactionIDs(context.Background(), start, end)
+	require.NoError(t, err)
+
+	require.Len(t, refs, MaxPageSize+2)
+	assert.Equal(t, "1", refs[0].ID)
+	assert.True(t, refs[0].UpdatedAt.Equal(time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)), "never updated falls back to created_at")
+	assert.Equal(t, "2", refs[1].ID)
+	assert.True(t, refs[1].UpdatedAt.Equal(time.Date(2024, 3, 5, 12, 30, 0, 0, time.UTC)))
+	assert.Equal(t, strconv.Itoa(MaxPageSize+2), refs[len(refs)-1].ID)
+
+	requests := server.RequestsTo(http.MethodGet, "/v1/transactions")
+	require.Len(t, requests, 2)
+	for _, req := range requests {
+		assert.Equal(t, "2024-03-01", req.Query.Get("start"))
+		assert.Equal(t, "2024-03-31", req.Query.Get("end"))
+	}
+}
+
+// pagedHandler serves three pages of two items each with Firefly III's pagination meta.
+// item builds the item with the given ID.
+func pagedHandler(item func(id int) map[string]interface{}) http.HandlerFunc {
+	const totalPages, perPage = 3, 2
+	return func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		data := []map[string]interface{}{}
+		for i := range perPage {
+			data = append(data, item((page-1)*perPage+i+1))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": data,
+			"meta": map[string]interface{}{"pagination": map[string]int{
+				"total": totalPages * perPage, "count": perPage, "per_page": perPage,
+				"current_page": page, "total_pages": totalPages,
+			}},
+		})
+	}
+}
+
+// pagedTransaction builds a transaction for pagedHandler
+func pagedTransaction(id int) map[string]interface{} {
+	return map[string]interface{}{"id": strconv.Itoa(id), "type": "transactions", "attributes": map[string]interface{}{
+		"transactions": []map[string]interface{}{{
+			"type": "withdrawal", "date": "2024-03-01T00:00:00+00:00", "amount": "10.00", "description": fmt.Sprintf("Purchase %d", id),
+		}},
+	}}
+}
+
+// TestListAllTransactions tests following the pagination meta through all pages
+func TestListAllTransactions(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.HandleFunc(http.MethodGet, "/v1/transactions", pagedHandler(pagedTransaction))
+	server.HandleFunc(http.MethodGet, "/v1/accounts", pagedHandler(func(id int) map[string]interface{} {
+		return map[string]interface{}{"id": strconv.Itoa(id), "type": "accounts", "attributes": map[string]interface{}{
+			"name": fmt.Sprintf("Account %d", id), "type": "expense",
+		}}
+	}))
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Pages are not full, so only the pagination meta tells that more follow
+	transactions, err := client.ListAllTransactions(ctx)
+	require.NoError(t, err)
+	ids := make([]string, 0, len(transactions))
+	for _, tx := range transactions {
+		ids = append(ids, tx.ID)
+	}
+	assert.Equal(t, []string{"1", "2", "3", "4", "5", "6"}, ids)
+	assert.Equal(t, "Purchase 6", transactions[5].Description)
+
+	requests := server.RequestsTo(http.MethodGet, "/v1/transactions")
+	require.Len(t, requests, 3)
+	for i, req := range requests {
+		assert.Equal(t, strconv.Itoa(i+1), req.Query.Get("page"))
+	}
+
+	accounts, err := client.ListAllAccounts(ctx)
+	require.NoError(t, err)
+	require.Len(t, accounts, 6)
+	assert.Equal(t, "Account 1", accounts[0].Name)
+	assert.Equal(t, "Account 6", accounts[5].Name)
+	assert.Len(t, server.RequestsTo(http.MethodGet, "/v1/accounts"), 3)
+}
+
+// TestIterateTransactions tests streaming transactions page by page and stopping early
+func TestIterateTransactions(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.HandleFunc(http.MethodGet, "/v1/transactions", pagedHandler(pagedTransaction))
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	var seen []string
+	err = client.IterateTransactions(context.Background(), func(tx TransactionModel) error {
+		seen = append(seen, tx.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3", "4", "5", "6"}, seen)
+
+	t.Run("callback error", func(t *testing.T) {
+		server.Reset()
+		errStop := errors.New("stop")
+		seen = nil
+		err := client.IterateTransactions(context.Background(), func(tx TransactionModel) error {
+			seen = append(seen, tx.ID)
+			if tx.ID == "3" {
+				return errStop
+			}
+			return nil
+		})
+		assert.ErrorIs(t, err, errStop)
+		assert.Equal(t, []string{"1", "2", "3"}, seen)
+		assert.Len(t, server.RequestsTo(http.MethodGet, "/v1/transactions"), 2)
+	})
+
+	t.Run("canceled between pages", func(t *testing.T) {
+		server.Reset()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		seen = nil
+		err := client.IterateTransactions(ctx, func(tx TransactionModel) error {
+			seen = append(seen, tx.ID)
+			cancel()
+			return nil
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, []string{"1", "2"}, seen, "the page being handled is finished")
+		assert.Len(t, server.RequestsTo(http.MethodGet, "/v1/transactions"), 1)
+	})
+}
+
+// TestGetCategoryByNameAllPages tests that category lookups page through all categories
+func TestGetCategoryByNameAllPages(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.HandleFunc(http.MethodGet, "/v1/categories", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		count := MaxPageSize
+		if page == 2 {
+			count = 1
+		}
+		data := make([]map[string]interface{}, 0, count)
+		for i := range count {
+			id := (page-1)*MaxPageSize + i + 1
+			data = append(data, map[string]interface{}{
+				"id":         strconv.Itoa(id),
+				"type":       "categories",
+				"attributes": map[string]interface{}{"name": fmt.Sprintf("Category %d", id)},
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data, "meta": map[string]interface{}{}})
+	})
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	category, err := client.GetCategoryByName(context.Background(), fmt.Sprintf("category %d", MaxPageSize+1))
+	require.NoError(t, err)
+	assert.Equal(t, strconv.Itoa(MaxPageSize+1), category.ID)
+
+	requests := server.RequestsTo(http.MethodGet, "/v1/categories")
+	require.Len(t, requests, 2)
+	assert.Equal(t, strconv.Itoa(MaxPageSize), requests[1].Query.Get("limit"))
+}
+
+// TestListBudgetsForPeriod tests that the period is sent and spent reflects it
+func TestListBudgetsForPeriod(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		spent := "[]"
+		if query.Get("start") == "2024-02-01" && query.Get("end") == "2024-02-29" {
+			spent = `[{"sum": "-123.45", "currency_code": "EUR"}]`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": [{"id": "1", "type": "budgets", "attributes": {"name": "Groceries", "spent": ` + spent + `}}], "meta": {}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	start := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)
+	budgets, err := client.ListBudgetsForPeriod(context.Background(), start, end, 1, 50)
+	require.NoError(t, err)
+	require.Len(t, budgets, 1)
+	require.NotNil(t, budgets[0].Spent)
+	require.Len(t, *budgets[0].Spent, 1)
+	assert.Equal(t, "-123.45", stringValue((*budgets[0].Spent)[0].Sum))
+	assert.Equal(t, "EUR", stringValue((*budgets[0].Spent)[0].CurrencyCode))
+
+	// Without a period the spent array is empty
+	budgets, err = client.ListBudgets(context.Background(), 1, 50)
+	require.NoError(t, err)
+	require.Len(t, budgets, 1)
+	assert.Empty(t, *budgets[0].Spent)
+
+	// An inverted period is rejected
+	_, err = client.ListBudgetsForPeriod(context.Background(), end, start, 1, 50)
+	assert.Error(t, err)
+}
+
+// TestListBudgetLimits tests that only the limits overlapping the requested period are returned
+func TestListBudgetLimits(t *testing.T) {
+	limit := func(id, start, end string) string {
+		return `{"id": "` + id + `", "type": "budget_limits", "attributes": {"budget_id": "1", "amount": "100.00", "start": "` + start + `", "end": "` + end + `"}}`
+	}
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/budgets/1/limits", http.StatusOK, `{"data": [`+
+		limit("1", "2024-02-01T00:00:00Z", "2024-02-29T23:59:59Z")+`, `+
+		limit("2", "2024-02-15T00:00:00Z", "2024-03-14T23:59:59Z")+`, `+
+		limit("3", "2024-03-01T00:00:00Z", "2024-03-31T23:59:59Z")+`, `+
+		limit("4", "2024-03-31T00:00:00Z", "2024-04-29T23:59:59Z")+`, `+
+		limit("5", "2024-04-01T00:00:00Z", "2024-04-30T23:59:59Z")+
+		`], "meta": {}}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	limits, err := client.ListBudgetLimits(ctx, "1", start, end)
+	require.NoError(t, err)
+
+	var ids []string
+	for _, limit := range limits {
+		ids = append(ids, limit.ID)
+	}
+	assert.Equal(t, []string{"2", "3", "4"}, ids, "limits ending before March or starting after it are excluded")
+
+	requests := server.RequestsTo(http.MethodGet, "/v1/budgets/1/limits")
+	require.Len(t, requests, 1)
+	assert.Equal(t, "2024-03-01", requests[0].Query.Get("start"))
+	assert.Equal(t, "2024-03-31", requests[0].Query.Get("end"))
+
+	// Without a period every limit of the budget is returned
+	limits, err = client.GetBudgetLimits(ctx, "1")
+	require.NoError(t, err)
+	assert.Len(t, limits, 5)
+	requests = server.RequestsTo(http.MethodGet, "/v1/budgets/1/limits")
+	require.Len(t, requests, 2)
+	assert.False(t, requests[1].Query.Has("start"))
+
+	// An inverted period is rejected without a request
+	_, err = client.ListBudgetLimits(ctx, "1", end, start)
+	assert.Error(t, err)
+	assert.Len(t, server.RequestsTo(http.MethodGet, "/v1/budgets/1/limits"), 2)
+}
+
+// TestListBudgetLimitsAllBudgets tests that limits of all budgets are listed without a period and
+// that DeleteBudgetLimit finds the budget of a limit that way
+func TestListBudgetLimitsAllBudgets(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/budget-limits", http.StatusOK, `{"data": [
+		{"id": "10", "type": "budget_limits", "attributes": {"budget_id": "1", "amount": "100.00", "start": "2024-03-01T00:00:00Z", "end": "2024-03-31T23:59:59Z"}},
+		{"id": "20", "type": "budget_limits", "attributes": {"budget_id": "2", "amount": "250.00", "start": "2024-04-01T00:00:00Z", "end": "2024-04-30T23:59:59Z"}}
+	], "meta": {}}`)
+	server.Handle(http.MethodDelete, "/v1/budgets/2/limits/20", http.StatusNoContent, ``)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	limits, err := client.GetBudgetLimits(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, limits, 2)
+
+	// The endpoint requires both dates, so open sides are sent as wide bounds
+	requests := server.RequestsTo(http.MethodGet, "/v1/budget-limits")
+	require.Len(t, requests, 1)
+	assert.Equal(t, "1900-01-01", requests[0].Query.Get("start"))
+	assert.Equal(t, "2999-12-31", requests[0].Query.Get("end"))
+
+	start := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	limits, err = client.ListBudgetLimits(ctx, "", start, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, limits, 1)
+	assert.Equal(t, "20", limits[0].ID)
+	requests = server.RequestsTo(http.MethodGet, "/v1/budget-limits")
+	require.Len(t, requests, 2)
+	assert.Equal(t, "2024-04-01", requests[1].Query.Get("start"))
+	assert.Equal(t, "2999-12-31", requests[1].Query.Get("end"))
+
+	require.NoError(t, client.DeleteBudgetLimit(ctx, "20"))
+	assert.Len(t, server.RequestsTo(http.MethodDelete, "/v1/budgets/2/limits/20"), 1)
+
+	assert.Error(t, client.DeleteBudgetLimit(ctx, "99"))
+}
+
+// TestGetAllBudgetLimits tests fetching the limits of several budgets in one request
+func TestGetAllBudgetLimits(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		assert.Equal(t, "/v1/budget-limits", r.URL.Path)
+		assert.Equal(t, "2024-03-01", r.URL.Query().Get("start"))
+		assert.Equal(t, "2024-03-31", r.URL.Query().Get("end"))
+
+		limit := func(id, budgetID, amount string) string {
+			return `{"id": "` + id + `", "type": "budget_limits", "attributes": {"budget_id": "` + budgetID + `", "amount": "` + amount + `", "start": "2024-03-01T00:00:00Z", "end": "2024-03-31T23:59:59Z"}}`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": [` +
+			limit("10", "1", "100.00") + `, ` +
+			limit("20", "2", "250.00") + `, ` +
+			limit("11", "1", "50.00") + `, ` +
+			limit("30", "3", "75.00") +
+			`], "meta": {}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	limits, err := client.GetAllBudgetLimits(context.Background(), start, end)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	require.Len(t, limits, 4)
+	var ids, budgetIDs []string
+	for _, limit := range limits {
+		ids = append(ids, limit.ID)
+		budgetIDs = append(budgetIDs, stringValue(limit.BudgetID))
+	}
+	assert.Equal(t, []string{"10", "11", "20", "30"}, ids)
+	assert.Equal(t, []string{"1", "1", "2", "3"}, budgetIDs)
+	assert.Equal(t, "250.00", limits[2].Amount)
+	assert.Equal(t, start, limits[0].Start)
+
+	// An inverted period is rejected without a request
+	_, err = client.GetAllBudgetLimits(context.Background(), end, start)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+// TestMoveTransaction tests that only the accounts change when moving a transaction
+func TestMoveTransaction(t *testing.T) {
+	split := map[string]interface{}{
+		"transaction_journal_id": "42",
+		"type":                   "withdrawal",
+		"date":                   "2024-01-15T00:00:00Z",
+		"amount":                 "25.00",
+		"description":            "Lunch",
+		"currency_code":          "USD",
+		"category_name":          "Food",
+		"source_id":              "1",
+		"destination_id":         "2",
+	}
+	var update map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			var body struct {
+				Transactions []map[string]interface{} `json:"transactions"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Len(t, body.Transactions, 1)
+			update = body.Transactions[0]
+			// Apply the partial update like Firefly III does
+			for key, value := range update {
+				split[key] = value
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"id":   "7",
+				"type": "transactions",
+				"attributes": map[string]interface{}{
+					"created_at":   "2024-01-15T10:00:00Z",
+					"transactions": []interface{}{split},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	before, err := client.GetTransaction(ctx, "7")
+	require.NoError(t, err)
+
+	require.NoError(t, client.MoveTransaction(ctx, "7", "", "3"))
+
+	// The existing values are sent back alongside the new destination
+	assert.Equal(t, "42", update["transaction_journal_id"])
+	assert.Equal(t, "3", update["destination_id"])
+	assert.Equal(t, "1", update["source_id"])
+	assert.Equal(t, "25.00", update["amount"])
+	assert.Equal(t, "Lunch", update["description"])
+	assert.Equal(t, "Food", update["category_name"])
+
+	after, err := client.GetTransaction(ctx, "7")
+	require.NoError(t, err)
+	assert.Equal(t, "3", after.DestinationID)
+
+	after.DestinationID = before.DestinationID
+	assert.Equal(t, before, after)
+
+	// At least one account is required
+	assert.Error(t, client.MoveTransaction(ctx, "7", "", ""))
+}
+
+// TestPatchTransaction tests that partial updates leave unspecified fields as stored
+func TestPatchTransaction(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/transactions/7", http.StatusOK, `{"data": {"id": "7", "type": "transactions", "attributes": {
+		"created_at": "2024-01-15T10:00:00Z",
+		"group_title": "Lunch and coffee",
+		"transactions": [
+			{"transaction_journal_id": "42", "type": "withdrawal", "date": "2024-01-15T00:00:00Z", "amount": "25.000000000000", "description": "Lunch",
+			 "currency_code": "USD", "category_name": "Food", "budget_id": "3", "notes": "With the team", "tags": ["work"], "source_id": "1", "destination_id": "2"},
+			{"transaction_journal_id": "43", "type": "withdrawal", "date": "2024-01-15T00:00:00Z", "amount": "4.50", "description": "Coffee",
+			 "currency_code": "USD", "source_id": "1", "destination_id": "2"}
+		]
+	}}}`)
+	server.Handle(http.MethodPut, "/v1/transactions/7", http.StatusOK, `{"data": {"id": "7", "type": "transactions", "attributes": {"transactions": []}}}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	sentSplits := func() []map[string]interface{} {
+		updates := server.RequestsTo(http.MethodPut, "/v1/transactions/7")
+		require.Len(t, updates, 1)
+		var body struct {
+			ApplyRules   *bool                    `json:"apply_rules"`
+			Transactions []map[string]interface{} `json:"transactions"`
+		}
+		require.NoError(t, updates[0].JSON(&body))
+		require.NotNil(t, body.ApplyRules)
+		assert.False(t, *body.ApplyRules, "rules are only applied when requested")
+		require.Len(t, body.Transactions, 2)
+		return body.Transactions
+	}
+
+	t.Run("description", func(t *testing.T) {
+		server.Reset()
+		require.NoError(t, client.PatchTransaction(ctx, "7", TransactionPatch{Description: stringPtr("Team lunch")}))
+
+		splits := sentSplits()
+		assert.Equal(t, "Team lunch", splits[0]["description"])
+		assert.Equal(t, "25.000000000000", splits[0]["amount"], "the amount is sent back as stored")
+		assert.Equal(t, "Food", splits[0]["category_name"])
+		assert.Equal(t, "3", splits[0]["budget_id"])
+		assert.Equal(t, "With the team", splits[0]["notes"])
+		assert.Equal(t, []interface{}{"work"}, splits[0]["tags"])
+		assert.Equal(t, "Coffee", splits[1]["description"])
+	})
+
+	t.Run("amount of one split", func(t *testing.T) {
+		server.Reset()
+		require.NoError(t, client.PatchTransaction(ctx, "7", TransactionPatch{JournalID: "43", Amount: float64Ptr(5)}))
+
+		splits := sentSplits()
+		assert.Equal(t, "25.000000000000", splits[0]["amount"])
+		assert.Equal(t, "Lunch", splits[0]["description"])
+		assert.Equal(t, "5.00", splits[1]["amount"])
+		assert.Equal(t, "Coffee", splits[1]["description"])
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		server.Reset()
+		assert.Error(t, client.PatchTransaction(ctx, "7", TransactionPatch{Description: stringPtr("")}))
+		assert.ErrorIs(t, client.PatchTransaction(ctx, "7", TransactionPatch{JournalID: "99", Description: stringPtr("Lunch")}), ErrNotFoundSentinel)
+		assert.Empty(t, server.RequestsTo(http.MethodPut, "/v1/transactions/7"))
+	})
+}
+
+// TestDeleteTransactionSplit tests removing one split of a transaction group
+func TestDeleteTransactionSplit(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/transactions/700", http.StatusOK, `{"data": {"id": "700", "type": "transactions", "attributes": {
+		"created_at": "2024-03-05T18:01:12+01:00",
+		"group_title": "Hardware store",
+		"transactions": [
+			{"transaction_journal_id": "801", "type": "withdrawal", "date": "2024-03-05T00:00:00+01:00", "amount": "19.99", "description": "Paint", "category_name": "Home", "source_id": "1", "destination_id": "21"},
+			{"transaction_journal_id": "802", "type": "withdrawal", "date": "2024-03-05T00:00:00+01:00", "amount": "5.00", "description": "Brushes", "source_id": "1", "destination_id": "21"}
+		]
+	}}}`)
+	server.Handle(http.MethodPut, "/v1/transactions/700", http.StatusOK, `{"data": {"id": "700", "type": "transactions", "attributes": {"transactions": []}}}`)
+	server.Handle(http.MethodGet, "/v1/transactions/701", http.StatusOK, `{"data": {"id": "701", "type": "transactions", "attributes": {
+		"transactions": [{"transaction_journal_id": "803", "type": "withdrawal", "date": "2024-03-05T00:00:00+01:00", "amount": "5.00", "description": "Single"}]
+	}}}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, client.DeleteTransactionSplit(ctx, "700", "802"))
+
+	updates := server.RequestsTo(http.MethodPut, "/v1/transactions/700")
+	require.Len(t, updates, 1)
+	var update TransactionUpdate
+	require.NoError(t, updates[0].JSON(&update))
+	require.NotNil(t, update.Transactions)
+	require.Len(t, *update.Transactions, 1, "only the remaining split is sent")
+	remaining := (*update.Transactions)[0]
+	assert.Equal(t, "801", stringValue(remaining.TransactionJournalId))
+	assert.Equal(t, "Paint", stringValue(remaining.Description))
+	assert.Equal(t, "19.99", stringValue(remaining.Amount))
+	assert.Equal(t, "Home", stringValue(remaining.CategoryName))
+
+	// Unknown splits and the last split of a group are rejected without an update
+	server.Reset()
+	err = client.DeleteTransactionSplit(ctx, "700", "999")
+	assert.ErrorIs(t, err, ErrNotFoundSentinel)
+	err = client.DeleteTransactionSplit(ctx, "701", "803")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "last split")
+	assert.Empty(t, server.RequestsTo(http.MethodPut, "/v1/transactions/700"))
+	assert.Empty(t, server.RequestsTo(http.MethodPut, "/v1/transactions/701"))
+}
+
+// TestGetTransactionWithoutCreatedAt tests that responses without timestamps convert without panicking
+func TestGetTransactionWithoutCreatedAt(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/transactions/1", http.StatusOK, `{"data": {"id": "1", "type": "transactions", "attributes": {
+		"transactions": [{"type": "withdrawal", "date": "2024-01-15T00:00:00+00:00", "amount": "10.00", "description": "Coffee"}]
+	}}}`)
+	server.Handle(http.MethodGet, "/v1/transactions/2", http.StatusOK, `{"data": {"id": "2", "type": "transactions", "attributes": {"transactions": []}}}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	var tx *TransactionModel
+	require.NotPanics(t, func() { tx, err = client.GetTransaction(ctx, "1") })
+	require.NoError(t, err)
+	assert.Equal(t, "Coffee", tx.Description)
+	assert.True(t, tx.Date.Equal(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, tx.CreatedAt.IsZero())
+	assert.True(t, tx.UpdatedAt.IsZero())
+
+	require.NotPanics(t, func() { tx, err = client.GetTransaction(ctx, "2") })
+	require.NoError(t, err)
+	assert.True(t, tx.Date.IsZero())
+}
+
+// TestTransactionPaymentDate tests that the payment date round-trips through read, update and import
+func TestTransactionPaymentDate(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/transactions/1", http.StatusOK, `{"data": {"id": "1", "type": "transactions", "attributes": {
+		"transactions": [{"type": "withdrawal", "date": "2024-01-15T00:00:00+00:00", "amount": "10.00", "description": "Invoice",
+			"currency_code": "EUR", "payment_date": "2024-01-20T00:00:00+00:00", "due_date": "2024-01-31T00:00:00+00:00"}]
+	}}}`)
+	server.Handle(http.MethodPut, "/v1/transactions/1", http.StatusOK, `{"data": {"id": "1", "type": "transactions", "attributes": {"transactions": []}}}`)
+	server.Handle(http.MethodPost, "/v1/transactions", http.StatusOK, `{"data": {"id": "2", "type": "transactions", "attributes": {"transactions": []}}}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	tx, err := client.GetTransaction(ctx, "1")
+	require.NoError(t, err)
+	require.NotNil(t, tx.PaymentDate)
+	assert.True(t, tx.PaymentDate.Equal(time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)))
+	require.NotNil(t, tx.DueDate)
+	assert.True(t, tx.DueDate.Equal(time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)))
+	assert.Nil(t, tx.ProcessDate)
+	assert.Nil(t, tx.BookDate)
+
+	require.NoError(t, client.UpdateTransaction(ctx, "1", *tx))
+
+	updates := server.RequestsTo(http.MethodPut, "/v1/transactions/1")
+	require.Len(t, updates, 1)
+	var update UpdateTransactionJSONRequestBody
+	require.NoError(t, updates[0].JSON(&update))
+	require.Len(t, *update.Transactions, 1)
+	require.NotNil(t, (*update.Transactions)[0].PaymentDate)
+	assert.True(t, (*update.Transactions)[0].PaymentDate.Equal(*tx.PaymentDate))
+	assert.Nil(t, (*update.Transactions)[0].ProcessDate)
+
+	tx.SourceName = "Checking"
+	tx.DestinationName = "Utility"
+	require.NoError(t, client.ImportTransaction(ctx, *tx))
+
+	stores := server.RequestsTo(http.MethodPost, "/v1/transactions")
+	require.Len(t, stores, 1)
+	var store StoreTransactionJSONRequestBody
+	require.NoError(t, stores[0].JSON(&store))
+	require.Len(t, store.Transactions, 1)
+	require.NotNil(t, store.Transactions[0].PaymentDate)
+	assert.True(t, store.Transactions[0].PaymentDate.Equal(*tx.PaymentDate))
+	require.NotNil(t, store.Transactions[0].DueDate)
+	assert.True(t, store.Transactions[0].DueDate.Equal(*tx.DueDate))
+}
+
+// TestTransactionNotesMarkdown tests that markdown notes round-trip through read, update and import unchanged
+func TestTransactionNotesMarkdown(t *testing.T) {
+	const notes = "# Receipt *#42*\n\n" +
+		"- **Total**: 10 < 20 & \"quoted\" 'single'\n" +
+		"- `code_span` with \\backslash\\ and \\*escaped\\*\n" +
+		"- [invoice](https://shop.example.com/?a=1&b=<2>)\n\n" +
+		"> Line with trailing spaces  \n" +
+		"> Tab\there, HTML <br/> &amp; entity, emoji 🧾 and ümlauts\n"
+	encoded, err := json.Marshal(notes)
+	require.NoError(t, err)
+
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/transactions/1", http.StatusOK, `{"data": {"id": "1", "type": "transactions", "attributes": {
+		"transactions": [{"type": "withdrawal", "date": "2024-01-15T00:00:00+00:00", "amount": "10.00", "description": "Shop",
+			"currency_code": "EUR", "notes": `+string(encoded)+`}]
+	}}}`)
+	server.Handle(http.MethodPut, "/v1/transactions/1", http.StatusOK, `{"data": {"id": "1", "type": "transactions", "attributes": {"transactions": []}}}`)
+	server.Handle(http.MethodPost, "/v1/transactions", http.StatusOK, `{"data": {"id": "2", "type": "transactions", "attributes": {"transactions": []}}}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	tx, err := client.GetTransaction(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, notes, tx.Notes)
+
+	require.NoError(t, client.UpdateTransaction(ctx, "1", *tx))
+	updates := server.RequestsTo(http.MethodPut, "/v1/transactions/1")
+	require.Len(t, updates, 1)
+	var update UpdateTransactionJSONRequestBody
+	require.NoError(t, updates[0].JSON(&update))
+	require.Len(t, *update.Transactions, 1)
+	assert.Equal(t, notes, stringValue((*update.Transactions)[0].Notes))
+
+	tx.SourceName = "Checking"
+	tx.DestinationName = "Shop"
+	require.NoError(t, client.ImportTransaction(ctx, *tx))
+	stores := server.RequestsTo(http.MethodPost, "/v1/transactions")
+	require.Len(t, stores, 1)
+	var store StoreTransactionJSONRequestBody
+	require.NoError(t, stores[0].JSON(&store))
+	require.Len(t, store.Transactions, 1)
+	assert.Equal(t, notes, stringValue(store.Transactions[0].Notes))
+
+	// Empty notes clear the stored notes on update
+	tx.Notes = ""
+	require.NoError(t, client.UpdateTransaction(ctx, "1", *tx))
+	updates = server.RequestsTo(http.MethodPut, "/v1/transactions/1")
+	require.Len(t, updates, 2)
+	require.NoError(t, updates[1].JSON(&update))
+	require.NotNil(t, (*update.Transactions)[0].Notes)
+	assert.Empty(t, *(*update.Transactions)[0].Notes)
+}
+
+// TestGeotaggedTransaction tests that the location of a transaction is read and that invalid coordinates are dropped
+func TestGeotaggedTransaction(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/transactions/1", http.StatusOK, `{"data": {"id": "1", "type": "transactions", "attributes": {
+		"transactions": [{"type": "withdrawal", "date": "2024-01-15T00:00:00+00:00", "amount": "4.50", "description": "Coffee",
+			"currency_code": "EUR", "latitude": 52.3676, "longitude": 4.9041, "zoom_level": 12}]
+	}}}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	tx, err := client.GetTransaction(ctx, "1")
+	require.NoError(t, err)
+	require.NotNil(t, tx.Latitude)
+	require.NotNil(t, tx.Longitude)
+	require.NotNil(t, tx.ZoomLevel)
+	assert.Equal(t, 52.3676, *tx.Latitude)
+	assert.Equal(t, 4.9041, *tx.Longitude)
+	assert.Equal(t, int32(12), *tx.ZoomLevel)
+
+	// The location is read-only, so it is never a reason to reject a write
+	tx.Latitude = float64Ptr(91)
+	tx.Longitude = nil
+	assert.Empty(t, validateTransaction(*tx))
+
+	tests := []struct {
+		name        string
+		location    string
+		coordinates bool // Whether the coordinates are kept
+	}{
+		{"latitude out of range", `"latitude": 91, "longitude": 4.9041, "zoom_level": 12`, false},
+		{"longitude out of range", `"latitude": 52.3676, "longitude": -181, "zoom_level": 12`, false},
+		{"longitude missing", `"latitude": 52.3676, "zoom_level": 12`, false},
+		{"negative zoom level", `"latitude": 52.3676, "longitude": 4.9041, "zoom_level": -1`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server.Handle(http.MethodGet, "/v1/transactions/2", http.StatusOK, `{"data": {"id": "2", "type": "transactions", "attributes": {
+				"transactions": [{"type": "withdrawal", "date": "2024-01-15T00:00:00+00:00", "amount": "4.50", "description": "Coffee",
+					"currency_code": "EUR", `+tt.location+`}]
+			}}}`)
+			tx, err := client.GetTransaction(ctx, "2")
+			require.NoError(t, err)
+			assert.Nil(t, tx.ZoomLevel)
+			assert.Equal(t, tt.coordinates, tx.Latitude != nil)
+			assert.Equal(t, tt.coordinates, tx.Longitude != nil)
+		})
+	}
+}
+
+// TestCloneTransaction tests that a clone is created on the new date with every split, tag, category and note
+func TestCloneTransaction(t *testing.T) {
+	split := func(amount, category, notes string) string {
+		return fmt.Sprintf(`{"type": "withdrawal", "date": "2024-01-15T00:00:00+00:00", "amount": %q, "description": "Groceries",
+			"currency_code": "EUR", "transaction_journal_id": "11", "source_id": "1", "destination_name": "Market",
+			"category_name": %q, "tags": ["weekly"], "notes": %q, "external_id": "bank-1", "reconciled": true}`, amount, category, notes)
+	}
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/transactions/1", http.StatusOK, `{"data": {"id": "1", "type": "transactions", "attributes": {
+		"group_title": "Shopping", "transactions": [`+split("20.00", "Food", "Fruit")+`, `+split("5.00", "Household", "Soap")+`]}}}`)
+	server.Handle(http.MethodPost, "/v1/transactions", http.StatusOK, `{"data": {"id": "2", "type": "transactions", "attributes": {
+		"transactions": [{"type": "withdrawal", "date": "2024-02-15T00:00:00+00:00", "amount": "20.00", "description": "Groceries",
+			"currency_code": "EUR", "transaction_journal_id": "21"}]}}}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	newDate := time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)
+	clone, err := client.CloneTransaction(ctx, "1", newDate)
+	require.NoError(t, err)
+	assert.Equal(t, "2", clone.ID)
+	assert.Equal(t, "21", clone.JournalID)
+	assert.True(t, clone.Date.Equal(newDate))
+
+	requests := server.RequestsTo(http.MethodPost, "/v1/transactions")
+	require.Len(t, requests, 1)
+	var body StoreTransactionJSONRequestBody
+	require.NoError(t, requests[0].JSON(&body))
+	assert.Equal(t, "Shopping", stringValue(body.GroupTitle))
+	assert.False(t, boolValue(body.ApplyRules))
+	require.Len(t, body.Transactions, 2)
+	for i, want := range []struct{ amount, category, notes string }{{"20.00", "Food", "Fruit"}, {"5.00", "Household", "Soap"}} {
+		stored := body.Transactions[i]
+		assert.True(t, stored.Date.Equal(newDate))
+		assert.Equal(t, want.amount, stored.Amount)
+		assert.Equal(t, want.category, stringValue(stored.CategoryName))
+		assert.Equal(t, want.notes, stringValue(stored.Notes))
+		require.NotNil(t, stored.Tags)
+		assert.Equal(t, []string{"weekly"}, *stored.Tags)
+		assert.Equal(t, "1", stringValue(stored.SourceId))
+		assert.Equal(t, "Market", stringValue(stored.DestinationName))
+		assert.Nil(t, stored.ExternalId)
+		assert.Nil(t, stored.Reconciled)
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		server.Reset()
+		_, err := client.CloneTransaction(ctx, "1", time.Time{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Date is required")
+		assert.Empty(t, server.Requests())
+
+		_, err = client.CloneTransaction(ctx, "99", newDate)
+		assert.ErrorIs(t, err, ErrNotFoundSentinel)
+	})
+}
+
+// TestDecimalAmounts tests that the amount strings of the server are kept with WithDecimalAmounts
+func TestDecimalAmounts(t *testing.T) {
+	split := func(amount string) string {
+		return fmt.Sprintf(`{"type": "withdrawal", "date": "2024-01-15T00:00:00+00:00", "amount": %q, "description": "Split"}`, amount)
+	}
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/transactions/1", http.StatusOK,
+		`{"data": {"id": "1", "type": "transactions", "attributes": {"transactions": [`+split("100.00")+`]}}}`)
+	server.Handle(http.MethodGet, "/v1/transactions/2", http.StatusOK,
+		`{"data": {"id": "2", "type": "transactions", "attributes": {"group_title": "Shopping", "transactions": [`+split("100")+`, `+split("0.500000000000")+`]}}}`)
+	server.Handle(http.MethodGet, "/v1/transactions", http.StatusOK,
+		`{"data": [{"type": "transactions", "id": "1", "attributes": {"transactions": [`+split("100.00")+`]}}], "meta": {}}`)
+
+	config := DefaultClientConfig().WithDecimalAmounts()
+	config.BaseURL = server.URL
+	config.Token = "test-token"
+	client, err := NewFireflyClientWithConfig(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	tx, err := client.GetTransaction(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, "100.00", tx.AmountRaw)
+	assert.Equal(t, 100.0, tx.Amount)
+
+	group, err := client.GetTransactionGroup(ctx, "2")
+	require.NoError(t, err)
+	require.Len(t, group.Splits, 2)
+	assert.Equal(t, "100", group.Splits[0].AmountRaw)
+	assert.Equal(t, "0.500000000000", group.Splits[1].AmountRaw)
+	assert.Equal(t, 0.5, group.Splits[1].Amount)
+
+	transactions, err := client.ListTransactions(ctx, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, "100.00", transactions[0].AmountRaw)
+
+	// The raw strings are only kept when enabled
+	client, err = NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	tx, err = client.GetTransaction(ctx, "1")
+	require.NoError(t, err)
+	assert.Empty(t, tx.AmountRaw)
+	assert.Equal(t, 100.0, tx.Amount)
+}
+
+// TestGetTransactions tests batched transaction lookups keep input order and report per-ID failures
+func TestGetTransactions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/v1/transactions/")
+		if id == "404" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// Answer later IDs first to shuffle completion order
+		if id == "1" {
+			time.Sleep(20 * time.Millisecond)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"id":   id,
+				"type": "transactions",
+				"attributes": map[string]interface{}{
+					"created_at": "2024-01-15T10:00:00Z",
+					"transactions": []interface{}{map[string]interface{}{
+						"type":        "withdrawal",
+						"date":        "2024-01-15T00:00:00Z",
+						"amount":      "10.00",
+						"description": "Transaction " + id,
+					}},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	t.Run("order", func(t *testing.T) {
+		transactions, err := client.GetTransactions(ctx, []string{"1", "2", "3", "4", "5"})
+		require.NoError(t, err)
+		require.Len(t, transactions, 5)
+		for i, tx := range transactions {
+			assert.Equal(t, fmt.Sprintf("%d", i+1), tx.ID)
+		}
+	})
+
+	t.Run("partial failure", func(t *testing.T) {
+		transactions, err := client.GetTransactions(ctx, []string{"3", "404", "1"})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrNotFoundSentinel)
+		assert.Contains(t, err.Error(), "404")
+
+		require.Len(t, transactions, 2)
+		assert.Equal(t, "3", transactions[0].ID)
+		assert.Equal(t, "1", transactions[1].ID)
+	})
+}
+
+// TestMaxConcurrentRequests tests that batch helpers and webhook handlers respect the configured parallelism
+func TestMaxConcurrentRequests(t *testing.T) {
+	var inFlight, peak int32
+	track := func() func() {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			current := atomic.LoadInt32(&peak)
+			if n <= current || atomic.CompareAndSwapInt32(&peak, current, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return func() { atomic.AddInt32(&inFlight, -1) }
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer track()()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"id": "1", "type": "transactions", "attributes": {"created_at": "2024-01-15T10:00:00Z", "transactions": []}}}`))
+	}))
+	defer server.Close()
+
+	config := DefaultClientConfig().WithMaxConcurrentRequests(2)
+	config.BaseURL = server.URL
+	client, err := NewFireflyClientWithConfig(config)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = client.GetTransactions(ctx, []string{"1", "2", "3", "4", "5", "6", "7", "8"})
+	require.NoError(t, err)
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(2))
+	assert.Greater(t, atomic.LoadInt32(&peak), int32(0))
+
+	atomic.StoreInt32(&peak, 0)
+	manager := client.GetWebhookManager()
+	for i := 0; i < 6; i++ {
+		manager.RegisterHandlerFunc("transaction.created", func(ctx context.Context, event *WebhookEvent) error {
+			defer track()()
+			return nil
+		})
+	}
+	require.NoError(t, manager.ProcessWebhook(ctx, []byte(`{"id": "1", "type": "transaction.created"}`)))
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(2))
+	assert.Greater(t, atomic.LoadInt32(&peak), int32(0))
+}
+
+// TestRegisterTransactionHandler tests that webhook events are hydrated with the transaction they refer to
+func TestRegisterTransactionHandler(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/transactions/123", http.StatusOK, `{"data": {"id": "123", "type": "transactions", "attributes": {
+		"created_at": "2024-03-02T09:15:42+01:00",
+		"transactions": [{"type": "withdrawal", "date": "2024-03-01T00:00:00+01:00", "amount": "42.50", "description": "Weekly groceries"}]
+	}}}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	var got *TransactionModel
+	client.RegisterTransactionHandler("transaction.created", func(ctx context.Context, event *WebhookEvent, tx *TransactionModel) error {
+		assert.Equal(t, "event-1", event.ID)
+		got = tx
+		return nil
+	})
+	manager := client.GetWebhookManager()
+
+	require.NoError(t, manager.ProcessWebhook(ctx, []byte(`{"id": "event-1", "type": "transaction.created", "data": {"transaction_id": "123"}}`)))
+	require.NotNil(t, got)
+	assert.Equal(t, "123", got.ID)
+	assert.Equal(t, "Weekly groceries", got.Description)
+	assert.Equal(t, 42.5, got.Amount)
+
+	// Numeric IDs are accepted as well
+	got = nil
+	require.NoError(t, manager.ProcessWebhook(ctx, []byte(`{"id": "event-1", "type": "transaction.created", "data": {"transaction_id": 123}}`)))
+	require.NotNil(t, got)
+	assert.Len(t, server.RequestsTo(http.MethodGet, "/v1/transactions/123"), 2)
+
+	// Events without a transaction ID and failed lookups never reach the handler
+	got = nil
+	assert.Error(t, manager.ProcessWebhook(ctx, []byte(`{"id": "event-2", "type": "transaction.created", "data": {}}`)))
+	assert.Error(t, manager.ProcessWebhook(ctx, []byte(`{"id": "event-3", "type": "transaction.created", "data": {"transaction_id": "404"}}`)))
+	assert.Nil(t, got)
+}
+
+// TestListEmptyResults tests that list methods return empty slices rather than errors for zero results
+func TestListEmptyResults(t *testing.T) {
+	ctx := context.Background()
+
+	emptyBody := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer emptyBody.Close()
+
+	emptyData := mockServer(t, http.StatusOK, `{"data": [], "meta": {}}`)
+	defer emptyData.Close()
+
+	for _, server := range []*httptest.Server{emptyBody, emptyData} {
+		client, err := NewFireflyClient(server.URL, "test-token")
+		require.NoError(t, err)
+
+		categories, err := client.ListCategories(ctx, 1, 10)
+		require.NoError(t, err)
+		assert.NotNil(t, categories)
+		assert.Empty(t, categories)
+
+		budgets, err := client.ListBudgets(ctx, 1, 10)
+		require.NoError(t, err)
+		assert.NotNil(t, budgets)
+		assert.Empty(t, budgets)
+
+		limits, err := client.GetBudgetLimits(ctx, "1")
+		require.NoError(t, err)
+		assert.NotNil(t, limits)
+		assert.Empty(t, limits)
+
+		transactions, err := client.ListTransactions(ctx, 1, 10)
+		require.NoError(t, err)
+		assert.NotNil(t, transactions)
+		assert.Empty(t, transactions)
+	}
+}
+
+// TestContextCancellation tests that cancelling the context promptly aborts in-flight requests
+func TestContextCancellation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Drain the body so the server notices the client going away
+		io.Copy(io.Discard, r.Body)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	tx := TransactionModel{
+		Currency:    "USD",
+		Amount:      10,
+		TransType:   "withdrawal",
+		Description: "Slow",
+		Date:        time.Now(),
+	}
+
+	operations := map[string]func(ctx context.Context) error{
+		"GetTransaction": func(ctx context.Context) error {
+			_, err := client.GetTransaction(ctx, "1")
+			return err
+		},
+		"ListTransactions": func(ctx context.Context) error {
+			_, err := client.ListTransactions(ctx, 1, 10)
+			return err
+		},
+		"ImportTransactions": func(ctx context.Context) error {
+			return client.ImportTransactions(ctx, []TransactionModel{tx})
+		},
+		"ListAccounts": func(ctx context.Context) error {
+			_, err := client.ListAccounts(ctx, 1, 10)
+			return err
+		},
+		"ListBudgets": func(ctx context.Context) error {
+			_, err := client.ListBudgets(ctx, 1, 10)
+			return err
+		},
+		"CreateBudget": func(ctx context.Context) error {
+			return client.CreateBudget(ctx, BudgetModel{Name: "Slow"})
+		},
+		"ImportData": func(ctx context.Context) error {
+			_, err := client.ImportData(ctx, ImportTypeTransactions, ImportFormatCSV, []byte("a,b\n"), nil)
+			return err
+		},
+		"ExportData": func(ctx context.Context) error {
+			_, err := client.ExportData(ctx, DataTypeTransactions, ExportFormatCSV)
+			return err
+		},
+		"GetBudgetLimits": func(ctx context.Context) error {
+			_, err := client.GetBudgetLimits(ctx, "1")
+			return err
+		},
+		"SetBudgetLimit": func(ctx context.Context) error {
+			return client.SetBudgetLimit(ctx, "1", BudgetLimitModel{ID: "2", Amount: "100", Period: "monthly", Start: time.Now(), End: time.Now().AddDate(0, 1, 0)})
+		},
+		"DestroyData": func(ctx context.Context) error {
+			return client.DestroyData(ctx, DataTypeTransactions)
+		},
+		"PurgeData": func(ctx context.Context) error {
+			return client.PurgeData(ctx)
+		},
+	}
+
+	for name, operation := range operations {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(50*time.Millisecond, cancel)
+
+			started := time.Now()
+			err := operation(ctx)
+
+			require.Error(t, err)
+			assert.Less(t, time.Since(started), time.Second)
+			assert.ErrorIs(t, err, context.Canceled)
+			assert.Equal(t, errbuilder.CodeCanceled, errbuilder.CodeOf(err))
+		})
+	}
+}
+
+// TestInsecureBaseURL tests that plain http is only accepted for loopback hosts unless explicitly allowed
+func TestInsecureBaseURL(t *testing.T) {
+	for _, baseURL := range []string{
+		"http://localhost:8080/api",
+		"http://127.0.0.1/api",
+		"http://[::1]:8080/api",
+		"https://firefly.example.com/api",
+	} {
+		_, err := NewFireflyClient(baseURL, "test-token")
+		assert.NoError(t, err, baseURL)
+	}
+
+	_, err := NewFireflyClient("http://firefly.example.com/api", "test-token")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "AllowInsecure")
+
+	config := DefaultClientConfig()
+	config.BaseURL = "http://192.168.1.10/api"
+	config.Token = "test-token"
+
+	_, err = NewFireflyClientWithConfig(config)
+	require.Error(t, err)
+
+	config.AllowInsecure = true
+	_, err = NewFireflyClientWithConfig(config)
+	assert.NoError(t, err)
+}
+
+// recordingImporter is an importer that records how it is driven by the client
+type recordingImporter struct {
+	*importers.BaseImporter
+
+	mu          sync.Mutex
+	calls       []string
+	initialized []importers.ImporterConfig
+	connErr     error         // Returned by TestConnection
+	started     chan struct{} // Signalled when Import starts, if set
+	release     chan struct{} // Import waits for it, if set
+}
+
+func newRecordingImporter() *recordingImporter {
+	return &recordingImporter{BaseImporter: importers.NewBaseImporter()}
+}
+
+func (i *recordingImporter) record(call string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.calls = append(i.calls, call)
+}
+
+func (i *recordingImporter) Initialize(ctx context.Context, config importers.ImporterConfig) error {
+	i.record("Initialize")
+	i.mu.Lock()
+	i.initialized = append(i.initialized, config)
+	i.mu.Unlock()
+	return i.BaseImporter.Initialize(ctx, config)
+}
+
+func (i *recordingImporter) ValidateConfig(config importers.ImporterConfig) error {
+	if config.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func (i *recordingImporter) TestConnection(ctx context.Context) error {
+	i.record("TestConnection")
+	return i.connErr
+}
+
+func (i *recordingImporter) Import(ctx context.Context, options importers.ImportOptions) (*importers.ImportResult, error) {
+	i.record("Import")
+	if i.started != nil {
+		i.started <- struct{}{}
+	}
+	if i.release != nil {
+		<-i.release
+	}
+	return i.NewResult(importers.ImportStats{RowsRead: 1, Created: 1}, nil), nil
+}
+
+func (i *recordingImporter) GetCapabilities() importers.ImporterCapabilities {
+	return importers.ImporterCapabilities{SupportedTypes: []string{"transactions"}}
+}
+
+// TestRunImporterInitializes tests that importers are initialized with their registered config before importing
+func TestRunImporterInitializes(t *testing.T) {
+	client, err := NewFireflyClient("http://localhost", "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	importer := newRecordingImporter()
+	config := importers.ImporterConfig{
+		Name:     "bank-csv",
+		Settings: map[string]interface{}{"delimiter": ";"},
+		Mappings: map[string]string{"Omschrijving": "description"},
+	}
+	require.NoError(t, client.RegisterImporterWithConfig(importer, config))
+	assert.Empty(t, importer.calls, "registration does not initialize")
+
+	result, err := client.RunImporter(ctx, "bank-csv", importers.ImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Stats.Created)
+	require.Len(t, importer.initialized, 1)
+	assert.Equal(t, config, importer.initialized[0])
+	assert.Equal(t, []string{"Initialize", "TestConnection", "Import"}, importer.calls)
+
+	// Every run starts with a fresh initialization
+	_, err = client.RunImporter(ctx, "bank-csv", importers.ImportOptions{})
+	require.NoError(t, err)
+	assert.Len(t, importer.initialized, 2)
+
+	assert.Error(t, client.RegisterImporterWithConfig(newRecordingImporter(), importers.ImporterConfig{}))
+	_, err = client.RunImporter(ctx, "missing", importers.ImportOptions{})
+	assert.Error(t, err)
+}
+
+// TestRunImporterWithConfig tests running an importer with a configuration for a single run
+func TestRunImporterWithConfig(t *testing.T) {
+	client, err := NewFireflyClient("http://localhost", "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	importer := newRecordingImporter()
+	registered := importers.ImporterConfig{Name: "bank-csv", Settings: map[string]interface{}{"file": "january.csv"}}
+	require.NoError(t, client.RegisterImporterWithConfig(importer, registered))
+
+	override := importers.ImporterConfig{Name: "bank-csv", Settings: map[string]interface{}{"file": "february.csv"}}
+	_, err = client.RunImporterWithConfig(ctx, "bank-csv", override, importers.ImportOptions{})
+	require.NoError(t, err)
+	require.Len(t, importer.initialized, 1)
+	assert.Equal(t, override, importer.initialized[0])
+
+	// The registered configuration is unchanged
+	_, err = client.RunImporter(ctx, "bank-csv", importers.ImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, registered, importer.initialized[1])
+
+	_, err = client.RunImporterWithConfig(ctx, "bank-csv", importers.ImporterConfig{}, importers.ImportOptions{})
+	assert.ErrorContains(t, err, "invalid importer configuration")
+	assert.Len(t, importer.initialized, 2)
+}
+
+// TestRunImporterConnectionFailure tests that the import is aborted when the connection test fails
+func TestRunImporterConnectionFailure(t *testing.T) {
+	client, err := NewFireflyClient("http://localhost", "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	importer := newRecordingImporter()
+	importer.connErr = errors.New("bank API unreachable")
+	require.NoError(t, client.RegisterImporterWithConfig(importer, importers.ImporterConfig{Name: "bank-api"}))
+
+	result, err := client.RunImporter(ctx, "bank-api", importers.ImportOptions{})
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, importer.connErr)
+	assert.Contains(t, err.Error(), "connection test of importer bank-api failed")
+	assert.Equal(t, []string{"Initialize", "TestConnection"}, importer.calls)
+}
+
+// TestRunImporterConcurrently tests that a second run of a running importer is rejected
+func TestRunImporterConcurrently(t *testing.T) {
+	client, err := NewFireflyClient("http://localhost", "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	importer := newRecordingImporter()
+	importer.started = make(chan struct{})
+	importer.release = make(chan struct{})
+	require.NoError(t, client.RegisterImporterWithConfig(importer, importers.ImporterConfig{Name: "bank-csv"}))
+	other := newRecordingImporter()
+	require.NoError(t, client.RegisterImporterWithConfig(other, importers.ImporterConfig{Name: "bank-ofx"}))
+
+	done := make(chan error)
+	go func() {
+		_, err := client.RunImporter(ctx, "bank-csv", importers.ImportOptions{})
+		done <- err
+	}()
+	<-importer.started
+
+	_, err = client.RunImporter(ctx, "bank-csv", importers.ImportOptions{})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrImporterRunningSentinel)
+	assert.Contains(t, err.Error(), "already running")
+	_, err = client.RunImporterWithConfig(ctx, "bank-csv", importers.ImporterConfig{Name: "bank-csv"}, importers.ImportOptions{})
+	assert.ErrorIs(t, err, ErrImporterRunningSentinel)
+
+	// Other importers are not blocked
+	_, err = client.RunImporter(ctx, "bank-ofx", importers.ImportOptions{})
+	assert.NoError(t, err)
+
+	close(importer.release)
+	require.NoError(t, <-done)
+
+	// Once the first run finished the importer can run again
+	go func() { <-importer.started }()
+	_, err = client.RunImporter(ctx, "bank-csv", importers.ImportOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, importer.initialized, 2, "rejected runs do not reinitialize the importer")
+}
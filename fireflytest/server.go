@@ -0,0 +1,136 @@
+// Package fireflytest provides a mock Firefly III API server for tests.
+//
+// Register canned responses per route, point the client at Server.URL and
+// assert on the recorded requests afterwards:
+//
+//	server := fireflytest.NewServer(t)
+//	server.Handle(http.MethodGet, "/v1/accounts", http.StatusOK, `{"data": [], "meta": {}}`)
+//
+//	client, _ := firefly.NewFireflyClient(server.URL, "test-token")
+//	client.ListAccounts(ctx, 1, 50)
+//
+//	requests := server.RequestsTo(http.MethodGet, "/v1/accounts")
+package fireflytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+// Request is a request recorded by the Server
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// JSON decodes the request body into v
+func (r Request) JSON(v interface{}) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// Server is a mock Firefly III API server that serves canned responses per route
+// and records every request it receives. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	routes   map[string]http.HandlerFunc // "METHOD /path" -> handler
+	requests []Request
+}
+
+// NewServer starts a Server that is closed when the test finishes
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	s := &Server{routes: make(map[string]http.HandlerFunc)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Handle registers a canned JSON response for a route, replacing any previous one.
+// path is matched exactly against the request path, e.g. "/v1/accounts".
+func (s *Server) Handle(method, path string, status int, body string) {
+	s.HandleFunc(method, path, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	})
+}
+
+// HandleJSON registers a response for a route whose body is v encoded as JSON
+func (s *Server) HandleJSON(method, path string, status int, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		panic("fireflytest: cannot encode response: " + err.Error())
+	}
+	s.Handle(method, path, status, string(body))
+}
+
+// HandleFunc registers a handler for a route, replacing any previous one.
+// The request body has already been recorded and can be read again by the handler.
+func (s *Server) HandleFunc(method, path string, handler http.HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes[method+" "+path] = handler
+}
+
+// Requests returns all requests received so far in arrival order
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// RequestsTo returns the requests received for a route in arrival order
+func (s *Server) RequestsTo(method, path string) []Request {
+	var matched []Request
+	for _, r := range s.Requests() {
+		if r.Method == method && r.Path == path {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+// Reset forgets all recorded requests, keeping the registered routes
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+}
+
+// serveHTTP records the request and dispatches it to the handler of its route.
+// Unknown routes are answered with a Firefly style 404.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+		Body:   body,
+	})
+	handler, ok := s.routes[r.Method+" "+r.URL.Path]
+	s.mu.Unlock()
+
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"message": "Resource not found", "exception": "NotFoundHttpException"}`)
+		return
+	}
+	handler(w, r)
+}
@@ -0,0 +1,108 @@
+package fireflytest
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestServerCannedResponses tests serving registered routes and 404s for unknown ones
+func TestServerCannedResponses(t *testing.T) {
+	server := NewServer(t)
+	server.Handle(http.MethodGet, "/v1/about", http.StatusOK, `{"data": {"version": "6.1.0"}}`)
+	server.HandleJSON(http.MethodPost, "/v1/tags", http.StatusCreated, map[string]interface{}{"data": map[string]string{"id": "7"}})
+
+	resp, err := http.Get(server.URL + "/v1/about")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.JSONEq(t, `{"data": {"version": "6.1.0"}}`, string(body))
+
+	resp, err = http.Post(server.URL+"/v1/tags", "application/json", strings.NewReader(`{"tag": "food"}`))
+	require.NoError(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.JSONEq(t, `{"data": {"id": "7"}}`, string(body))
+
+	// The method is part of the route
+	resp, err = http.Post(server.URL+"/v1/about", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// Registering a route again replaces the response
+	server.Handle(http.MethodGet, "/v1/about", http.StatusServiceUnavailable, `{"message": "maintenance"}`)
+	resp, err = http.Get(server.URL + "/v1/about")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+// TestServerRecordsRequests tests recording and filtering received requests
+func TestServerRecordsRequests(t *testing.T) {
+	server := NewServer(t)
+	var handlerBody string
+	server.HandleFunc(http.MethodPost, "/v1/tags", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		handlerBody = string(body)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/v1/tags?page=2", strings.NewReader(`{"tag": "food"}`))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer test-token")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	resp, err = http.Get(server.URL + "/v1/unknown")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, `{"tag": "food"}`, handlerBody, "handlers can still read the recorded body")
+	require.Len(t, server.Requests(), 2)
+
+	requests := server.RequestsTo(http.MethodPost, "/v1/tags")
+	require.Len(t, requests, 1)
+	assert.Equal(t, "2", requests[0].Query.Get("page"))
+	assert.Equal(t, "Bearer test-token", requests[0].Header.Get("Authorization"))
+
+	var tag struct {
+		Tag string `json:"tag"`
+	}
+	require.NoError(t, requests[0].JSON(&tag))
+	assert.Equal(t, "food", tag.Tag)
+
+	server.Reset()
+	assert.Empty(t, server.Requests())
+	assert.Empty(t, server.RequestsTo(http.MethodPost, "/v1/tags"))
+}
+
+// TestServerConcurrentRequests tests that concurrent requests are all recorded
+func TestServerConcurrentRequests(t *testing.T) {
+	server := NewServer(t)
+	server.Handle(http.MethodGet, "/v1/about", http.StatusOK, `{}`)
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := http.Get(server.URL + "/v1/about")
+			if assert.NoError(t, err) {
+				resp.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, server.RequestsTo(http.MethodGet, "/v1/about"), 20)
+}
@@ -0,0 +1,260 @@
+package firefly
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZanzyTHEbar/fireflyiii-client-go/fireflytest"
+)
+
+// loadFixture decodes a JSON fixture from testdata/fixtures into v
+func loadFixture(t testing.TB, name string, v interface{}) {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "fixtures", name))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, v))
+}
+
+// TestTransactionFixtures tests converting real Firefly III transaction responses to TransactionModel
+func TestTransactionFixtures(t *testing.T) {
+	cet := time.FixedZone("", 1*60*60)
+	cest := time.FixedZone("", 2*60*60)
+
+	tests := []struct {
+		fixture string
+		want    TransactionModel
+	}{
+		{
+			fixture: "transaction_withdrawal.json",
+			want: TransactionModel{
+				ID:              "512",
+				JournalID:       "601",
+				Currency:        "EUR",
+				CurrencyID:      "1",
+				Amount:          42.5,
+				TransType:       "withdrawal",
+				Description:     "Weekly groceries",
+				Date:            time.Date(2024, 3, 1, 0, 0, 0, 0, cet),
+				Category:        "Groceries",
+				SourceID:        "1",
+				SourceName:      "Checking Account",
+				DestinationID:   "17",
+				DestinationName: "Albert Heijn",
+				Tags:            []string{"food", "weekly"},
+				ExternalID:      "bank-2024-03-01-001",
+				CreatedAt:       time.Date(2024, 3, 2, 9, 15, 42, 0, cet),
+				UpdatedAt:       time.Date(2024, 3, 2, 9, 15, 42, 0, cet),
+			},
+		},
+		{
+			// Split transactions are described by their group title and the first split
+			fixture: "transaction_split.json",
+			want: TransactionModel{
+				ID:              "513",
+				JournalID:       "602",
+				Currency:        "EUR",
+				CurrencyID:      "1",
+				Amount:          19.99,
+				TransType:       "withdrawal",
+				Description:     "Hardware store",
+				Date:            time.Date(2024, 3, 5, 17, 45, 0, 0, cet),
+				Category:        "Home",
+				SourceID:        "1",
+				SourceName:      "Checking Account",
+				DestinationID:   "21",
+				DestinationName: "Praxis",
+				Tags:            []string{},
+				Notes:           "Living room",
+				CreatedAt:       time.Date(2024, 3, 5, 18, 1, 12, 0, cet),
+				UpdatedAt:       time.Date(2024, 3, 6, 8, 30, 0, 0, cet),
+			},
+		},
+		{
+			fixture: "transaction_foreign.json",
+			want: TransactionModel{
+				ID:              "514",
+				JournalID:       "604",
+				Currency:        "EUR",
+				CurrencyID:      "1",
+				Amount:          61.23,
+				TransType:       "withdrawal",
+				Description:     "Hotel Kyoto",
+				Date:            time.Date(2024, 4, 10, 0, 0, 0, 0, cest),
+				Category:        "Travel",
+				ForeignAmount:   float64Ptr(10000),
+				ForeignCurrency: stringPtr("JPY"),
+				SourceID:        "2",
+				SourceName:      "Credit Card",
+				DestinationID:   "30",
+				DestinationName: "Kyoto Inn",
+				Tags:            []string{"japan-2024"},
+				ExternalURL:     "https://bank.example.com/tx/604",
+				CreatedAt:       time.Date(2024, 4, 12, 21, 3, 55, 0, cest),
+				UpdatedAt:       time.Date(2024, 4, 12, 21, 3, 55, 0, cest),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			var single TransactionSingle
+			loadFixture(t, tt.fixture, &single)
+
+			got, err := transactionModelFromRead(single.Data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestAccountFixtures tests converting a real Firefly III account response to AccountModel
+func TestAccountFixtures(t *testing.T) {
+	var single AccountSingle
+	loadFixture(t, "account_asset.json", &single)
+
+	got, err := accountModelFromRead(single.Data)
+	require.NoError(t, err)
+	assert.Equal(t, AccountModel{
+		ID:       "1",
+		Name:     "Checking Account",
+		Type:     "asset",
+		Currency: "EUR",
+		Balance:  -1234.56,
+		IBAN:     "NL02ABNA0123456789",
+		Number:   "0123456789",
+		Active:   true,
+		Role:     "defaultAsset",
+
+		IncludeNetWorth: true,
+	}, got)
+}
+
+// TestAccountModelFieldsFromAPI tests that every AccountModel field is filled from a real account
+// response, so the model has no field that Firefly III never provides, like the former BankName
+func TestAccountModelFieldsFromAPI(t *testing.T) {
+	var single AccountSingle
+	loadFixture(t, "account_asset.json", &single)
+
+	got, err := accountModelFromRead(single.Data)
+	require.NoError(t, err)
+
+	value := reflect.ValueOf(got)
+	for i := range value.NumField() {
+		assert.False(t, value.Field(i).IsZero(), "AccountModel.%s is not filled from the API", value.Type().Field(i).Name)
+	}
+}
+
+// TestBudgetFixtures tests converting a real Firefly III budget response to BudgetModel
+func TestBudgetFixtures(t *testing.T) {
+	cet := time.FixedZone("", 1*60*60)
+
+	var single BudgetSingle
+	loadFixture(t, "budget.json", &single)
+
+	got := budgetModelFromRead(single.Data)
+	assert.Equal(t, "3", got.ID)
+	assert.Equal(t, "Food", got.Name)
+	assert.True(t, got.Active)
+	assert.Equal(t, "Groceries and eating out", stringValue(got.Notes))
+	assert.Equal(t, int32(2), int32Value(got.Order))
+	assert.Equal(t, "400.000000000000", stringValue(got.AutoBudgetAmount))
+	require.NotNil(t, got.AutoBudgetPeriod)
+	assert.Equal(t, AutoBudgetPeriodMonthly, *got.AutoBudgetPeriod)
+	require.NotNil(t, got.AutoBudgetType)
+	assert.Equal(t, AutoBudgetTypeReset, *got.AutoBudgetType)
+	assert.Equal(t, time.Date(2023, 1, 1, 10, 0, 0, 0, cet), got.CreatedAt)
+	assert.Equal(t, time.Date(2024, 2, 1, 8, 0, 0, 0, cet), got.UpdatedAt)
+
+	require.NotNil(t, got.Spent)
+	require.Len(t, *got.Spent, 1)
+	assert.Equal(t, "-123.450000000000", stringValue((*got.Spent)[0].Sum))
+	assert.Equal(t, "EUR", stringValue((*got.Spent)[0].CurrencyCode))
+}
+
+// TestListTransactionGroups tests that listed split transactions keep every split with its own
+// date and category, while single split transactions have one split
+func TestListTransactionGroups(t *testing.T) {
+	var withdrawal, split TransactionSingle
+	loadFixture(t, "transaction_withdrawal.json", &withdrawal)
+	loadFixture(t, "transaction_split_multi_currency.json", &split)
+	body, err := json.Marshal(TransactionArray{Data: []TransactionRead{withdrawal.Data, split.Data}})
+	require.NoError(t, err)
+
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/transactions", http.StatusOK, string(body))
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	groups, err := client.ListTransactionGroups(ctx, TransactionFilter{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+
+	require.Len(t, groups[0].Splits, 1)
+	assert.Equal(t, "Groceries", groups[0].Splits[0].Category)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.FixedZone("", 1*60*60)), groups[0].Splits[0].Date)
+
+	require.Len(t, groups[1].Splits, len(split.Data.Attributes.Transactions))
+	for i, apiSplit := range split.Data.Attributes.Transactions {
+		assert.Equal(t, stringValue(apiSplit.CategoryName), groups[1].Splits[i].Category)
+		assert.True(t, apiSplit.Date.Equal(groups[1].Splits[i].Date))
+		assert.Equal(t, apiSplit.Description, groups[1].Splits[i].Description)
+	}
+
+	// The flat list describes the same transactions by their first split
+	transactions, err := client.ListTransactions(ctx, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, "Groceries", transactions[0].Category)
+	assert.Equal(t, groups[1].Splits[0].Category, transactions[1].Category)
+	assert.True(t, groups[1].Splits[0].Date.Equal(transactions[1].Date))
+}
+
+// TestTransactionGroupTotals tests per-currency totals of a multi-currency split transaction
+func TestTransactionGroupTotals(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("testdata", "fixtures", "transaction_split_multi_currency.json"))
+	require.NoError(t, err)
+
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/transactions/515", http.StatusOK, string(fixture))
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	group, err := client.GetTransactionGroup(context.Background(), "515")
+	require.NoError(t, err)
+	assert.Equal(t, "515", group.ID)
+	assert.Equal(t, "Trip to Zurich", group.Title)
+	require.Len(t, group.Splits, 4)
+	assert.Equal(t, "Train tickets", group.Splits[0].Description)
+	assert.Equal(t, "CHF", group.Splits[1].Currency)
+	assert.Equal(t, "Eating out", group.Splits[1].Category)
+	assert.Equal(t, "deposit", group.Splits[3].TransType)
+
+	totals := group.Totals()
+	require.Len(t, totals, 2)
+	assert.Equal(t, "-100.10", FormatMoney(totals["EUR"], "EUR"))
+	assert.Equal(t, "-58.00", FormatMoney(totals["CHF"], "CHF"))
+
+	t.Run("transfer", func(t *testing.T) {
+		transfer := TransactionGroupModel{Splits: []TransactionModel{
+			{Currency: "EUR", Amount: 250, TransType: "transfer"},
+			{CurrencyID: "7", Amount: 10, TransType: "withdrawal"},
+		}}
+		totals := transfer.Totals()
+		require.Len(t, totals, 2)
+		assert.Equal(t, 0, totals["EUR"].Sign())
+		assert.Equal(t, "-10", totals["7"].String())
+	})
+}
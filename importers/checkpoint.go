@@ -0,0 +1,97 @@
+package importers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// CheckpointStore persists how far an import got, so an import that failed midway can resume
+// instead of starting over. Checkpoints are keyed by importer name.
+type CheckpointStore interface {
+	// LoadCheckpoint returns the number of rows already processed, or 0 if there is no checkpoint
+	LoadCheckpoint(ctx context.Context, importer string) (int, error)
+
+	// SaveCheckpoint records that the first rows rows have been processed
+	SaveCheckpoint(ctx context.Context, importer string, rows int) error
+
+	// ClearCheckpoint removes the checkpoint once the import has completed
+	ClearCheckpoint(ctx context.Context, importer string) error
+}
+
+// MemoryCheckpointStore is a CheckpointStore that keeps checkpoints in memory.
+// It is safe for concurrent use; checkpoints are lost when the process exits.
+type MemoryCheckpointStore struct {
+	mu          sync.Mutex
+	checkpoints map[string]int
+}
+
+// NewMemoryCheckpointStore creates an empty in-memory checkpoint store
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{checkpoints: make(map[string]int)}
+}
+
+// LoadCheckpoint returns the number of rows already processed by the importer
+func (s *MemoryCheckpointStore) LoadCheckpoint(ctx context.Context, importer string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.checkpoints[importer], nil
+}
+
+// SaveCheckpoint records the number of rows processed by the importer
+func (s *MemoryCheckpointStore) SaveCheckpoint(ctx context.Context, importer string, rows int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoints[importer] = rows
+	return nil
+}
+
+// ClearCheckpoint removes the checkpoint of the importer
+func (s *MemoryCheckpointStore) ClearCheckpoint(ctx context.Context, importer string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.checkpoints, importer)
+	return nil
+}
+
+// SetCheckpointStore enables resumable imports by recording checkpoints in store.
+// Importers without a store always start from the first row.
+func (b *BaseImporter) SetCheckpointStore(store CheckpointStore) {
+	b.checkpoints = store
+}
+
+// ResumeRow returns the number of rows to skip because a previous run of the import already
+// processed them. Importers that support resuming call it before processing the first row.
+func (b *BaseImporter) ResumeRow(ctx context.Context) (int, error) {
+	if b.checkpoints == nil {
+		return 0, nil
+	}
+	rows, err := b.checkpoints.LoadCheckpoint(ctx, b.config.Name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load checkpoint of importer %q: %w", b.config.Name, err)
+	}
+	return rows, nil
+}
+
+// Checkpoint records that the first rows rows have been processed successfully.
+// Importers call it after every batch that was imported completely.
+func (b *BaseImporter) Checkpoint(ctx context.Context, rows int) error {
+	if b.checkpoints == nil {
+		return nil
+	}
+	if err := b.checkpoints.SaveCheckpoint(ctx, b.config.Name, rows); err != nil {
+		return fmt.Errorf("failed to save checkpoint of importer %q: %w", b.config.Name, err)
+	}
+	return nil
+}
+
+// CompleteCheckpoint removes the checkpoint after the import finished, so the next run starts over
+func (b *BaseImporter) CompleteCheckpoint(ctx context.Context) error {
+	if b.checkpoints == nil {
+		return nil
+	}
+	if err := b.checkpoints.ClearCheckpoint(ctx, b.config.Name); err != nil {
+		return fmt.Errorf("failed to clear checkpoint of importer %q: %w", b.config.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package importers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestImportResume tests that a failed import resumes after the last completed batch
+func TestImportResume(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryCheckpointStore()
+	data := "1,1\n2,2\n3,3\n4,4\n5,5\n6,6\n7,7\n"
+
+	importer := newCSVImporter(data)
+	importer.SetCheckpointStore(store)
+	importer.failAt = 6
+	require.NoError(t, importer.Initialize(ctx, ImporterConfig{Name: "csv"}))
+
+	result, err := importer.Import(ctx, ImportOptions{})
+	require.Error(t, err)
+	assert.Equal(t, 5, result.Stats.Created, "rows before the failure were imported")
+
+	checkpoint, err := store.LoadCheckpoint(ctx, "csv")
+	require.NoError(t, err)
+	assert.Equal(t, 4, checkpoint, "only completed batches are checkpointed")
+
+	// The resumed run skips the checkpointed rows and clears the checkpoint when done
+	importer.failAt = 0
+	result, err = importer.Import(ctx, ImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Stats.RowsRead)
+	assert.Equal(t, 3, result.Stats.Created)
+
+	checkpoint, err = store.LoadCheckpoint(ctx, "csv")
+	require.NoError(t, err)
+	assert.Zero(t, checkpoint)
+
+	// Checkpoints are kept per importer name
+	other := newCSVImporter(data)
+	other.SetCheckpointStore(store)
+	require.NoError(t, store.SaveCheckpoint(ctx, "csv", 6))
+	require.NoError(t, other.Initialize(ctx, ImporterConfig{Name: "other"}))
+	result, err = other.Import(ctx, ImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 7, result.Stats.RowsRead)
+}
+
+// TestImportWithoutCheckpointStore tests that imports start over without a store
+func TestImportWithoutCheckpointStore(t *testing.T) {
+	ctx := context.Background()
+	importer := newCSVImporter("1,1\n2,2\n3,3\n")
+	importer.failAt = 3
+	require.NoError(t, importer.Initialize(ctx, ImporterConfig{Name: "csv"}))
+
+	_, err := importer.Import(ctx, ImportOptions{})
+	require.Error(t, err)
+
+	importer.failAt = 0
+	result, err := importer.Import(ctx, ImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Stats.RowsRead)
+}
@@ -2,6 +2,9 @@ package importers
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ZanzyTHEbar/errbuilder-go"
@@ -93,10 +96,53 @@ type ImportResult struct {
 	// End time of the import
 	EndTime time.Time
 
-	// Summary of the import operation
+	// Structured counts of the import operation, for programmatic consumers
+	Stats ImportStats
+
+	// Summary of the import operation, rendered from Stats
 	Summary string
 }
 
+// ImportStats holds the counts and duration of an import operation
+type ImportStats struct {
+	// Number of rows read from the source
+	RowsRead int
+
+	// Number of transactions created in Firefly III
+	Created int
+
+	// Number of rows skipped because they were already imported
+	SkippedDuplicate int
+
+	// Number of rows that could not be imported
+	Failed int
+
+	// Time the import took
+	Elapsed time.Duration
+
+	// Time spent in each phase of the import, as recorded with BaseImporter.TrackPhase
+	Phases map[ImportPhase]time.Duration
+
+	// Throughput of the import: rows read per second of Elapsed
+	RowsPerSecond float64
+}
+
+// ImportPhase identifies a phase of an import for timing
+type ImportPhase string
+
+// Phases of an import
+const (
+	ImportPhaseParse    ImportPhase = "parse"    // Reading and decoding the source
+	ImportPhaseValidate ImportPhase = "validate" // Checking rows and detecting duplicates
+	ImportPhaseUpload   ImportPhase = "upload"   // Creating transactions in Firefly III
+)
+
+// String renders the stats as a human readable summary
+func (s ImportStats) String() string {
+	return fmt.Sprintf("Read %d rows: %d created, %d skipped as duplicates, %d failed in %s",
+		s.RowsRead, s.Created, s.SkippedDuplicate, s.Failed, s.Elapsed.Round(time.Millisecond))
+}
+
 // ImportOptions represents options for the import operation
 type ImportOptions struct {
 	// Whether to detect and skip duplicates
@@ -129,7 +175,9 @@ type Importer interface {
 	// TestConnection tests the connection to the data source
 	TestConnection(ctx context.Context) error
 
-	// Import performs the import operation with the given options
+	// Import performs the import operation with the given options.
+	// Implementations must check for cancellation before every batch, see BaseImporter.CheckCancelled.
+	// A cancelled import returns the result of the batches imported so far together with the context error.
 	Import(ctx context.Context, options ImportOptions) (*ImportResult, error)
 
 	// GetProgress returns the current progress of an ongoing import
@@ -179,9 +227,14 @@ type ImporterCapabilities struct {
 type BaseImporter struct {
 	config     ImporterConfig
 	progress   *ImportProgress
-	cancelled  bool
+	cancelled  atomic.Bool
 	ctx        context.Context
 	cancelFunc context.CancelFunc
+
+	checkpoints CheckpointStore // Optional store for resumable imports
+
+	phaseMu sync.Mutex
+	phases  map[ImportPhase]time.Duration // Time spent per phase since Initialize
 }
 
 // NewBaseImporter creates a new BaseImporter instance
@@ -191,13 +244,18 @@ func NewBaseImporter() *BaseImporter {
 	}
 }
 
-// Initialize implements basic initialization for importers
+// Initialize implements basic initialization for importers. It clears a cancellation of
+// the previous run, so the importer can run again.
 func (b *BaseImporter) Initialize(ctx context.Context, config ImporterConfig) error {
 	b.config = config
 	b.ctx, b.cancelFunc = context.WithCancel(ctx)
+	b.cancelled.Store(false)
 	b.progress = &ImportProgress{
 		StartTime: time.Now(),
 	}
+	b.phaseMu.Lock()
+	b.phases = nil
+	b.phaseMu.Unlock()
 	return nil
 }
 
@@ -209,7 +267,7 @@ func (b *BaseImporter) GetProgress(ctx context.Context) (*ImportProgress, error)
 // Cancel stops the current import operation
 func (b *BaseImporter) Cancel(ctx context.Context) error {
 	if b.cancelFunc != nil {
-		b.cancelled = true
+		b.cancelled.Store(true)
 		b.cancelFunc()
 	}
 	return nil
@@ -218,7 +276,7 @@ func (b *BaseImporter) Cancel(ctx context.Context) error {
 // Cleanup performs basic cleanup
 func (b *BaseImporter) Cleanup(ctx context.Context) error {
 	b.progress = nil
-	b.cancelled = false
+	b.cancelled.Store(false)
 	return nil
 }
 
@@ -234,5 +292,81 @@ func (b *BaseImporter) UpdateProgress(processed, succeeded, failed int, status s
 
 // IsCancelled returns whether the import has been cancelled
 func (b *BaseImporter) IsCancelled() bool {
-	return b.cancelled
+	return b.cancelled.Load()
+}
+
+// CheckCancelled returns a context error if the import has been cancelled through Cancel,
+// or if ctx or the context passed to Initialize is done. Importers must call it before
+// processing each batch and stop importing when it returns an error.
+func (b *BaseImporter) CheckCancelled(ctx context.Context) error {
+	if b.cancelled.Load() {
+		return context.Canceled
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if b.ctx != nil {
+		return b.ctx.Err()
+	}
+	return nil
+}
+
+// TrackPhase starts timing a phase of the import and returns a function that stops it.
+// Time spent in the same phase adds up, so importers can track each batch separately:
+//
+//	stop := b.TrackPhase(importers.ImportPhaseUpload)
+//	err := upload(batch)
+//	stop()
+func (b *BaseImporter) TrackPhase(phase ImportPhase) func() {
+	start := time.Now()
+	return func() {
+		elapsed := time.Since(start)
+		b.phaseMu.Lock()
+		defer b.phaseMu.Unlock()
+		if b.phases == nil {
+			b.phases = make(map[ImportPhase]time.Duration)
+		}
+		b.phases[phase] += elapsed
+	}
+}
+
+// NewResult builds the final result of an import from its stats. The counts of the result and
+// its Summary are derived from stats; Elapsed is measured from the start of the import when unset.
+// Phase timings recorded with TrackPhase are added unless stats already has them.
+// The import succeeds when no rows failed and errs is empty.
+func (b *BaseImporter) NewResult(stats ImportStats, errs errbuilder.ErrorMap) *ImportResult {
+	end := time.Now()
+	start := end
+	if b.progress != nil && !b.progress.StartTime.IsZero() {
+		start = b.progress.StartTime
+	}
+	if stats.Elapsed == 0 {
+		stats.Elapsed = end.Sub(start)
+	}
+	if stats.Phases == nil {
+		b.phaseMu.Lock()
+		if len(b.phases) > 0 {
+			stats.Phases = make(map[ImportPhase]time.Duration, len(b.phases))
+			for phase, elapsed := range b.phases {
+				stats.Phases[phase] = elapsed
+			}
+		}
+		b.phaseMu.Unlock()
+	}
+	if stats.Elapsed > 0 {
+		stats.RowsPerSecond = float64(stats.RowsRead) / stats.Elapsed.Seconds()
+	}
+
+	return &ImportResult{
+		Success:        stats.Failed == 0 && len(errs) == 0,
+		TotalProcessed: stats.RowsRead,
+		Succeeded:      stats.Created,
+		Failed:         stats.Failed,
+		Skipped:        stats.SkippedDuplicate,
+		Errors:         errs,
+		StartTime:      start,
+		EndTime:        end,
+		Stats:          stats,
+		Summary:        stats.String(),
+	}
 }
@@ -0,0 +1,222 @@
+package importers
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ZanzyTHEbar/errbuilder-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// csvImporter is a minimal CSV importer built on BaseImporter. Rows are "id,amount";
+// rows with an already seen id are duplicates and rows without an amount fail.
+// Rows are imported in batches of batchSize, and afterBatch is called after each batch.
+// The import fails with an error when it reaches row failAt (counting from 1), and
+// uploading a row takes uploadDelay.
+type csvImporter struct {
+	*BaseImporter
+	data        string
+	seen        map[string]bool
+	batchSize   int
+	afterBatch  func(batch int)
+	failAt      int
+	uploadDelay time.Duration
+}
+
+func newCSVImporter(data string) *csvImporter {
+	return &csvImporter{BaseImporter: NewBaseImporter(), data: data, seen: make(map[string]bool), batchSize: 2}
+}
+
+func (i *csvImporter) Import(ctx context.Context, options ImportOptions) (*ImportResult, error) {
+	stopParse := i.TrackPhase(ImportPhaseParse)
+	rows, err := csv.NewReader(strings.NewReader(i.data)).ReadAll()
+	stopParse()
+	if err != nil {
+		return nil, err
+	}
+
+	resume, err := i.ResumeRow(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats ImportStats
+	var errs errbuilder.ErrorMap
+	for start := resume; start < len(rows); start += i.batchSize {
+		if err := i.CheckCancelled(ctx); err != nil {
+			return i.NewResult(stats, errs), err
+		}
+
+		end := min(start+i.batchSize, len(rows))
+		for n := start; n < end; n++ {
+			if n+1 == i.failAt {
+				return i.NewResult(stats, errs), fmt.Errorf("connection lost at row %d", n+1)
+			}
+			row := rows[n]
+			stats.RowsRead++
+
+			stopValidate := i.TrackPhase(ImportPhaseValidate)
+			valid, duplicate := row[1] != "", options.SkipDuplicates && i.seen[row[0]]
+			stopValidate()
+
+			switch {
+			case !valid:
+				stats.Failed++
+				errs.Set(fmt.Sprintf("row %d", n+1), "Amount is required")
+			case duplicate:
+				stats.SkippedDuplicate++
+			default:
+				stopUpload := i.TrackPhase(ImportPhaseUpload)
+				time.Sleep(i.uploadDelay)
+				i.seen[row[0]] = true
+				stats.Created++
+				stopUpload()
+			}
+		}
+
+		if err := i.Checkpoint(ctx, end); err != nil {
+			return i.NewResult(stats, errs), err
+		}
+		if i.afterBatch != nil {
+			i.afterBatch(start / i.batchSize)
+		}
+	}
+
+	if err := i.CompleteCheckpoint(ctx); err != nil {
+		return i.NewResult(stats, errs), err
+	}
+	return i.NewResult(stats, errs), nil
+}
+
+// TestImportResultStats tests that the result counts and summary are derived from the stats
+func TestImportResultStats(t *testing.T) {
+	importer := newCSVImporter("1,10.00\n2,5.50\n1,10.00\n3,\n4,7.25\n")
+	require.NoError(t, importer.Initialize(context.Background(), ImporterConfig{Name: "csv"}))
+
+	result, err := importer.Import(context.Background(), ImportOptions{SkipDuplicates: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, result.Stats.RowsRead)
+	assert.Equal(t, 3, result.Stats.Created)
+	assert.Equal(t, 1, result.Stats.SkippedDuplicate)
+	assert.Equal(t, 1, result.Stats.Failed)
+
+	assert.False(t, result.Success)
+	assert.Equal(t, 5, result.TotalProcessed)
+	assert.Equal(t, 3, result.Succeeded)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Equal(t, 1, result.Failed)
+	assert.Contains(t, result.Errors.Get("row 4"), "Amount is required")
+	assert.False(t, result.EndTime.Before(result.StartTime))
+	assert.Equal(t, result.Stats.String(), result.Summary)
+	assert.Contains(t, result.Summary, "Read 5 rows: 3 created, 1 skipped as duplicates, 1 failed")
+}
+
+// TestImportStatsString tests rendering the summary with a fixed duration
+func TestImportStatsString(t *testing.T) {
+	stats := ImportStats{RowsRead: 2, Created: 2, Elapsed: 1500 * time.Millisecond}
+	assert.Equal(t, "Read 2 rows: 2 created, 0 skipped as duplicates, 0 failed in 1.5s", stats.String())
+
+	importer := NewBaseImporter()
+	result := importer.NewResult(stats, nil)
+	assert.True(t, result.Success)
+	assert.Equal(t, 1500*time.Millisecond, result.Stats.Elapsed)
+}
+
+// TestImportCancellation tests that an import stops between batches once cancelled
+func TestImportCancellation(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("cancel", func(t *testing.T) {
+		importer := newCSVImporter("1,1\n2,2\n3,3\n4,4\n5,5\n6,6\n")
+		require.NoError(t, importer.Initialize(ctx, ImporterConfig{Name: "csv"}))
+		importer.afterBatch = func(batch int) {
+			if batch == 0 {
+				require.NoError(t, importer.Cancel(ctx))
+			}
+		}
+
+		result, err := importer.Import(ctx, ImportOptions{})
+		require.ErrorIs(t, err, context.Canceled)
+		require.NotNil(t, result)
+		assert.Equal(t, 2, result.Stats.RowsRead)
+		assert.Equal(t, 2, result.Stats.Created)
+		assert.True(t, importer.IsCancelled())
+	})
+
+	t.Run("context", func(t *testing.T) {
+		importer := newCSVImporter("1,1\n2,2\n3,3\n4,4\n5,5\n6,6\n")
+		require.NoError(t, importer.Initialize(ctx, ImporterConfig{Name: "csv"}))
+		importCtx, cancel := context.WithCancel(ctx)
+		importer.afterBatch = func(batch int) {
+			if batch == 1 {
+				cancel()
+			}
+		}
+
+		result, err := importer.Import(importCtx, ImportOptions{})
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 4, result.Stats.Created)
+		assert.False(t, importer.IsCancelled())
+	})
+
+	t.Run("run again", func(t *testing.T) {
+		importer := newCSVImporter("1,1\n2,2\n3,3\n4,4\n")
+		require.NoError(t, importer.Initialize(ctx, ImporterConfig{Name: "csv"}))
+		importer.afterBatch = func(batch int) {
+			require.NoError(t, importer.Cancel(ctx))
+		}
+		_, err := importer.Import(ctx, ImportOptions{})
+		require.ErrorIs(t, err, context.Canceled)
+
+		// A new run is not affected by the cancellation of the previous one
+		require.NoError(t, importer.Initialize(ctx, ImporterConfig{Name: "csv"}))
+		assert.False(t, importer.IsCancelled())
+		importer.afterBatch = nil
+		result, err := importer.Import(ctx, ImportOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, 4, result.Stats.RowsRead)
+	})
+
+	t.Run("not cancelled", func(t *testing.T) {
+		importer := NewBaseImporter()
+		assert.NoError(t, importer.CheckCancelled(ctx))
+		require.NoError(t, importer.Initialize(ctx, ImporterConfig{}))
+		assert.NoError(t, importer.CheckCancelled(ctx))
+		require.NoError(t, importer.Cancel(ctx))
+		assert.ErrorIs(t, importer.CheckCancelled(ctx), context.Canceled)
+		require.NoError(t, importer.Cleanup(ctx))
+		assert.False(t, importer.IsCancelled())
+	})
+}
+
+// TestImportPhaseTimings tests that phase durations and throughput are recorded in the result
+func TestImportPhaseTimings(t *testing.T) {
+	ctx := context.Background()
+	importer := newCSVImporter("1,1\n2,2\n3,\n4,4\n")
+	importer.uploadDelay = 5 * time.Millisecond
+	require.NoError(t, importer.Initialize(ctx, ImporterConfig{Name: "csv"}))
+
+	result, err := importer.Import(ctx, ImportOptions{})
+	require.NoError(t, err)
+
+	stats := result.Stats
+	require.Len(t, stats.Phases, 3)
+	assert.Positive(t, stats.Phases[ImportPhaseParse])
+	assert.Positive(t, stats.Phases[ImportPhaseValidate])
+	assert.GreaterOrEqual(t, stats.Phases[ImportPhaseUpload], 15*time.Millisecond, "upload time adds up over rows")
+	assert.LessOrEqual(t, stats.Phases[ImportPhaseUpload], stats.Elapsed)
+	assert.Positive(t, stats.RowsPerSecond)
+	assert.InDelta(t, float64(stats.RowsRead)/stats.Elapsed.Seconds(), stats.RowsPerSecond, 0.001)
+
+	// A new run starts with fresh timings
+	require.NoError(t, importer.Initialize(ctx, ImporterConfig{Name: "csv"}))
+	result = importer.NewResult(ImportStats{RowsRead: 10, Elapsed: 2 * time.Second}, nil)
+	assert.Empty(t, result.Stats.Phases)
+	assert.Equal(t, 5.0, result.Stats.RowsPerSecond)
+}
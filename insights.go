@@ -0,0 +1,74 @@
+package firefly
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ZanzyTHEbar/errbuilder-go"
+	"github.com/oapi-codegen/runtime/types"
+)
+
+// InsightModel is the amount spent or earned in one currency for one object, such as a category,
+// between two dates
+type InsightModel struct {
+	ID         string // ID of the object, empty for totals
+	Name       string // Name of the object, empty for totals
+	Currency   string
+	Difference float64 // Negative for expenses, positive for income
+}
+
+// ExpenseInsightsByCategory retrieves the amount spent per category and currency from start to end.
+// Insights are optional in Firefly III; servers without them return an error matching
+// ErrUnsupportedEndpointSentinel.
+func (c *FireflyClient) ExpenseInsightsByCategory(ctx context.Context, start, end time.Time) ([]InsightModel, error) {
+	if end.Before(start) {
+		var errs errbuilder.ErrorMap
+		errs.Set("end", "End must not be before start")
+		return nil, ValidationErr("Insight", errs)
+	}
+
+	// Call the API
+	resp, err := c.clientAPI.InsightExpenseCategoryWithResponse(ctx, &InsightExpenseCategoryParams{
+		Start: types.Date{Time: start},
+		End:   types.Date{Time: end},
+	})
+	if err != nil {
+		return nil, APIErr("Failed to get expense insights", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK {
+		return nil, optionalResponseErr("Insight", resp.HTTPResponse, resp.Body)
+	}
+
+	if len(resp.Body) == 0 {
+		return []InsightModel{}, nil
+	}
+
+	var entries InsightGroup
+	if err := json.Unmarshal(resp.Body, &entries); err != nil {
+		return nil, APIErr("Failed to parse insights response", err)
+	}
+
+	insights := make([]InsightModel, 0, len(entries))
+	for _, entry := range entries {
+		insight := InsightModel{
+			ID:       stringValue(entry.Id),
+			Name:     stringValue(entry.Name),
+			Currency: stringValue(entry.CurrencyCode),
+		}
+		if entry.Difference != nil {
+			difference, err := strconv.ParseFloat(*entry.Difference, 64)
+			if err != nil {
+				return nil, APIErr("Failed to parse insight amount", err)
+			}
+			insight.Difference = difference
+		}
+		insights = append(insights, insight)
+	}
+
+	return insights, nil
+}
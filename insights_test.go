@@ -0,0 +1,82 @@
+package firefly
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZanzyTHEbar/fireflyiii-client-go/fireflytest"
+)
+
+// TestExpenseInsightsByCategory tests reading the expenses per category
+func TestExpenseInsightsByCategory(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/insight/expense/category", http.StatusOK, `[
+		{"id": "3", "name": "Groceries", "difference": "-123.45", "difference_float": -123.45, "currency_id": "1", "currency_code": "EUR"},
+		{"id": "5", "name": "Rent", "difference": "-900", "difference_float": -900, "currency_id": "1", "currency_code": "EUR"}
+	]`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	insights, err := client.ExpenseInsightsByCategory(context.Background(), start, end)
+	require.NoError(t, err)
+
+	assert.Equal(t, []InsightModel{
+		{ID: "3", Name: "Groceries", Currency: "EUR", Difference: -123.45},
+		{ID: "5", Name: "Rent", Currency: "EUR", Difference: -900},
+	}, insights)
+
+	requests := server.RequestsTo(http.MethodGet, "/v1/insight/expense/category")
+	require.Len(t, requests, 1)
+	assert.Equal(t, "2024-03-01", requests[0].Query.Get("start"))
+	assert.Equal(t, "2024-03-31", requests[0].Query.Get("end"))
+}
+
+// TestInsightsUnsupportedEndpoint tests that a missing insights endpoint is reported as unsupported
+// rather than as a missing resource
+func TestInsightsUnsupportedEndpoint(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/insight/expense/category", http.StatusNotFound, `{"message": "Resource not found"}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	_, err = client.ExpenseInsightsByCategory(context.Background(), start, start.AddDate(0, 1, -1))
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrUnsupportedEndpointSentinel)
+	assert.NotErrorIs(t, err, ErrNotFoundSentinel)
+
+	var httpErr *HTTPError
+	require.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusNotFound, httpErr.StatusCode)
+
+	// Other failures keep their usual errors
+	server.Handle(http.MethodGet, "/v1/insight/expense/category", http.StatusUnauthorized, `{"message": "Unauthenticated."}`)
+	_, err = client.ExpenseInsightsByCategory(context.Background(), start, start.AddDate(0, 1, -1))
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrUnauthorizedSentinel)
+	assert.NotErrorIs(t, err, ErrUnsupportedEndpointSentinel)
+}
+
+// TestChartUnsupportedEndpoint tests that servers without the chart endpoint report it as unsupported
+func TestChartUnsupportedEndpoint(t *testing.T) {
+	server := fireflytest.NewServer(t)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	_, err = client.GenerateChart(ChartTypeDefault, ChartPeriodMonthly, start, start.AddDate(0, 1, -1))
+	assert.ErrorIs(t, err, ErrUnsupportedEndpointSentinel)
+	_, err = client.GenerateReport(ReportTypeDefault, start, start.AddDate(0, 1, -1), nil)
+	assert.ErrorIs(t, err, ErrUnsupportedEndpointSentinel)
+}
@@ -0,0 +1,4 @@
+package firefly
+
+// Compile-time check that the client implements the full interface
+var _ FireflyClientInterface = (*FireflyClient)(nil)
@@ -0,0 +1,191 @@
+package firefly
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Page sizes of Firefly III list endpoints
+const (
+	DefaultPageSize = 50 // Used when a list method is called with a limit of 0 or less
+	MaxPageSize     = 50 // Larger limits are clamped to this size
+)
+
+// SortDirection is the direction in which a list is ordered
+type SortDirection string
+
+const (
+	SortAscending  SortDirection = "asc"
+	SortDescending SortDirection = "desc"
+)
+
+// SortField is a field Firefly III can order list results by
+type SortField string
+
+const (
+	SortByDate         SortField = "date"
+	SortByAmount       SortField = "amount"
+	SortByDescription  SortField = "description"
+	SortByName         SortField = "name"
+	SortByOrder        SortField = "order"
+	SortByIBAN         SortField = "iban"
+	SortByBalance      SortField = "balance"
+	SortByLastActivity SortField = "last_activity"
+)
+
+// Sort describes the ordering of a list request
+type Sort struct {
+	Field     SortField
+	Direction SortDirection
+}
+
+// queryValue returns the value of the sort query parameter, prefixing descending fields with "-"
+func (s Sort) queryValue() string {
+	if s.Direction == SortDescending {
+		return "-" + string(s.Field)
+	}
+	return string(s.Field)
+}
+
+// TransactionFilter narrows down and orders the results of ListTransactionsFiltered
+type TransactionFilter struct {
+	Page  int
+	Limit int
+	Start time.Time // Only transactions on or after this date (optional)
+	End   time.Time // Only transactions on or before this date (optional)
+	Type  string    // Transaction type filter, e.g. "withdrawal" (optional)
+	Sort  *Sort     // Ordering of the results (optional)
+}
+
+// AccountFilter narrows down and orders the results of ListAccountsFiltered
+type AccountFilter struct {
+	Page  int
+	Limit int
+	Type  string    // Account type filter, e.g. "asset" (optional)
+	Date  time.Time // Date used to calculate balances (optional)
+	Sort  *Sort     // Ordering of the results (optional)
+}
+
+// sortEditor returns a request editor that adds the sort query parameter when a sort is set
+func sortEditor(sort *Sort) RequestEditorFn {
+	return func(ctx context.Context, req *http.Request) error {
+		if sort == nil || sort.Field == "" {
+			return nil
+		}
+		query := req.URL.Query()
+		query.Set("sort", sort.queryValue())
+		req.URL.RawQuery = query.Encode()
+		return nil
+	}
+}
+
+// optionalDate converts a non-zero time to an API date
+func optionalDate(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// pageParams returns the page and limit query parameters of a list request.
+// Pages start at 1, limits default to DefaultPageSize and are clamped to MaxPageSize.
+func pageParams(page, limit int) (*int32, *int32) {
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+	return int32Ptr(page), int32Ptr(limit)
+}
+
+// listAllPages calls list for consecutive pages of MaxPageSize items until a page is not full
+func listAllPages[T any](ctx context.Context, list func(ctx context.Context, page, limit int) ([]T, error)) ([]T, error) {
+	all := []T{}
+	for page := 1; ; page++ {
+		items, err := list(ctx, page, MaxPageSize)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if len(items) < MaxPageSize {
+			return all, nil
+		}
+	}
+}
+
+// iteratePages calls yield for every item on consecutive pages of MaxPageSize items. It follows
+// meta.pagination until current_page reaches total_pages; without pagination meta it stops at the
+// first page that is not full. The context is checked between pages, and an error from yield
+// stops the iteration and is returned as is.
+func iteratePages[T any](ctx context.Context, list func(ctx context.Context, page, limit int) ([]T, Meta, error), yield func(T) error) error {
+	for page := 1; ; page++ {
+		if page > 1 {
+			if err := ctx.Err(); err != nil {
+				return ContextErr(err)
+			}
+		}
+
+		items, meta, err := list(ctx, page, MaxPageSize)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := yield(item); err != nil {
+				return err
+			}
+		}
+
+		if isLastPage(meta, page, len(items)) {
+			return nil
+		}
+	}
+}
+
+// isLastPage reports whether no page follows the listed page, by the pagination meta of the
+// response or, without one, by whether the page held fewer than MaxPageSize items
+func isLastPage(meta Meta, page, count int) bool {
+	if pagination := meta.Pagination; pagination != nil && pagination.TotalPages != nil {
+		current := page
+		if pagination.CurrentPage != nil {
+			current = *pagination.CurrentPage
+		}
+		return count == 0 || current >= *pagination.TotalPages
+	}
+	return count < MaxPageSize
+}
+
+// decodeListResponse decodes the body of a list response into out while it is read, instead of
+// buffering the whole body and decoding it afterwards. Unsuccessful responses are mapped to their
+// typed error for resource, and name describes the list in parse errors, such as "transactions".
+// It reports false, leaving out unchanged, if the body is empty.
+func decodeListResponse(resp *http.Response, resource, name string, out interface{}) (bool, error) {
+	defer resp.Body.Close()
+
+	// Check response
+	if resp.StatusCode != http.StatusOK {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return false, APIErr("Failed to read "+name+" response", err)
+		}
+		return false, responseErr(resource, resp, body)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		return false, APIErr("Failed to parse "+name+" response", err)
+	}
+
+	// Drain what follows the JSON value so the connection can be reused
+	io.Copy(io.Discard, resp.Body)
+	return true, nil
+}
@@ -0,0 +1,205 @@
+package firefly
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ZanzyTHEbar/errbuilder-go"
+)
+
+// DefaultCurrencyDecimals is the number of decimals used for currencies without a known minor unit
+const DefaultCurrencyDecimals = 2
+
+// currencyDecimals lists the ISO 4217 currencies whose minor unit differs from DefaultCurrencyDecimals
+var currencyDecimals = map[string]int32{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// CurrencyDecimals returns the number of decimals of a currency code.
+// Unknown codes use DefaultCurrencyDecimals.
+func CurrencyDecimals(currencyCode string) int32 {
+	if decimals, ok := currencyDecimals[strings.ToUpper(strings.TrimSpace(currencyCode))]; ok {
+		return decimals
+	}
+	return DefaultCurrencyDecimals
+}
+
+// maxMoneyScale is the largest number of decimals of a Money, so 10^scale fits in an int64
+const maxMoneyScale = 18
+
+// Money is an exact decimal amount as used by the Firefly III API, which sends amounts as strings.
+// The zero value is zero.
+type Money struct {
+	units int64 // The amount scaled by 10^scale
+	scale int32 // The number of decimals
+}
+
+// ParseMoney parses an API amount such as "-12.50" or "1234.560000000000" without losing precision.
+// Trailing zero decimals are dropped. Amounts with more than 18 decimals are rejected.
+func ParseMoney(s string) (Money, error) {
+	invalid := func() (Money, error) {
+		var errs errbuilder.ErrorMap
+		errs.Set("amount", "Invalid amount: "+strconv.Quote(s))
+		return Money{}, ValidationErr("Money", errs)
+	}
+
+	value := strings.TrimSpace(s)
+	negative := false
+	if value != "" && (value[0] == '-' || value[0] == '+') {
+		negative = value[0] == '-'
+		value = value[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	fraction = strings.TrimRight(fraction, "0")
+	if whole == "" && fraction == "" && !strings.Contains(value, "0") {
+		return invalid()
+	}
+	if len(fraction) > maxMoneyScale {
+		return invalid()
+	}
+
+	var units int64
+	for _, r := range whole + fraction {
+		if r < '0' || r > '9' {
+			return invalid()
+		}
+		if units > (math.MaxInt64-int64(r-'0'))/10 {
+			return invalid()
+		}
+		units = units*10 + int64(r-'0')
+	}
+	if negative {
+		units = -units
+	}
+
+	return Money{units: units, scale: int32(len(fraction))}, nil
+}
+
+// MoneyFromFloat converts a float amount using the shortest decimal representation of f, rounded
+// to 18 decimals. NaN, infinities and amounts beyond about ±9.2e18 cannot be represented and
+// give zero; the client rejects such amounts before sending them.
+func MoneyFromFloat(f float64) Money {
+	amount, err := moneyFromFloat(f)
+	if err != nil {
+		return Money{}
+	}
+	return amount
+}
+
+// moneyFromFloat converts a float amount like MoneyFromFloat, returning a validation error for
+// floats Money cannot represent instead of zero
+func moneyFromFloat(f float64) (Money, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		var errs errbuilder.ErrorMap
+		errs.Set("amount", fmt.Sprintf("Invalid amount: %v", f))
+		return Money{}, ValidationErr("Money", errs)
+	}
+
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if _, fraction, _ := strings.Cut(s, "."); len(fraction) > maxMoneyScale {
+		s = strconv.FormatFloat(f, 'f', maxMoneyScale, 64)
+	}
+	return ParseMoney(s)
+}
+
+// FormatMoney formats an amount with the decimals of the currency, rounding half away from zero
+func FormatMoney(amount Money, currencyCode string) string {
+	return amount.Round(CurrencyDecimals(currencyCode)).StringFixed()
+}
+
+// Round returns the amount rounded half away from zero to the given number of decimals,
+// at most 18
+func (m Money) Round(decimals int32) Money {
+	decimals = min(max(decimals, 0), maxMoneyScale)
+	if m.scale <= decimals {
+		return Money{units: m.units * pow10(decimals-m.scale), scale: decimals}
+	}
+
+	divisor := pow10(m.scale - decimals)
+	units, remainder := m.units/divisor, m.units%divisor
+	if remainder*2 >= divisor {
+		units++
+	} else if remainder*2 <= -divisor {
+		units--
+	}
+	return Money{units: units, scale: decimals}
+}
+
+// Add returns the exact sum of two amounts
+func (m Money) Add(other Money) Money {
+	scale := max(m.scale, other.scale)
+	return Money{
+		units: m.units*pow10(scale-m.scale) + other.units*pow10(scale-other.scale),
+		scale: scale,
+	}
+}
+
+// Neg returns the amount with its sign flipped
+func (m Money) Neg() Money {
+	return Money{units: -m.units, scale: m.scale}
+}
+
+// Sign returns -1, 0, or 1 depending on the sign of the amount
+func (m Money) Sign() int {
+	switch {
+	case m.units < 0:
+		return -1
+	case m.units > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Float64 returns the nearest float64 of the amount
+func (m Money) Float64() float64 {
+	f, _ := strconv.ParseFloat(m.String(), 64)
+	return f
+}
+
+// String returns the amount without trailing zero decimals, e.g. "-12.5"
+func (m Money) String() string {
+	s := m.StringFixed()
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+// StringFixed returns the amount with all of its decimals, e.g. "-12.50" after Round(2)
+func (m Money) StringFixed() string {
+	units := m.units
+	sign := ""
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+
+	digits := strconv.FormatInt(units, 10)
+	if m.scale <= 0 {
+		return sign + digits
+	}
+	if pad := int(m.scale) + 1 - len(digits); pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+	split := len(digits) - int(m.scale)
+	return sign + digits[:split] + "." + digits[split:]
+}
+
+// pow10 returns 10^n for 0 <= n <= maxMoneyScale. Money never has more decimals than
+// maxMoneyScale, so a larger n is a bug that would otherwise overflow silently.
+func pow10(n int32) int64 {
+	if n < 0 || n > maxMoneyScale {
+		panic(fmt.Sprintf("firefly: 10^%d does not fit in an int64", n))
+	}
+	result := int64(1)
+	for range n {
+		result *= 10
+	}
+	return result
+}
@@ -0,0 +1,131 @@
+package firefly
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseMoney tests parsing API amounts without losing precision
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   string
+		sign   int
+		float  float64
+		hasErr bool
+	}{
+		{input: "12.50", want: "12.5", sign: 1, float: 12.5},
+		{input: "-12.50", want: "-12.5", sign: -1, float: -12.5},
+		{input: "0", want: "0", sign: 0, float: 0},
+		{input: "-0.000000000000", want: "0", sign: 0, float: 0},
+		{input: "1234.567890000000", want: "1234.56789", sign: 1, float: 1234.56789},
+		{input: "0.1", want: "0.1", sign: 1, float: 0.1},
+		{input: " +7 ", want: "7", sign: 1, float: 7},
+		{input: "", hasErr: true},
+		{input: "-", hasErr: true},
+		{input: ".", hasErr: true},
+		{input: "1.2.3", hasErr: true},
+		{input: "1e5", hasErr: true},
+		{input: "abc", hasErr: true},
+		{input: "99999999999999999999", hasErr: true},
+		{input: "0.000000000000000001", want: "0.000000000000000001", sign: 1, float: 1e-18},
+		{input: "0.0000000000000000001", hasErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			amount, err := ParseMoney(tt.input)
+			if tt.hasErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, amount.String())
+			assert.Equal(t, tt.sign, amount.Sign())
+			assert.Equal(t, tt.float, amount.Float64())
+		})
+	}
+}
+
+// TestFormatMoney tests formatting with the decimals of the currency
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		want     string
+	}{
+		{amount: "12.5", currency: "EUR", want: "12.50"},
+		{amount: "-12.5", currency: "EUR", want: "-12.50"},
+		{amount: "0", currency: "EUR", want: "0.00"},
+		{amount: "1.005", currency: "USD", want: "1.01"},
+		{amount: "-1.005", currency: "USD", want: "-1.01"},
+		{amount: "-0.004", currency: "USD", want: "0.00"},
+		{amount: "1234.56789", currency: "", want: "1234.57"},
+		{amount: "1500.5", currency: "JPY", want: "1501"},
+		{amount: "1.2345", currency: "kwd", want: "1.235"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.amount+" "+tt.currency, func(t *testing.T) {
+			amount, err := ParseMoney(tt.amount)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, FormatMoney(amount, tt.currency))
+		})
+	}
+
+	// Float amounts format like their decimal representation
+	assert.Equal(t, "-42.00", FormatMoney(MoneyFromFloat(-42), "EUR"))
+}
+
+// TestMoneyArithmetic tests exact addition across scales
+func TestMoneyArithmetic(t *testing.T) {
+	a, err := ParseMoney("0.1")
+	require.NoError(t, err)
+	b, err := ParseMoney("0.25")
+	require.NoError(t, err)
+
+	assert.Equal(t, "0.35", a.Add(b).String())
+	assert.Equal(t, "-0.15", a.Add(b.Neg()).String())
+	assert.Equal(t, "0.1", Money{}.Add(a).String())
+	assert.Equal(t, 0, a.Add(a.Neg()).Sign())
+
+	// The largest scale still adds and rounds exactly
+	tiny, err := ParseMoney("0.000000000000000001")
+	require.NoError(t, err)
+	assert.Equal(t, "0.100000000000000001", a.Add(tiny).String())
+	assert.Equal(t, "0.10", a.Add(tiny).Round(2).StringFixed())
+	assert.Equal(t, "0.100000000000000000", a.Round(30).StringFixed(), "rounding is capped at 18 decimals")
+}
+
+// TestMoneyFromFloat tests converting floats, including the ones Money cannot represent
+func TestMoneyFromFloat(t *testing.T) {
+	assert.Equal(t, "12.5", MoneyFromFloat(12.5).String())
+	assert.Equal(t, "0.000000000000000001", MoneyFromFloat(1.2e-18).String(), "tiny floats are rounded to 18 decimals")
+
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1), 1e19, -1e19} {
+		_, err := moneyFromFloat(f)
+		assert.Error(t, err, "%v", f)
+		assert.Zero(t, MoneyFromFloat(f).Sign())
+	}
+}
+
+// TestValidateTransactionAmount tests that amounts the API cannot receive are rejected before sending
+func TestValidateTransactionAmount(t *testing.T) {
+	valid := TransactionModel{Amount: 10, Currency: "EUR", Description: "Coffee", TransType: "withdrawal", Date: time.Now()}
+	assert.Nil(t, validateTransaction(valid))
+
+	for _, amount := range []float64{0, -1, math.NaN(), math.Inf(1), math.Inf(-1), 1e19} {
+		tx := valid
+		tx.Amount = amount
+		errs := validateTransaction(tx)
+		assert.Contains(t, errs, "amount", "%v", amount)
+	}
+
+	tx := valid
+	tx.ForeignAmount = float64Ptr(math.NaN())
+	assert.Contains(t, validateTransaction(tx), "foreign_amount")
+}
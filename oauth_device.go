@@ -0,0 +1,188 @@
+package firefly
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// devicePollInterval is the default wait between device token polls (RFC 8628 section 3.2)
+var devicePollInterval = 5 * time.Second
+
+// DeviceAuthResponse holds the device authorization response of the OAuth2 device flow (RFC 8628)
+type DeviceAuthResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval,omitempty"`
+}
+
+// deviceTokenError is the error body of a device token poll
+type deviceTokenError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// StartDeviceAuth starts the OAuth2 device authorization flow.
+// Show the returned user code and verification URI to the user, then call PollDeviceToken.
+func (c *FireflyClient) StartDeviceAuth(ctx context.Context) (*DeviceAuthResponse, error) {
+	if c.config == nil || c.config.OAuth2 == nil {
+		return nil, OAuth2Err(&OAuth2Error{
+			ErrorCode:        "oauth2_not_configured",
+			ErrorDescription: "OAuth2 configuration is missing",
+		})
+	}
+
+	oauth2Config := c.config.OAuth2
+	if oauth2Config.ClientID == "" || oauth2Config.DeviceAuthURL == "" || oauth2Config.TokenURL == "" {
+		return nil, OAuth2Err(&OAuth2Error{
+			ErrorCode:        "oauth2_configuration_incomplete",
+			ErrorDescription: "client_id, device_auth_url, and token_url are required",
+		})
+	}
+
+	form := url.Values{"client_id": {oauth2Config.ClientID}}
+	if len(oauth2Config.Scopes) > 0 {
+		form.Set("scope", strings.Join(oauth2Config.Scopes, " "))
+	}
+
+	status, body, err := c.postOAuth2Form(ctx, oauth2Config.DeviceAuthURL, form)
+	if err != nil {
+		return nil, OAuth2Err(&OAuth2Error{
+			ErrorCode:        "device_auth_failed",
+			ErrorDescription: "Failed to start device authorization: " + err.Error(),
+		})
+	}
+	if status != http.StatusOK {
+		var tokenErr deviceTokenError
+		_ = json.Unmarshal(body, &tokenErr)
+		return nil, OAuth2Err(&OAuth2Error{
+			ErrorCode:        "device_auth_failed",
+			ErrorDescription: "Device authorization endpoint returned " + http.StatusText(status) + " " + tokenErr.Error,
+		})
+	}
+
+	var response DeviceAuthResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, OAuth2Err(&OAuth2Error{
+			ErrorCode:        "device_auth_failed",
+			ErrorDescription: "Failed to parse device authorization response: " + err.Error(),
+		})
+	}
+	if response.DeviceCode == "" {
+		return nil, OAuth2Err(&OAuth2Error{
+			ErrorCode:        "device_auth_failed",
+			ErrorDescription: "Device authorization response has no device_code",
+		})
+	}
+
+	// Remember the server's poll interval for PollDeviceToken
+	if response.Interval > 0 {
+		c.deviceIntervals.Store(response.DeviceCode, time.Duration(response.Interval)*time.Second)
+	}
+
+	return &response, nil
+}
+
+// PollDeviceToken polls the token endpoint until the user approved the device code from StartDeviceAuth.
+// It waits between polls as RFC 8628 requires and fails when the user denies access or the code expires.
+func (c *FireflyClient) PollDeviceToken(ctx context.Context, deviceCode string) (*OAuth2TokenResponse, error) {
+	if c.config == nil || c.config.OAuth2 == nil {
+		return nil, OAuth2Err(&OAuth2Error{
+			ErrorCode:        "oauth2_not_configured",
+			ErrorDescription: "OAuth2 configuration is missing",
+		})
+	}
+
+	oauth2Config := c.config.OAuth2
+	form := url.Values{
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		"device_code": {deviceCode},
+		"client_id":   {oauth2Config.ClientID},
+	}
+	if oauth2Config.ClientSecret != "" {
+		form.Set("client_secret", oauth2Config.ClientSecret)
+	}
+
+	interval := devicePollInterval
+	if stored, ok := c.deviceIntervals.Load(deviceCode); ok {
+		interval = stored.(time.Duration)
+	}
+	defer c.deviceIntervals.Delete(deviceCode)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ContextErr(ctx.Err())
+		case <-time.After(interval):
+		}
+
+		status, body, err := c.postOAuth2Form(ctx, oauth2Config.TokenURL, form)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ContextErr(ctxErr)
+			}
+			return nil, OAuth2Err(&OAuth2Error{
+				ErrorCode:        "token_request_failed",
+				ErrorDescription: "Failed to poll device token: " + err.Error(),
+			})
+		}
+
+		if status == http.StatusOK {
+			var token OAuth2TokenResponse
+			if err := json.Unmarshal(body, &token); err != nil {
+				return nil, OAuth2Err(&OAuth2Error{
+					ErrorCode:        "token_request_failed",
+					ErrorDescription: "Failed to parse device token response: " + err.Error(),
+				})
+			}
+			return &token, nil
+		}
+
+		var tokenErr deviceTokenError
+		_ = json.Unmarshal(body, &tokenErr)
+		switch tokenErr.Error {
+		case "authorization_pending":
+			// The user has not approved yet
+		case "slow_down":
+			interval += 5 * time.Second
+		default:
+			errorCode := tokenErr.Error
+			if errorCode == "" {
+				errorCode = "token_request_failed"
+			}
+			return nil, OAuth2Err(&OAuth2Error{
+				ErrorCode:        errorCode,
+				ErrorDescription: tokenErr.ErrorDescription,
+			})
+		}
+	}
+}
+
+// postOAuth2Form posts a form to an OAuth2 endpoint, bypassing the API transport
+func (c *FireflyClient) postOAuth2Form(ctx context.Context, endpoint string, form url.Values) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := (&http.Client{Timeout: c.config.Timeout}).Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, body, nil
+}
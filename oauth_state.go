@@ -0,0 +1,56 @@
+package firefly
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultOAuth2StateTTL is how long a state issued by GenerateOAuth2AuthURL stays valid
+const DefaultOAuth2StateTTL = 10 * time.Minute
+
+// oauthStateStore remembers the OAuth2 states issued by the client so callbacks can be checked against them
+type oauthStateStore struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	states map[string]time.Time // state -> expiry
+	now    func() time.Time
+}
+
+// newOAuthStateStore creates a state store whose states expire after ttl
+func newOAuthStateStore(ttl time.Duration) *oauthStateStore {
+	if ttl <= 0 {
+		ttl = DefaultOAuth2StateTTL
+	}
+	return &oauthStateStore{
+		ttl:    ttl,
+		states: make(map[string]time.Time),
+		now:    time.Now,
+	}
+}
+
+// Add records an issued state and drops expired ones
+func (s *oauthStateStore) Add(state string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for issued, expiry := range s.states {
+		if now.After(expiry) {
+			delete(s.states, issued)
+		}
+	}
+	s.states[state] = now.Add(s.ttl)
+}
+
+// Consume reports whether state was issued and has not expired. A state can only be consumed once.
+func (s *oauthStateStore) Consume(state string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiry, ok := s.states[state]
+	if !ok {
+		return false
+	}
+	delete(s.states, state)
+	return !s.now().After(expiry)
+}
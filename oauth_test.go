@@ -2,11 +2,13 @@ package firefly
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"golang.org/x/oauth2"
 	"golang.org/x/time/rate"
 
 	"github.com/stretchr/testify/assert"
@@ -301,6 +303,118 @@ func TestWebhookManager(t *testing.T) {
 	})
 }
 
+// TestWebhookDeduplication tests that redelivered events are dropped within the window and processed after it
+func TestWebhookDeduplication(t *testing.T) {
+	ctx := context.Background()
+	payload := []byte(`{"id": "event-1", "type": "transaction.created", "data": {"transaction_id": "123"}}`)
+
+	newManager := func(config WebhookDedupConfig) (*WebhookManager, *int) {
+		manager := NewWebhookManager()
+		manager.SetDeduplication(config)
+		calls := 0
+		manager.RegisterHandlerFunc("transaction.created", func(ctx context.Context, event *WebhookEvent) error {
+			calls++
+			return nil
+		})
+		return manager, &calls
+	}
+
+	t.Run("within window", func(t *testing.T) {
+		manager, calls := newManager(WebhookDedupConfig{Window: time.Hour})
+		require.NoError(t, manager.ProcessWebhook(ctx, payload))
+		require.NoError(t, manager.ProcessWebhook(ctx, payload))
+		assert.Equal(t, 1, *calls)
+
+		// A different event is still processed
+		require.NoError(t, manager.ProcessWebhook(ctx, []byte(`{"id": "event-2", "type": "transaction.created"}`)))
+		assert.Equal(t, 2, *calls)
+	})
+
+	t.Run("outside window", func(t *testing.T) {
+		manager, calls := newManager(WebhookDedupConfig{Window: time.Hour})
+		require.NoError(t, manager.ProcessWebhook(ctx, payload))
+
+		manager.dedup.now = func() time.Time { return time.Now().Add(time.Hour + time.Minute) }
+		require.NoError(t, manager.ProcessWebhook(ctx, payload))
+		assert.Equal(t, 2, *calls)
+	})
+
+	t.Run("body hash", func(t *testing.T) {
+		withoutID := []byte(`{"type": "transaction.created", "data": {"transaction_id": "123"}}`)
+
+		manager, calls := newManager(WebhookDedupConfig{Window: time.Hour})
+		require.NoError(t, manager.ProcessWebhook(ctx, withoutID))
+		require.NoError(t, manager.ProcessWebhook(ctx, withoutID))
+		assert.Equal(t, 2, *calls, "events without an ID are only deduplicated by body hash")
+
+		manager, calls = newManager(WebhookDedupConfig{Window: time.Hour, ByBodyHash: true})
+		require.NoError(t, manager.ProcessWebhook(ctx, withoutID))
+		require.NoError(t, manager.ProcessWebhook(ctx, withoutID))
+		assert.Equal(t, 1, *calls)
+	})
+
+	t.Run("max entries", func(t *testing.T) {
+		manager, calls := newManager(WebhookDedupConfig{Window: time.Hour, MaxEntries: 1})
+		require.NoError(t, manager.ProcessWebhook(ctx, payload))
+		require.NoError(t, manager.ProcessWebhook(ctx, []byte(`{"id": "event-2", "type": "transaction.created"}`)))
+		require.NoError(t, manager.ProcessWebhook(ctx, payload))
+		assert.Equal(t, 3, *calls, "the oldest delivery is forgotten first")
+	})
+
+	t.Run("failed events are retried", func(t *testing.T) {
+		manager := NewWebhookManager()
+		manager.SetDeduplication(WebhookDedupConfig{Window: time.Hour})
+		calls := 0
+		manager.RegisterHandlerFunc("transaction.created", func(ctx context.Context, event *WebhookEvent) error {
+			calls++
+			if calls == 1 {
+				return fmt.Errorf("temporary failure")
+			}
+			return nil
+		})
+
+		assert.Error(t, manager.ProcessWebhook(ctx, payload))
+		require.NoError(t, manager.ProcessWebhook(ctx, payload))
+		require.NoError(t, manager.ProcessWebhook(ctx, payload))
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("retried events are evicted in delivery order", func(t *testing.T) {
+		manager := NewWebhookManager()
+		manager.SetDeduplication(WebhookDedupConfig{Window: time.Hour, MaxEntries: 3})
+		calls := map[string]int{}
+		manager.RegisterHandlerFunc("transaction.created", func(ctx context.Context, event *WebhookEvent) error {
+			calls[event.ID]++
+			if event.ID == "event-1" && calls[event.ID] == 1 {
+				return fmt.Errorf("temporary failure")
+			}
+			return nil
+		})
+		event := func(id string) []byte {
+			return []byte(`{"id": "` + id + `", "type": "transaction.created"}`)
+		}
+
+		require.NoError(t, manager.ProcessWebhook(ctx, event("event-a")))
+		assert.Error(t, manager.ProcessWebhook(ctx, event("event-1")))
+		require.NoError(t, manager.ProcessWebhook(ctx, event("event-b")))
+		require.NoError(t, manager.ProcessWebhook(ctx, event("event-1")))
+
+		// The retried event-1 counts from its redelivery, so event-b is forgotten before it
+		require.NoError(t, manager.ProcessWebhook(ctx, event("event-c")))
+		require.NoError(t, manager.ProcessWebhook(ctx, event("event-d")))
+		require.NoError(t, manager.ProcessWebhook(ctx, event("event-1")))
+		assert.Equal(t, 2, calls["event-1"])
+		assert.Equal(t, []string{"id:event-1", "id:event-c", "id:event-d"}, manager.dedup.order)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		manager, calls := newManager(WebhookDedupConfig{})
+		require.NoError(t, manager.ProcessWebhook(ctx, payload))
+		require.NoError(t, manager.ProcessWebhook(ctx, payload))
+		assert.Equal(t, 2, *calls)
+	})
+}
+
 func TestFireflyClientAdvancedFeatures(t *testing.T) {
 	t.Run("NewFireflyClientWithConfig", func(t *testing.T) {
 		config := DefaultClientConfig()
@@ -364,6 +478,54 @@ func TestFireflyClientAdvancedFeatures(t *testing.T) {
 	})
 }
 
+// TestLogsNeverContainToken tests that logged requests and errors carry neither the bearer token
+// nor credentials in query parameters
+func TestLogsNeverContainToken(t *testing.T) {
+	const token = "secret-token-1234567890"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Authorization", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	var logged []string
+	logger := func(format string, args ...interface{}) {
+		logged = append(logged, fmt.Sprintf(format, args...))
+	}
+
+	client, err := NewFireflyClient(server.URL, token)
+	require.NoError(t, err)
+	retryConfig := DefaultRetryConfig()
+	retryConfig.InitialDelay = time.Millisecond
+	retryConfig.MaxDelay = time.Millisecond
+	client.AddMiddleware(NewLoggingMiddleware(logger))
+	client.AddMiddleware(NewRetryMiddleware(retryConfig))
+	ctx := context.Background()
+
+	_, err = client.Do(ctx, http.MethodGet, "/v1/about?access_token="+token+"&page=2", nil, nil)
+	require.Error(t, err)
+	logged = append(logged, err.Error())
+
+	_, err = client.GetAccount(ctx, "1")
+	require.Error(t, err)
+	logged = append(logged, err.Error())
+
+	// Transport errors quote the request URL
+	server.Close()
+	_, err = client.Do(ctx, http.MethodGet, "/v1/about?access_token="+token, nil, nil)
+	require.Error(t, err)
+	logged = append(logged, err.Error())
+
+	require.NotEmpty(t, logged)
+	for _, line := range logged {
+		assert.NotContains(t, line, token)
+		assert.NotContains(t, line, token[:8])
+	}
+	assert.Contains(t, logged[0], "access_token=REDACTED")
+	assert.Contains(t, logged[0], "page=2")
+}
+
 func TestOAuth2Methods(t *testing.T) {
 	t.Run("GenerateOAuth2AuthURL", func(t *testing.T) {
 		config := DefaultClientConfig()
@@ -425,3 +587,433 @@ func TestOAuth2Methods(t *testing.T) {
 		assert.Contains(t, err.Error(), "OAuth2 Error")
 	})
 }
+
+func TestOAuth2TokenOnManualRequests(t *testing.T) {
+	var tokenRequests int
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token": "oauth-access-token", "token_type": "Bearer", "expires_in": 3600}`))
+	}))
+	defer tokenServer.Close()
+
+	var authHeaders []string
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		w.Write([]byte("chart"))
+	}))
+	defer apiServer.Close()
+
+	config := DefaultClientConfig().WithOAuth2(OAuth2Config{
+		ClientID:     "test-client",
+		ClientSecret: "test-secret",
+		TokenURL:     tokenServer.URL,
+	})
+	config.BaseURL = apiServer.URL
+	config.Token = "static-token"
+
+	client, err := NewFireflyClientWithConfig(config)
+	require.NoError(t, err)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		chart, err := client.GenerateChart(ChartTypeDefault, ChartPeriodMonthly, start, start.AddDate(0, 1, 0))
+		require.NoError(t, err)
+		assert.Equal(t, "chart", string(chart))
+	}
+
+	// The OAuth2 token replaces the static token and is reused while valid
+	assert.Equal(t, []string{"Bearer oauth-access-token", "Bearer oauth-access-token"}, authHeaders)
+	assert.Equal(t, 1, tokenRequests)
+}
+
+// TestOAuth2RefreshBeforeExpiry tests that short-lived tokens are replaced before they expire
+func TestOAuth2RefreshBeforeExpiry(t *testing.T) {
+	var tokenRequests int
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token": "token-%d", "token_type": "Bearer", "expires_in": 120}`, tokenRequests)
+	}))
+	defer tokenServer.Close()
+
+	var authHeaders []string
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"id": "1", "type": "accounts", "attributes": {"name": "Checking", "type": "asset"}}}`))
+	}))
+	defer apiServer.Close()
+
+	config := DefaultClientConfig().WithOAuth2(OAuth2Config{
+		ClientID:     "test-client",
+		ClientSecret: "test-secret",
+		TokenURL:     tokenServer.URL,
+	})
+	config.BaseURL = apiServer.URL
+	client, err := NewFireflyClientWithConfig(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	now := time.Now()
+	client.tokenSource.now = func() time.Time { return now }
+
+	_, err = client.GetAccount(ctx, "1")
+	require.NoError(t, err)
+	_, err = client.GetAccount(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, tokenRequests, "the token is cached while it has more than a minute left")
+
+	// Less than a minute before the token expires it is replaced
+	now = now.Add(61 * time.Second)
+	_, err = client.GetAccount(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, tokenRequests)
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-1", "Bearer token-2"}, authHeaders)
+
+	// The new token expires two minutes after it was issued
+	now = time.Now()
+	token, err := client.CurrentToken(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", token)
+}
+
+// TestOAuth2StoredRefreshToken tests that a configured refresh token is redeemed for access tokens
+// and that rotated refresh tokens are used for the next refresh
+func TestOAuth2StoredRefreshToken(t *testing.T) {
+	var grants, refreshTokens []string
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		grants = append(grants, r.PostForm.Get("grant_type"))
+		refreshTokens = append(refreshTokens, r.PostForm.Get("refresh_token"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token": "token-%d", "token_type": "Bearer", "refresh_token": "refresh-%d", "expires_in": 30}`, len(grants), len(grants))
+	}))
+	defer tokenServer.Close()
+
+	var authHeaders []string
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"id": "1", "type": "accounts", "attributes": {"name": "Checking", "type": "asset"}}}`))
+	}))
+	defer apiServer.Close()
+
+	var refreshed []string
+	config := DefaultClientConfig().
+		WithOAuth2(OAuth2Config{
+			ClientID:     "test-client",
+			TokenURL:     tokenServer.URL,
+			RefreshToken: "stored-refresh",
+		}).
+		WithOnTokenRefresh(func(token *oauth2.Token) {
+			refreshed = append(refreshed, token.RefreshToken)
+		})
+	config.BaseURL = apiServer.URL
+	client, err := NewFireflyClientWithConfig(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Tokens expiring within a minute are refreshed for every request
+	for i := 0; i < 2; i++ {
+		_, err = client.GetAccount(ctx, "1")
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{"refresh_token", "refresh_token"}, grants)
+	assert.Equal(t, []string{"stored-refresh", "refresh-1"}, refreshTokens)
+	assert.Equal(t, []string{"refresh-1", "refresh-2"}, refreshed)
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-2"}, authHeaders)
+}
+
+// TestOAuth2RefreshOnUnauthorized tests that a 401 refreshes the token once and retries the request
+func TestOAuth2RefreshOnUnauthorized(t *testing.T) {
+	var tokenRequests int
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token": "token-%d", "token_type": "Bearer", "expires_in": 3600}`, tokenRequests)
+	}))
+	defer tokenServer.Close()
+
+	var authHeaders []string
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"id": "1", "type": "tags", "attributes": {"tag": "groceries"}}}`))
+	}))
+	defer apiServer.Close()
+
+	config := DefaultClientConfig().WithOAuth2(OAuth2Config{
+		ClientID:     "test-client",
+		ClientSecret: "test-secret",
+		TokenURL:     tokenServer.URL,
+	})
+	config.BaseURL = apiServer.URL
+
+	client, err := NewFireflyClientWithConfig(config)
+	require.NoError(t, err)
+
+	tag, err := client.GetTag("1")
+	require.NoError(t, err)
+	assert.Equal(t, "groceries", tag.Attributes.Tag)
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-2"}, authHeaders)
+	assert.Equal(t, 2, tokenRequests)
+
+	t.Run("refresh fails", func(t *testing.T) {
+		// The first token is rejected by the API and every later token request fails
+		var tokenRequests int
+		failingTokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tokenRequests++
+			w.Header().Set("Content-Type", "application/json")
+			if tokenRequests > 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error": "invalid_client"}`))
+				return
+			}
+			w.Write([]byte(`{"access_token": "stale", "token_type": "Bearer", "expires_in": 3600}`))
+		}))
+		defer failingTokenServer.Close()
+
+		config := DefaultClientConfig().WithOAuth2(OAuth2Config{
+			ClientID:     "test-client",
+			ClientSecret: "test-secret",
+			TokenURL:     failingTokenServer.URL,
+		})
+		config.BaseURL = apiServer.URL
+
+		client, err := NewFireflyClientWithConfig(config)
+		require.NoError(t, err)
+
+		_, err = client.GetTag("1")
+		assert.Error(t, err)
+		assert.Equal(t, 2, tokenRequests)
+	})
+}
+
+// TestOAuth2StateValidation tests that code exchange only accepts states issued by the client
+func TestOAuth2StateValidation(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token": "user-token", "token_type": "Bearer", "refresh_token": "refresh", "expires_in": 3600}`))
+	}))
+	defer tokenServer.Close()
+
+	newClient := func(t *testing.T) *FireflyClient {
+		config := DefaultClientConfig()
+		config.BaseURL = "https://example.com"
+		config.OAuth2 = &OAuth2Config{
+			ClientID:    "test-client",
+			AuthURL:     "https://example.com/auth",
+			TokenURL:    tokenServer.URL,
+			RedirectURL: "http://localhost:8080/callback",
+		}
+		client, err := NewFireflyClientWithConfig(config)
+		require.NoError(t, err)
+		return client
+	}
+	ctx := context.Background()
+
+	t.Run("valid state", func(t *testing.T) {
+		client := newClient(t)
+		_, err := client.GenerateOAuth2AuthURL("issued-state")
+		require.NoError(t, err)
+
+		token, err := client.ExchangeOAuth2Code(ctx, "code", "issued-state")
+		require.NoError(t, err)
+		assert.Equal(t, "user-token", token.AccessToken)
+
+		// A state can only be used once
+		_, err = client.ExchangeOAuth2Code(ctx, "code", "issued-state")
+		assert.Error(t, err)
+	})
+
+	t.Run("forged state", func(t *testing.T) {
+		client := newClient(t)
+		_, err := client.GenerateOAuth2AuthURL("issued-state")
+		require.NoError(t, err)
+
+		_, err = client.ExchangeOAuth2Code(ctx, "code", "forged-state")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid_state")
+	})
+
+	t.Run("expired state", func(t *testing.T) {
+		client := newClient(t)
+		_, err := client.GenerateOAuth2AuthURL("issued-state")
+		require.NoError(t, err)
+
+		client.oauthStates.now = func() time.Time { return time.Now().Add(DefaultOAuth2StateTTL + time.Minute) }
+		_, err = client.ExchangeOAuth2Code(ctx, "code", "issued-state")
+		assert.Error(t, err)
+	})
+}
+
+// TestOAuth2OnTokenRefresh tests that the refresh callback sees every new token
+func TestOAuth2OnTokenRefresh(t *testing.T) {
+	var tokenRequests int
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token": "token-%d", "token_type": "Bearer", "refresh_token": "refresh-%d", "expires_in": 3600}`, tokenRequests, tokenRequests)
+	}))
+	defer tokenServer.Close()
+
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer token-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("chart"))
+	}))
+	defer apiServer.Close()
+
+	var refreshed []*oauth2.Token
+	config := DefaultClientConfig().
+		WithOAuth2(OAuth2Config{
+			ClientID:     "test-client",
+			ClientSecret: "test-secret",
+			TokenURL:     tokenServer.URL,
+		}).
+		WithOnTokenRefresh(func(token *oauth2.Token) {
+			refreshed = append(refreshed, token)
+		})
+	config.BaseURL = apiServer.URL
+
+	client, err := NewFireflyClientWithConfig(config)
+	require.NoError(t, err)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		_, err = client.GenerateChart(ChartTypeDefault, ChartPeriodMonthly, start, start.AddDate(0, 1, 0))
+		require.NoError(t, err)
+	}
+
+	// The initial token and the one fetched after the 401; the cached token is not reported again
+	require.Len(t, refreshed, 2)
+	assert.Equal(t, "token-1", refreshed[0].AccessToken)
+	assert.Equal(t, "token-2", refreshed[1].AccessToken)
+	assert.Equal(t, "refresh-2", refreshed[1].RefreshToken)
+}
+
+// TestOAuth2DeviceFlow tests the device authorization flow against a mock server
+func TestOAuth2DeviceFlow(t *testing.T) {
+	defaultInterval := devicePollInterval
+	devicePollInterval = 10 * time.Millisecond
+	defer func() { devicePollInterval = defaultInterval }()
+
+	var polls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/device":
+			assert.Equal(t, "test-client", r.PostForm.Get("client_id"))
+			w.Write([]byte(`{"device_code": "device-123", "user_code": "ABCD-EFGH", "verification_uri": "https://example.com/device", "expires_in": 600}`))
+		case "/token":
+			assert.Equal(t, "urn:ietf:params:oauth:grant-type:device_code", r.PostForm.Get("grant_type"))
+			assert.Equal(t, "device-123", r.PostForm.Get("device_code"))
+			polls++
+			if polls < 3 {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error": "authorization_pending"}`))
+				return
+			}
+			w.Write([]byte(`{"access_token": "device-token", "token_type": "Bearer", "refresh_token": "device-refresh", "expires_in": 3600}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultClientConfig()
+	config.BaseURL = "https://example.com"
+	config.OAuth2 = &OAuth2Config{
+		ClientID:      "test-client",
+		DeviceAuthURL: server.URL + "/device",
+		TokenURL:      server.URL + "/token",
+	}
+	client, err := NewFireflyClientWithConfig(config)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	auth, err := client.StartDeviceAuth(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "ABCD-EFGH", auth.UserCode)
+	assert.Equal(t, "https://example.com/device", auth.VerificationURI)
+
+	token, err := client.PollDeviceToken(ctx, auth.DeviceCode)
+	require.NoError(t, err)
+	assert.Equal(t, "device-token", token.AccessToken)
+	assert.Equal(t, "device-refresh", token.RefreshToken)
+	assert.Equal(t, 3, polls)
+
+	t.Run("access denied", func(t *testing.T) {
+		deniedServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "access_denied"}`))
+		}))
+		defer deniedServer.Close()
+
+		config.OAuth2.TokenURL = deniedServer.URL
+		_, err := client.PollDeviceToken(ctx, "device-123")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "access_denied")
+	})
+}
+
+// TestCurrentToken tests reading the effective access token for static and OAuth2 clients
+func TestCurrentToken(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("static token", func(t *testing.T) {
+		client, err := NewFireflyClient("http://localhost:8080", "static-token")
+		require.NoError(t, err)
+
+		token, err := client.CurrentToken(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "static-token", token)
+	})
+
+	t.Run("no token", func(t *testing.T) {
+		client, err := NewFireflyClient("http://localhost:8080", "")
+		require.NoError(t, err)
+
+		_, err = client.CurrentToken(ctx)
+		assert.Error(t, err)
+	})
+
+	t.Run("OAuth2 token", func(t *testing.T) {
+		var tokenRequests int
+		tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tokenRequests++
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"access_token": "oauth-access-token", "token_type": "Bearer", "expires_in": 3600}`))
+		}))
+		defer tokenServer.Close()
+
+		config := DefaultClientConfig().WithOAuth2(OAuth2Config{
+			ClientID:     "test-client",
+			ClientSecret: "test-secret",
+			TokenURL:     tokenServer.URL,
+		})
+		config.BaseURL = "https://example.com"
+		config.Token = "static-token"
+
+		client, err := NewFireflyClientWithConfig(config)
+		require.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			token, err := client.CurrentToken(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, "oauth-access-token", token)
+		}
+		assert.Equal(t, 1, tokenRequests)
+	})
+}
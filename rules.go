@@ -0,0 +1,361 @@
+package firefly
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ZanzyTHEbar/errbuilder-go"
+)
+
+// triggerParams validates the date range and account IDs of a rule trigger and converts them
+// to query parameters. Start and end must both be set or both be zero.
+func triggerParams(resource string, start, end time.Time, accountIDs []string) (*time.Time, *time.Time, *[]int64, error) {
+	var errs errbuilder.ErrorMap
+	if start.IsZero() != end.IsZero() {
+		errs.Set("start", "Start and end must both be set or both be empty")
+	}
+	if !start.IsZero() && end.Before(start) {
+		errs.Set("end", "End must not be before start")
+	}
+
+	var accounts *[]int64
+	if len(accountIDs) > 0 {
+		ids := make([]int64, 0, len(accountIDs))
+		for i, id := range accountIDs {
+			value, err := strconv.ParseInt(id, 10, 64)
+			if err != nil {
+				errs.Set(fmt.Sprintf("accountIDs[%d]", i), fmt.Sprintf("Invalid account ID %q", id))
+				continue
+			}
+			ids = append(ids, value)
+		}
+		accounts = &ids
+	}
+
+	if errs != nil {
+		return nil, nil, nil, ValidationErr(resource, errs)
+	}
+	return optionalDate(start), optionalDate(end), accounts, nil
+}
+
+// TriggerRuleGroup applies the rules of a rule group to the existing transactions from start to end.
+// Zero dates let Firefly III choose the range. accountIDs optionally limits the run to transactions
+// of these asset or liability accounts.
+func (c *FireflyClient) TriggerRuleGroup(ctx context.Context, groupID string, start, end time.Time, accountIDs ...string) error {
+	from, to, accounts, err := triggerParams("RuleGroup", start, end, accountIDs)
+	if err != nil {
+		return err
+	}
+
+	// Call the API
+	resp, err := c.clientAPI.FireRuleGroupWithResponse(ctx, groupID, &FireRuleGroupParams{
+		Start:    dateToAPIDate(from),
+		End:      dateToAPIDate(to),
+		Accounts: accounts,
+	})
+	if err != nil {
+		return APIErr("Failed to trigger rule group", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusNoContent && resp.StatusCode() != http.StatusOK {
+		return responseErr("Rule group", resp.HTTPResponse, resp.Body)
+	}
+
+	return nil
+}
+
+// TriggerRule applies a rule to the existing transactions from start to end, like TriggerRuleGroup
+func (c *FireflyClient) TriggerRule(ctx context.Context, ruleID string, start, end time.Time, accountIDs ...string) error {
+	from, to, accounts, err := triggerParams("Rule", start, end, accountIDs)
+	if err != nil {
+		return err
+	}
+
+	// Call the API
+	resp, err := c.clientAPI.FireRuleWithResponse(ctx, ruleID, &FireRuleParams{
+		Start:    dateToAPIDate(from),
+		End:      dateToAPIDate(to),
+		Accounts: accounts,
+	})
+	if err != nil {
+		return APIErr("Failed to trigger rule", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusNoContent && resp.StatusCode() != http.StatusOK {
+		return responseErr("Rule", resp.HTTPResponse, resp.Body)
+	}
+
+	return nil
+}
+
+// RescanBill re-matches the transactions from start to end against a bill. Firefly III links
+// transactions to bills through rules and has no rescan endpoint, so this triggers every rule
+// of the bill over the date range. Bills without rules are left as they are.
+func (c *FireflyClient) RescanBill(ctx context.Context, billID string, start, end time.Time) error {
+	if _, _, _, err := triggerParams("Bill", start, end, nil); err != nil {
+		return err
+	}
+
+	// Call the API
+	resp, err := c.clientAPI.ListRuleByBillWithResponse(ctx, billID, &ListRuleByBillParams{})
+	if err != nil {
+		return APIErr("Failed to list bill rules", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK {
+		return responseErr("Bill", resp.HTTPResponse, resp.Body)
+	}
+	if resp.HTTPResponse == nil || len(resp.Body) == 0 {
+		return nil
+	}
+
+	var apiResp RuleArray
+	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
+		return APIErr("Failed to parse bill rules response", err)
+	}
+
+	for _, rule := range apiResp.Data {
+		if err := c.TriggerRule(ctx, rule.Id, start, end); err != nil {
+			return fmt.Errorf("failed to rescan bill %s with rule %s: %w", billID, rule.Id, err)
+		}
+	}
+
+	return nil
+}
+
+// RuleGroupModel represents a Firefly III rule group in our domain model.
+// Rules belong to exactly one group and are applied in the group's order.
+type RuleGroupModel struct {
+	ID          string
+	Title       string
+	Description string
+	Active      bool
+	Order       int32 // Position among the rule groups, 0 lets Firefly III choose
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// RuleModel represents a Firefly III rule in our domain model, without its triggers and actions
+type RuleModel struct {
+	ID             string
+	RuleGroupID    string
+	Title          string
+	Description    string
+	Active         bool
+	Order          int32
+	Trigger        string // When the rule fires, "store-journal" or "update-journal"
+	Strict         bool   // Whether all triggers must match instead of any
+	StopProcessing bool   // Whether later rules in the group are skipped when this rule fires
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// ruleGroupModelFromRead converts an API rule group to a RuleGroupModel
+func ruleGroupModelFromRead(groupRead RuleGroupRead) RuleGroupModel {
+	return RuleGroupModel{
+		ID:          groupRead.Id,
+		Title:       groupRead.Attributes.Title,
+		Description: stringValue(groupRead.Attributes.Description),
+		// Firefly III treats a missing active flag as active
+		Active:    groupRead.Attributes.Active == nil || *groupRead.Attributes.Active,
+		Order:     int32Value(groupRead.Attributes.Order),
+		CreatedAt: timeValue(groupRead.Attributes.CreatedAt),
+		UpdatedAt: timeValue(groupRead.Attributes.UpdatedAt),
+	}
+}
+
+// ruleModelFromRead converts an API rule to a RuleModel
+func ruleModelFromRead(ruleRead RuleRead) RuleModel {
+	return RuleModel{
+		ID:             ruleRead.Id,
+		RuleGroupID:    ruleRead.Attributes.RuleGroupId,
+		Title:          ruleRead.Attributes.Title,
+		Description:    stringValue(ruleRead.Attributes.Description),
+		Active:         ruleRead.Attributes.Active == nil || *ruleRead.Attributes.Active,
+		Order:          int32Value(ruleRead.Attributes.Order),
+		Trigger:        string(ruleRead.Attributes.Trigger),
+		Strict:         ruleRead.Attributes.Strict == nil || *ruleRead.Attributes.Strict,
+		StopProcessing: boolValue(ruleRead.Attributes.StopProcessing),
+		CreatedAt:      timeValue(ruleRead.Attributes.CreatedAt),
+		UpdatedAt:      timeValue(ruleRead.Attributes.UpdatedAt),
+	}
+}
+
+// validateRuleGroup validates a rule group and returns an error map
+func validateRuleGroup(group RuleGroupModel) errbuilder.ErrorMap {
+	var errs errbuilder.ErrorMap
+
+	if group.Title == "" {
+		errs.Set("title", "Title is required")
+	}
+	if group.Order < 0 {
+		errs.Set("order", "Order must not be negative")
+	}
+
+	return errs
+}
+
+// ListRuleGroups retrieves rule groups with pagination
+func (c *FireflyClient) ListRuleGroups(ctx context.Context, page, limit int) ([]RuleGroupModel, error) {
+	page32, limit32 := pageParams(page, limit)
+
+	// Call the API
+	resp, err := c.clientAPI.ListRuleGroupWithResponse(ctx, &ListRuleGroupParams{
+		Page:  page32,
+		Limit: limit32,
+	})
+	if err != nil {
+		return nil, APIErr("Failed to list rule groups", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseErr("Rule group", resp.HTTPResponse, resp.Body)
+	}
+
+	// Convert API response to RuleGroupModels
+	if resp.HTTPResponse == nil || len(resp.Body) == 0 {
+		return []RuleGroupModel{}, nil
+	}
+
+	var apiResp RuleGroupArray
+	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
+		return nil, APIErr("Failed to parse rule groups response", err)
+	}
+
+	groups := make([]RuleGroupModel, 0, len(apiResp.Data))
+	for _, groupRead := range apiResp.Data {
+		groups = append(groups, ruleGroupModelFromRead(groupRead))
+	}
+
+	return groups, nil
+}
+
+// CreateRuleGroup creates a new rule group and returns it with its ID
+func (c *FireflyClient) CreateRuleGroup(ctx context.Context, group RuleGroupModel) (*RuleGroupModel, error) {
+	// Validate rule group
+	if errs := validateRuleGroup(group); errs != nil {
+		return nil, ValidationErr("RuleGroup", errs)
+	}
+
+	store := StoreRuleGroupJSONRequestBody{
+		Title:       group.Title,
+		Description: stringPtr(group.Description),
+		Active:      boolPtr(group.Active),
+	}
+	if group.Order > 0 {
+		store.Order = &group.Order
+	}
+
+	// Call the API
+	resp, err := c.clientAPI.StoreRuleGroupWithResponse(ctx, &StoreRuleGroupParams{}, store)
+	if err != nil {
+		return nil, APIErr("Failed to create rule group", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusCreated {
+		return nil, responseErr("Rule group", resp.HTTPResponse, resp.Body)
+	}
+
+	if resp.HTTPResponse == nil || len(resp.Body) == 0 {
+		return nil, APIErr("No rule group data found", fmt.Errorf("empty response"))
+	}
+
+	var apiResp RuleGroupSingle
+	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
+		return nil, APIErr("Failed to parse rule group response", err)
+	}
+
+	created := ruleGroupModelFromRead(apiResp.Data)
+	return &created, nil
+}
+
+// UpdateRuleGroup updates an existing rule group. A zero Order keeps the group's position.
+func (c *FireflyClient) UpdateRuleGroup(ctx context.Context, id string, group RuleGroupModel) error {
+	// Validate rule group
+	if errs := validateRuleGroup(group); errs != nil {
+		return ValidationErr("RuleGroup", errs)
+	}
+
+	update := UpdateRuleGroupJSONRequestBody{
+		Title:       stringPtr(group.Title),
+		Description: stringPtr(group.Description),
+		Active:      boolPtr(group.Active),
+	}
+	if group.Order > 0 {
+		update.Order = &group.Order
+	}
+
+	// Call the API
+	resp, err := c.clientAPI.UpdateRuleGroupWithResponse(ctx, id, &UpdateRuleGroupParams{}, update)
+	if err != nil {
+		return APIErr("Failed to update rule group", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK {
+		return responseErr("Rule group", resp.HTTPResponse, resp.Body)
+	}
+
+	return nil
+}
+
+// DeleteRuleGroup deletes a rule group and all of its rules
+func (c *FireflyClient) DeleteRuleGroup(ctx context.Context, id string) error {
+	// Call the API
+	resp, err := c.clientAPI.DeleteRuleGroupWithResponse(ctx, id, &DeleteRuleGroupParams{})
+	if err != nil {
+		return APIErr("Failed to delete rule group", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusNoContent {
+		return responseErr("Rule group", resp.HTTPResponse, resp.Body)
+	}
+
+	return nil
+}
+
+// ListRulesInGroup retrieves the rules of a rule group with pagination
+func (c *FireflyClient) ListRulesInGroup(ctx context.Context, groupID string, page, limit int) ([]RuleModel, error) {
+	page32, limit32 := pageParams(page, limit)
+
+	// Call the API
+	resp, err := c.clientAPI.ListRuleByGroupWithResponse(ctx, groupID, &ListRuleByGroupParams{
+		Page:  page32,
+		Limit: limit32,
+	})
+	if err != nil {
+		return nil, APIErr("Failed to list rules of rule group", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseErr("Rule group", resp.HTTPResponse, resp.Body)
+	}
+
+	// Convert API response to RuleModels
+	if resp.HTTPResponse == nil || len(resp.Body) == 0 {
+		return []RuleModel{}, nil
+	}
+
+	var apiResp RuleArray
+	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
+		return nil, APIErr("Failed to parse rules response", err)
+	}
+
+	rules := make([]RuleModel, 0, len(apiResp.Data))
+	for _, ruleRead := range apiResp.Data {
+		rules = append(rules, ruleModelFromRead(ruleRead))
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,155 @@
+package firefly
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZanzyTHEbar/fireflyiii-client-go/fireflytest"
+)
+
+// TestTriggerRuleGroup tests that rule groups are triggered with the date range and accounts
+func TestTriggerRuleGroup(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodPost, "/v1/rule-groups/4/trigger", http.StatusNoContent, "")
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, client.TriggerRuleGroup(ctx, "4", start, end, "1", "7"))
+	require.NoError(t, client.TriggerRuleGroup(ctx, "4", time.Time{}, time.Time{}))
+
+	requests := server.RequestsTo(http.MethodPost, "/v1/rule-groups/4/trigger")
+	require.Len(t, requests, 2)
+	assert.Equal(t, "2024-01-01", requests[0].Query.Get("start"))
+	assert.Equal(t, "2024-03-31", requests[0].Query.Get("end"))
+	assert.Equal(t, []string{"1", "7"}, requests[0].Query["accounts[]"])
+	assert.False(t, requests[1].Query.Has("start"))
+	assert.False(t, requests[1].Query.Has("end"))
+
+	t.Run("invalid", func(t *testing.T) {
+		server.Reset()
+		err := client.TriggerRuleGroup(ctx, "4", start, time.Time{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "both be set")
+
+		err = client.TriggerRuleGroup(ctx, "4", end, start, "checking")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "End must not be before start")
+		assert.Contains(t, err.Error(), `Invalid account ID "checking"`)
+		assert.Empty(t, server.Requests())
+	})
+
+	t.Run("unknown group", func(t *testing.T) {
+		err := client.TriggerRuleGroup(ctx, "99", start, end)
+		assert.ErrorIs(t, err, ErrNotFoundSentinel)
+	})
+}
+
+// TestRescanBill tests that rescanning a bill triggers each of its rules over the date range
+func TestRescanBill(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/bills/3/rules", http.StatusOK, `{"data": [
+		{"type": "rules", "id": "10", "attributes": {"title": "Rent"}},
+		{"type": "rules", "id": "11", "attributes": {"title": "Rent (old landlord)"}}
+	], "meta": {}}`)
+	server.Handle(http.MethodPost, "/v1/rules/10/trigger", http.StatusNoContent, "")
+	server.Handle(http.MethodPost, "/v1/rules/11/trigger", http.StatusNoContent, "")
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, client.RescanBill(context.Background(), "3", start, end))
+
+	for _, path := range []string{"/v1/rules/10/trigger", "/v1/rules/11/trigger"} {
+		requests := server.RequestsTo(http.MethodPost, path)
+		require.Len(t, requests, 1, path)
+		assert.Equal(t, "2024-01-01", requests[0].Query.Get("start"))
+		assert.Equal(t, "2024-06-30", requests[0].Query.Get("end"))
+	}
+
+	err = client.RescanBill(context.Background(), "404", start, end)
+	assert.ErrorIs(t, err, ErrNotFoundSentinel)
+}
+
+// TestCreateRuleGroup tests that rule groups are created and returned with their ID
+func TestCreateRuleGroup(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodPost, "/v1/rule-groups", http.StatusOK, `{"data":
+		{"type": "rule_groups", "id": "5", "attributes": {"title": "Groceries", "description": "Supermarket rules", "active": true, "order": 2}}}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	group, err := client.CreateRuleGroup(ctx, RuleGroupModel{Title: "Groceries", Description: "Supermarket rules", Active: true})
+	require.NoError(t, err)
+	assert.Equal(t, "5", group.ID)
+	assert.Equal(t, "Groceries", group.Title)
+	assert.True(t, group.Active)
+	assert.Equal(t, int32(2), group.Order)
+
+	requests := server.RequestsTo(http.MethodPost, "/v1/rule-groups")
+	require.Len(t, requests, 1)
+	var body RuleGroupStore
+	require.NoError(t, requests[0].JSON(&body))
+	assert.Equal(t, "Groceries", body.Title)
+	assert.Equal(t, "Supermarket rules", stringValue(body.Description))
+	assert.True(t, boolValue(body.Active))
+	assert.Nil(t, body.Order)
+
+	t.Run("invalid", func(t *testing.T) {
+		server.Reset()
+		_, err := client.CreateRuleGroup(ctx, RuleGroupModel{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Title is required")
+		assert.Empty(t, server.Requests())
+	})
+}
+
+// TestListRulesInGroup tests that the rules of a rule group are listed
+func TestListRulesInGroup(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/rule-groups/5/rules", http.StatusOK, `{"data": [
+		{"type": "rules", "id": "10", "attributes": {"title": "Supermarket", "rule_group_id": "5", "order": 1,
+			"trigger": "store-journal", "strict": false, "stop_processing": true}},
+		{"type": "rules", "id": "11", "attributes": {"title": "Bakery", "rule_group_id": "5", "order": 2,
+			"active": false, "trigger": "update-journal"}}
+	]}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	rules, err := client.ListRulesInGroup(context.Background(), "5", 1, 50)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+
+	assert.Equal(t, "10", rules[0].ID)
+	assert.Equal(t, "5", rules[0].RuleGroupID)
+	assert.Equal(t, "Supermarket", rules[0].Title)
+	assert.True(t, rules[0].Active)
+	assert.False(t, rules[0].Strict)
+	assert.True(t, rules[0].StopProcessing)
+	assert.Equal(t, "store-journal", rules[0].Trigger)
+
+	assert.Equal(t, "11", rules[1].ID)
+	assert.False(t, rules[1].Active)
+	assert.True(t, rules[1].Strict)
+	assert.Equal(t, int32(2), rules[1].Order)
+
+	requests := server.RequestsTo(http.MethodGet, "/v1/rule-groups/5/rules")
+	require.Len(t, requests, 1)
+	assert.Equal(t, "50", requests[0].Query.Get("limit"))
+
+	_, err = client.ListRulesInGroup(context.Background(), "99", 1, 50)
+	assert.ErrorIs(t, err, ErrNotFoundSentinel)
+}
@@ -0,0 +1,127 @@
+package firefly
+
+import (
+	"strings"
+	"time"
+)
+
+// TransactionSearchBuilder builds a Firefly III search query from typed filters, for use with
+// SearchTransactions. Filters are combined with AND, in the order they were added:
+//
+//	query := NewTransactionSearch().AmountMore(100).Category("Food").DateAfter(start).String()
+//	// amount_more:100 category_is:Food date_after:2024-01-01
+type TransactionSearchBuilder struct {
+	terms []string
+}
+
+// NewTransactionSearch creates an empty transaction search
+func NewTransactionSearch() *TransactionSearchBuilder {
+	return &TransactionSearchBuilder{}
+}
+
+// operator adds an "operator:value" term, quoting the value when needed
+func (b *TransactionSearchBuilder) operator(name, value string) *TransactionSearchBuilder {
+	b.terms = append(b.terms, name+":"+quoteSearchValue(value))
+	return b
+}
+
+// Text matches transactions with these words in their description, like a plain search
+func (b *TransactionSearchBuilder) Text(text string) *TransactionSearchBuilder {
+	if text = strings.TrimSpace(text); text != "" {
+		b.terms = append(b.terms, quoteSearchValue(text))
+	}
+	return b
+}
+
+// DescriptionContains matches transactions whose description contains text
+func (b *TransactionSearchBuilder) DescriptionContains(text string) *TransactionSearchBuilder {
+	return b.operator("description_contains", text)
+}
+
+// Amount matches transactions of exactly this amount
+func (b *TransactionSearchBuilder) Amount(amount float64) *TransactionSearchBuilder {
+	return b.operator("amount", MoneyFromFloat(amount).String())
+}
+
+// AmountMore matches transactions with an amount larger than amount
+func (b *TransactionSearchBuilder) AmountMore(amount float64) *TransactionSearchBuilder {
+	return b.operator("amount_more", MoneyFromFloat(amount).String())
+}
+
+// AmountLess matches transactions with an amount smaller than amount
+func (b *TransactionSearchBuilder) AmountLess(amount float64) *TransactionSearchBuilder {
+	return b.operator("amount_less", MoneyFromFloat(amount).String())
+}
+
+// AmountBetween matches transactions with an amount larger than min and smaller than max
+func (b *TransactionSearchBuilder) AmountBetween(min, max float64) *TransactionSearchBuilder {
+	return b.AmountMore(min).AmountLess(max)
+}
+
+// DateOn matches transactions on the day of date
+func (b *TransactionSearchBuilder) DateOn(date time.Time) *TransactionSearchBuilder {
+	return b.operator("date_on", date.Format(time.DateOnly))
+}
+
+// DateAfter matches transactions on or after the day of date
+func (b *TransactionSearchBuilder) DateAfter(date time.Time) *TransactionSearchBuilder {
+	return b.operator("date_after", date.Format(time.DateOnly))
+}
+
+// DateBefore matches transactions on or before the day of date
+func (b *TransactionSearchBuilder) DateBefore(date time.Time) *TransactionSearchBuilder {
+	return b.operator("date_before", date.Format(time.DateOnly))
+}
+
+// DateBetween matches transactions from the day of start up to and including the day of end
+func (b *TransactionSearchBuilder) DateBetween(start, end time.Time) *TransactionSearchBuilder {
+	return b.DateAfter(start).DateBefore(end)
+}
+
+// Category matches transactions in the category with this name
+func (b *TransactionSearchBuilder) Category(name string) *TransactionSearchBuilder {
+	return b.operator("category_is", name)
+}
+
+// Account matches transactions with this account, by name, as source or destination
+func (b *TransactionSearchBuilder) Account(name string) *TransactionSearchBuilder {
+	return b.operator("account_is", name)
+}
+
+// AccountID matches transactions with the account with this ID as source or destination
+func (b *TransactionSearchBuilder) AccountID(id string) *TransactionSearchBuilder {
+	return b.operator("account_id", id)
+}
+
+// SourceAccount matches transactions from the account with this name
+func (b *TransactionSearchBuilder) SourceAccount(name string) *TransactionSearchBuilder {
+	return b.operator("source_account_is", name)
+}
+
+// DestinationAccount matches transactions to the account with this name
+func (b *TransactionSearchBuilder) DestinationAccount(name string) *TransactionSearchBuilder {
+	return b.operator("destination_account_is", name)
+}
+
+// Tag matches transactions with this tag
+func (b *TransactionSearchBuilder) Tag(tag string) *TransactionSearchBuilder {
+	return b.operator("tag_is", tag)
+}
+
+// Type matches transactions of this kind, such as TransactionKindWithdrawal
+func (b *TransactionSearchBuilder) Type(kind TransactionKind) *TransactionSearchBuilder {
+	return b.operator("type", string(kind))
+}
+
+// String returns the search query
+func (b *TransactionSearchBuilder) String() string {
+	return strings.Join(b.terms, " ")
+}
+
+// quoteSearchValue quotes values with spaces, quotes or colons, so Firefly III reads them as one value
+func quoteSearchValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\"':\\") {
+		return value
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
@@ -0,0 +1,59 @@
+package firefly
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZanzyTHEbar/fireflyiii-client-go/fireflytest"
+)
+
+// TestTransactionSearchBuilder tests the query strings generated from typed filters
+func TestTransactionSearchBuilder(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 31, 23, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		search *TransactionSearchBuilder
+		want   string
+	}{
+		{"empty", NewTransactionSearch(), ""},
+		{"amount range", NewTransactionSearch().AmountBetween(10, 100.5), "amount_more:10 amount_less:100.5"},
+		{"exact amount", NewTransactionSearch().Amount(12.3), "amount:12.3"},
+		{"date range", NewTransactionSearch().DateBetween(start, end), "date_after:2024-01-01 date_before:2024-01-31"},
+		{"date on", NewTransactionSearch().DateOn(end), "date_on:2024-01-31"},
+		{"category and tag", NewTransactionSearch().AmountMore(100).Category("Food").Tag("holiday"), "amount_more:100 category_is:Food tag_is:holiday"},
+		{"quoted values", NewTransactionSearch().Category("Daily groceries").Account(`Joe's "main" account`), `category_is:"Daily groceries" account_is:"Joe's \"main\" account"`},
+		{"accounts", NewTransactionSearch().SourceAccount("Checking").DestinationAccount("Corner Shop").AccountID("12"), `source_account_is:Checking destination_account_is:"Corner Shop" account_id:12`},
+		{"text and type", NewTransactionSearch().Text("coffee beans").DescriptionContains("latte").Type(TransactionKindWithdrawal), `"coffee beans" description_contains:latte type:withdrawal`},
+		{"colon in value", NewTransactionSearch().Tag("trip:paris"), `tag_is:"trip:paris"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.search.String())
+		})
+	}
+}
+
+// TestSearchTransactionsWithBuilder tests sending a built query to the search endpoint
+func TestSearchTransactionsWithBuilder(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/search/transactions", http.StatusOK, `{"data": [], "meta": {}}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	query := NewTransactionSearch().AmountMore(100).Category("Eating out")
+	_, err = client.SearchTransactions(context.Background(), query.String())
+	require.NoError(t, err)
+
+	requests := server.RequestsTo(http.MethodGet, "/v1/search/transactions")
+	require.Len(t, requests, 1)
+	assert.Equal(t, `amount_more:100 category_is:"Eating out"`, requests[0].Query.Get("query"))
+}
@@ -0,0 +1,248 @@
+package firefly
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ZanzyTHEbar/errbuilder-go"
+)
+
+// NetWorthPoint is the net worth in one currency on a date
+type NetWorthPoint struct {
+	Date     time.Time
+	Currency string
+	NetWorth float64
+}
+
+// seriesDates returns the dates from start to end, step apart. The series always ends with end,
+// and monthly and yearly steps stay on the day of the month of start, or the last day of
+// shorter months.
+func seriesDates(start, end time.Time, step ChartPeriod) ([]time.Time, error) {
+	var errs errbuilder.ErrorMap
+	switch step {
+	case ChartPeriodDaily, ChartPeriodWeekly, ChartPeriodMonthly, ChartPeriodYearly:
+	default:
+		errs.Set("step", fmt.Sprintf("Unsupported step %q", step))
+	}
+	if end.Before(start) {
+		errs.Set("end", "End must not be before start")
+	}
+	if errs != nil {
+		return nil, ValidationErr("Series", errs)
+	}
+
+	var dates []time.Time
+	for n := 0; ; n++ {
+		date := addPeriods(start, step, n)
+		if !date.Before(end) {
+			break
+		}
+		dates = append(dates, date)
+	}
+	return append(dates, end), nil
+}
+
+// addPeriods adds n periods to t, clamping monthly and yearly steps to the end of the month
+func addPeriods(t time.Time, period ChartPeriod, n int) time.Time {
+	switch period {
+	case ChartPeriodDaily:
+		return t.AddDate(0, 0, n)
+	case ChartPeriodWeekly:
+		return t.AddDate(0, 0, 7*n)
+	}
+
+	months := n
+	if period == ChartPeriodYearly {
+		months = 12 * n
+	}
+	year, month, day := t.Date()
+	first := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(day, lastDay)-1)
+}
+
+// NetWorthSeries computes the net worth at every step from start to end, with a final point at end.
+// The net worth on a date is the sum of the balances of the asset and liability accounts that are
+// included in the net worth, per currency. Points are sorted by date and currency.
+// Dates are fetched concurrently, bounded by MaxConcurrentRequests and the client's rate limit.
+func (c *FireflyClient) NetWorthSeries(ctx context.Context, start, end time.Time, step ChartPeriod) ([]NetWorthPoint, error) {
+	dates, err := seriesDates(start, end, step)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make([]map[string]Money, len(dates))
+	failures := make([]error, len(dates))
+
+	var wg sync.WaitGroup
+	for i, date := range dates {
+		wg.Add(1)
+		go func(i int, date time.Time) {
+			defer wg.Done()
+
+			release, err := c.acquireSlot(ctx)
+			if err != nil {
+				failures[i] = err
+				return
+			}
+			defer release()
+
+			totals[i], failures[i] = c.netWorthOn(ctx, date)
+		}(i, date)
+	}
+	wg.Wait()
+
+	errs := make(map[string]error)
+	for i, err := range failures {
+		if err != nil {
+			errs[dates[i].Format(time.DateOnly)] = err
+		}
+	}
+	if len(errs) > 0 {
+		return nil, BatchErr("NetWorthSeries", errs)
+	}
+
+	var points []NetWorthPoint
+	for i, date := range dates {
+		currencies := make([]string, 0, len(totals[i]))
+		for currency := range totals[i] {
+			currencies = append(currencies, currency)
+		}
+		sort.Strings(currencies)
+
+		for _, currency := range currencies {
+			points = append(points, NetWorthPoint{
+				Date:     date,
+				Currency: currency,
+				NetWorth: totals[i][currency].Float64(),
+			})
+		}
+	}
+
+	return points, nil
+}
+
+// netWorthOn sums the balances on date of the accounts included in the net worth, per currency
+func (c *FireflyClient) netWorthOn(ctx context.Context, date time.Time) (map[string]Money, error) {
+	totals := make(map[string]Money)
+	for _, accountType := range []AccountTypeFilter{AccountTypeFilterAsset, AccountTypeFilterLiabilities} {
+		accounts, err := listAllPages(ctx, func(ctx context.Context, page, limit int) ([]AccountModel, error) {
+			return c.ListAccountsFiltered(ctx, AccountFilter{Page: page, Limit: limit, Type: string(accountType), Date: date})
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, account := range accounts {
+			if !account.IncludeNetWorth {
+				continue
+			}
+			totals[account.Currency] = totals[account.Currency].Add(MoneyFromFloat(account.Balance))
+		}
+	}
+	return totals, nil
+}
+
+// SpendPoint is the amount spent in one currency during a period
+type SpendPoint struct {
+	Start    time.Time // First day of the period
+	End      time.Time // Last day of the period
+	Currency string
+	Spent    float64
+}
+
+// CategorySpendSeries computes the amount spent in a category per step from start to end. The last
+// period ends at end and may be shorter than step. Only withdrawal splits of the category count, so
+// other splits of the same transaction do not. Every currency spent in has a point for every period,
+// sorted by period and currency.
+func (c *FireflyClient) CategorySpendSeries(ctx context.Context, categoryID string, start, end time.Time, step ChartPeriod) ([]SpendPoint, error) {
+	if categoryID == "" {
+		var errs errbuilder.ErrorMap
+		errs.Set("categoryID", "Category ID is required")
+		return nil, ValidationErr("Series", errs)
+	}
+
+	starts, err := seriesDates(start, end, step)
+	if err != nil {
+		return nil, err
+	}
+	if len(starts) > 1 {
+		// The final date is the end of the last period
+		starts = starts[:len(starts)-1]
+	}
+
+	txReads, err := listAllPages(ctx, func(ctx context.Context, page, limit int) ([]TransactionRead, error) {
+		return c.listCategoryTransactionReads(ctx, categoryID, TransactionFilter{
+			Page:  page,
+			Limit: limit,
+			Start: start,
+			End:   end,
+			Type:  string(TransactionTypeFilterWithdrawal),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	spent := make([]map[string]Money, len(starts))
+	currencies := make(map[string]bool)
+	lastDay := end.Format(time.DateOnly)
+	for _, txRead := range txReads {
+		for _, split := range txRead.Attributes.Transactions {
+			if split.Type != Withdrawal || stringValue(split.CategoryId) != categoryID {
+				continue
+			}
+
+			day := split.Date.Format(time.DateOnly)
+			if day > lastDay {
+				continue
+			}
+			bucket := sort.Search(len(starts), func(i int) bool { return starts[i].Format(time.DateOnly) > day }) - 1
+			if bucket < 0 {
+				continue
+			}
+
+			amount, err := ParseMoney(split.Amount)
+			if err != nil {
+				c.logf("Skipping split of transaction %s: invalid amount %q", txRead.Id, split.Amount)
+				continue
+			}
+			if amount.Sign() < 0 {
+				amount = amount.Neg()
+			}
+			currency := stringValue(split.CurrencyCode)
+			if spent[bucket] == nil {
+				spent[bucket] = make(map[string]Money)
+			}
+			spent[bucket][currency] = spent[bucket][currency].Add(amount)
+			currencies[currency] = true
+		}
+	}
+
+	codes := make([]string, 0, len(currencies))
+	for currency := range currencies {
+		codes = append(codes, currency)
+	}
+	sort.Strings(codes)
+
+	points := make([]SpendPoint, 0, len(starts)*len(codes))
+	for i, periodStart := range starts {
+		periodEnd := end
+		if i+1 < len(starts) {
+			periodEnd = starts[i+1].AddDate(0, 0, -1)
+		}
+		for _, currency := range codes {
+			points = append(points, SpendPoint{
+				Start:    periodStart,
+				End:      periodEnd,
+				Currency: currency,
+				Spent:    spent[i][currency].Float64(),
+			})
+		}
+	}
+
+	return points, nil
+}
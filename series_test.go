@@ -0,0 +1,146 @@
+package firefly
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZanzyTHEbar/fireflyiii-client-go/fireflytest"
+)
+
+// accountJSON renders an account with a balance for list responses
+func accountJSON(id, accountType, balance, currency string, include bool) string {
+	return fmt.Sprintf(`{"type": "accounts", "id": %q, "attributes": {"name": "Account %s", "type": %q, "current_balance": %q, "currency_code": %q, "include_net_worth": %t}}`,
+		id, id, accountType, balance, currency, include)
+}
+
+// TestSeriesDates tests the dates of series steps
+func TestSeriesDates(t *testing.T) {
+	day := func(month time.Month, d int) time.Time { return time.Date(2024, month, d, 0, 0, 0, 0, time.UTC) }
+
+	dates, err := seriesDates(day(1, 31), day(4, 15), ChartPeriodMonthly)
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{day(1, 31), day(2, 29), day(3, 31), day(4, 15)}, dates)
+
+	dates, err = seriesDates(day(3, 1), day(3, 15), ChartPeriodWeekly)
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{day(3, 1), day(3, 8), day(3, 15)}, dates)
+
+	dates, err = seriesDates(day(3, 1), day(3, 1), ChartPeriodDaily)
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{day(3, 1)}, dates)
+
+	_, err = seriesDates(day(3, 2), day(3, 1), ChartPeriod("2D"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "End must not be before start")
+	assert.Contains(t, err.Error(), "Unsupported step")
+}
+
+// TestNetWorthSeries tests computing the net worth per currency over a date range
+func TestNetWorthSeries(t *testing.T) {
+	// Balances per account type and date; the savings account is excluded from the net worth
+	balances := map[string]map[string][]string{
+		"asset": {
+			"2024-03-01": {accountJSON("1", "asset", "1000.10", "EUR", true), accountJSON("2", "asset", "500.00", "EUR", false), accountJSON("3", "asset", "200.00", "USD", true)},
+			"2024-03-02": {accountJSON("1", "asset", "1100.20", "EUR", true), accountJSON("2", "asset", "500.00", "EUR", false), accountJSON("3", "asset", "200.00", "USD", true)},
+			"2024-03-03": {accountJSON("1", "asset", "900.00", "EUR", true), accountJSON("2", "asset", "500.00", "EUR", false), accountJSON("3", "asset", "250.00", "USD", true)},
+		},
+		"liabilities": {
+			"2024-03-01": {accountJSON("4", "liabilities", "-300.05", "EUR", true)},
+			"2024-03-02": {accountJSON("4", "liabilities", "-300.05", "EUR", true)},
+			"2024-03-03": {accountJSON("4", "liabilities", "-250.00", "EUR", true)},
+		},
+	}
+
+	server := fireflytest.NewServer(t)
+	server.HandleFunc(http.MethodGet, "/v1/accounts", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		accounts, ok := balances[query.Get("type")][query.Get("date")]
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"message": "Unexpected account query"}`))
+			return
+		}
+		fmt.Fprintf(w, `{"data": [%s], "meta": {}}`, strings.Join(accounts, ", "))
+	})
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	points, err := client.NetWorthSeries(ctx, start, start.AddDate(0, 0, 2), ChartPeriodDaily)
+	require.NoError(t, err)
+	assert.Equal(t, []NetWorthPoint{
+		{Date: start, Currency: "EUR", NetWorth: 700.05},
+		{Date: start, Currency: "USD", NetWorth: 200},
+		{Date: start.AddDate(0, 0, 1), Currency: "EUR", NetWorth: 800.15},
+		{Date: start.AddDate(0, 0, 1), Currency: "USD", NetWorth: 200},
+		{Date: start.AddDate(0, 0, 2), Currency: "EUR", NetWorth: 650},
+		{Date: start.AddDate(0, 0, 2), Currency: "USD", NetWorth: 250},
+	}, points)
+	assert.Len(t, server.RequestsTo(http.MethodGet, "/v1/accounts"), 6, "one request per account type and date")
+
+	t.Run("failed date", func(t *testing.T) {
+		_, err := client.NetWorthSeries(ctx, start, start.AddDate(0, 0, 3), ChartPeriodDaily)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "2024-03-04")
+	})
+}
+
+// splitJSON renders a transaction split for list responses
+func splitJSON(date, amount, currency, categoryID string) string {
+	return fmt.Sprintf(`{"type": "withdrawal", "date": %q, "amount": %q, "currency_code": %q, "description": "Groceries", "source_id": "1", "destination_id": "2", "category_id": %q}`,
+		date, amount, currency, categoryID)
+}
+
+// TestCategorySpendSeries tests bucketing category spend into periods across month boundaries
+func TestCategorySpendSeries(t *testing.T) {
+	groups := []string{
+		splitJSON("2024-01-31T12:00:00+00:00", "10.00", "EUR", "5"),
+		splitJSON("2024-02-01T08:00:00+00:00", "5.50", "EUR", "5"),
+		// Still February 14 in the time zone of the transaction
+		splitJSON("2024-02-14T23:30:00+01:00", "1.25", "EUR", "5"),
+		splitJSON("2024-02-15T00:00:00+00:00", "20.00", "EUR", "5") + ", " + splitJSON("2024-02-15T00:00:00+00:00", "99.00", "EUR", "6"),
+		splitJSON("2024-03-15T10:00:00+00:00", "3.00", "USD", "5"),
+	}
+	var data []string
+	for i, splits := range groups {
+		data = append(data, fmt.Sprintf(`{"type": "transactions", "id": "%d", "attributes": {"transactions": [%s]}}`, i+1, splits))
+	}
+
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/categories/5/transactions", http.StatusOK,
+		fmt.Sprintf(`{"data": [%s], "meta": {}}`, strings.Join(data, ", ")))
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	day := func(month time.Month, d int) time.Time { return time.Date(2024, month, d, 0, 0, 0, 0, time.UTC) }
+	points, err := client.CategorySpendSeries(ctx, "5", day(1, 15), day(3, 20), ChartPeriodMonthly)
+	require.NoError(t, err)
+	assert.Equal(t, []SpendPoint{
+		{Start: day(1, 15), End: day(2, 14), Currency: "EUR", Spent: 16.75},
+		{Start: day(1, 15), End: day(2, 14), Currency: "USD", Spent: 0},
+		{Start: day(2, 15), End: day(3, 14), Currency: "EUR", Spent: 20},
+		{Start: day(2, 15), End: day(3, 14), Currency: "USD", Spent: 0},
+		{Start: day(3, 15), End: day(3, 20), Currency: "EUR", Spent: 0},
+		{Start: day(3, 15), End: day(3, 20), Currency: "USD", Spent: 3},
+	}, points)
+
+	requests := server.RequestsTo(http.MethodGet, "/v1/categories/5/transactions")
+	require.Len(t, requests, 1)
+	assert.Equal(t, "2024-01-15", requests[0].Query.Get("start"))
+	assert.Equal(t, "2024-03-20", requests[0].Query.Get("end"))
+	assert.Equal(t, "withdrawal", requests[0].Query.Get("type"))
+
+	_, err = client.CategorySpendSeries(ctx, "", day(1, 15), day(3, 20), ChartPeriodMonthly)
+	assert.Error(t, err)
+}
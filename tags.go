@@ -0,0 +1,204 @@
+package firefly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/ZanzyTHEbar/errbuilder-go"
+)
+
+// BatchResult reports which items of a batch operation succeeded and which failed
+type BatchResult struct {
+	Succeeded []string         // IDs of the items that succeeded, in input order
+	Failed    map[string]error // Errors of the items that failed, keyed by ID
+
+	operation string
+}
+
+// Err returns the failures as one BatchErr, or nil if every item succeeded
+func (r *BatchResult) Err() error {
+	if len(r.Failed) == 0 {
+		return nil
+	}
+	return BatchErr(r.operation, r.Failed)
+}
+
+// EnsureTags creates the tags that do not exist yet. Existing tags are looked up once and
+// remembered per client and WithToken token, so repeated calls only create what is new. Names are matched
+// case-insensitively, like Firefly III does.
+func (c *FireflyClient) EnsureTags(ctx context.Context, names []string) error {
+	var errs errbuilder.ErrorMap
+	for i, name := range names {
+		if strings.TrimSpace(name) == "" {
+			errs.Set(fmt.Sprintf("names[%d]", i), "Tag is required")
+		}
+	}
+	if errs != nil {
+		return ValidationErr("Tag", errs)
+	}
+
+	// Concurrent calls may list or create the same tags; a duplicate is fine, so the cache
+	// is only locked around its own reads and writes
+	scope := cacheScope(ctx)
+	c.tagMu.Lock()
+	_, loaded := c.tagCache[scope]
+	c.tagMu.Unlock()
+
+	if !loaded {
+		tags, err := listAllPages(ctx, c.listTags)
+		if err != nil {
+			return err
+		}
+
+		keys := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			keys = append(keys, strings.ToLower(tag.Attributes.Tag))
+		}
+		c.rememberTags(scope, keys...)
+	}
+
+	for _, name := range names {
+		key := strings.ToLower(strings.TrimSpace(name))
+		c.tagMu.Lock()
+		_, ok := c.tagCache[scope][key]
+		c.tagMu.Unlock()
+		if ok {
+			continue
+		}
+
+		// A tag created elsewhere in the meantime is a duplicate, which is fine
+		if err := c.storeTag(ctx, strings.TrimSpace(name)); err != nil && !errors.Is(err, ErrDuplicateSentinel) {
+			return err
+		}
+		c.rememberTags(scope, key)
+	}
+
+	return nil
+}
+
+// rememberTags adds lowercased tags to the tags known to exist in a cache scope
+func (c *FireflyClient) rememberTags(scope string, keys ...string) {
+	c.tagMu.Lock()
+	defer c.tagMu.Unlock()
+
+	if c.tagCache == nil {
+		c.tagCache = make(map[string]map[string]struct{})
+	}
+	known, ok := c.tagCache[scope]
+	if !ok {
+		known = make(map[string]struct{}, len(keys))
+		c.tagCache[scope] = known
+	}
+	for _, key := range keys {
+		known[key] = struct{}{}
+	}
+}
+
+// storeTag creates a tag with the given name
+func (c *FireflyClient) storeTag(ctx context.Context, name string) error {
+	// Call the API
+	resp, err := c.clientAPI.StoreTagWithResponse(ctx, &StoreTagParams{}, TagModelStore{Tag: name})
+	if err != nil {
+		return APIErr("Failed to create tag", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusCreated {
+		return responseErr("Tag", resp.HTTPResponse, resp.Body)
+	}
+
+	return nil
+}
+
+// TagTransactions adds a tag to every split of the given transactions, creating the tag if needed.
+// Transactions are updated concurrently, at most MaxConcurrentRequests at a time, and transactions
+// that already have the tag are left unchanged. The result lists the transactions that were tagged
+// and the ones that failed; if any failed, the error is the result's BatchErr.
+func (c *FireflyClient) TagTransactions(ctx context.Context, transactionIDs []string, tag string) (*BatchResult, error) {
+	if err := c.EnsureTags(ctx, []string{tag}); err != nil {
+		return nil, err
+	}
+	tag = strings.TrimSpace(tag)
+
+	failures := make([]error, len(transactionIDs))
+
+	var wg sync.WaitGroup
+	for i, id := range transactionIDs {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+
+			release, err := c.acquireSlot(ctx)
+			if err != nil {
+				failures[i] = err
+				return
+			}
+			defer release()
+
+			failures[i] = c.tagTransaction(ctx, id, tag)
+		}(i, id)
+	}
+	wg.Wait()
+
+	result := &BatchResult{
+		Succeeded: make([]string, 0, len(transactionIDs)),
+		Failed:    make(map[string]error),
+		operation: "TagTransactions",
+	}
+	for i, id := range transactionIDs {
+		if failures[i] != nil {
+			result.Failed[id] = failures[i]
+			continue
+		}
+		result.Succeeded = append(result.Succeeded, id)
+	}
+
+	return result, result.Err()
+}
+
+// tagTransaction adds a tag to the splits of a transaction group that do not have it yet
+func (c *FireflyClient) tagTransaction(ctx context.Context, id, tag string) error {
+	txRead, err := c.getTransactionRead(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	splits := make([]TransactionSplitUpdate, 0, len(txRead.Attributes.Transactions))
+	for _, split := range txRead.Attributes.Transactions {
+		update := transactionSplitUpdateFromSplit(split)
+
+		var tags []string
+		if split.Tags != nil {
+			tags = *split.Tags
+		}
+		if !containsFold(tags, tag) {
+			tags = append(append([]string{}, tags...), tag)
+			update.Tags = &tags
+			changed = true
+		}
+		splits = append(splits, update)
+	}
+	if !changed {
+		return nil
+	}
+
+	return c.updateTransactionGroup(ctx, id, UpdateTransactionJSONRequestBody{
+		ApplyRules:   boolPtr(false),
+		Transactions: &splits,
+	})
+}
+
+// containsFold reports whether values contains value, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package firefly
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZanzyTHEbar/fireflyiii-client-go/fireflytest"
+)
+
+// TestEnsureTags tests that only missing tags are created, and only once
+func TestEnsureTags(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/tags", http.StatusOK, `{"data": [
+		{"type": "tags", "id": "1", "attributes": {"tag": "Travel"}}
+	], "meta": {}}`)
+	server.Handle(http.MethodPost, "/v1/tags", http.StatusOK, `{"data": {"type": "tags", "id": "2", "attributes": {"tag": "new"}}}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, client.EnsureTags(ctx, []string{"travel", "Groceries", "2024 trip", "groceries"}))
+
+	var created []string
+	for _, req := range server.RequestsTo(http.MethodPost, "/v1/tags") {
+		var store TagModelStore
+		require.NoError(t, req.JSON(&store))
+		created = append(created, store.Tag)
+	}
+	assert.Equal(t, []string{"Groceries", "2024 trip"}, created)
+
+	// Known tags are served from the cache
+	server.Reset()
+	require.NoError(t, client.EnsureTags(ctx, []string{"Travel", "GROCERIES"}))
+	assert.Empty(t, server.Requests())
+
+	err = client.EnsureTags(ctx, []string{"ok", " "})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Tag is required")
+}
+
+// TestTagTransactions tests adding a tag to the splits of several transactions
+func TestTagTransactions(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/tags", http.StatusOK, `{"data": [{"type": "tags", "id": "1", "attributes": {"tag": "groceries"}}], "meta": {}}`)
+	server.Handle(http.MethodPost, "/v1/tags", http.StatusOK, `{"data": {"type": "tags", "id": "2", "attributes": {"tag": "holiday"}}}`)
+	server.Handle(http.MethodGet, "/v1/transactions/1", http.StatusOK, `{"data": {"id": "1", "type": "transactions", "attributes": {"transactions": [
+		{"transaction_journal_id": "11", "type": "withdrawal", "date": "2024-03-05T00:00:00+00:00", "amount": "10.00", "description": "Bread"}
+	]}}}`)
+	server.Handle(http.MethodGet, "/v1/transactions/2", http.StatusOK, `{"data": {"id": "2", "type": "transactions", "attributes": {"transactions": [
+		{"transaction_journal_id": "21", "type": "withdrawal", "date": "2024-03-05T00:00:00+00:00", "amount": "5.00", "description": "Hotel", "tags": ["Holiday"]}
+	]}}}`)
+	server.Handle(http.MethodGet, "/v1/transactions/3", http.StatusOK, `{"data": {"id": "3", "type": "transactions", "attributes": {"group_title": "Shopping", "transactions": [
+		{"transaction_journal_id": "31", "type": "withdrawal", "date": "2024-03-05T00:00:00+00:00", "amount": "19.99", "description": "Paint", "tags": ["groceries"]},
+		{"transaction_journal_id": "32", "type": "withdrawal", "date": "2024-03-05T00:00:00+00:00", "amount": "5.00", "description": "Brushes"}
+	]}}}`)
+	for _, path := range []string{"/v1/transactions/1", "/v1/transactions/3"} {
+		server.Handle(http.MethodPut, path, http.StatusOK, `{"data": {"id": "1", "type": "transactions", "attributes": {"transactions": []}}}`)
+	}
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	result, err := client.TagTransactions(ctx, []string{"1", "2", "3"}, "holiday")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3"}, result.Succeeded)
+	assert.Empty(t, result.Failed)
+	assert.Len(t, server.RequestsTo(http.MethodPost, "/v1/tags"), 1)
+
+	// Transaction 2 already has the tag and is not updated
+	assert.Empty(t, server.RequestsTo(http.MethodPut, "/v1/transactions/2"))
+
+	updates := server.RequestsTo(http.MethodPut, "/v1/transactions/1")
+	require.Len(t, updates, 1)
+	var update TransactionUpdate
+	require.NoError(t, updates[0].JSON(&update))
+	require.Len(t, *update.Transactions, 1)
+	assert.Equal(t, []string{"holiday"}, *(*update.Transactions)[0].Tags)
+	assert.False(t, *update.ApplyRules)
+
+	updates = server.RequestsTo(http.MethodPut, "/v1/transactions/3")
+	require.Len(t, updates, 1)
+	require.NoError(t, updates[0].JSON(&update))
+	require.Len(t, *update.Transactions, 2)
+	assert.Equal(t, []string{"groceries", "holiday"}, *(*update.Transactions)[0].Tags, "existing tags are kept")
+	assert.Equal(t, []string{"holiday"}, *(*update.Transactions)[1].Tags)
+	assert.Equal(t, "19.99", stringValue((*update.Transactions)[0].Amount))
+
+	t.Run("partial failure", func(t *testing.T) {
+		result, err := client.TagTransactions(ctx, []string{"1", "404"}, "holiday")
+		require.Error(t, err)
+		assert.Equal(t, []string{"1"}, result.Succeeded)
+		require.Contains(t, result.Failed, "404")
+		assert.ErrorIs(t, result.Failed["404"], ErrNotFoundSentinel)
+		assert.ErrorIs(t, err, ErrNotFoundSentinel)
+	})
+}
+
+// TestEnsureTagsCancel tests that a tag listing stops with its context and does not block other calls
+func TestEnsureTagsCancel(t *testing.T) {
+	listing := make(chan struct{})
+	server := fireflytest.NewServer(t)
+	server.HandleFunc(http.MethodGet, "/v1/tags", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer slow-token" {
+			close(listing)
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": [{"type": "tags", "id": "1", "attributes": {"tag": "travel"}}], "meta": {}}`))
+	})
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(WithToken(context.Background(), "slow-token"))
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- client.EnsureTags(ctx, []string{"travel"}) }()
+	<-listing
+
+	// Another call completes while the slow listing is in flight
+	require.NoError(t, client.EnsureTags(context.Background(), []string{"travel"}))
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("EnsureTags did not stop when its context was canceled")
+	}
+}
@@ -0,0 +1,91 @@
+package firefly
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// tokenRefreshMargin is how long before its expiry a cached token is replaced, so requests
+// do not reach the API with a token that expires on the way
+const tokenRefreshMargin = 60 * time.Second
+
+// tokenSourceFunc adapts a function to the oauth2.TokenSource interface
+type tokenSourceFunc func() (*oauth2.Token, error)
+
+// Token calls f
+func (f tokenSourceFunc) Token() (*oauth2.Token, error) {
+	return f()
+}
+
+// refreshingTokenSource caches OAuth2 tokens from a base source and can be forced to fetch a new one,
+// e.g. after the API rejected the cached token with a 401
+type refreshingTokenSource struct {
+	mu        sync.Mutex
+	base      oauth2.TokenSource
+	token     *oauth2.Token
+	onRefresh func(*oauth2.Token) // Called outside the lock whenever a new token was fetched
+	now       func() time.Time
+}
+
+// newRefreshingTokenSource wraps base, which is expected to fetch a new token on every call
+func newRefreshingTokenSource(base oauth2.TokenSource, onRefresh func(*oauth2.Token)) *refreshingTokenSource {
+	return &refreshingTokenSource{base: base, onRefresh: onRefresh, now: time.Now}
+}
+
+// valid reports whether the cached token can still be used, that is it has not expired and
+// will not within tokenRefreshMargin; s.mu must be held
+func (s *refreshingTokenSource) valid() bool {
+	if s.token == nil || s.token.AccessToken == "" {
+		return false
+	}
+	return s.token.Expiry.IsZero() || s.now().Add(tokenRefreshMargin).Before(s.token.Expiry)
+}
+
+// Token returns the cached token while it is valid and fetches a new one otherwise
+func (s *refreshingTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	if s.valid() {
+		token := s.token
+		s.mu.Unlock()
+		return token, nil
+	}
+	token, err := s.fetch()
+	s.mu.Unlock()
+
+	return s.notify(token, err)
+}
+
+// Refresh discards the cached token and fetches a new one. If the cached access token already
+// differs from rejected, another request refreshed it in the meantime and it is returned as is.
+func (s *refreshingTokenSource) Refresh(rejected string) (*oauth2.Token, error) {
+	s.mu.Lock()
+	if s.valid() && s.token.AccessToken != rejected {
+		token := s.token
+		s.mu.Unlock()
+		return token, nil
+	}
+	token, err := s.fetch()
+	s.mu.Unlock()
+
+	return s.notify(token, err)
+}
+
+// fetch requests a new token from the base source; s.mu must be held
+func (s *refreshingTokenSource) fetch() (*oauth2.Token, error) {
+	token, err := s.base.Token()
+	if err != nil {
+		return nil, err
+	}
+	s.token = token
+	return token, nil
+}
+
+// notify passes a newly fetched token to the refresh callback
+func (s *refreshingTokenSource) notify(token *oauth2.Token, err error) (*oauth2.Token, error) {
+	if err == nil && s.onRefresh != nil {
+		s.onRefresh(token)
+	}
+	return token, err
+}
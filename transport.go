@@ -0,0 +1,763 @@
+package firefly
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ZanzyTHEbar/errbuilder-go"
+)
+
+// ResponseInterceptor is called with the raw body of every API response after it has been read.
+// op identifies the call as "METHOD /path". The body must not be modified.
+type ResponseInterceptor func(op string, status int, body []byte)
+
+// interceptTransport is an http.RoundTripper that hands raw response bodies to a ResponseInterceptor
+type interceptTransport struct {
+	base        http.RoundTripper
+	interceptor ResponseInterceptor
+}
+
+// RoundTrip performs the request and passes the buffered response body to the interceptor
+func (t *interceptTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	// Restore the body for the caller
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	t.interceptor(req.Method+" "+req.URL.Path, resp.StatusCode, body)
+
+	return resp, nil
+}
+
+// decompressTransport is an http.RoundTripper that decodes gzip encoded response bodies which
+// net/http left encoded. net/http only decodes responses to requests it asked for gzip itself,
+// not those whose Accept-Encoding header was set by the caller or by middleware. Successful and
+// error responses are decoded alike.
+type decompressTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip performs the request and decodes the response body if it is still gzip encoded
+func (t *decompressTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.Uncompressed || !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return resp, err
+	}
+
+	resp.Body = &gzipBody{body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+// gzipBody decodes a gzip encoded response body. The gzip header is read on the first Read,
+// so empty bodies, such as those of HEAD requests, can be closed without an error.
+type gzipBody struct {
+	body   io.ReadCloser
+	reader *gzip.Reader
+	err    error
+}
+
+// Read reads decoded data from the body
+func (b *gzipBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	if b.reader == nil {
+		b.reader, b.err = gzip.NewReader(b.body)
+		if b.err != nil {
+			return 0, b.err
+		}
+	}
+	return b.reader.Read(p)
+}
+
+// Close closes the underlying body
+func (b *gzipBody) Close() error {
+	return b.body.Close()
+}
+
+// unauthorizedRetryTransport is an http.RoundTripper that refreshes the OAuth2 token once
+// when the API answers 401 and retries the request with the new token
+type unauthorizedRetryTransport struct {
+	base   http.RoundTripper
+	tokens *refreshingTokenSource
+}
+
+// RoundTrip performs the request and retries it once with a refreshed token on 401
+func (t *unauthorizedRetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// Requests whose body cannot be replayed are returned as is
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+
+	// Tokens set with WithToken are not replaced by the client's own credentials
+	if _, ok := tokenFromContext(req.Context()); ok {
+		return resp, nil
+	}
+
+	rejected := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	token, err := t.tokens.Refresh(rejected)
+	if err != nil {
+		resp.Body.Close()
+		return nil, OAuth2Err(&OAuth2Error{
+			ErrorCode:        "token_refresh_failed",
+			ErrorDescription: "Failed to refresh OAuth2 token after 401: " + err.Error(),
+		})
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		retry.Body = body
+	}
+	token.SetAuthHeader(retry)
+
+	// Release the connection of the rejected response before retrying
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	return t.base.RoundTrip(retry)
+}
+
+// isMutatingMethod reports whether requests with method change data on the server. Retrying them
+// may apply a change twice, so only ClientConfig.AutoRetry retries them.
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// autoRetryTransport is an http.RoundTripper that retries failed mutating requests, replaying their body
+type autoRetryTransport struct {
+	base   http.RoundTripper
+	config *RetryConfig
+}
+
+// newAutoRetryTransport creates an autoRetryTransport using the retry settings of config
+func newAutoRetryTransport(base http.RoundTripper, config *ClientConfig) *autoRetryTransport {
+	retryConfig := DefaultRetryConfig()
+	retryConfig.MaxRetries = config.RetryCount
+	retryConfig.InitialDelay = config.RetryDelay
+	retryConfig.ShouldRetry = config.RetryOn
+	return &autoRetryTransport{base: base, config: retryConfig}
+}
+
+// RoundTrip performs the request and retries it while it fails with a retryable error
+func (t *autoRetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isMutatingMethod(req.Method) {
+		return t.base.RoundTrip(req)
+	}
+
+	// Requests whose body cannot be replayed are sent once
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 {
+			attemptReq = req.Clone(req.Context())
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				attemptReq.Body = body
+			}
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+		if attempt >= t.config.MaxRetries || !t.shouldRetry(req.Context(), resp, err) {
+			return resp, err
+		}
+
+		// Release the connection of the failed response before retrying
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(t.config.calculateBackoffDelay(attempt)):
+		}
+	}
+}
+
+// shouldRetry reports whether a request that got resp or err is worth another attempt
+func (t *autoRetryTransport) shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if t.config.ShouldRetry != nil {
+		return t.config.retryOn(resp, err)
+	}
+	if err != nil {
+		// The request did not get a response, such as on a refused or reset connection
+		return true
+	}
+	return t.config.isRetryableError(&HTTPError{StatusCode: resp.StatusCode})
+}
+
+// redactTransport is an http.RoundTripper that strips sensitive data from error responses
+// before the client turns them into errors
+type redactTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip performs the request and redacts the body, headers and request URL of error responses
+func (t *redactTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode < 400 {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(redactBody(body)))
+
+	for _, key := range []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"} {
+		resp.Header.Del(key)
+	}
+
+	// Errors report the URL of the response's request, whose query may hold search terms
+	redacted := *req
+	redacted.Header = nil
+	redacted.URL = redactURL(req.URL)
+	resp.Request = &redacted
+
+	return resp, nil
+}
+
+// redactBody keeps only the message and the validation errors of a JSON error body.
+// Other bodies, such as HTML error pages, are dropped.
+func redactBody(body []byte) []byte {
+	var envelope struct {
+		Message string          `json:"message,omitempty"`
+		Errors  json.RawMessage `json:"errors,omitempty"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil
+	}
+	redacted, err := json.Marshal(envelope)
+	if err != nil {
+		return nil
+	}
+	return redacted
+}
+
+// redactURL returns a copy of u without user info and with every query value replaced
+func redactURL(u *url.URL) *url.URL {
+	redacted := *u
+	redacted.User = nil
+	if query := u.Query(); len(query) > 0 {
+		for key := range query {
+			query[key] = []string{"REDACTED"}
+		}
+		redacted.RawQuery = query.Encode()
+	}
+	return &redacted
+}
+
+// coalesceTransport is an http.RoundTripper that lets concurrent identical GET requests share
+// one request to the server. Requests are identical when their URL and Authorization header match.
+// The shared request does not end with the context of the caller that started it; it is only
+// cancelled once every caller waiting for it has stopped waiting.
+type coalesceTransport struct {
+	base http.RoundTripper
+
+	mu    sync.Mutex
+	calls map[string]*coalescedCall
+}
+
+// coalescedCall is a GET request in flight, with the buffered result once done is closed
+type coalescedCall struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int // Callers waiting for the result, guarded by coalesceTransport.mu
+	resp    *http.Response
+	body    []byte
+	err     error
+}
+
+// RoundTrip performs the request, or waits for the identical request already in flight
+func (t *coalesceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.base.RoundTrip(req)
+	}
+	key := req.URL.String() + "\x00" + req.Header.Get("Authorization")
+
+	t.mu.Lock()
+	call, ok := t.calls[key]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.WithoutCancel(req.Context()))
+		call = &coalescedCall{done: make(chan struct{}), cancel: cancel}
+		if t.calls == nil {
+			t.calls = make(map[string]*coalescedCall)
+		}
+		t.calls[key] = call
+		go t.do(key, call, req.Clone(ctx))
+	}
+	call.waiters++
+	t.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.response(req)
+	case <-req.Context().Done():
+		t.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			call.cancel()
+			if t.calls[key] == call {
+				delete(t.calls, key)
+			}
+		}
+		t.mu.Unlock()
+		return nil, req.Context().Err()
+	}
+}
+
+// do performs the shared request and buffers its result
+func (t *coalesceTransport) do(key string, call *coalescedCall, req *http.Request) {
+	defer call.cancel()
+
+	call.resp, call.err = t.base.RoundTrip(req)
+	if call.err == nil {
+		call.body, call.err = io.ReadAll(call.resp.Body)
+		call.resp.Body.Close()
+	}
+
+	t.mu.Lock()
+	if t.calls[key] == call {
+		delete(t.calls, key)
+	}
+	t.mu.Unlock()
+	close(call.done)
+}
+
+// response returns a copy of the shared response, with its own body, for req
+func (c *coalescedCall) response(req *http.Request) (*http.Response, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	resp := *c.resp
+	resp.Header = c.resp.Header.Clone()
+	resp.Body = io.NopCloser(bytes.NewReader(c.body))
+	resp.Request = req
+	return &resp, nil
+}
+
+// middlewareTransport is an http.RoundTripper that passes every request and response through
+// the client's middleware chain. When a RetryMiddleware rejects the response of a request that
+// does not change data, the request is sent again with its backoff until it succeeds or the
+// retries run out. Mutating requests are only retried by autoRetryTransport when
+// ClientConfig.AutoRetry is set, so a create the server stored before failing is not stored
+// twice and the two retry layers never multiply each other's attempts.
+type middlewareTransport struct {
+	base  http.RoundTripper
+	chain *MiddlewareChain
+}
+
+// RoundTrip processes the request, performs it and processes the response
+func (t *middlewareTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	retry := t.chain.retryConfig()
+
+	// Mutating requests and requests whose body cannot be replayed are sent once
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	retryable := replayable && !isMutatingMethod(req.Method)
+
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 {
+			attemptReq = req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				attemptReq.Body = body
+			}
+		}
+
+		attemptReq, err := t.chain.ProcessRequest(ctx, attemptReq)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := t.base.RoundTrip(attemptReq)
+		if err != nil {
+			return nil, err
+		}
+
+		processed, err := t.chain.ProcessResponse(ctx, resp)
+		if err == nil {
+			return processed, nil
+		}
+
+		// Errors other than a retry request from RetryMiddleware fail the request
+		var httpErr *HTTPError
+		if retry == nil || !errors.As(err, &httpErr) || httpErr.StatusCode != resp.StatusCode {
+			resp.Body.Close()
+			return nil, err
+		}
+
+		// Out of retries, the caller turns the response into its usual error
+		if attempt >= retry.MaxRetries || !retryable || ctx.Err() != nil {
+			return resp, nil
+		}
+
+		// Release the connection of the rejected response before retrying
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(retry.calculateBackoffDelay(attempt)):
+		}
+	}
+}
+
+// sensitiveQueryParams are query parameters that carry credentials
+var sensitiveQueryParams = []string{"access_token", "refresh_token", "token", "api_key", "client_secret"}
+
+// safeURL returns u for logs and errors, without user info and with the values of
+// credential query parameters replaced
+func safeURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	safe := *u
+	safe.User = nil
+	query := u.Query()
+	redacted := false
+	for _, key := range sensitiveQueryParams {
+		for name := range query {
+			if strings.EqualFold(name, key) {
+				query[name] = []string{"REDACTED"}
+				redacted = true
+			}
+		}
+	}
+	if redacted {
+		safe.RawQuery = query.Encode()
+	}
+	return safe.String()
+}
+
+// wrapTransport layers the configured transport features over the base transport
+func wrapTransport(base http.RoundTripper, config *ClientConfig, tokens *refreshingTokenSource) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	base = &decompressTransport{base: base}
+	if config == nil {
+		return base
+	}
+
+	if config.ResponseInterceptor != nil {
+		base = &interceptTransport{base: base, interceptor: config.ResponseInterceptor}
+	}
+
+	if tokens != nil {
+		base = &unauthorizedRetryTransport{base: base, tokens: tokens}
+	}
+
+	if config.AutoRetry && config.RetryCount > 0 {
+		base = newAutoRetryTransport(base, config)
+	}
+
+	if config.CoalesceRequests {
+		base = &coalesceTransport{base: base}
+	}
+
+	if config.RedactSensitive {
+		base = &redactTransport{base: base}
+	}
+
+	return base
+}
+
+// endpointURL joins an API path to the client's base URL, independent of trailing or leading slashes
+func (c *FireflyClient) endpointURL(path string) (string, error) {
+	return url.JoinPath(c.baseURL, path)
+}
+
+// newRequest creates a request for an API path relative to the client's base URL
+func (c *FireflyClient) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	requestURL, err := c.endpointURL(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request URL: %w", err)
+	}
+	return http.NewRequestWithContext(ctx, method, requestURL, body)
+}
+
+// doRequest sends a manually built request with the same authentication and headers as the generated client
+func (c *FireflyClient) doRequest(req *http.Request) (*http.Response, error) {
+	if err := c.editRequest(req.Context(), req); err != nil {
+		return nil, err
+	}
+	return c.client.Do(req)
+}
+
+// Do calls an API endpoint the client does not wrap yet. path is relative to the base URL, such as
+// "/v1/about", and may carry a query string. body is sent as is when it is an io.Reader or []byte
+// and encoded as JSON otherwise; nil sends no body. The request gets the same authentication and
+// headers as the wrapped methods and passes through the client's middleware.
+//
+// A successful JSON response is decoded into out unless out is nil. The body of the returned
+// response has already been read and can be read again, so it does not need to be closed.
+// Unsuccessful responses are returned together with the typed error for their status code.
+func (c *FireflyClient) Do(ctx context.Context, method, path string, body interface{}, out interface{}) (*http.Response, error) {
+	var errs errbuilder.ErrorMap
+	endpoint, err := url.Parse(path)
+	if err != nil {
+		errs.Set("path", fmt.Sprintf("Invalid path %q: %v", path, err))
+		return nil, ValidationErr("Request", errs)
+	}
+
+	var reader io.Reader
+	isJSON := false
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		reader = b
+	case []byte:
+		reader = bytes.NewReader(b)
+	default:
+		encoded, err := json.Marshal(b)
+		if err != nil {
+			errs.Set("body", fmt.Sprintf("Body cannot be encoded: %v", err))
+			return nil, ValidationErr("Request", errs)
+		}
+		reader = bytes.NewReader(encoded)
+		isJSON = true
+	}
+
+	req, err := c.newRequest(ctx, method, endpoint.Path, reader)
+	if err != nil {
+		return nil, APIErr("Failed to create request", err)
+	}
+	req.URL.RawQuery = endpoint.RawQuery
+	req.Header.Set("Accept", "application/json")
+	if isJSON {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Call the API
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, APIErr(fmt.Sprintf("Failed to call %s %s", method, safeURL(endpoint)), err)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return resp, APIErr("Failed to read response", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	// Check response
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp, responseErr("Resource", resp, data)
+	}
+
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return resp, APIErr("Failed to parse response", err)
+		}
+	}
+
+	return resp, nil
+}
+
+// editRequest applies authentication and the configured headers to an outgoing request
+func (c *FireflyClient) editRequest(ctx context.Context, req *http.Request) error {
+	// Add authentication
+	if err := c.authorize(ctx, req); err != nil {
+		return err
+	}
+
+	if c.config != nil {
+		// Add user agent
+		if c.config.UserAgent != "" {
+			req.Header.Set("User-Agent", c.config.UserAgent)
+		}
+
+		// Add debug headers if enabled
+		if c.config.DebugMode {
+			req.Header.Set("X-Debug", "true")
+		}
+	}
+
+	return nil
+}
+
+// tokenContextKey is the context key of the token set by WithToken
+type tokenContextKey struct{}
+
+// WithToken returns a context whose requests are authenticated with token instead of the client's
+// configured token or OAuth2 credentials, so one client can act for several users or tenants.
+//
+// The token is used as given: the client does not refresh it, does not retry a 401 with its own
+// credentials, and does not check which user it belongs to. Only derive the context from a token
+// the caller is allowed to use, and do not cache responses of one token for requests of another.
+// The client's own caches, such as known categories or the default currency, are kept per token.
+// An empty token leaves the configured authentication in place.
+func WithToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, tokenContextKey{}, token)
+}
+
+// tokenFromContext returns the token set by WithToken, if any
+func tokenFromContext(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(tokenContextKey{}).(string)
+	return token, ok && token != ""
+}
+
+// cacheScope returns the prefix of cache keys for lookups made with ctx, so what one WithToken
+// token can see is never served to another token or to the configured credentials. The token
+// is hashed to keep it out of the cache.
+func cacheScope(ctx context.Context) string {
+	token, ok := tokenFromContext(ctx)
+	if !ok {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:]) + ":"
+}
+
+// authorize sets the Authorization header from the context token, the OAuth2 token source or the static token
+func (c *FireflyClient) authorize(ctx context.Context, req *http.Request) error {
+	if token, ok := tokenFromContext(ctx); ok {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+
+	if c.tokenSource != nil {
+		token, err := c.tokenSource.Token()
+		if err != nil {
+			return OAuth2Err(&OAuth2Error{
+				ErrorCode:        "token_request_failed",
+				ErrorDescription: "Failed to obtain OAuth2 token: " + err.Error(),
+			})
+		}
+		token.SetAuthHeader(req)
+		return nil
+	}
+
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	return nil
+}
+
+// setMaxConcurrentRequests sizes the request semaphore and the webhook handler limit.
+// The webhook manager gets its own semaphore so handlers calling batch helpers cannot deadlock.
+func (c *FireflyClient) setMaxConcurrentRequests(max int) {
+	if max <= 0 {
+		max = DefaultMaxConcurrentRequests
+	}
+	c.requestSlots = make(chan struct{}, max)
+	c.webhookMgr.SetMaxConcurrency(max)
+}
+
+// acquireSlot blocks until fewer than MaxConcurrentRequests helper requests are running.
+// The returned function releases the slot.
+func (c *FireflyClient) acquireSlot(ctx context.Context) (func(), error) {
+	select {
+	case c.requestSlots <- struct{}{}:
+		return func() { <-c.requestSlots }, nil
+	case <-ctx.Done():
+		return nil, ContextErr(ctx.Err())
+	}
+}
+
+// CurrentToken returns the access token the client currently sends with its requests.
+// For OAuth2 clients this is the cached token, or a newly fetched one if it expired.
+// The returned token is a credential and should not be logged.
+func (c *FireflyClient) CurrentToken(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", ContextErr(err)
+	}
+
+	if c.tokenSource != nil {
+		token, err := c.tokenSource.Token()
+		if err != nil {
+			return "", OAuth2Err(&OAuth2Error{
+				ErrorCode:        "token_request_failed",
+				ErrorDescription: "Failed to obtain OAuth2 token: " + err.Error(),
+			})
+		}
+		return token.AccessToken, nil
+	}
+
+	if c.token == "" {
+		return "", AuthenticationErr(fmt.Errorf("no access token configured"))
+	}
+	return c.token, nil
+}
+
+// checkInsecureBaseURL rejects plain http base URLs for non-loopback hosts unless explicitly allowed,
+// since the API token would otherwise be sent in the clear
+func checkInsecureBaseURL(baseURL string, allowInsecure bool) error {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid base URL: %w", err)
+	}
+	if allowInsecure || !strings.EqualFold(parsed.Scheme, "http") || isLoopbackHost(parsed.Hostname()) {
+		return nil
+	}
+	return fmt.Errorf("refusing to send credentials over plain http to %s: use https or set ClientConfig.AllowInsecure", parsed.Host)
+}
+
+// isLoopbackHost reports whether host refers to the local machine
+func isLoopbackHost(host string) bool {
+	host = strings.ToLower(host)
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,204 @@
+package firefly
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ZanzyTHEbar/errbuilder-go"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// Roles a Firefly III user can have
+const (
+	UserRoleOwner = "owner" // Administrator of the Firefly III instance
+	UserRoleDemo  = "demo"  // Demo user with restricted permissions
+)
+
+// UserModel represents a Firefly III user in our domain model.
+// Managing users requires a token of a user with the owner role.
+type UserModel struct {
+	ID          string
+	Email       string
+	Role        string // One of the UserRole values, empty for regular users
+	Blocked     bool
+	BlockedCode string // Reason the user is blocked, such as "email_changed"
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// userModelFromRead converts an API user to a UserModel
+func userModelFromRead(userRead UserRead) UserModel {
+	user := UserModel{
+		ID:        userRead.Id,
+		Email:     string(userRead.Attributes.Email),
+		Blocked:   boolValue(userRead.Attributes.Blocked),
+		CreatedAt: timeValue(userRead.Attributes.CreatedAt),
+		UpdatedAt: timeValue(userRead.Attributes.UpdatedAt),
+	}
+	if userRead.Attributes.Role != nil && *userRead.Attributes.Role != UserRolePropertyLessThannil {
+		user.Role = string(*userRead.Attributes.Role)
+	}
+	if userRead.Attributes.BlockedCode != nil && *userRead.Attributes.BlockedCode != UserBlockedCodePropertyLessThannil {
+		user.BlockedCode = string(*userRead.Attributes.BlockedCode)
+	}
+	return user
+}
+
+// userToAPI converts a UserModel to the API representation
+func userToAPI(user UserModel) User {
+	apiUser := User{
+		Email:   openapi_types.Email(user.Email),
+		Blocked: boolPtr(user.Blocked),
+	}
+	if user.Role != "" {
+		role := UserRoleProperty(user.Role)
+		apiUser.Role = &role
+	}
+	if user.BlockedCode != "" {
+		blockedCode := UserBlockedCodeProperty(user.BlockedCode)
+		apiUser.BlockedCode = &blockedCode
+	}
+	return apiUser
+}
+
+// validateUser validates a user and returns an error map
+func validateUser(user UserModel) errbuilder.ErrorMap {
+	var errs errbuilder.ErrorMap
+
+	if user.Email == "" {
+		errs.Set("email", "Email is required")
+	}
+	if user.Role != "" && user.Role != UserRoleOwner && user.Role != UserRoleDemo {
+		errs.Set("role", fmt.Sprintf("Role must be empty, %q or %q", UserRoleOwner, UserRoleDemo))
+	}
+
+	return errs
+}
+
+// userFromBody parses a single user response
+func userFromBody(resp *http.Response, body []byte) (*UserModel, error) {
+	if resp == nil || len(body) == 0 {
+		return nil, APIErr("No user data found", fmt.Errorf("empty response"))
+	}
+
+	var apiResp UserSingle
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, APIErr("Failed to parse user response", err)
+	}
+
+	user := userModelFromRead(apiResp.Data)
+	return &user, nil
+}
+
+// ListUsers retrieves the users of the Firefly III instance with pagination
+func (c *FireflyClient) ListUsers(ctx context.Context, page, limit int) ([]UserModel, error) {
+	page32, limit32 := pageParams(page, limit)
+
+	// Call the API
+	resp, err := c.clientAPI.ListUserWithResponse(ctx, &ListUserParams{
+		Page:  page32,
+		Limit: limit32,
+	})
+	if err != nil {
+		return nil, APIErr("Failed to list users", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK {
+		return nil, adminResponseErr("User", resp.HTTPResponse, resp.Body)
+	}
+
+	// Convert API response to UserModels
+	if resp.HTTPResponse == nil || len(resp.Body) == 0 {
+		return []UserModel{}, nil
+	}
+
+	var apiResp UserArray
+	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
+		return nil, APIErr("Failed to parse users response", err)
+	}
+
+	users := make([]UserModel, 0, len(apiResp.Data))
+	for _, userRead := range apiResp.Data {
+		users = append(users, userModelFromRead(userRead))
+	}
+
+	return users, nil
+}
+
+// GetUser retrieves a single user by ID
+func (c *FireflyClient) GetUser(ctx context.Context, id string) (*UserModel, error) {
+	// Call the API
+	resp, err := c.clientAPI.GetUserWithResponse(ctx, id, &GetUserParams{})
+	if err != nil {
+		return nil, APIErr("Failed to get user", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK {
+		return nil, adminResponseErr("User", resp.HTTPResponse, resp.Body)
+	}
+
+	return userFromBody(resp.HTTPResponse, resp.Body)
+}
+
+// CreateUser creates a new user and returns it with its ID
+func (c *FireflyClient) CreateUser(ctx context.Context, user UserModel) (*UserModel, error) {
+	// Validate user
+	if errs := validateUser(user); errs != nil {
+		return nil, ValidationErr("User", errs)
+	}
+
+	// Call the API
+	resp, err := c.clientAPI.StoreUserWithResponse(ctx, &StoreUserParams{}, userToAPI(user))
+	if err != nil {
+		return nil, APIErr("Failed to create user", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusCreated {
+		return nil, adminResponseErr("User", resp.HTTPResponse, resp.Body)
+	}
+
+	return userFromBody(resp.HTTPResponse, resp.Body)
+}
+
+// UpdateUser updates an existing user
+func (c *FireflyClient) UpdateUser(ctx context.Context, id string, user UserModel) error {
+	// Validate user
+	if errs := validateUser(user); errs != nil {
+		return ValidationErr("User", errs)
+	}
+
+	// Call the API
+	resp, err := c.clientAPI.UpdateUserWithResponse(ctx, id, &UpdateUserParams{}, userToAPI(user))
+	if err != nil {
+		return APIErr("Failed to update user", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK {
+		return adminResponseErr("User", resp.HTTPResponse, resp.Body)
+	}
+
+	return nil
+}
+
+// DeleteUser deletes a user and all of their data
+func (c *FireflyClient) DeleteUser(ctx context.Context, id string) error {
+	// Call the API
+	resp, err := c.clientAPI.DeleteUserWithResponse(ctx, id, &DeleteUserParams{})
+	if err != nil {
+		return APIErr("Failed to delete user", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusNoContent {
+		return adminResponseErr("User", resp.HTTPResponse, resp.Body)
+	}
+
+	return nil
+}
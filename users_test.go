@@ -0,0 +1,121 @@
+package firefly
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ZanzyTHEbar/errbuilder-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZanzyTHEbar/fireflyiii-client-go/fireflytest"
+)
+
+// userJSON is the API representation of the owner of an instance
+const userJSON = `{
+	"type": "users",
+	"id": "1",
+	"attributes": {
+		"created_at": "2024-01-10T12:00:00+01:00",
+		"updated_at": "2024-01-10T12:00:00+01:00",
+		"email": "admin@example.com",
+		"blocked": false,
+		"blocked_code": null,
+		"role": "owner"
+	}
+}`
+
+// TestUsers tests the user administration methods
+func TestUsers(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/users", http.StatusOK, `{"data": [`+userJSON+`, {"type": "users", "id": "2", "attributes": {
+		"email": "blocked@example.com", "blocked": true, "blocked_code": "email_changed", "role": null
+	}}], "meta": {}}`)
+	server.Handle(http.MethodGet, "/v1/users/1", http.StatusOK, `{"data": `+userJSON+`}`)
+	server.Handle(http.MethodPost, "/v1/users", http.StatusOK, `{"data": {"type": "users", "id": "3", "attributes": {"email": "new@example.com", "blocked": false, "role": null}}}`)
+	server.Handle(http.MethodPut, "/v1/users/3", http.StatusOK, `{"data": {"type": "users", "id": "3", "attributes": {"email": "new@example.com", "blocked": false, "role": "demo"}}}`)
+	server.Handle(http.MethodDelete, "/v1/users/3", http.StatusNoContent, "")
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("list", func(t *testing.T) {
+		users, err := client.ListUsers(ctx, 1, 50)
+		require.NoError(t, err)
+		require.Len(t, users, 2)
+		assert.Equal(t, UserModel{
+			ID:        "1",
+			Email:     "admin@example.com",
+			Role:      UserRoleOwner,
+			CreatedAt: time.Date(2024, 1, 10, 12, 0, 0, 0, time.FixedZone("", 1*60*60)),
+			UpdatedAt: time.Date(2024, 1, 10, 12, 0, 0, 0, time.FixedZone("", 1*60*60)),
+		}, users[0])
+		assert.Equal(t, "blocked@example.com", users[1].Email)
+		assert.Empty(t, users[1].Role)
+		assert.True(t, users[1].Blocked)
+		assert.Equal(t, "email_changed", users[1].BlockedCode)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		user, err := client.GetUser(ctx, "1")
+		require.NoError(t, err)
+		assert.Equal(t, "admin@example.com", user.Email)
+	})
+
+	t.Run("create, update and delete", func(t *testing.T) {
+		user, err := client.CreateUser(ctx, UserModel{Email: "new@example.com"})
+		require.NoError(t, err)
+		assert.Equal(t, "3", user.ID)
+
+		stores := server.RequestsTo(http.MethodPost, "/v1/users")
+		require.Len(t, stores, 1)
+		var store User
+		require.NoError(t, stores[0].JSON(&store))
+		assert.Equal(t, "new@example.com", string(store.Email))
+		assert.Nil(t, store.Role)
+
+		require.NoError(t, client.UpdateUser(ctx, "3", UserModel{Email: "new@example.com", Role: UserRoleDemo}))
+		updates := server.RequestsTo(http.MethodPut, "/v1/users/3")
+		require.Len(t, updates, 1)
+		var update User
+		require.NoError(t, updates[0].JSON(&update))
+		require.NotNil(t, update.Role)
+		assert.Equal(t, UserRolePropertyDemo, *update.Role)
+
+		require.NoError(t, client.DeleteUser(ctx, "3"))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		server.Reset()
+		_, err := client.CreateUser(ctx, UserModel{Role: "admin"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Email is required")
+		assert.Contains(t, err.Error(), "Role must be empty")
+		assert.Empty(t, server.Requests())
+	})
+}
+
+// TestUsersForbidden tests that tokens without the owner role get a clear authorization error
+func TestUsersForbidden(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/users", http.StatusForbidden, `{"message": "You need the \"owner\"-role to do this.", "exception": "AuthorizationException"}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	_, err = client.ListUsers(context.Background(), 1, 50)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Admin Permission Required")
+
+	var builderErr *errbuilder.ErrBuilder
+	require.True(t, errors.As(err, &builderErr))
+	assert.Equal(t, errbuilder.CodePermissionDenied, builderErr.Code)
+
+	var httpErr *HTTPError
+	require.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusForbidden, httpErr.StatusCode)
+}
@@ -0,0 +1,117 @@
+package firefly
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultWebhookDedupEntries is how many deliveries are remembered when WebhookDedupConfig.MaxEntries is not set
+const DefaultWebhookDedupEntries = 10000
+
+// WebhookDedupConfig configures how WebhookManager drops redelivered webhook events
+type WebhookDedupConfig struct {
+	// Window is how long a delivery is remembered. Zero or less disables deduplication.
+	Window time.Duration
+
+	// MaxEntries bounds the remembered deliveries, the oldest are forgotten first.
+	// Zero means DefaultWebhookDedupEntries.
+	MaxEntries int
+
+	// ByBodyHash also drops deliveries whose payload was seen before, which covers events without an ID
+	ByBodyHash bool
+}
+
+// webhookDedup remembers recently delivered webhook events
+type webhookDedup struct {
+	mu         sync.Mutex
+	window     time.Duration
+	maxEntries int
+	byBodyHash bool
+	seen       map[string]time.Time // key -> expiry
+	order      []string             // keys in delivery order, oldest first
+	now        func() time.Time
+}
+
+// newWebhookDedup creates a deduplicator for the given configuration
+func newWebhookDedup(config WebhookDedupConfig) *webhookDedup {
+	maxEntries := config.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = DefaultWebhookDedupEntries
+	}
+	return &webhookDedup{
+		window:     config.Window,
+		maxEntries: maxEntries,
+		byBodyHash: config.ByBodyHash,
+		seen:       make(map[string]time.Time),
+		now:        time.Now,
+	}
+}
+
+// keys returns the keys identifying a delivery
+func (d *webhookDedup) keys(event *WebhookEvent, payload []byte) []string {
+	var keys []string
+	if event.ID != "" {
+		keys = append(keys, "id:"+event.ID)
+	}
+	if d.byBodyHash {
+		sum := sha256.Sum256(payload)
+		keys = append(keys, "body:"+hex.EncodeToString(sum[:]))
+	}
+	return keys
+}
+
+// Seen reports whether any of the keys was delivered within the window, and records them otherwise
+func (d *webhookDedup) Seen(keys []string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	d.expire(now)
+
+	for _, key := range keys {
+		if expiry, ok := d.seen[key]; ok && now.Before(expiry) {
+			return true
+		}
+	}
+
+	for _, key := range keys {
+		d.seen[key] = now.Add(d.window)
+		d.order = append(d.order, key)
+	}
+	for len(d.seen) > d.maxEntries && len(d.order) > 0 {
+		delete(d.seen, d.order[0])
+		d.order = d.order[1:]
+	}
+	return false
+}
+
+// Forget removes the keys, so that a redelivery of a failed event is processed again
+func (d *webhookDedup) Forget(keys []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, key := range keys {
+		if _, ok := d.seen[key]; !ok {
+			continue
+		}
+		delete(d.seen, key)
+		if i := slices.Index(d.order, key); i >= 0 {
+			d.order = slices.Delete(d.order, i, i+1)
+		}
+	}
+}
+
+// expire drops the keys whose window has passed. Keys expire in delivery order.
+func (d *webhookDedup) expire(now time.Time) {
+	for len(d.order) > 0 {
+		key := d.order[0]
+		if expiry, ok := d.seen[key]; ok && now.Before(expiry) {
+			return
+		}
+		delete(d.seen, key)
+		d.order = d.order[1:]
+	}
+}
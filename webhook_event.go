@@ -0,0 +1,78 @@
+package firefly
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// webhookTimestampLayouts are the timestamp layouts accepted in webhook events, besides Unix
+// seconds. Firefly III and Laravel write RFC 3339 with or without fractional seconds, and
+// database timestamps without the "T" or a zone, which are read as UTC.
+var webhookTimestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	time.DateOnly,
+}
+
+// UnmarshalJSON decodes a webhook event. The timestamp may be in any of the formats Firefly III
+// emits, including Unix seconds as a number or string. An unreadable timestamp does not fail the
+// event: Timestamp is set to the time of decoding instead, and WebhookManager logs a warning.
+func (e *WebhookEvent) UnmarshalJSON(data []byte) error {
+	type plainEvent WebhookEvent
+	aux := struct {
+		*plainEvent
+		Timestamp json.RawMessage `json:"timestamp"`
+	}{plainEvent: (*plainEvent)(e)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	timestamp, err := parseWebhookTimestamp(aux.Timestamp)
+	if err != nil {
+		e.Timestamp = time.Now()
+		e.timestampErr = err
+		return nil
+	}
+	e.Timestamp = timestamp
+	e.timestampErr = nil
+	return nil
+}
+
+// parseWebhookTimestamp parses a JSON timestamp value. A missing or null timestamp is the zero time.
+func parseWebhookTimestamp(raw json.RawMessage) (time.Time, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return time.Time{}, nil
+	}
+
+	var value string
+	if raw[0] == '"' {
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return time.Time{}, fmt.Errorf("invalid timestamp %s: %w", raw, err)
+		}
+		if value == "" {
+			return time.Time{}, nil
+		}
+	} else {
+		value = string(raw)
+	}
+
+	// Unix seconds, possibly with a fraction
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		whole := int64(seconds)
+		return time.Unix(whole, int64((seconds-float64(whole))*1e9)).UTC(), nil
+	}
+
+	for _, layout := range webhookTimestampLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported timestamp format %q", value)
+}
@@ -0,0 +1,87 @@
+package firefly
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWebhookEventTimestampFormats tests that the timestamp formats Firefly III emits are all read
+func TestWebhookEventTimestampFormats(t *testing.T) {
+	want := time.Date(2024, 3, 1, 12, 30, 45, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		timestamp string
+		want      time.Time
+	}{
+		{"RFC 3339", `"2024-03-01T12:30:45Z"`, want},
+		{"RFC 3339 with offset", `"2024-03-01T14:30:45+02:00"`, want},
+		{"RFC 3339 with microseconds", `"2024-03-01T12:30:45.000000Z"`, want},
+		{"offset without colon", `"2024-03-01T14:30:45+0200"`, want},
+		{"database timestamp", `"2024-03-01 12:30:45"`, want},
+		{"database timestamp with offset", `"2024-03-01 14:30:45+02:00"`, want},
+		{"local timestamp", `"2024-03-01T12:30:45"`, want},
+		{"date", `"2024-03-01"`, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"Unix seconds", `1709296245`, want},
+		{"Unix seconds as string", `"1709296245"`, want},
+		{"Unix seconds with fraction", `1709296245.5`, want.Add(500 * time.Millisecond)},
+		{"null", `null`, time.Time{}},
+		{"empty", `""`, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var event WebhookEvent
+			payload := fmt.Sprintf(`{"id": "1", "type": "transaction.created", "timestamp": %s, "data": {"transaction_id": "123"}}`, tt.timestamp)
+			require.NoError(t, json.Unmarshal([]byte(payload), &event))
+
+			assert.True(t, tt.want.Equal(event.Timestamp), "got %s", event.Timestamp)
+			assert.NoError(t, event.timestampErr)
+			assert.Equal(t, "1", event.ID)
+			assert.Equal(t, "123", event.Data["transaction_id"])
+		})
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		var event WebhookEvent
+		require.NoError(t, json.Unmarshal([]byte(`{"id": "1", "type": "transaction.created"}`), &event))
+		assert.True(t, event.Timestamp.IsZero())
+	})
+}
+
+// TestWebhookEventTimestampFallback tests that an unreadable timestamp falls back to the receive time
+// with a warning instead of failing the event
+func TestWebhookEventTimestampFallback(t *testing.T) {
+	var warnings []string
+	manager := NewWebhookManager()
+	manager.SetLogger(func(format string, args ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	})
+
+	var received *WebhookEvent
+	manager.RegisterHandlerFunc("transaction.created", func(ctx context.Context, event *WebhookEvent) error {
+		received = event
+		return nil
+	})
+
+	before := time.Now()
+	err := manager.ProcessWebhook(context.Background(), []byte(`{"id": "evt-1", "type": "transaction.created", "timestamp": "next tuesday"}`))
+	require.NoError(t, err)
+
+	require.NotNil(t, received)
+	assert.False(t, received.Timestamp.Before(before))
+	assert.False(t, received.Timestamp.After(time.Now()))
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "evt-1")
+	assert.Contains(t, warnings[0], "next tuesday")
+
+	// Other invalid fields still fail the event
+	err = manager.ProcessWebhook(context.Background(), []byte(`{"id": 5, "type": "transaction.created"}`))
+	assert.Error(t, err)
+}
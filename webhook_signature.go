@@ -0,0 +1,84 @@
+package firefly
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha3"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebhookSignatureHeader is the request header in which Firefly III sends the signature of a webhook delivery
+const WebhookSignatureHeader = "Signature"
+
+// Reasons a webhook delivery fails signature verification
+var (
+	errWebhookSignatureMissing   = errors.New("webhook signature missing")
+	errWebhookSignatureMalformed = errors.New("webhook signature header malformed")
+	errWebhookSignatureMismatch  = errors.New("webhook signature does not match")
+	errWebhookSignatureExpired   = errors.New("webhook signature timestamp outside tolerance")
+)
+
+// webhookSignatureHashes are the hashes accepted for the HMAC of a webhook signature.
+// Firefly III signs with SHA3-256; SHA-256 is accepted for proxies and test senders.
+var webhookSignatureHashes = []func() hash.Hash{
+	func() hash.Hash { return sha3.New256() },
+	sha256.New,
+}
+
+// verifyWebhookSignature checks a Signature header of the form "t=<unix seconds>,v1=<hex HMAC>"
+// against the body. The HMAC is computed over "<t>.<body>" with secret. A tolerance greater than
+// zero rejects signatures whose timestamp is further than tolerance from now, so captured
+// deliveries cannot be replayed later.
+func verifyWebhookSignature(header string, body []byte, secret string, tolerance time.Duration, now time.Time) error {
+	if header == "" {
+		return errWebhookSignatureMissing
+	}
+
+	var timestamp string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return errWebhookSignatureMalformed
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signature, err := hex.DecodeString(value)
+			if err != nil {
+				return errWebhookSignatureMalformed
+			}
+			signatures = append(signatures, signature)
+		}
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return errWebhookSignatureMalformed
+	}
+
+	if tolerance > 0 {
+		age := now.Sub(time.Unix(seconds, 0))
+		if age > tolerance || age < -tolerance {
+			return errWebhookSignatureExpired
+		}
+	}
+
+	for _, newHash := range webhookSignatureHashes {
+		mac := hmac.New(newHash, []byte(secret))
+		mac.Write([]byte(timestamp + "."))
+		mac.Write(body)
+		expected := mac.Sum(nil)
+		for _, signature := range signatures {
+			if hmac.Equal(signature, expected) {
+				return nil
+			}
+		}
+	}
+	return errWebhookSignatureMismatch
+}
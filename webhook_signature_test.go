@@ -0,0 +1,97 @@
+package firefly
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha3"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// signWebhook builds a Signature header for body the way Firefly III does
+func signWebhook(newHash func() hash.Hash, secret string, timestamp time.Time, body string) string {
+	t := fmt.Sprint(timestamp.Unix())
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write([]byte(t + "." + body))
+	return fmt.Sprintf("t=%s,v1=%s", t, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// TestWebhookServerSignature tests that a webhook server with a secret only processes correctly signed deliveries
+func TestWebhookServerSignature(t *testing.T) {
+	const secret = "webhook-secret"
+	const payload = `{"id": "evt-1", "type": "transaction.created", "data": {"transaction_id": "7"}}`
+	now := time.Now()
+	sha3256 := func() hash.Hash { return sha3.New256() }
+
+	tests := []struct {
+		name      string
+		signature string
+		body      string
+		want      int
+	}{
+		{"valid", signWebhook(sha256.New, secret, now, payload), payload, http.StatusOK},
+		{"valid SHA3-256", signWebhook(sha3256, secret, now, payload), payload, http.StatusOK},
+		{"valid among several", "t=" + fmt.Sprint(now.Unix()) + ",v1=00ff,v1=" + strings.SplitN(signWebhook(sha256.New, secret, now, payload), "v1=", 2)[1], payload, http.StatusOK},
+		{"wrong secret", signWebhook(sha256.New, "other-secret", now, payload), payload, http.StatusUnauthorized},
+		{"modified body", signWebhook(sha256.New, secret, now, payload), strings.Replace(payload, `"7"`, `"8"`, 1), http.StatusUnauthorized},
+		{"missing", "", payload, http.StatusUnauthorized},
+		{"expired", signWebhook(sha256.New, secret, now.Add(-10*time.Minute), payload), payload, http.StatusUnauthorized},
+		{"no timestamp", "v1=00ff", payload, http.StatusBadRequest},
+		{"no signature", "t=" + fmt.Sprint(now.Unix()), payload, http.StatusBadRequest},
+		{"not hex", "t=" + fmt.Sprint(now.Unix()) + ",v1=xyz", payload, http.StatusBadRequest},
+		{"garbage", "sha256 abcdef", payload, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewWebhookManager()
+			handled := 0
+			manager.RegisterHandlerFunc("transaction.created", func(ctx context.Context, event *WebhookEvent) error {
+				handled++
+				return nil
+			})
+			server := NewWebhookServer(":0", "/webhook", secret, manager)
+			server.SetSignatureTolerance(5 * time.Minute)
+
+			req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(tt.body))
+			if tt.signature != "" {
+				req.Header.Set(WebhookSignatureHeader, tt.signature)
+			}
+			rec := httptest.NewRecorder()
+			server.handleWebhook(rec, req)
+
+			assert.Equal(t, tt.want, rec.Code)
+			if tt.want == http.StatusOK {
+				assert.Equal(t, 1, handled)
+			} else {
+				assert.Zero(t, handled, "rejected deliveries are not processed")
+			}
+		})
+	}
+
+	t.Run("without tolerance", func(t *testing.T) {
+		server := NewWebhookServer(":0", "/webhook", secret, nil)
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(payload))
+		req.Header.Set(WebhookSignatureHeader, signWebhook(sha256.New, secret, now.Add(-24*time.Hour), payload))
+		rec := httptest.NewRecorder()
+		server.handleWebhook(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("without secret", func(t *testing.T) {
+		server := NewWebhookServer(":0", "/webhook", "", nil)
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(payload))
+		rec := httptest.NewRecorder()
+		server.handleWebhook(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
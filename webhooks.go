@@ -0,0 +1,142 @@
+package firefly
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ZanzyTHEbar/errbuilder-go"
+)
+
+// WebhookMessageModel is a message Firefly III queued for a webhook, with its delivery status
+type WebhookMessageModel struct {
+	ID        string
+	WebhookID string
+	UUID      string
+	Sent      bool   // Delivered successfully
+	Errored   bool   // Delivery failed, see the message's attempts for why
+	Message   string // JSON payload sent to the webhook URL
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// WebhookAttemptModel is one attempt to deliver a webhook message
+type WebhookAttemptModel struct {
+	ID         string
+	MessageID  string
+	StatusCode int    // HTTP status code of the receiver, 0 if it could not be reached
+	Response   string // Response of the receiver, may contain sensitive data
+	Logs       string // Firefly III's log of the attempt, may contain sensitive data
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// ListWebhookMessages retrieves the messages Firefly III sent or tried to send to a webhook
+func (c *FireflyClient) ListWebhookMessages(ctx context.Context, webhookID string) ([]WebhookMessageModel, error) {
+	if webhookID == "" {
+		var errs errbuilder.ErrorMap
+		errs.Set("webhookID", "Webhook ID is required")
+		return nil, ValidationErr("Webhook", errs)
+	}
+
+	// Call the API
+	resp, err := c.clientAPI.GetWebhookMessagesWithResponse(ctx, webhookID, &GetWebhookMessagesParams{})
+	if err != nil {
+		return nil, APIErr("Failed to list webhook messages", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseErr("Webhook", resp.HTTPResponse, resp.Body)
+	}
+
+	if len(resp.Body) == 0 {
+		return []WebhookMessageModel{}, nil
+	}
+
+	var apiResp WebhookMessageArray
+	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
+		return nil, APIErr("Failed to parse webhook messages response", err)
+	}
+
+	messages := make([]WebhookMessageModel, 0, len(apiResp.Data))
+	for _, messageRead := range apiResp.Data {
+		messages = append(messages, WebhookMessageModel{
+			ID:        messageRead.Id,
+			WebhookID: stringValue(messageRead.Attributes.WebhookId),
+			UUID:      stringValue(messageRead.Attributes.Uuid),
+			Sent:      boolValue(messageRead.Attributes.Sent),
+			Errored:   boolValue(messageRead.Attributes.Errored),
+			Message:   stringValue(messageRead.Attributes.Message),
+			CreatedAt: timeValue(messageRead.Attributes.CreatedAt),
+			UpdatedAt: timeValue(messageRead.Attributes.UpdatedAt),
+		})
+	}
+
+	return messages, nil
+}
+
+// ListWebhookAttempts retrieves all delivery attempts of a webhook message, reading all pages
+func (c *FireflyClient) ListWebhookAttempts(ctx context.Context, webhookID, messageID string) ([]WebhookAttemptModel, error) {
+	var errs errbuilder.ErrorMap
+	if webhookID == "" {
+		errs.Set("webhookID", "Webhook ID is required")
+	}
+	message, err := strconv.Atoi(messageID)
+	if err != nil {
+		errs.Set("messageID", fmt.Sprintf("Invalid message ID %q", messageID))
+	}
+	if errs != nil {
+		return nil, ValidationErr("Webhook", errs)
+	}
+
+	return listAllPages(ctx, func(ctx context.Context, page, limit int) ([]WebhookAttemptModel, error) {
+		return c.listWebhookAttempts(ctx, webhookID, message, page, limit)
+	})
+}
+
+// listWebhookAttempts retrieves a page of delivery attempts of a webhook message
+func (c *FireflyClient) listWebhookAttempts(ctx context.Context, webhookID string, messageID, page, limit int) ([]WebhookAttemptModel, error) {
+	page32, limit32 := pageParams(page, limit)
+
+	// Call the API
+	resp, err := c.clientAPI.GetWebhookMessageAttemptsWithResponse(ctx, webhookID, messageID, &GetWebhookMessageAttemptsParams{
+		Page:  page32,
+		Limit: limit32,
+	})
+	if err != nil {
+		return nil, APIErr("Failed to list webhook attempts", err)
+	}
+
+	// Check response
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseErr("Webhook", resp.HTTPResponse, resp.Body)
+	}
+
+	if len(resp.Body) == 0 {
+		return []WebhookAttemptModel{}, nil
+	}
+
+	var apiResp WebhookAttemptArray
+	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
+		return nil, APIErr("Failed to parse webhook attempts response", err)
+	}
+
+	attempts := make([]WebhookAttemptModel, 0, len(apiResp.Data))
+	for _, attemptRead := range apiResp.Data {
+		attempts = append(attempts, WebhookAttemptModel{
+			ID:         attemptRead.Id,
+			MessageID:  stringValue(attemptRead.Attributes.WebhookMessageId),
+			StatusCode: int(int32Value(attemptRead.Attributes.StatusCode)),
+			Response:   stringValue(attemptRead.Attributes.Response),
+			Logs:       stringValue(attemptRead.Attributes.Logs),
+			CreatedAt:  timeValue(attemptRead.Attributes.CreatedAt),
+			UpdatedAt:  timeValue(attemptRead.Attributes.UpdatedAt),
+		})
+	}
+
+	return attempts, nil
+}
@@ -0,0 +1,74 @@
+package firefly
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZanzyTHEbar/fireflyiii-client-go/fireflytest"
+)
+
+// TestListWebhookMessages tests reading the delivery status of webhook messages
+func TestListWebhookMessages(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/webhooks/4/messages", http.StatusOK, `{"data": [
+		{"type": "webhook_messages", "id": "10", "attributes": {"webhook_id": "4", "uuid": "2f1b3c4d-0000-4000-8000-000000000010", "sent": true, "errored": false, "message": "{\"id\": 1}", "created_at": "2024-03-01T12:00:00+00:00"}},
+		{"type": "webhook_messages", "id": "11", "attributes": {"webhook_id": "4", "uuid": "2f1b3c4d-0000-4000-8000-000000000011", "sent": false, "errored": true, "message": "{\"id\": 2}", "created_at": "2024-03-02T12:00:00+00:00"}}
+	], "meta": {}}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	messages, err := client.ListWebhookMessages(context.Background(), "4")
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+
+	assert.Equal(t, "10", messages[0].ID)
+	assert.True(t, messages[0].Sent)
+	assert.False(t, messages[0].Errored)
+	assert.Equal(t, "11", messages[1].ID)
+	assert.Equal(t, "4", messages[1].WebhookID)
+	assert.False(t, messages[1].Sent)
+	assert.True(t, messages[1].Errored)
+	assert.Equal(t, `{"id": 2}`, messages[1].Message)
+	assert.Equal(t, time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC), messages[1].CreatedAt.UTC())
+}
+
+// TestListWebhookAttemptsFailed tests parsing the attempts of a message whose delivery failed
+func TestListWebhookAttemptsFailed(t *testing.T) {
+	server := fireflytest.NewServer(t)
+	server.Handle(http.MethodGet, "/v1/webhooks/4/messages/11/attempts", http.StatusOK, `{"data": [
+		{"type": "webhook_attempts", "id": "31", "attributes": {"webhook_message_id": "11", "status_code": 502, "response": "Bad Gateway", "logs": "Server error response", "created_at": "2024-03-02T12:00:05+00:00"}},
+		{"type": "webhook_attempts", "id": "32", "attributes": {"webhook_message_id": "11", "status_code": null, "response": null, "logs": "cURL error 28: Connection timed out", "created_at": "2024-03-02T12:05:05+00:00"}}
+	], "meta": {}}`)
+
+	client, err := NewFireflyClient(server.URL, "test-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	attempts, err := client.ListWebhookAttempts(ctx, "4", "11")
+	require.NoError(t, err)
+	require.Len(t, attempts, 2)
+
+	assert.Equal(t, WebhookAttemptModel{
+		ID:         "31",
+		MessageID:  "11",
+		StatusCode: http.StatusBadGateway,
+		Response:   "Bad Gateway",
+		Logs:       "Server error response",
+		CreatedAt:  attempts[0].CreatedAt,
+	}, attempts[0])
+	assert.Equal(t, time.Date(2024, 3, 2, 12, 0, 5, 0, time.UTC), attempts[0].CreatedAt.UTC())
+	assert.Zero(t, attempts[1].StatusCode, "unreachable receivers have no status code")
+	assert.Empty(t, attempts[1].Response)
+	assert.Contains(t, attempts[1].Logs, "timed out")
+
+	// Message IDs are numeric
+	_, err = client.ListWebhookAttempts(ctx, "4", "abc")
+	assert.Error(t, err)
+	assert.Len(t, server.RequestsTo(http.MethodGet, "/v1/webhooks/4/messages/11/attempts"), 1)
+}